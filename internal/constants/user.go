@@ -40,6 +40,7 @@ var (
 	ErrorUserNotExist      = errors.New("user not exists")
 	ErrorUserSignUpFail    = errors.New("user sign up fail")
 	ErrorPasswordIncorrect = errors.New("user password incorrect")
+	ErrorAccountLocked     = errors.New("user account locked")
 
 	// TreeService
 	// Node DAO
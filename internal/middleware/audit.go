@@ -90,6 +90,8 @@ func (m *AuditLogMiddleware) AuditLog() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 跳过登录接口的审计
 		if c.Request.URL.Path == "/api/user/login" ||
+			c.Request.URL.Path == "/metrics" ||
+			c.Request.URL.Path == "/healthz" ||
 			c.Request.URL.Path == "/api/user/logout" ||
 			c.Request.URL.Path == "/api/user/refresh_token" ||
 			c.Request.URL.Path == "/api/user/signup" ||
@@ -51,6 +51,8 @@ func (cm *CasbinMiddleware) CheckCasbin() gin.HandlerFunc {
 		path := c.Request.URL.Path
 		// 如果请求的路径是下述路径，则不进行权限验证
 		if path == "/api/user/login" ||
+			path == "/metrics" ||
+			path == "/healthz" ||
 			path == "/api/user/logout" ||
 			strings.Contains(path, "hello") ||
 			path == "/api/user/refresh_token" ||
@@ -52,6 +52,8 @@ func (m *JWTMiddleware) CheckLogin() gin.HandlerFunc {
 		path := ctx.Request.URL.Path
 		// 如果请求的路径是下述路径，则不进行token验证
 		if path == "/api/user/login" ||
+			path == "/metrics" ||
+			path == "/healthz" ||
 			//path == "/api/user/signup" ||   // 不允许用户自己注册账号
 			path == "/api/user/logout" ||
 			path == "/api/user/refresh_token" ||
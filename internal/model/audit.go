@@ -26,6 +26,8 @@
 package model
 
 import (
+	"encoding/json"
+
 	"gorm.io/datatypes"
 )
 
@@ -40,6 +42,8 @@ type AuditLog struct {
 	OperationType string         `json:"operation_type" gorm:"type:VARCHAR(20);index;not null;comment:操作类型"`
 	TargetType    string         `json:"target_type" gorm:"size:64;not null;comment:目标资源类型"`
 	TargetID      string         `json:"target_id" gorm:"size:255;index;comment:目标资源ID"`
+	Action        string         `json:"action" gorm:"size:64;index;comment:业务操作标识"`
+	Detail        string         `json:"detail" gorm:"type:text;comment:操作详情"`
 	StatusCode    int            `json:"status_code" gorm:"not null;comment:HTTP状态码"`
 	RequestBody   datatypes.JSON `json:"request_body" gorm:"type:json;comment:请求体"`
 	ResponseBody  datatypes.JSON `json:"response_body" gorm:"type:json;comment:响应体"`
@@ -64,8 +68,25 @@ type AuditLogDetail struct {
 type ListAuditLogsRequest struct {
 	PageNumber    int    `json:"page_number" validate:"required,min=1" gorm:"comment:页码"`
 	PageSize      int    `json:"page_size" validate:"required,min=1,max=100" gorm:"comment:每页大小"`
-	OperationType string `json:"operation_type" validate:"omitempty,oneof=CREATE UPDATE DELETE OTHER" gorm:"comment:操作类型过滤"`
+	OperationType string `json:"operation_type" validate:"omitempty,oneof=CREATE UPDATE DELETE OTHER ACTION" gorm:"comment:操作类型过滤"`
 	UserID        uint   `json:"user_id" validate:"omitempty,min=1" gorm:"comment:操作人ID过滤"`
+	Action        string `json:"action" validate:"omitempty,max=64" gorm:"comment:业务操作标识过滤"`
 	StartTime     int64  `json:"start_time" validate:"required" gorm:"comment:开始时间"`
 	EndTime       int64  `json:"end_time" validate:"required,gtfield=StartTime" gorm:"comment:结束时间"`
 }
+
+// MarshalJSON 序列化审计日志，启用 RFC3339 时间输出时追加 created_at_rfc3339
+func (a AuditLog) MarshalJSON() ([]byte, error) {
+	type alias AuditLog
+	if !RFC3339TimeEnabled() {
+		return json.Marshal(alias(a))
+	}
+
+	return json.Marshal(struct {
+		alias
+		CreatedAtRFC3339 string `json:"created_at_rfc3339,omitempty"`
+	}{
+		alias:            alias(a),
+		CreatedAtRFC3339: FormatUnixRFC3339(a.CreatedAt),
+	})
+}
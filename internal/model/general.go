@@ -26,6 +26,10 @@
 package model
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/plugin/soft_delete"
@@ -44,3 +48,126 @@ type ListReq struct {
 	Size   int    `json:"size" form:"size" binding:"required,min=1,max=100"`
 	Search string `json:"search" form:"search" binding:"omitempty"`
 }
+
+// Labels 标签键值对，数据库中以 JSON 对象存储
+type Labels map[string]string
+
+// Contains 判断标签是否包含 matchers 中的全部键值，matchers 为空时返回 true；
+// 抑制规则和发送组路由共用这一等值匹配语义
+func (l Labels) Contains(matchers Labels) bool {
+	for key, val := range matchers {
+		if l[key] != val {
+			return false
+		}
+	}
+	return true
+}
+
+func (l *Labels) Scan(value interface{}) error {
+	if value == nil {
+		*l = Labels{}
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("invalid type for Labels: %T", value)
+	}
+
+	trimmed := strings.TrimSpace(string(data))
+	if trimmed == "" {
+		*l = Labels{}
+		return nil
+	}
+
+	// 兼容旧数据格式 key=value|key=value
+	if !strings.HasPrefix(trimmed, "{") {
+		*l = parseLegacyLabels(trimmed)
+		return nil
+	}
+
+	labels := Labels{}
+	if err := json.Unmarshal(data, &labels); err != nil {
+		return fmt.Errorf("error unmarshaling Labels: %v", err)
+	}
+	*l = labels
+
+	return nil
+}
+
+func (l Labels) Value() (driver.Value, error) {
+	if l == nil {
+		return "{}", nil
+	}
+
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling Labels: %v", err)
+	}
+
+	return string(data), nil
+}
+
+// SilenceMatcher 静默匹配器，字段与 Alertmanager 静默 API 一致
+type SilenceMatcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	IsEqual bool   `json:"isEqual"`
+}
+
+// SilenceMatchers 静默匹配器列表，数据库中以 JSON 数组存储
+type SilenceMatchers []SilenceMatcher
+
+func (m *SilenceMatchers) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*m = SilenceMatchers{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("invalid type for SilenceMatchers: %T", value)
+	}
+
+	if len(strings.TrimSpace(string(data))) == 0 {
+		*m = SilenceMatchers{}
+		return nil
+	}
+
+	return json.Unmarshal(data, m)
+}
+
+func (m SilenceMatchers) Value() (driver.Value, error) {
+	if m == nil {
+		return "[]", nil
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling SilenceMatchers: %v", err)
+	}
+
+	return string(data), nil
+}
+
+// parseLegacyLabels 解析以 | 分隔的 key=value 旧格式标签，忽略格式不正确的项
+func parseLegacyLabels(s string) Labels {
+	labels := Labels{}
+	for _, pair := range strings.Split(s, "|") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			continue
+		}
+		labels[key] = value
+	}
+	return labels
+}
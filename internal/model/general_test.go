@@ -0,0 +1,76 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLabelsScan(t *testing.T) {
+	for _, c := range []struct {
+		name  string
+		value interface{}
+		want  Labels
+	}{
+		{"NULL", nil, Labels{}},
+		{"空字符串", "", Labels{}},
+		{"JSON 字节", []byte(`{"alertname":"cpu","severity":"critical"}`), Labels{"alertname": "cpu", "severity": "critical"}},
+		{"JSON 字符串", `{"instance":"node-1"}`, Labels{"instance": "node-1"}},
+		{"旧格式", "alertname=cpu|bad|=x|instance=node-1", Labels{"alertname": "cpu", "instance": "node-1"}},
+	} {
+		var got Labels
+		if err := got.Scan(c.value); err != nil {
+			t.Fatalf("%s: Scan 返回错误: %v", c.name, err)
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Fatalf("%s: 期望 %v, 实际 %v", c.name, c.want, got)
+		}
+	}
+
+	for _, value := range []interface{}{`{"alertname":`, []byte(`{"alertname":1}`), 42} {
+		var got Labels
+		if err := got.Scan(value); err == nil {
+			t.Fatalf("格式错误的值 %v 应返回错误", value)
+		}
+	}
+}
+
+func TestLabelsValue(t *testing.T) {
+	v, err := Labels(nil).Value()
+	if err != nil || v != "{}" {
+		t.Fatalf("nil 标签应存储为 {}, 实际 %v, %v", v, err)
+	}
+
+	v, err = Labels{"alertname": "cpu"}.Value()
+	if err != nil {
+		t.Fatalf("Value 返回错误: %v", err)
+	}
+	var back Labels
+	if err := back.Scan(v); err != nil || back["alertname"] != "cpu" {
+		t.Fatalf("存储后应能还原标签, 实际 %v, %v", back, err)
+	}
+}
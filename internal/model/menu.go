@@ -41,21 +41,47 @@ type Meta struct {
 }
 
 type Menu struct {
-	ID           int       `json:"id" gorm:"primaryKey;autoIncrement;comment:主键ID"`                                     // 主键ID，自增
-	CreatedAt    int64     `json:"created_at" gorm:"autoCreateTime;comment:创建时间"`                                       // 创建时间，自动记录
-	UpdatedAt    int64     `json:"updated_at" gorm:"autoUpdateTime;comment:更新时间"`                                       // 更新时间，自动记录
-	DeletedAt    int64     `json:"deleted_at" gorm:"index;default:0;comment:删除时间"`                                      // 软删除时间，使用普通索引
-	Name         string    `json:"name" gorm:"type:varchar(50);not null;comment:菜单显示名称"`                                // 菜单显示名称，非空
-	ParentID     int       `json:"parent_id" gorm:"default:0;comment:上级菜单ID,0表示顶级菜单"`                                 // 上级菜单ID,0表示顶级菜单
-	Path         string    `json:"path" gorm:"type:varchar(255);not null;comment:前端路由访问路径"`                            // 前端路由访问路径，非空
-	Component    string    `json:"component" gorm:"type:varchar(255);not null;comment:前端组件文件路径"`                       // 前端组件文件路径，非空
-	RouteName    string    `json:"route_name" gorm:"type:varchar(50);uniqueIndex:idx_route_del;not null;comment:前端路由名称"` // 前端路由名称，唯一且非空
-	Hidden       int8      `json:"hidden" gorm:"type:tinyint(1);default:0;comment:菜单是否隐藏 0显示 1隐藏"`                    // 菜单是否隐藏，使用int8节省空间
-	Redirect     string    `json:"redirect" gorm:"type:varchar(255);default:'';comment:重定向路径"`                         // 重定向路径
-	Meta         MetaField `json:"meta" gorm:"type:json;serializer:json;comment:菜单元数据"`                                // 菜单元数据，使用JSON存储
-	Children     []*Menu   `json:"children" gorm:"-"`                                                                  // 子菜单列表,不映射到数据库
-	Users        []*User   `json:"users" gorm:"many2many:user_menus;comment:关联用户"`                                    // 多对多关联用户
-	Roles        []*Role   `json:"roles" gorm:"many2many:role_menus;comment:关联角色"`                                    // 多对多关联角色
+	ID             int       `json:"id" gorm:"primaryKey;autoIncrement;comment:主键ID"`                                     // 主键ID，自增
+	CreatedAt      int64     `json:"created_at" gorm:"autoCreateTime;comment:创建时间"`                                       // 创建时间，自动记录
+	UpdatedAt      int64     `json:"updated_at" gorm:"autoUpdateTime;comment:更新时间"`                                       // 更新时间，自动记录
+	DeletedAt      int64     `json:"deleted_at" gorm:"index;uniqueIndex:idx_route_del,priority:2;default:0;comment:删除时间"` // 软删除时间，与路由名称组成唯一索引
+	Name           string    `json:"name" gorm:"type:varchar(50);not null;comment:菜单显示名称"`                                // 菜单显示名称，非空
+	ParentID       int       `json:"parent_id" gorm:"default:0;comment:上级菜单ID,0表示顶级菜单"`                                 // 上级菜单ID,0表示顶级菜单
+	SortOrder      int       `json:"sort_order" gorm:"default:0;comment:同级菜单排序,数值越小越靠前"`                            // 同级菜单排序,数值越小越靠前
+	Path           string    `json:"path" gorm:"type:varchar(255);not null;comment:前端路由访问路径"`                            // 前端路由访问路径，非空
+	Component      string    `json:"component" gorm:"type:varchar(255);not null;comment:前端组件文件路径"`                       // 前端组件文件路径，非空
+	RouteName      string    `json:"route_name" gorm:"type:varchar(50);uniqueIndex:idx_route_del,priority:1;not null;comment:前端路由名称"` // 前端路由名称，未删除的菜单中唯一且非空
+	PermissionCode string    `json:"permission_code" gorm:"type:varchar(100);index;default:'';comment:菜单关联的权限编码"`       // 菜单关联的权限编码,用于按权限构建可访问菜单
+	Hidden         int8      `json:"hidden" gorm:"type:tinyint(1);default:0;comment:菜单是否隐藏 0显示 1隐藏"`                    // 菜单是否隐藏，使用int8节省空间
+	Redirect       string    `json:"redirect" gorm:"type:varchar(255);default:'';comment:重定向路径"`                         // 重定向路径
+	Meta           MetaField `json:"meta" gorm:"type:json;serializer:json;comment:菜单元数据"`                                // 菜单元数据，使用JSON存储
+	Children       []*Menu   `json:"children" gorm:"-"`                                                                  // 子菜单列表,不映射到数据库
+	HasChildren    bool      `json:"has_children" gorm:"-"`                                                              // 是否存在子菜单,按深度裁剪时用于前端懒加载
+	Users          []*User   `json:"users" gorm:"many2many:user_menus;comment:关联用户"`                                    // 多对多关联用户
+	Roles          []*Role   `json:"roles" gorm:"many2many:role_menus;comment:关联角色"`                                    // 多对多关联角色
+}
+
+// MenuStats 菜单统计信息
+type MenuStats struct {
+	Total    int64 `json:"total"`     // 未删除的菜单总数
+	Hidden   int64 `json:"hidden"`    // 未删除且隐藏的菜单数
+	Deleted  int64 `json:"deleted"`   // 已软删除的菜单数
+	MaxDepth int   `json:"max_depth"` // 菜单树最大深度，仅有顶级菜单时为1
+}
+
+// MenuDuplicateGroup 共享同一路由名称或路径的未删除菜单分组
+type MenuDuplicateGroup struct {
+	Field string  `json:"field"` // 重复的字段，route_name 或 path
+	Value string  `json:"value"` // 重复的字段值
+	Menus []*Menu `json:"menus"` // 该分组内的菜单，按路由名称和ID升序
+}
+
+// MenuDeletePreview 删除菜单的预览结果，存在未删除的子孙菜单时删除会被拒绝
+type MenuDeletePreview struct {
+	Menu        *Menu   `json:"menu"`        // 待删除的菜单
+	Blocked     bool    `json:"blocked"`     // 是否会被拒绝删除
+	Reason      string  `json:"reason"`      // 拒绝删除的原因，未被拒绝时为空
+	Descendants []*Menu `json:"descendants"` // 阻止删除的未删除子孙菜单，按层级和ID排序
 }
 
 type CreateMenuRequest struct {
@@ -88,6 +114,20 @@ type UpdateMenuRequest struct {
 	Meta      MetaField `json:"meta"`                       // 元数据
 }
 
+type MoveMenuRequest struct {
+	ParentId  int `json:"parent_id" binding:"gte=0"`  // 新的父菜单ID,0表示移动为顶级菜单
+	SortOrder int `json:"sort_order" binding:"gte=0"` // 在新的同级菜单中的位置,从0开始
+}
+
+type SetMenuPermissionRequest struct {
+	PermissionCode string `json:"permission_code" binding:"max=100"` // 权限编码,为空表示取消关联
+}
+
+type SetMenuHiddenBulkRequest struct {
+	Ids    []int `json:"ids" binding:"required,min=1"` // 菜单ID列表
+	Hidden int   `json:"hidden" binding:"oneof=0 1"`   // 是否隐藏 0显示 1隐藏
+}
+
 type DeleteMenuRequest struct {
 	Id int `json:"id" binding:"required,gt=0"` // 菜单ID
 }
@@ -129,3 +169,21 @@ func (m *MetaField) Value() (driver.Value, error) {
 	}
 	return json.Marshal(m)
 }
+
+// MarshalJSON 序列化菜单，启用 RFC3339 时间输出时追加 created_at_rfc3339 和 updated_at_rfc3339
+func (m Menu) MarshalJSON() ([]byte, error) {
+	type alias Menu
+	if !RFC3339TimeEnabled() {
+		return json.Marshal(alias(m))
+	}
+
+	return json.Marshal(struct {
+		alias
+		CreatedAtRFC3339 string `json:"created_at_rfc3339,omitempty"`
+		UpdatedAtRFC3339 string `json:"updated_at_rfc3339,omitempty"`
+	}{
+		alias:            alias(m),
+		CreatedAtRFC3339: FormatUnixRFC3339(m.CreatedAt),
+		UpdatedAtRFC3339: FormatUnixRFC3339(m.UpdatedAt),
+	})
+}
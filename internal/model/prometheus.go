@@ -26,6 +26,10 @@
 package model
 
 import (
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+
 	"github.com/prometheus/alertmanager/template"
 )
 
@@ -37,6 +41,7 @@ type MonitorScrapePool struct {
 	DeletedAt             int64      `json:"deleted_at" gorm:"index:idx_deleted_at;default:0;comment:删除时间"`
 	Name                  string     `json:"name" binding:"required,min=1,max=50" gorm:"uniqueIndex:idx_name_deleted_at;size:100;not null;comment:pool池名称"`
 	PrometheusInstances   StringList `json:"prometheus_instances" gorm:"type:text;comment:Prometheus实例ID列表"`
+	PrometheusPort        int        `json:"prometheus_port" gorm:"default:9090;not null;comment:Prometheus实例端口"`
 	AlertManagerInstances StringList `json:"alert_manager_instances" gorm:"type:text;comment:AlertManager实例ID列表"`
 	UserID                int        `json:"user_id" gorm:"index;not null;comment:所属用户ID"`
 	ScrapeInterval        int        `json:"scrape_interval" gorm:"default:30;type:smallint;not null;comment:采集间隔(秒)"`
@@ -58,8 +63,8 @@ type MonitorScrapeJob struct {
 	ID                       int        `json:"id" gorm:"primaryKey;autoIncrement;comment:主键ID"`
 	CreatedAt                int64      `json:"created_at" gorm:"autoCreateTime;comment:创建时间"`
 	UpdatedAt                int64      `json:"updated_at" gorm:"autoUpdateTime;comment:更新时间"`
-	DeletedAt                int64      `json:"deleted_at" gorm:"index:idx_deleted_at;default:0;comment:删除时间"`
-	Name                     string     `json:"name" binding:"required,min=1,max=50" gorm:"uniqueIndex:idx_name_deleted_at;size:100;comment:采集任务名称"`
+	DeletedAt                int64      `json:"deleted_at" gorm:"index:idx_deleted_at;uniqueIndex:idx_pool_name_deleted_at,priority:3;default:0;comment:删除时间"`
+	Name                     string     `json:"name" binding:"required,min=1,max=50" gorm:"uniqueIndex:idx_pool_name_deleted_at,priority:2;size:100;comment:采集任务名称"`
 	UserID                   int        `json:"user_id" gorm:"index;not null;comment:任务关联的用户ID"`
 	Enable                   bool       `json:"enable" gorm:"type:tinyint(1);default:1;not null;comment:是否启用采集任务"`
 	ServiceDiscoveryType     string     `json:"service_discovery_type" gorm:"size:50;not null;default:'http';comment:服务发现类型(k8s/http)"`
@@ -67,7 +72,7 @@ type MonitorScrapeJob struct {
 	Scheme                   string     `json:"scheme" gorm:"size:10;not null;default:'http';comment:监控采集的协议方案(http/https)"`
 	ScrapeInterval           int        `json:"scrape_interval" gorm:"default:30;not null;comment:采集的时间间隔(秒)"`
 	ScrapeTimeout            int        `json:"scrape_timeout" gorm:"default:10;not null;comment:采集的超时时间(秒)"`
-	PoolID                   int        `json:"pool_id" gorm:"index;not null;comment:关联的采集池ID"`
+	PoolID                   int        `json:"pool_id" gorm:"index;uniqueIndex:idx_pool_name_deleted_at,priority:1;not null;comment:关联的采集池ID"`
 	RelabelConfigsYamlString string     `json:"relabel_configs_yaml_string" gorm:"type:text;comment:relabel配置的YAML字符串"`
 	RefreshInterval          int        `json:"refresh_interval" gorm:"default:300;not null;comment:刷新目标的时间间隔(秒)"`
 	Port                     int        `json:"port" gorm:"default:9090;not null;comment:采集端口号"`
@@ -127,6 +132,51 @@ type MonitorAlertRule struct {
 	AnnotationsMap map[string]string `json:"annotations_map" gorm:"-"`
 }
 
+// AlertStatus 告警事件状态，取值即为落库的 status 字段值
+type AlertStatus string
+
+const (
+	AlertStatusFiring   AlertStatus = "firing"   // 触发中
+	AlertStatusResolved AlertStatus = "resolved" // 已恢复
+	AlertStatusUpgraded AlertStatus = "upgraded" // 已升级
+	AlertStatusSilenced AlertStatus = "已屏蔽"      // 已屏蔽
+	AlertStatusClaimed  AlertStatus = "已认领"      // 已认领
+)
+
+// validAlertStatuses 允许写入和筛选的告警事件状态
+var validAlertStatuses = map[AlertStatus]struct{}{
+	AlertStatusFiring:   {},
+	AlertStatusResolved: {},
+	AlertStatusUpgraded: {},
+	AlertStatusSilenced: {},
+	AlertStatusClaimed:  {},
+}
+
+// IsValidStatus 判断 s 是否为允许的告警事件状态
+func IsValidStatus(s string) bool {
+	_, ok := validAlertStatuses[AlertStatus(s)]
+	return ok
+}
+
+// 告警级别，取自告警的 severity 标签
+const (
+	AlertSeverityCritical = "critical" // 严重
+	AlertSeverityWarning  = "warning"  // 警告
+	AlertSeverityInfo     = "info"     // 信息
+
+	// DefaultAlertSeverity 告警未携带 severity 标签时使用的级别
+	DefaultAlertSeverity = AlertSeverityWarning
+)
+
+// SeverityFromLabels 从告警标签中解析告警级别，统一为小写，缺失时返回 DefaultAlertSeverity
+func SeverityFromLabels(labels Labels) string {
+	severity := strings.ToLower(strings.TrimSpace(labels["severity"]))
+	if severity == "" {
+		return DefaultAlertSeverity
+	}
+	return severity
+}
+
 // MonitorAlertEvent 告警事件与相关实体的关系
 type MonitorAlertEvent struct {
 	ID             int               `json:"id" gorm:"primaryKey;autoIncrement;comment:主键ID"`
@@ -135,20 +185,28 @@ type MonitorAlertEvent struct {
 	DeletedAt      int64             `json:"deleted_at" gorm:"index:idx_deleted_at;default:0;comment:删除时间"`
 	AlertName      string            `json:"alert_name" binding:"required,min=1,max=200" gorm:"size:200;not null;comment:告警名称"`
 	Fingerprint    string            `json:"fingerprint" binding:"required,min=1,max=50" gorm:"uniqueIndex:idx_fingerprint_deleted_at;size:100;not null;comment:告警唯一ID"`
-	Status         string            `json:"status" gorm:"size:50;not null;default:'firing';comment:告警状态(firing/silenced/claimed/resolved)"`
+	Status         string            `json:"status" gorm:"size:50;not null;default:'firing';comment:告警状态(firing/upgraded/已屏蔽/已认领/resolved)"`
+	Severity       string            `json:"severity" gorm:"size:20;index;not null;default:'warning';comment:告警级别(critical/warning/info),缺失时为warning"`
 	RuleID         int               `json:"rule_id" gorm:"index;not null;comment:关联的告警规则ID"`
 	SendGroupID    int               `json:"send_group_id" gorm:"index;not null;comment:关联的发送组ID"`
+	TeamID         int               `json:"team_id" gorm:"index;default:0;comment:所属团队ID"`
 	EventTimes     int               `json:"event_times" gorm:"not null;default:1;comment:触发次数"`
 	SilenceID      string            `json:"silence_id" gorm:"size:100;comment:AlertManager返回的静默ID"`
 	RenLingUserID  int               `json:"ren_ling_user_id" gorm:"index;comment:认领告警的用户ID"`
-	Labels         StringList        `json:"labels" gorm:"type:text;not null;comment:标签组,格式为key=value"`
+	AckUserID      int               `json:"ack_user_id" gorm:"index;comment:确认告警的用户ID"`
+	AckAt          int64             `json:"ack_at" gorm:"default:0;comment:确认告警时间"`
+	Version        int               `json:"version" gorm:"not null;default:0;comment:乐观锁版本号"`
+	LastNotifiedAt int64             `json:"last_notified_at" gorm:"default:0;comment:最近一次发送通知时间"`
+	FiredAt        int64             `json:"fired_at" gorm:"index;default:0;comment:本轮告警开始时间,恢复后重新触发时更新"`
+	ResolvedAt     int64             `json:"resolved_at" gorm:"index;default:0;comment:告警恢复时间,重新触发时清零"`
+	Labels         Labels            `json:"labels" gorm:"type:text;not null;comment:标签组,JSON对象"`
+	Annotations    Labels            `json:"annotations" gorm:"type:text;comment:注解(summary/description/runbook_url等),JSON对象"`
 	AlertRuleName  string            `json:"alert_rule_name" gorm:"-"`
 	SendGroupName  string            `json:"send_group_name" gorm:"-"`
 	Alert          template.Alert    `json:"alert" gorm:"-"`
 	SendGroup      *MonitorSendGroup `json:"send_group" gorm:"-"`
 	RenLingUser    *User             `json:"ren_ling_user" gorm:"-"`
 	Rule           *MonitorAlertRule `json:"rule" gorm:"-"`
-	LabelsMap      map[string]string `json:"labels_map" gorm:"-"`
 	AnnotationsMap map[string]string `json:"annotations_map" gorm:"-"`
 }
 
@@ -176,6 +234,12 @@ type MonitorRecordRule struct {
 	AnnotationsMap map[string]string `json:"annotations_map" gorm:"-"`
 }
 
+// 发送组机器人选择策略
+const (
+	RobotSelectRoundRobin      = "round_robin"      // 依次轮换
+	RobotSelectFingerprintHash = "fingerprint_hash" // 按告警指纹哈希，同一告警固定发往同一机器人
+)
+
 // MonitorSendGroup 发送组的配置
 type MonitorSendGroup struct {
 	ID                     int        `json:"id" gorm:"primaryKey;autoIncrement;comment:主键ID"`
@@ -188,8 +252,16 @@ type MonitorSendGroup struct {
 	UserID                 int        `json:"user_id" gorm:"index;not null;comment:创建该发送组的用户ID"`
 	PoolID                 int        `json:"pool_id" gorm:"index;not null;comment:关联的AlertManager实例ID"`
 	OnDutyGroupID          int        `json:"on_duty_group_id" gorm:"index;comment:值班组ID"`
+	TeamID                 int        `json:"team_id" gorm:"index;default:0;comment:所属团队ID,路由到该发送组的告警事件归属该团队"`
 	StaticReceiveUsers     []*User    `json:"static_receive_users" gorm:"many2many:monitor_send_group_static_receive_users;comment:静态配置的接收人列表"`
-	FeiShuQunRobotToken    string     `json:"fei_shu_qun_robot_token" gorm:"size:255;comment:飞书机器人Token"`
+	FeiShuQunRobotToken    string     `json:"fei_shu_qun_robot_token" gorm:"size:255;comment:群机器人Token,按channel_type对应的渠道解析"`
+	ChannelType            string     `json:"channel_type" gorm:"size:20;default:'feishu';comment:通知渠道类型(feishu/dingtalk),为空时使用飞书"`
+	FallbackRobotTokens    StringList `json:"fallback_robot_tokens" gorm:"type:text;comment:备用飞书机器人Token列表,主机器人发送失败时按顺序尝试"`
+	RobotTokens            StringList `json:"robot_tokens" gorm:"type:text;comment:分担负载的飞书机器人Token列表,与主机器人一起按选择策略轮换"`
+	RobotSelectStrategy    string     `json:"robot_select_strategy" binding:"omitempty,oneof=round_robin fingerprint_hash" gorm:"size:20;default:'';comment:机器人选择策略(round_robin/fingerprint_hash),为空时始终优先使用主机器人"`
+	MessageTemplate        string     `json:"message_template" gorm:"type:text;comment:飞书卡片消息模板,为空时使用全局默认模板"`
+	RouteMatchers          Labels     `json:"route_matchers" gorm:"type:text;comment:路由匹配标签,告警标签全部匹配时路由到该发送组"`
+	RoutePriority          int        `json:"route_priority" gorm:"default:0;comment:路由优先级,数值越小越优先匹配"`
 	RepeatInterval         string     `json:"repeat_interval" gorm:"size:50;default:'4h';comment:重复发送时间间隔"`
 	SendResolved           bool       `json:"send_resolved" gorm:"type:tinyint(1);default:1;not null;comment:是否发送恢复通知"`
 	NotifyMethods          StringList `json:"notify_methods" gorm:"type:text;comment:通知方法列表"` // 例如: ["email", "feishu", "dingtalk"]
@@ -260,6 +332,187 @@ type AlertEventSilenceRequest struct {
 	Time    string `json:"time"`
 }
 
+const (
+	AlertEventAuditActionClaim   = "claim"
+	AlertEventAuditActionUnclaim = "unclaim"
+)
+
+// AlertEventAudit 告警事件认领审计记录
+type AlertEventAudit struct {
+	ID        int    `json:"id" gorm:"primaryKey;autoIncrement;comment:主键ID"`
+	CreatedAt int64  `json:"created_at" gorm:"autoCreateTime;comment:操作时间"`
+	EventID   int    `json:"event_id" gorm:"index;not null;comment:告警事件ID"`
+	UserID    int    `json:"user_id" gorm:"index;not null;comment:操作人ID"`
+	Action    string `json:"action" gorm:"size:20;not null;comment:操作类型(claim/unclaim)"`
+}
+
+// AlertEventLabel 告警事件标签索引，每个标签一行，用于按标签键值查询事件；
+// 事件上的 Labels 字段仍保留完整标签用于展示。key 为 MySQL 保留字，列名使用 label_key/label_value
+type AlertEventLabel struct {
+	ID      int    `json:"id" gorm:"primaryKey;autoIncrement;comment:主键ID"`
+	EventID int    `json:"event_id" gorm:"index:idx_event_id;not null;comment:告警事件ID"`
+	Key     string `json:"key" gorm:"column:label_key;size:100;index:idx_label_key_value,priority:1;not null;comment:标签键"`
+	Value   string `json:"value" gorm:"column:label_value;size:255;index:idx_label_key_value,priority:2;not null;comment:标签值"`
+}
+
+// 标签索引列的最大字符数，与 AlertEventLabel 的列定义保持一致
+const (
+	AlertEventLabelKeyMaxLen   = 100
+	AlertEventLabelValueMaxLen = 255
+)
+
+// LabelIndexable 判断标签键能否写入标签索引，超长的键不建立索引，避免写入失败
+func LabelIndexable(key string) bool {
+	return key != "" && utf8.RuneCountInString(key) <= AlertEventLabelKeyMaxLen
+}
+
+// LabelIndexValue 返回写入标签索引的值，超长时按字符截断；按标签查询时对查询值做同样处理，保证仍能命中
+func LabelIndexValue(value string) string {
+	if utf8.RuneCountInString(value) <= AlertEventLabelValueMaxLen {
+		return value
+	}
+	return string([]rune(value)[:AlertEventLabelValueMaxLen])
+}
+
+// NotificationLog 告警通知投递记录，保存渠道返回的消息ID用于事后关联
+type NotificationLog struct {
+	ID        int    `json:"id" gorm:"primaryKey;autoIncrement;comment:主键ID"`
+	CreatedAt int64  `json:"created_at" gorm:"autoCreateTime;comment:发送时间"`
+	EventID   int    `json:"event_id" gorm:"index;not null;comment:告警事件ID"`
+	Channel   string `json:"channel" gorm:"size:50;not null;comment:通知渠道"`
+	MessageID string `json:"message_id" gorm:"size:100;comment:渠道返回的消息ID,渠道未返回时为空"`
+}
+
+// MonitorInhibitRule 告警抑制规则，存在匹配 SourceMatchers 的活跃告警时，
+// 抑制匹配 TargetMatchers 且 Equal 中标签值均相同的告警通知，语义同 Alertmanager inhibit_rules
+type MonitorInhibitRule struct {
+	ID             int        `json:"id" gorm:"primaryKey;autoIncrement;comment:主键ID"`
+	CreatedAt      int64      `json:"created_at" gorm:"autoCreateTime;comment:创建时间"`
+	UpdatedAt      int64      `json:"updated_at" gorm:"autoUpdateTime;comment:更新时间"`
+	DeletedAt      int64      `json:"deleted_at" gorm:"index:idx_deleted_at;default:0;comment:删除时间"`
+	Name           string     `json:"name" binding:"required,min=1,max=50" gorm:"size:100;not null;comment:抑制规则名称"`
+	Enable         bool       `json:"enable" gorm:"type:tinyint(1);default:1;not null;comment:是否启用"`
+	SourceMatchers Labels     `json:"source_matchers" gorm:"type:text;comment:源告警匹配标签"`
+	TargetMatchers Labels     `json:"target_matchers" gorm:"type:text;comment:被抑制告警匹配标签"`
+	Equal          StringList `json:"equal" gorm:"type:text;comment:源告警与被抑制告警取值必须相同的标签"`
+}
+
+// MonitorSilence 告警静默记录
+type MonitorSilence struct {
+	ID        int             `json:"id" gorm:"primaryKey;autoIncrement;comment:主键ID"`
+	CreatedAt int64           `json:"created_at" gorm:"autoCreateTime;comment:创建时间"`
+	UpdatedAt int64           `json:"updated_at" gorm:"autoUpdateTime;comment:更新时间"`
+	DeletedAt int64           `json:"deleted_at" gorm:"index:idx_deleted_at;default:0;comment:删除时间"`
+	SilenceID string          `json:"silence_id" gorm:"size:64;index;comment:Alertmanager静默ID"`
+	Matchers  SilenceMatchers `json:"matchers" gorm:"type:text;not null;comment:静默匹配器,JSON数组"`
+	StartsAt  int64           `json:"starts_at" gorm:"comment:静默开始时间"`
+	EndsAt    int64           `json:"ends_at" gorm:"index;comment:静默结束时间"`
+	CreatedBy string          `json:"created_by" gorm:"size:100;comment:创建人"`
+	Comment   string          `json:"comment" gorm:"type:text;comment:静默说明"`
+}
+
+// AlertEventWithUser 告警事件及其认领人，事件未被认领或认领人已不存在时 Claimant 为 nil
+type AlertEventWithUser struct {
+	Event    *MonitorAlertEvent `json:"event"`
+	Claimant *UserBrief         `json:"claimant"`
+}
+
+// AlertEventPage 基于游标分页的告警事件列表，NextCursor 为空表示没有更多数据
+type AlertEventPage struct {
+	Items      []*MonitorAlertEvent `json:"items"`
+	NextCursor string               `json:"next_cursor"`
+}
+
+// AlertEventListWithStats 告警事件分页列表及按状态统计的总数，StatusCounts 与 Items 来自同一事务快照，
+// 各状态数量之和等于 Total
+type AlertEventListWithStats struct {
+	Items        []*MonitorAlertEvent `json:"items"`
+	Total        int64                `json:"total"`
+	StatusCounts map[string]int64     `json:"status_counts"`
+}
+
+// SilenceImportResult 导入 Alertmanager 静默的结果统计
+type SilenceImportResult struct {
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"` // 已过期或已导入过的静默
+}
+
+// RuleEventCount 告警规则在时间窗口内产生的事件数
+type RuleEventCount struct {
+	RuleID   int    `json:"rule_id"`
+	RuleName string `json:"rule_name,omitempty"` // 规则名称，规则已删除时为空
+	Count    int64  `json:"count"`
+}
+
+// AlertEventGroup 按指纹聚合的告警事件，状态和时间取最新一条事件
+type AlertEventGroup struct {
+	Fingerprint string `json:"fingerprint"`
+	AlertName   string `json:"alert_name"`
+	Status      string `json:"status"`
+	CreatedAt   int64  `json:"created_at"`
+	EventTimes  int64  `json:"event_times"` // 同一指纹所有事件的触发次数之和
+}
+
+// 健康检查组件状态
+const (
+	HealthStatusOK      = "ok"
+	HealthStatusFail    = "fail"
+	HealthStatusSkipped = "skipped"
+)
+
+// ComponentHealth 单个依赖组件的健康检查结果
+type ComponentHealth struct {
+	Name      string `json:"name"`            // 组件名称
+	Status    string `json:"status"`          // ok、fail 或 skipped
+	Error     string `json:"error,omitempty"` // 检查失败原因
+	LatencyMs int64  `json:"latency_ms"`      // 检查耗时(毫秒)
+}
+
+// HealthStatus 告警子系统健康检查结果，任一已启用组件失败时 Healthy 为 false
+type HealthStatus struct {
+	Healthy    bool               `json:"healthy"`
+	CheckedAt  int64              `json:"checked_at"`
+	Components []*ComponentHealth `json:"components"`
+}
+
+// ConfigPreview 配置预览结果，包含新渲染的配置与当前下发配置的差异
+type ConfigPreview struct {
+	Pool     string `json:"pool"`     // 所属池名称
+	Instance string `json:"instance"` // 实例IP
+	Config   string `json:"config"`   // 新渲染的配置
+	Deployed string `json:"deployed"` // 当前下发的配置
+	Diff     string `json:"diff"`     // 逐行差异，删除行以 "- " 开头，新增行以 "+ " 开头
+	Changed  bool   `json:"changed"`  // 新配置是否与当前配置不同
+}
+
+// FeishuCard 飞书 interactive 卡片消息内容
+type FeishuCard struct {
+	Title     string `json:"title"`      // 卡片标题
+	Severity  string `json:"severity"`   // 告警级别，决定标题栏颜色
+	Instance  string `json:"instance"`   // 告警实例
+	Summary   string `json:"summary"`    // 告警摘要
+	Content   string `json:"content"`    // 正文，支持 markdown
+	ActionURL string `json:"action_url"` // 查看详情按钮链接，为空时不展示按钮
+}
+
+// AlertEventFilter 告警事件组合查询条件，零值字段表示不过滤
+type AlertEventFilter struct {
+	Name           string   `json:"name" form:"name"`                       // 告警名称子串
+	Status         string   `json:"status" form:"status"`                   // 告警状态
+	Statuses       []string `json:"statuses" form:"statuses"`               // 告警状态列表，匹配任一状态，为空时不过滤
+	Severity       string   `json:"severity" form:"severity"`               // 告警级别，为空时不过滤
+	StartTime      int64    `json:"start_time" form:"start_time"`           // 创建时间起点(Unix秒)
+	EndTime        int64    `json:"end_time" form:"end_time"`               // 创建时间终点(Unix秒)
+	IncludeDeleted bool     `json:"include_deleted" form:"include_deleted"` // 是否包含已软删除的事件，默认不包含
+}
+
+// SearchAlertEventRequest 告警事件组合搜索请求
+type SearchAlertEventRequest struct {
+	Page int `json:"page" form:"page" binding:"required,min=1"`
+	Size int `json:"size" form:"size" binding:"required,min=1,max=100"`
+	AlertEventFilter
+}
+
 type BatchEventAlertSilenceRequest struct {
 	IDs []int `json:"ids" binding:"required"`
 	AlertEventSilenceRequest
@@ -269,6 +522,19 @@ type BatchRequest struct {
 	IDs []int `json:"ids" binding:"required"`
 }
 
+// BatchEventAlertClaimRequest 批量认领告警事件请求，AllOrNothing 为 true 时任一事件失败则全部回滚
+type BatchEventAlertClaimRequest struct {
+	IDs          []int `json:"ids" binding:"required"`
+	AllOrNothing bool  `json:"all_or_nothing"`
+}
+
+// ClaimResult 批量认领中单个事件的处理结果
+type ClaimResult struct {
+	ID    int    `json:"id"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
 type PromqlExprCheckReq struct {
 	PromqlExpr string `json:"promql_expr" binding:"required"`
 }
@@ -289,3 +555,21 @@ type OnDutyOne struct {
 	User       *User  `json:"user"`
 	OriginUser string `json:"origin_user"` // 原始用户名
 }
+
+// MarshalJSON 序列化告警事件，保留原有 unix 时间戳字段，启用 RFC3339 时间输出时额外返回格式化时间
+func (m MonitorAlertEvent) MarshalJSON() ([]byte, error) {
+	type alias MonitorAlertEvent
+	if !RFC3339TimeEnabled() {
+		return json.Marshal(alias(m))
+	}
+
+	return json.Marshal(struct {
+		alias
+		CreatedAtRFC3339 string `json:"created_at_rfc3339,omitempty"`
+		UpdatedAtRFC3339 string `json:"updated_at_rfc3339,omitempty"`
+	}{
+		alias:            alias(m),
+		CreatedAtRFC3339: FormatUnixRFC3339(m.CreatedAt),
+		UpdatedAtRFC3339: FormatUnixRFC3339(m.UpdatedAt),
+	})
+}
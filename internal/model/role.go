@@ -86,3 +86,9 @@ type AssignUsersRoleRequest struct {
 	UserIds []int `json:"user_ids" binding:"required,gt=0"` // 用户ID
 	RoleIds []int `json:"role_ids"`                         // 角色ID列表
 }
+
+// 角色类型，与 Role.RoleType 对应
+const (
+	RoleTypeSystem int8 = 1 // 系统角色
+	RoleTypeCustom int8 = 2 // 自定义角色
+)
@@ -0,0 +1,51 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+package model
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// rfc3339TimeEnabled 为 true 时模型序列化为 JSON 会额外输出 RFC3339 格式的时间字段
+var rfc3339TimeEnabled atomic.Bool
+
+// SetRFC3339TimeEnabled 设置 JSON 输出是否额外包含 RFC3339 格式的时间字段，默认关闭以保持兼容
+func SetRFC3339TimeEnabled(enabled bool) {
+	rfc3339TimeEnabled.Store(enabled)
+}
+
+// RFC3339TimeEnabled 返回 JSON 输出是否额外包含 RFC3339 格式的时间字段
+func RFC3339TimeEnabled() bool {
+	return rfc3339TimeEnabled.Load()
+}
+
+// FormatUnixRFC3339 将 unix 时间戳格式化为 RFC3339 字符串，时间戳为 0 时返回空字符串
+func FormatUnixRFC3339(ts int64) string {
+	if ts <= 0 {
+		return ""
+	}
+	return time.Unix(ts, 0).Format(time.RFC3339)
+}
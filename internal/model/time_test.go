@@ -0,0 +1,106 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// marshalFields 序列化 v 并解析为字段映射
+func marshalFields(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("序列化失败: %v", err)
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("解析序列化结果失败: %v", err)
+	}
+	return fields
+}
+
+func TestMarshalJSONRFC3339(t *testing.T) {
+	const created, updated = int64(1700000000), int64(1700003600)
+	wantCreated := time.Unix(created, 0).Format(time.RFC3339)
+	wantUpdated := time.Unix(updated, 0).Format(time.RFC3339)
+
+	child := &Menu{Name: "用户管理", CreatedAt: created}
+	cases := []struct {
+		name  string
+		value interface{}
+		want  map[string]string
+	}{
+		{"告警事件", &MonitorAlertEvent{ID: 1, CreatedAt: created, UpdatedAt: updated},
+			map[string]string{"created_at_rfc3339": wantCreated, "updated_at_rfc3339": wantUpdated}},
+		{"菜单", Menu{Name: "系统管理", CreatedAt: created, UpdatedAt: updated, Children: []*Menu{child}},
+			map[string]string{"created_at_rfc3339": wantCreated, "updated_at_rfc3339": wantUpdated}},
+		{"审计日志", AuditLog{CreatedAt: created, UpdatedAt: updated},
+			map[string]string{"created_at_rfc3339": wantCreated}},
+	}
+
+	t.Cleanup(func() { SetRFC3339TimeEnabled(false) })
+	for _, c := range cases {
+		// 默认关闭时输出与原有格式一致
+		SetRFC3339TimeEnabled(false)
+		fields := marshalFields(t, c.value)
+		for key := range c.want {
+			if _, ok := fields[key]; ok {
+				t.Fatalf("%s: 默认不应输出 %s, 实际 %v", c.name, key, fields)
+			}
+		}
+		if fields["created_at"] != float64(created) {
+			t.Fatalf("%s: created_at 应保持 unix 时间戳, 实际 %v", c.name, fields["created_at"])
+		}
+
+		SetRFC3339TimeEnabled(true)
+		fields = marshalFields(t, c.value)
+		if fields["created_at"] != float64(created) {
+			t.Fatalf("%s: 启用后 created_at 仍应为 unix 时间戳, 实际 %v", c.name, fields["created_at"])
+		}
+		for key, want := range c.want {
+			if fields[key] != want {
+				t.Fatalf("%s: %s 期望 %s, 实际 %v", c.name, key, want, fields[key])
+			}
+		}
+	}
+
+	// 嵌套子菜单同样输出格式化时间，时间戳为 0 的字段省略
+	SetRFC3339TimeEnabled(true)
+	fields := marshalFields(t, Menu{Children: []*Menu{child}})
+	if _, ok := fields["created_at_rfc3339"]; ok {
+		t.Fatalf("时间戳为 0 时不应输出格式化时间, 实际 %v", fields)
+	}
+	children, _ := fields["children"].([]interface{})
+	if len(children) != 1 {
+		t.Fatalf("应输出 1 个子菜单, 实际 %v", fields["children"])
+	}
+	if got := children[0].(map[string]interface{})["created_at_rfc3339"]; got != wantCreated {
+		t.Fatalf("子菜单 created_at_rfc3339 期望 %s, 实际 %v", wantCreated, got)
+	}
+}
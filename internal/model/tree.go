@@ -27,6 +27,7 @@ package model
 
 import (
 	"database/sql/driver"
+	"fmt"
 	"strings"
 )
 
@@ -198,8 +199,20 @@ type BindResourceReq struct {
 type StringList []string
 
 func (m *StringList) Scan(val interface{}) error {
-	s := val.([]uint8)
-	ss := strings.Split(string(s), "|")
+	// 不同驱动读取文本列时可能返回 []byte 或 string
+	var s string
+	switch v := val.(type) {
+	case []uint8:
+		s = string(v)
+	case string:
+		s = v
+	case nil:
+		*m = nil
+		return nil
+	default:
+		return fmt.Errorf("StringList 不支持的类型: %T", val)
+	}
+	ss := strings.Split(s, "|")
 	*m = ss
 	return nil
 }
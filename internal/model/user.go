@@ -27,22 +27,36 @@ package model
 
 // User 用户模型
 type User struct {
-	ID           int     `json:"id" gorm:"primaryKey;autoIncrement;comment:主键ID"`                                       // 主键ID，自增
-	CreatedAt    int64   `json:"created_at" gorm:"autoCreateTime;comment:创建时间"`                                         // 创建时间，自动记录
-	UpdatedAt    int64   `json:"updated_at" gorm:"autoUpdateTime;comment:更新时间"`                                         // 更新时间，自动记录
-	DeletedAt    int64   `json:"deleted_at" gorm:"index;default:0;comment:删除时间"`                                        // 软删除时间，使用普通索引
-	Username     string  `json:"username" gorm:"type:varchar(100);uniqueIndex:idx_username_del;not null;comment:用户登录名"` // 用户登录名，唯一且非空
-	Password     string  `json:"password" gorm:"type:varchar(255);not null;comment:用户登录密码"`                             // 用户登录密码，非空，JSON序列化时忽略
-	RealName     string  `json:"real_name" gorm:"type:varchar(100);comment:用户真实姓名"`                                     // 用户真实姓名
-	Desc         string  `json:"desc" gorm:"type:text;comment:用户描述"`                                                    // 用户描述，支持较长文本
-	Mobile       string  `json:"mobile" gorm:"type:varchar(20);uniqueIndex:idx_mobile_del;comment:手机号"`                 // 手机号，添加唯一索引
-	FeiShuUserId string  `json:"fei_shu_user_id" gorm:"type:varchar(50);uniqueIndex:idx_feishu_del;comment:飞书用户ID"`     // 飞书用户ID，添加唯一索引
-	AccountType  int8    `json:"account_type" gorm:"default:1;comment:账号类型 1普通用户 2服务账号" binding:"omitempty,oneof=1 2"`  // 账号类型，使用int8节省空间
-	HomePath     string  `json:"home_path" gorm:"type:varchar(255);default:'/';comment:登录后的默认首页"`                       // 登录后的默认首页，添加默认值
-	Enable       int8    `json:"enable" gorm:"default:1;comment:用户状态 1正常 2冻结" binding:"omitempty,oneof=1 2"`            // 用户状态，使用int8节省空间
-	Roles        []*Role `json:"roles" gorm:"many2many:user_roles;comment:关联角色"`                                        // 多对多关联角色
-	Menus        []*Menu `json:"menus" gorm:"many2many:user_menus;comment:关联菜单"`                                        // 多对多关联菜单
-	Apis         []*Api  `json:"apis" gorm:"many2many:user_apis;comment:关联接口"`                                          // 多对多关联接口
+	ID            int     `json:"id" gorm:"primaryKey;autoIncrement;comment:主键ID"`                                       // 主键ID，自增
+	CreatedAt     int64   `json:"created_at" gorm:"autoCreateTime;comment:创建时间"`                                         // 创建时间，自动记录
+	UpdatedAt     int64   `json:"updated_at" gorm:"autoUpdateTime;comment:更新时间"`                                         // 更新时间，自动记录
+	DeletedAt     int64   `json:"deleted_at" gorm:"index;default:0;comment:删除时间"`                                        // 软删除时间，使用普通索引
+	Username      string  `json:"username" gorm:"type:varchar(100);uniqueIndex:idx_username_del;not null;comment:用户登录名"` // 用户登录名，唯一且非空
+	Password      string  `json:"password" gorm:"type:varchar(255);not null;comment:用户登录密码"`                             // 用户登录密码，非空，JSON序列化时忽略
+	RealName      string  `json:"real_name" gorm:"type:varchar(100);comment:用户真实姓名"`                                     // 用户真实姓名
+	Desc          string  `json:"desc" gorm:"type:text;comment:用户描述"`                                                    // 用户描述，支持较长文本
+	Mobile        string  `json:"mobile" gorm:"type:varchar(20);uniqueIndex:idx_mobile_del;comment:手机号"`                 // 手机号，添加唯一索引
+	FeiShuUserId  string  `json:"fei_shu_user_id" gorm:"type:varchar(50);uniqueIndex:idx_feishu_del;comment:飞书用户ID"`     // 飞书用户ID，添加唯一索引
+	AccountType   int8    `json:"account_type" gorm:"default:1;comment:账号类型 1普通用户 2服务账号" binding:"omitempty,oneof=1 2"`  // 账号类型，使用int8节省空间
+	HomePath      string  `json:"home_path" gorm:"type:varchar(255);default:'/';comment:登录后的默认首页"`                       // 登录后的默认首页，添加默认值
+	Enable        int8    `json:"enable" gorm:"default:1;comment:用户状态 1正常 2冻结" binding:"omitempty,oneof=1 2"`            // 用户状态，使用int8节省空间
+	LoginFailures int     `json:"login_failures" gorm:"default:0;comment:连续登录失败次数"`                                      // 连续登录失败次数
+	LockedUntil   int64   `json:"locked_until" gorm:"default:0;comment:账号锁定截止时间"`                                        // 账号锁定截止时间，0表示未锁定
+	TeamID        int     `json:"team_id" gorm:"index;default:0;comment:所属团队ID"`                                         // 所属团队ID，0表示未分配团队
+	Roles         []*Role `json:"roles" gorm:"many2many:user_roles;comment:关联角色"`                                        // 多对多关联角色
+	Menus         []*Menu `json:"menus" gorm:"many2many:user_menus;comment:关联菜单"`                                        // 多对多关联菜单
+	Apis          []*Api  `json:"apis" gorm:"many2many:user_apis;comment:关联接口"`                                          // 多对多关联接口
+}
+
+// AdminUsername 内置管理员账号的用户名
+const AdminUsername = "admin"
+
+// UserBrief 用户的公开信息，用于在其他资源中展示关联用户，不含密码、角色等敏感或大字段
+type UserBrief struct {
+	ID           int    `json:"id"`              // 用户ID
+	Username     string `json:"username"`        // 用户登录名
+	RealName     string `json:"real_name"`       // 用户真实姓名
+	FeiShuUserId string `json:"fei_shu_user_id"` // 飞书用户ID
 }
 
 // TokenRequest 刷新令牌请求
@@ -26,43 +26,80 @@
 package api
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
 	"strconv"
 
 	"github.com/GoSimplicity/AI-CloudOps/pkg/utils"
 
 	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	alertEventDao "github.com/GoSimplicity/AI-CloudOps/internal/prometheus/dao/alert"
 	alertEventService "github.com/GoSimplicity/AI-CloudOps/internal/prometheus/service/alert"
+	auditService "github.com/GoSimplicity/AI-CloudOps/internal/system/service"
+	"github.com/GoSimplicity/AI-CloudOps/pkg/cursor"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
 type AlertEventHandler struct {
 	alertEventService alertEventService.AlertManagerEventService
+	auditService      auditService.AuditService
 	l                 *zap.Logger
 }
 
-func NewAlertEventHandler(l *zap.Logger, alertEventService alertEventService.AlertManagerEventService) *AlertEventHandler {
+func NewAlertEventHandler(l *zap.Logger, alertEventService alertEventService.AlertManagerEventService, auditService auditService.AuditService) *AlertEventHandler {
 	return &AlertEventHandler{
 		l:                 l,
 		alertEventService: alertEventService,
+		auditService:      auditService,
 	}
 }
 
 func (a *AlertEventHandler) RegisterRouters(server *gin.Engine) {
+	server.GET("/healthz", a.HealthCheck)
+
 	monitorGroup := server.Group("/api/monitor")
 
 	alertEvents := monitorGroup.Group("/alert_events")
 	{
 		alertEvents.GET("/list", a.GetMonitorAlertEventList)
+		alertEvents.GET("/page", a.GetMonitorAlertEventPage)
+		alertEvents.GET("/list_stats", a.GetMonitorAlertEventListWithStats)
+		alertEvents.GET("/search", a.SearchMonitorAlertEvents)
+		alertEvents.GET("/by_label", a.GetEventsByLabelKV)
 		alertEvents.POST("/:id/silence", a.EventAlertSilence)
 		alertEvents.POST("/:id/claim", a.EventAlertClaim)
+		alertEvents.POST("/:id/unclaim", a.EventAlertUnclaim)
+		alertEvents.GET("/:id", a.GetAlertEventWithClaimant)
+		alertEvents.GET("/:id/audit", a.GetEventAuditTrail)
+		alertEvents.GET("/:id/notifications", a.GetNotificationsForEvent)
 		alertEvents.POST("/:id/unSilence", a.EventAlertUnSilence)
 		alertEvents.POST("/silence", a.BatchEventAlertSilence)
+		alertEvents.POST("/claim", a.BatchEventAlertClaim)
 		alertEvents.GET("/total", a.GetMonitorAlertEventTotal)
+		alertEvents.GET("/export", a.ExportAlertEventsCSV)
+		alertEvents.POST("/silences/import", a.ImportSilencesFromAlertmanager)
+	}
+}
+
+// HealthCheck 告警子系统健康检查，供负载均衡和存活探针使用，任一组件失败时返回 503
+func (a *AlertEventHandler) HealthCheck(ctx *gin.Context) {
+	status := a.alertEventService.HealthCheck(ctx)
+	if !status.Healthy {
+		ctx.JSON(http.StatusServiceUnavailable, status)
+		return
 	}
+
+	ctx.JSON(http.StatusOK, status)
+}
+
+// teamIDFromClaims 从登录态中取调用方所属团队，不信任请求参数，避免越权查看其他团队的告警
+func teamIDFromClaims(ctx *gin.Context) int {
+	return ctx.MustGet("user").(utils.UserClaims).TeamID
 }
 
-// GetMonitorAlertEventList 获取告警事件列表
+// GetMonitorAlertEventList 获取告警事件列表，可通过 severity 参数按告警级别过滤
 func (a *AlertEventHandler) GetMonitorAlertEventList(ctx *gin.Context) {
 	var listReq model.ListReq
 
@@ -71,15 +108,79 @@ func (a *AlertEventHandler) GetMonitorAlertEventList(ctx *gin.Context) {
 		return
 	}
 
-	list, err := a.alertEventService.GetMonitorAlertEventList(ctx, &listReq)
+	teamID := teamIDFromClaims(ctx)
+
+	list, err := a.alertEventService.GetMonitorAlertEventList(ctx, teamID, ctx.Query("severity"), &listReq)
 	if err != nil {
-		utils.ErrorWithMessage(ctx, err.Error())
+		respondAlertEventError(ctx, err)
 		return
 	}
 
 	utils.SuccessWithData(ctx, list)
 }
 
+// GetMonitorAlertEventListWithStats 获取告警事件分页列表，同时返回按状态统计的总数
+func (a *AlertEventHandler) GetMonitorAlertEventListWithStats(ctx *gin.Context) {
+	var listReq model.ListReq
+
+	if err := ctx.ShouldBindQuery(&listReq); err != nil {
+		utils.ErrorWithDetails(ctx, err, "参数错误")
+		return
+	}
+
+	teamID := teamIDFromClaims(ctx)
+
+	stats, err := a.alertEventService.GetMonitorAlertEventListWithStats(ctx, teamID, &listReq)
+	if err != nil {
+		respondAlertEventError(ctx, err)
+		return
+	}
+
+	utils.SuccessWithData(ctx, stats)
+}
+
+// GetMonitorAlertEventPage 基于游标分页获取告警事件列表，响应中的 next_cursor 用于请求下一页
+func (a *AlertEventHandler) GetMonitorAlertEventPage(ctx *gin.Context) {
+	teamID := teamIDFromClaims(ctx)
+
+	size, err := strconv.Atoi(ctx.DefaultQuery("size", "20"))
+	if err != nil || size < 1 || size > 100 {
+		utils.ErrorWithMessage(ctx, "参数错误")
+		return
+	}
+
+	page, err := a.alertEventService.GetMonitorAlertEventPage(ctx, teamID, ctx.Query("cursor"), size)
+	if err != nil {
+		respondAlertEventError(ctx, err)
+		return
+	}
+
+	utils.SuccessWithData(ctx, page)
+}
+
+// SearchMonitorAlertEvents 按名称、状态和时间范围组合搜索告警事件
+func (a *AlertEventHandler) SearchMonitorAlertEvents(ctx *gin.Context) {
+	var req model.SearchAlertEventRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		utils.ErrorWithDetails(ctx, err, "参数错误")
+		return
+	}
+
+	uc := ctx.MustGet("user").(utils.UserClaims)
+
+	list, total, err := a.alertEventService.SearchMonitorAlertEvents(ctx, uc.TeamID, uc.Uid, &req)
+	if err != nil {
+		respondAlertEventError(ctx, err)
+		return
+	}
+
+	utils.SuccessWithData(ctx, gin.H{
+		"list":  list,
+		"total": total,
+	})
+}
+
 // EventAlertSilence 将指定告警事件设置为静默状态
 func (a *AlertEventHandler) EventAlertSilence(ctx *gin.Context) {
 	var silence model.AlertEventSilenceRequest
@@ -99,14 +200,17 @@ func (a *AlertEventHandler) EventAlertSilence(ctx *gin.Context) {
 	}
 
 	if err := a.alertEventService.EventAlertSilence(ctx, intId, &silence, uc.Uid); err != nil {
-		utils.ErrorWithMessage(ctx, err.Error())
+		respondAlertEventError(ctx, err)
 		return
 	}
 
+	a.auditService.RecordAction(ctx.Request.Context(), uc.Uid, auditService.AuditActionAlertEventSilence, "alert_event", id,
+		fmt.Sprintf("time=%s use_name=%t", silence.Time, silence.UseName))
+
 	utils.Success(ctx)
 }
 
-// EventAlertClaim 认领指定的告警事件
+// EventAlertClaim 认领指定的告警事件，认领审计记录由 DAO 在同一事务中写入
 func (a *AlertEventHandler) EventAlertClaim(ctx *gin.Context) {
 	uc := ctx.MustGet("user").(utils.UserClaims)
 
@@ -118,13 +222,106 @@ func (a *AlertEventHandler) EventAlertClaim(ctx *gin.Context) {
 	}
 
 	if err := a.alertEventService.EventAlertClaim(ctx, intId, uc.Uid); err != nil {
-		utils.ErrorWithMessage(ctx, err.Error())
+		respondAlertEventError(ctx, err)
+		return
+	}
+
+	utils.Success(ctx)
+}
+
+// EventAlertUnclaim 取消认领指定的告警事件
+func (a *AlertEventHandler) EventAlertUnclaim(ctx *gin.Context) {
+	uc := ctx.MustGet("user").(utils.UserClaims)
+
+	id := ctx.Param("id")
+	intId, err := strconv.Atoi(id)
+	if err != nil {
+		utils.ErrorWithMessage(ctx, "参数错误")
+		return
+	}
+
+	if err := a.alertEventService.EventAlertUnclaim(ctx, intId, uc.Uid); err != nil {
+		respondAlertEventError(ctx, err)
 		return
 	}
 
 	utils.Success(ctx)
 }
 
+// GetEventAuditTrail 获取告警事件的认领审计记录
+func (a *AlertEventHandler) GetEventAuditTrail(ctx *gin.Context) {
+	intId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		utils.ErrorWithMessage(ctx, "参数错误")
+		return
+	}
+
+	audits, err := a.alertEventService.GetEventAuditTrail(ctx, intId)
+	if err != nil {
+		respondAlertEventError(ctx, err)
+		return
+	}
+
+	utils.SuccessWithData(ctx, audits)
+}
+
+// GetAlertEventWithClaimant 获取告警事件详情及认领人信息
+func (a *AlertEventHandler) GetAlertEventWithClaimant(ctx *gin.Context) {
+	intId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		utils.ErrorWithMessage(ctx, "参数错误")
+		return
+	}
+
+	detail, err := a.alertEventService.GetAlertEventWithClaimant(ctx, intId)
+	if err != nil {
+		respondAlertEventError(ctx, err)
+		return
+	}
+
+	utils.SuccessWithData(ctx, detail)
+}
+
+// GetNotificationsForEvent 获取告警事件的通知投递记录
+func (a *AlertEventHandler) GetNotificationsForEvent(ctx *gin.Context) {
+	intId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		utils.ErrorWithMessage(ctx, "参数错误")
+		return
+	}
+
+	logs, err := a.alertEventService.GetNotificationsForEvent(ctx, intId)
+	if err != nil {
+		respondAlertEventError(ctx, err)
+		return
+	}
+
+	utils.SuccessWithData(ctx, logs)
+}
+
+// GetEventsByLabelKV 按标签键值查询告警事件，如 key=severity&value=critical，size 限制返回条数
+func (a *AlertEventHandler) GetEventsByLabelKV(ctx *gin.Context) {
+	key := ctx.Query("key")
+	if key == "" {
+		utils.ErrorWithMessage(ctx, "参数错误")
+		return
+	}
+
+	size, err := strconv.Atoi(ctx.DefaultQuery("size", "20"))
+	if err != nil || size < 1 || size > 100 {
+		utils.ErrorWithMessage(ctx, "参数错误")
+		return
+	}
+
+	events, err := a.alertEventService.GetEventsByLabelKV(ctx, teamIDFromClaims(ctx), key, ctx.Query("value"), size)
+	if err != nil {
+		respondAlertEventError(ctx, err)
+		return
+	}
+
+	utils.SuccessWithData(ctx, events)
+}
+
 // EventAlertUnSilence 取消指定告警事件的静默状态
 func (a *AlertEventHandler) EventAlertUnSilence(ctx *gin.Context) {
 	uc := ctx.MustGet("user").(utils.UserClaims)
@@ -137,7 +334,7 @@ func (a *AlertEventHandler) EventAlertUnSilence(ctx *gin.Context) {
 	}
 
 	if err := a.alertEventService.EventAlertClaim(ctx, intId, uc.Uid); err != nil {
-		utils.ErrorWithMessage(ctx, err.Error())
+		respondAlertEventError(ctx, err)
 		return
 	}
 
@@ -156,19 +353,107 @@ func (a *AlertEventHandler) BatchEventAlertSilence(ctx *gin.Context) {
 	}
 
 	if err := a.alertEventService.BatchEventAlertSilence(ctx, &req, uc.Uid); err != nil {
-		utils.ErrorWithMessage(ctx, err.Error())
+		respondAlertEventError(ctx, err)
 		return
 	}
 
 	utils.Success(ctx)
 }
 
+// BatchEventAlertClaim 批量认领告警事件，返回每个事件的认领结果
+func (a *AlertEventHandler) BatchEventAlertClaim(ctx *gin.Context) {
+	var req model.BatchEventAlertClaimRequest
+
+	uc := ctx.MustGet("user").(utils.UserClaims)
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.ErrorWithDetails(ctx, err, "参数错误")
+		return
+	}
+
+	results, err := a.alertEventService.BatchEventAlertClaim(ctx, &req, uc.Uid)
+	if err != nil {
+		respondAlertEventError(ctx, err)
+		return
+	}
+
+	utils.SuccessWithData(ctx, results)
+}
+
 // GetMonitorAlertEventTotal 获取监控告警事件总数
 func (a *AlertEventHandler) GetMonitorAlertEventTotal(ctx *gin.Context) {
-	total, err := a.alertEventService.GetMonitorAlertEventTotal(ctx)
+	teamID := teamIDFromClaims(ctx)
+
+	total, err := a.alertEventService.GetMonitorAlertEventTotal(ctx, teamID)
 	if err != nil {
-		utils.ErrorWithMessage(ctx, err.Error())
+		respondAlertEventError(ctx, err)
 		return
 	}
 	utils.SuccessWithData(ctx, total)
 }
+
+// ExportAlertEventsCSV 以 CSV 附件形式导出调用方所属团队指定时间范围内的告警事件
+func (a *AlertEventHandler) ExportAlertEventsCSV(ctx *gin.Context) {
+	start, err := strconv.ParseInt(ctx.Query("start"), 10, 64)
+	if err != nil {
+		utils.ErrorWithMessage(ctx, "参数错误")
+		return
+	}
+	end, err := strconv.ParseInt(ctx.Query("end"), 10, 64)
+	if err != nil {
+		utils.ErrorWithMessage(ctx, "参数错误")
+		return
+	}
+	if end < start {
+		utils.BadRequestError(ctx, "结束时间不能早于开始时间")
+		return
+	}
+
+	ctx.Header("Content-Type", "text/csv; charset=utf-8")
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=alert_events_%d_%d.csv", start, end))
+
+	if err := a.alertEventService.ExportEventsCSV(ctx, teamIDFromClaims(ctx), start, end, ctx.Writer); err != nil {
+		if !ctx.Writer.Written() {
+			// 尚未写出任何数据时撤回附件响应头，按普通错误返回
+			ctx.Writer.Header().Del("Content-Type")
+			ctx.Writer.Header().Del("Content-Disposition")
+			respondAlertEventError(ctx, err)
+			return
+		}
+		// 数据已开始写入响应体，出错时只能记录日志
+		a.l.Error("导出告警事件CSV失败", zap.Error(err), zap.Int64("start", start), zap.Int64("end", end))
+	}
+}
+
+// ImportSilencesFromAlertmanager 导入 Alertmanager 导出的静默 JSON，返回导入和跳过的数量
+func (a *AlertEventHandler) ImportSilencesFromAlertmanager(ctx *gin.Context) {
+	data, err := ctx.GetRawData()
+	if err != nil {
+		utils.ErrorWithMessage(ctx, "读取请求体失败")
+		return
+	}
+
+	result, err := a.alertEventService.ImportSilencesFromAlertmanager(ctx, data)
+	if err != nil {
+		utils.ErrorWithMessage(ctx, err.Error())
+		return
+	}
+
+	utils.SuccessWithData(ctx, result)
+}
+
+// respondAlertEventError 根据 DAO 层哨兵错误返回对应的 HTTP 状态码，其余错误按普通失败返回
+func respondAlertEventError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, alertEventDao.ErrInvalidID), errors.Is(err, cursor.ErrInvalidCursor):
+		utils.BadRequestError(ctx, err.Error())
+	case errors.Is(err, alertEventDao.ErrEventNotFound):
+		utils.NotFoundError(ctx, err.Error())
+	case errors.Is(err, alertEventDao.ErrPermissionDenied):
+		utils.ForbiddenError(ctx, err.Error())
+	case errors.Is(err, alertEventDao.ErrSilenced), errors.Is(err, alertEventDao.ErrAlertEventVersionConflict):
+		utils.ConflictError(ctx, err.Error())
+	default:
+		utils.ErrorWithMessage(ctx, err.Error())
+	}
+}
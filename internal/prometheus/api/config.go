@@ -55,6 +55,8 @@ func (c *ConfigYamlHandler) RegisterRouters(server *gin.Engine) {
 		prometheusConfigs.GET("/prometheus_alert", c.GetMonitorPrometheusAlertRuleYaml)
 		prometheusConfigs.GET("/prometheus_record", c.GetMonitorPrometheusRecordYaml)
 		prometheusConfigs.GET("/alertManager", c.GetMonitorAlertManagerYaml)
+		prometheusConfigs.GET("/prometheus/preview", c.PreviewMonitorPrometheusYaml)
+		prometheusConfigs.GET("/alertManager/preview", c.PreviewMonitorAlertManagerYaml)
 	}
 }
 
@@ -108,3 +110,25 @@ func (c *ConfigYamlHandler) GetMonitorAlertManagerYaml(ctx *gin.Context) {
 
 	ctx.String(http.StatusOK, yaml)
 }
+
+// PreviewMonitorPrometheusYaml 预览重新生成的 Prometheus 配置及与当前配置的差异
+func (c *ConfigYamlHandler) PreviewMonitorPrometheusYaml(ctx *gin.Context) {
+	previews, err := c.yamlService.PreviewMonitorPrometheusYaml(ctx)
+	if err != nil {
+		utils.ErrorWithMessage(ctx, err.Error())
+		return
+	}
+
+	utils.SuccessWithData(ctx, previews)
+}
+
+// PreviewMonitorAlertManagerYaml 预览重新生成的 AlertManager 配置及与当前配置的差异
+func (c *ConfigYamlHandler) PreviewMonitorAlertManagerYaml(ctx *gin.Context) {
+	previews, err := c.yamlService.PreviewMonitorAlertManagerYaml(ctx)
+	if err != nil {
+		utils.ErrorWithMessage(ctx, err.Error())
+		return
+	}
+
+	utils.SuccessWithData(ctx, previews)
+}
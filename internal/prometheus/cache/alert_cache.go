@@ -47,8 +47,10 @@ const alertSendGroupKey = "alert_send_group"
 type AlertConfigCache interface {
 	GetAlertManagerMainConfigYamlByIP(ip string) string
 	GenerateAlertManagerMainConfig(ctx context.Context) error
+	PreviewAlertManagerMainConfig(ctx context.Context) ([]*model.ConfigPreview, error)
 	GenerateAlertManagerMainConfigOnePool(pool *model.MonitorAlertManagerPool) *altconfig.Config
 	GenerateAlertManagerRouteConfigOnePool(ctx context.Context, pool *model.MonitorAlertManagerPool) ([]*altconfig.Route, []altconfig.Receiver)
+	GenerateAlertmanagerConfig(ctx context.Context) (map[string][]byte, error)
 }
 
 type alertConfigCache struct {
@@ -110,26 +112,9 @@ func (a *alertConfigCache) GenerateAlertManagerMainConfig(ctx context.Context) e
 			}
 			continue
 		}
-		// 生成单个AlertManager池的主配置
-		oneConfig := a.GenerateAlertManagerMainConfigOnePool(pool)
 
-		// 生成对应的routes和receivers配置
-		routes, receivers := a.GenerateAlertManagerRouteConfigOnePool(ctx, pool)
-		if len(routes) == 0 {
-			a.l.Debug("[监控模块]没有找到任何告警路由", zap.String("池子", pool.Name))
-			continue
-		}
-
-		// 更新配置
-		oneConfig.Route.Routes = routes
-		if oneConfig.Receivers == nil {
-			oneConfig.Receivers = receivers
-		} else {
-			oneConfig.Receivers = append(oneConfig.Receivers, receivers...)
-		}
-
-		// 序列化配置为YAML格式
-		yamlData, err := yaml.Marshal(oneConfig)
+		// 生成并校验单个AlertManager池的配置
+		yamlData, err := a.renderAlertManagerConfigOnePool(ctx, pool)
 		if err != nil {
 			a.l.Error("[监控模块]生成AlertManager配置失败",
 				zap.Error(err),
@@ -137,6 +122,10 @@ func (a *alertConfigCache) GenerateAlertManagerMainConfig(ctx context.Context) e
 			)
 			continue
 		}
+		if yamlData == nil {
+			a.l.Debug("[监控模块]没有找到任何告警路由", zap.String("池子", pool.Name))
+			continue
+		}
 
 		success := true
 		// 为每个实例生成配置
@@ -191,6 +180,98 @@ func (a *alertConfigCache) GenerateAlertManagerMainConfig(ctx context.Context) e
 	return nil
 }
 
+// PreviewAlertManagerMainConfig 渲染所有AlertManager池的配置并与当前下发的配置对比，不写入文件、不更新缓存
+func (a *alertConfigCache) PreviewAlertManagerMainConfig(ctx context.Context) ([]*model.ConfigPreview, error) {
+	pools, err := a.alertPoolDao.GetAllAlertManagerPools(ctx)
+	if err != nil {
+		a.l.Error("[监控模块]扫描数据库中的AlertManager集群失败", zap.Error(err))
+		return nil, err
+	}
+
+	previews := make([]*model.ConfigPreview, 0, len(pools))
+	for _, pool := range pools {
+		yamlData, err := a.renderAlertManagerConfigOnePool(ctx, pool)
+		if err != nil {
+			return nil, fmt.Errorf("生成AlertManager池 %s 配置失败: %w", pool.Name, err)
+		}
+		if yamlData == nil {
+			continue
+		}
+
+		for _, ip := range pool.AlertManagerInstances {
+			previews = append(previews, newConfigPreview(pool.Name, ip, yamlData, a.GetAlertManagerMainConfigYamlByIP(ip)))
+		}
+	}
+
+	return previews, nil
+}
+
+// GenerateAlertmanagerConfig 根据发送组生成所有AlertManager池的配置YAML，按池子名称返回
+func (a *alertConfigCache) GenerateAlertmanagerConfig(ctx context.Context) (map[string][]byte, error) {
+	pools, err := a.alertPoolDao.GetAllAlertManagerPools(ctx)
+	if err != nil {
+		a.l.Error("[监控模块]扫描数据库中的AlertManager集群失败", zap.Error(err))
+		return nil, err
+	}
+
+	configs := make(map[string][]byte, len(pools))
+
+	for _, pool := range pools {
+		yamlData, err := a.renderAlertManagerConfigOnePool(ctx, pool)
+		if err != nil {
+			return nil, fmt.Errorf("生成AlertManager池 %s 配置失败: %w", pool.Name, err)
+		}
+		if yamlData == nil {
+			continue
+		}
+
+		configs[pool.Name] = yamlData
+	}
+
+	return configs, nil
+}
+
+// renderAlertManagerConfigOnePool 渲染单个AlertManager池的完整配置并校验，没有告警路由时返回nil
+func (a *alertConfigCache) renderAlertManagerConfigOnePool(ctx context.Context, pool *model.MonitorAlertManagerPool) ([]byte, error) {
+	// 生成单个AlertManager池的主配置
+	oneConfig := a.GenerateAlertManagerMainConfigOnePool(pool)
+
+	// 生成对应的routes和receivers配置
+	routes, receivers := a.GenerateAlertManagerRouteConfigOnePool(ctx, pool)
+	if len(routes) == 0 {
+		return nil, nil
+	}
+
+	// 更新配置
+	oneConfig.Route.Routes = routes
+	oneConfig.Receivers = append(oneConfig.Receivers, receivers...)
+
+	// 默认接收者未被发送组定义时，补充一个空接收者，保证路由引用有效
+	defaultReceiverExists := false
+	for _, receiver := range oneConfig.Receivers {
+		if receiver.Name == oneConfig.Route.Receiver {
+			defaultReceiverExists = true
+			break
+		}
+	}
+	if !defaultReceiverExists {
+		oneConfig.Receivers = append(oneConfig.Receivers, altconfig.Receiver{Name: oneConfig.Route.Receiver})
+	}
+
+	// 序列化配置为YAML格式
+	yamlData, err := yaml.Marshal(oneConfig)
+	if err != nil {
+		return nil, fmt.Errorf("序列化AlertManager配置失败: %w", err)
+	}
+
+	// 使用 AlertManager 的配置加载逻辑校验生成结果
+	if _, err := altconfig.Load(string(yamlData)); err != nil {
+		return nil, fmt.Errorf("校验AlertManager配置失败: %w", err)
+	}
+
+	return yamlData, nil
+}
+
 // GenerateAlertManagerMainConfigOnePool 生成单个AlertManager池的主配置
 func (a *alertConfigCache) GenerateAlertManagerMainConfigOnePool(pool *model.MonitorAlertManagerPool) *altconfig.Config {
 	// 解析默认恢复时间
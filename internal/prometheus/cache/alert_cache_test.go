@@ -0,0 +1,148 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package cache
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	alertDao "github.com/GoSimplicity/AI-CloudOps/internal/prometheus/dao/alert"
+	altconfig "github.com/prometheus/alertmanager/config"
+	"go.uber.org/zap"
+)
+
+type stubAlertManagerPoolDAO struct {
+	alertDao.AlertManagerPoolDAO
+	pools []*model.MonitorAlertManagerPool
+}
+
+func (s *stubAlertManagerPoolDAO) GetAllAlertManagerPools(context.Context) ([]*model.MonitorAlertManagerPool, error) {
+	return s.pools, nil
+}
+
+type stubAlertManagerSendDAO struct {
+	alertDao.AlertManagerSendDAO
+	groups map[int][]*model.MonitorSendGroup
+}
+
+func (s *stubAlertManagerSendDAO) GetMonitorSendGroupByPoolId(_ context.Context, poolID int) ([]*model.MonitorSendGroup, error) {
+	return s.groups[poolID], nil
+}
+
+// TestGenerateAlertmanagerConfigMultipleReceivers 多个发送组生成各自的接收者和路由，输出能被 Alertmanager 加载
+func TestGenerateAlertmanagerConfigMultipleReceivers(t *testing.T) {
+	pool := &model.MonitorAlertManagerPool{
+		ID:             1,
+		Name:           "am",
+		ResolveTimeout: "5m",
+		GroupWait:      "30s",
+		GroupInterval:  "5m",
+		RepeatInterval: "4h",
+		GroupBy:        model.StringList{"alertname"},
+		Receiver:       "default",
+	}
+	emptyPool := &model.MonitorAlertManagerPool{ID: 2, Name: "empty", Receiver: "default"}
+	sendDAO := &stubAlertManagerSendDAO{groups: map[int][]*model.MonitorSendGroup{
+		pool.ID: {
+			{ID: 11, Name: "ops", RepeatInterval: "2h", SendResolved: true},
+			{ID: 12, Name: "dba", RepeatInterval: "invalid"},
+		},
+	}}
+
+	a := NewAlertConfigCache(zap.NewNop(), &stubAlertManagerPoolDAO{pools: []*model.MonitorAlertManagerPool{pool, emptyPool}}, sendDAO).(*alertConfigCache)
+	a.localYamlDir = t.TempDir()
+	a.alertWebhookAddr = "http://webhook:8888/api/v1/alerts/receive"
+
+	configs, err := a.GenerateAlertmanagerConfig(context.Background())
+	if err != nil {
+		t.Fatalf("GenerateAlertmanagerConfig 返回错误: %v", err)
+	}
+	if _, ok := configs[emptyPool.Name]; ok || len(configs) != 1 {
+		t.Fatalf("没有发送组的池子不应生成配置, 实际生成 %d 份", len(configs))
+	}
+
+	cfg, err := altconfig.Load(string(configs[pool.Name]))
+	if err != nil {
+		t.Fatalf("生成的配置无法被 Alertmanager 加载: %v\n%s", err, configs[pool.Name])
+	}
+
+	// 默认接收者未被发送组定义时补充空接收者
+	receivers := make(map[string]altconfig.Receiver, len(cfg.Receivers))
+	for _, receiver := range cfg.Receivers {
+		receivers[receiver.Name] = receiver
+	}
+	if len(receivers) != 3 {
+		t.Fatalf("期望接收者 ops、dba、default, 实际 %v", cfg.Receivers)
+	}
+	if def, ok := receivers["default"]; !ok || len(def.WebhookConfigs) != 0 {
+		t.Fatalf("默认接收者应为空接收者, 实际 %+v", def)
+	}
+
+	if cfg.Route.Receiver != "default" || len(cfg.Route.GroupByStr) != 1 || cfg.Route.GroupByStr[0] != "alertname" {
+		t.Fatalf("根路由应使用池子的默认接收者和分组标签, 实际 %+v", cfg.Route)
+	}
+	if len(cfg.Route.Routes) != 2 {
+		t.Fatalf("期望 2 条子路由, 实际 %d", len(cfg.Route.Routes))
+	}
+	for _, c := range []struct {
+		route  *altconfig.Route
+		name   string
+		id     string
+		repeat time.Duration
+	}{
+		{cfg.Route.Routes[0], "ops", "11", 2 * time.Hour},
+		{cfg.Route.Routes[1], "dba", "12", time.Hour},
+	} {
+		if c.route.Receiver != c.name {
+			t.Fatalf("路由接收者期望 %s, 实际 %s", c.name, c.route.Receiver)
+		}
+		if len(c.route.Matchers) != 1 || c.route.Matchers[0].Name != alertSendGroupKey || c.route.Matchers[0].Value != c.id {
+			t.Fatalf("发送组 %s 的路由应按 %s=%s 匹配, 实际 %v", c.name, alertSendGroupKey, c.id, c.route.Matchers)
+		}
+		if c.route.RepeatInterval == nil || time.Duration(*c.route.RepeatInterval) != c.repeat {
+			t.Fatalf("发送组 %s 的重复间隔期望 %s, 实际 %v", c.name, c.repeat, c.route.RepeatInterval)
+		}
+
+		receiver := receivers[c.name]
+		if len(receiver.WebhookConfigs) != 1 {
+			t.Fatalf("发送组 %s 应有一个 webhook 接收配置, 实际 %+v", c.name, receiver)
+		}
+		data, err := os.ReadFile(receiver.WebhookConfigs[0].URLFile)
+		if err != nil {
+			t.Fatalf("读取 webhook 地址文件失败: %v", err)
+		}
+		if want := a.alertWebhookAddr + "?" + alertSendGroupKey + "=" + c.id; strings.TrimSpace(string(data)) != want {
+			t.Fatalf("webhook 地址期望 %s, 实际 %s", want, data)
+		}
+	}
+	if !receivers["ops"].WebhookConfigs[0].SendResolved() || receivers["dba"].WebhookConfigs[0].SendResolved() {
+		t.Fatal("接收者应按发送组配置是否发送恢复通知")
+	}
+}
@@ -30,6 +30,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/GoSimplicity/AI-CloudOps/pkg/utils"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 )
@@ -133,3 +135,19 @@ func (mc *monitorCache) executeTask(ctx context.Context, taskName string, taskFn
 	)
 	return nil
 }
+
+// newConfigPreview 根据新渲染的配置和当前下发的配置构建预览结果
+func newConfigPreview(pool, instance string, config []byte, deployed string) *model.ConfigPreview {
+	rendered := string(config)
+	preview := &model.ConfigPreview{
+		Pool:     pool,
+		Instance: instance,
+		Config:   rendered,
+		Deployed: deployed,
+		Changed:  rendered != deployed,
+	}
+	if preview.Changed {
+		preview.Diff = utils.DiffLines(deployed, rendered)
+	}
+	return preview
+}
@@ -27,7 +27,10 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	nethttp "net/http"
 	"os"
 	"strings"
 	"sync"
@@ -37,6 +40,7 @@ import (
 
 	"github.com/GoSimplicity/AI-CloudOps/internal/model"
 	scrapeJobDao "github.com/GoSimplicity/AI-CloudOps/internal/prometheus/dao/scrape"
+	treeDao "github.com/GoSimplicity/AI-CloudOps/internal/tree/dao"
 	pcc "github.com/prometheus/common/config"
 	pm "github.com/prometheus/common/model"
 	pc "github.com/prometheus/prometheus/config"
@@ -54,9 +58,18 @@ const hashTmpKey = "__tmp_hash"
 type PromConfigCache interface {
 	GetPrometheusMainConfigByIP(ip string) string
 	GeneratePrometheusMainConfig(ctx context.Context) error
+	PreviewPrometheusMainConfig(ctx context.Context) ([]*model.ConfigPreview, error)
 	CreateBasePrometheusConfig(pool *model.MonitorScrapePool) (pc.Config, error)
 	GenerateScrapeConfigs(ctx context.Context, pool *model.MonitorScrapePool) []*pc.ScrapeConfig
 	ApplyHashMod(scrapeConfigs []*pc.ScrapeConfig, modNum, index int) []*pc.ScrapeConfig
+	GenerateFileSD(ctx context.Context, poolID int) ([]byte, error)
+	ReloadPoolPrometheus(ctx context.Context, pool *model.MonitorScrapePool) error
+}
+
+// FileSDTargetGroup Prometheus file_sd_configs 目标组
+type FileSDTargetGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels,omitempty"`
 }
 
 type promConfigCache struct {
@@ -66,20 +79,24 @@ type promConfigCache struct {
 	localYamlDir            string
 	scrapePoolDao           scrapeJobDao.ScrapePoolDAO
 	scrapeJobDao            scrapeJobDao.ScrapeJobDAO
+	treeNodeDao             treeDao.TreeNodeDAO
 	httpSdAPI               string
+	httpClient              *nethttp.Client
 	poolHashes              map[string]string
 }
 
-func NewPromConfigCache(l *zap.Logger, scrapePoolDao scrapeJobDao.ScrapePoolDAO, scrapeJobDao scrapeJobDao.ScrapeJobDAO) PromConfigCache {
+func NewPromConfigCache(l *zap.Logger, scrapePoolDao scrapeJobDao.ScrapePoolDAO, scrapeJobDao scrapeJobDao.ScrapeJobDAO, treeNodeDao treeDao.TreeNodeDAO, httpClient *nethttp.Client) PromConfigCache {
 	return &promConfigCache{
 		PrometheusMainConfigMap: make(map[string]string),
 		localYamlDir:            viper.GetString("prometheus.local_yaml_dir"),
 		httpSdAPI:               viper.GetString("prometheus.httpSdAPI"),
 		scrapePoolDao:           scrapePoolDao,
 		scrapeJobDao:            scrapeJobDao,
+		treeNodeDao:             treeNodeDao,
 		l:                       l,
 		mu:                      sync.RWMutex{},
 		poolHashes:              make(map[string]string),
+		httpClient:              httpClient,
 	}
 }
 
@@ -118,34 +135,21 @@ func (p *promConfigCache) GeneratePrometheusMainConfig(ctx context.Context) erro
 		// 标记该池子需要清理旧IP
 		updatedPools[pool.Name] = struct{}{}
 
-		baseConfig, err := p.CreateBasePrometheusConfig(pool)
+		yamlConfigs, err := p.renderPrometheusConfigOnePool(ctx, pool)
 		if err != nil {
-			p.l.Error("创建基础配置失败", zap.String("池子", pool.Name), zap.Error(err))
+			p.l.Error("生成Prometheus配置失败", zap.String("池子", pool.Name), zap.Error(err))
 			continue
 		}
-
-		scrapeConfigs := p.GenerateScrapeConfigs(ctx, pool)
-		if len(scrapeConfigs) == 0 {
+		if yamlConfigs == nil {
 			p.l.Info("未生成采集配置", zap.String("池子", pool.Name))
 			continue
 		}
-		baseConfig.ScrapeConfigs = scrapeConfigs
 
 		instanceConfigs := make(map[string]string) // 暂存实例配置
 		success := true
 
 		for idx, ip := range pool.PrometheusInstances {
-			configCopy := baseConfig
-			if len(pool.PrometheusInstances) > 1 {
-				configCopy.ScrapeConfigs = p.ApplyHashMod(scrapeConfigs, len(pool.PrometheusInstances), idx)
-			}
-
-			yamlData, err := yaml.Marshal(configCopy)
-			if err != nil {
-				p.l.Error("配置序列化失败", zap.String("池子", pool.Name), zap.Error(err))
-				success = false
-				break
-			}
+			yamlData := yamlConfigs[idx]
 
 			dir := fmt.Sprintf("%s/%s", p.localYamlDir, pool.Name)
 			if err := os.MkdirAll(dir, 0755); err != nil {
@@ -171,6 +175,9 @@ func (p *promConfigCache) GeneratePrometheusMainConfig(ctx context.Context) erro
 				validIPs[ip] = struct{}{}
 			}
 			tempPoolHashes[pool.Name] = currentHash
+
+			// 配置文件已落盘，通知 Prometheus 重新加载；失败只记录日志，下一轮配置变更时会再次触发
+			_ = p.ReloadPoolPrometheus(ctx, pool)
 		} else {
 			// 失败时删除可能已写入的临时文件
 			utils.CleanupFailedPool(p.localYamlDir, pool, len(pool.PrometheusInstances))
@@ -189,6 +196,62 @@ func (p *promConfigCache) GeneratePrometheusMainConfig(ctx context.Context) erro
 	return nil
 }
 
+// PreviewPrometheusMainConfig 渲染所有采集池的Prometheus配置并与当前下发的配置对比，不写入文件、不更新缓存
+func (p *promConfigCache) PreviewPrometheusMainConfig(ctx context.Context) ([]*model.ConfigPreview, error) {
+	pools, err := p.scrapePoolDao.GetAllMonitorScrapePool(ctx)
+	if err != nil {
+		p.l.Error("获取采集池失败", zap.Error(err))
+		return nil, err
+	}
+
+	previews := make([]*model.ConfigPreview, 0, len(pools))
+	for _, pool := range pools {
+		yamlConfigs, err := p.renderPrometheusConfigOnePool(ctx, pool)
+		if err != nil {
+			return nil, fmt.Errorf("生成采集池 %s 配置失败: %w", pool.Name, err)
+		}
+		if yamlConfigs == nil {
+			continue
+		}
+
+		for idx, ip := range pool.PrometheusInstances {
+			previews = append(previews, newConfigPreview(pool.Name, ip, yamlConfigs[idx], p.GetPrometheusMainConfigByIP(ip)))
+		}
+	}
+
+	return previews, nil
+}
+
+// renderPrometheusConfigOnePool 渲染采集池下每个实例的Prometheus配置，返回顺序与 pool.PrometheusInstances 一致，未生成采集配置时返回nil
+func (p *promConfigCache) renderPrometheusConfigOnePool(ctx context.Context, pool *model.MonitorScrapePool) ([][]byte, error) {
+	baseConfig, err := p.CreateBasePrometheusConfig(pool)
+	if err != nil {
+		return nil, fmt.Errorf("创建基础配置失败: %w", err)
+	}
+
+	scrapeConfigs := p.GenerateScrapeConfigs(ctx, pool)
+	if len(scrapeConfigs) == 0 {
+		return nil, nil
+	}
+	baseConfig.ScrapeConfigs = scrapeConfigs
+
+	yamlConfigs := make([][]byte, 0, len(pool.PrometheusInstances))
+	for idx := range pool.PrometheusInstances {
+		configCopy := baseConfig
+		if len(pool.PrometheusInstances) > 1 {
+			configCopy.ScrapeConfigs = p.ApplyHashMod(scrapeConfigs, len(pool.PrometheusInstances), idx)
+		}
+
+		yamlData, err := yaml.Marshal(configCopy)
+		if err != nil {
+			return nil, fmt.Errorf("配置序列化失败: %w", err)
+		}
+		yamlConfigs = append(yamlConfigs, yamlData)
+	}
+
+	return yamlConfigs, nil
+}
+
 // CreateBasePrometheusConfig 创建基础Prometheus配置
 func (p *promConfigCache) CreateBasePrometheusConfig(pool *model.MonitorScrapePool) (pc.Config, error) {
 	var config pc.Config
@@ -377,3 +440,85 @@ func (p *promConfigCache) ApplyHashMod(scrapeConfigs []*pc.ScrapeConfig, modNum,
 
 	return modified
 }
+
+// GenerateFileSD 生成采集池下已启用采集任务的 Prometheus file_sd_configs JSON
+func (p *promConfigCache) GenerateFileSD(ctx context.Context, poolID int) ([]byte, error) {
+	pool, err := p.scrapePoolDao.GetMonitorScrapePoolById(ctx, poolID)
+	if err != nil {
+		p.l.Error("获取采集池失败", zap.Error(err), zap.Int("poolID", poolID))
+		return nil, err
+	}
+
+	scrapeJobs, err := p.scrapeJobDao.GetMonitorScrapeJobsByPoolId(ctx, pool.ID)
+	if err != nil {
+		p.l.Error("获取采集任务失败", zap.Error(err), zap.String("池名", pool.Name))
+		return nil, err
+	}
+
+	// 解析采集池外部标签，合并到每个目标组中
+	externalLabels := utils.ParseExternalLabels(pool.ExternalLabels)
+
+	targetGroups := make([]*FileSDTargetGroup, 0)
+
+	for _, job := range scrapeJobs {
+		nodeIDs, err := utils.ConvertToIntList(job.TreeNodeIDs)
+		if err != nil {
+			p.l.Warn("无效的服务树节点ID", zap.Strings("节点", job.TreeNodeIDs), zap.String("任务名", job.Name), zap.Error(err))
+			continue
+		}
+
+		nodes, err := p.treeNodeDao.GetByIDs(ctx, nodeIDs)
+		if err != nil {
+			p.l.Error("获取服务树节点失败", zap.Ints("节点", nodeIDs), zap.String("任务名", job.Name), zap.Error(err))
+			return nil, err
+		}
+
+		for _, node := range nodes {
+			for _, ecs := range node.BindEcs {
+				labels := make(map[string]string)
+
+				if len(ecs.Tags)%2 == 0 {
+					tags, err := utils.ParseTags(ecs.Tags)
+					if err != nil {
+						p.l.Warn("解析 ECS 实例的 Tags 失败", zap.Int("node_id", node.ID), zap.Error(err))
+					}
+					for k, v := range tags {
+						labels[string(k)] = string(v)
+					}
+				}
+
+				for i := 0; i+1 < len(externalLabels); i += 2 {
+					labels[externalLabels[i]] = externalLabels[i+1]
+				}
+
+				targetGroups = append(targetGroups, &FileSDTargetGroup{
+					Targets: []string{fmt.Sprintf("%s:%d", ecs.IpAddr, job.Port)},
+					Labels:  labels,
+				})
+			}
+		}
+	}
+
+	data, err := json.Marshal(targetGroups)
+	if err != nil {
+		p.l.Error("序列化 file_sd 目标失败", zap.Error(err), zap.String("池名", pool.Name))
+		return nil, fmt.Errorf("序列化 file_sd 目标失败: %w", err)
+	}
+
+	return data, nil
+}
+
+// ReloadPoolPrometheus 通知采集池下的所有 Prometheus 实例重新加载配置
+func (p *promConfigCache) ReloadPoolPrometheus(ctx context.Context, pool *model.MonitorScrapePool) error {
+	var errs []error
+
+	for _, ip := range pool.PrometheusInstances {
+		prometheusURL := fmt.Sprintf("http://%s:%d", ip, pool.PrometheusPort)
+		if err := utils.ReloadPrometheus(ctx, p.httpClient, p.l, prometheusURL); err != nil {
+			p.l.Error("重新加载 Prometheus 配置失败", zap.String("池子", pool.Name), zap.String("实例", ip), zap.Error(err))
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
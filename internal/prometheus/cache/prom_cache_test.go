@@ -0,0 +1,255 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	nethttp "net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	scrapeJobDao "github.com/GoSimplicity/AI-CloudOps/internal/prometheus/dao/scrape"
+	treeDao "github.com/GoSimplicity/AI-CloudOps/internal/tree/dao"
+	"github.com/prometheus/prometheus/discovery/targetgroup"
+	"go.uber.org/zap"
+)
+
+type stubScrapePoolDAO struct {
+	scrapeJobDao.ScrapePoolDAO
+	pools []*model.MonitorScrapePool
+}
+
+func (s *stubScrapePoolDAO) GetAllMonitorScrapePool(context.Context) ([]*model.MonitorScrapePool, error) {
+	return s.pools, nil
+}
+
+func (s *stubScrapePoolDAO) GetMonitorScrapePoolById(_ context.Context, id int) (*model.MonitorScrapePool, error) {
+	for _, pool := range s.pools {
+		if pool.ID == id {
+			return pool, nil
+		}
+	}
+	return nil, nil
+}
+
+type stubScrapeJobDAO struct {
+	scrapeJobDao.ScrapeJobDAO
+	jobs []*model.MonitorScrapeJob
+}
+
+func (s *stubScrapeJobDAO) GetMonitorScrapeJobsByPoolId(context.Context, int) ([]*model.MonitorScrapeJob, error) {
+	return s.jobs, nil
+}
+
+type stubTreeNodeDAO struct {
+	treeDao.TreeNodeDAO
+	nodes []*model.TreeNode
+}
+
+func (s *stubTreeNodeDAO) GetByIDs(context.Context, []int) ([]*model.TreeNode, error) {
+	return s.nodes, nil
+}
+
+// TestGenerateFileSDMatchesPrometheusFormat 生成的 file_sd 内容需能被 Prometheus 的目标组解析
+func TestGenerateFileSDMatchesPrometheusFormat(t *testing.T) {
+	pool := &model.MonitorScrapePool{ID: 1, Name: "pool", ExternalLabels: model.StringList{"region=bj"}}
+	job := &model.MonitorScrapeJob{Name: "node", Port: 9100, TreeNodeIDs: model.StringList{"1"}}
+	nodes := []*model.TreeNode{{
+		Model: model.Model{ID: 1},
+		BindEcs: []*model.ResourceEcs{
+			{ResourceTree: model.ResourceTree{IpAddr: "10.0.0.1", Tags: model.StringList{"env", "prod"}}},
+			{ResourceTree: model.ResourceTree{IpAddr: "10.0.0.2"}},
+		},
+	}}
+
+	p := NewPromConfigCache(zap.NewNop(),
+		&stubScrapePoolDAO{pools: []*model.MonitorScrapePool{pool}},
+		&stubScrapeJobDAO{jobs: []*model.MonitorScrapeJob{job}},
+		&stubTreeNodeDAO{nodes: nodes},
+		nil,
+	)
+
+	data, err := p.GenerateFileSD(context.Background(), pool.ID)
+	if err != nil {
+		t.Fatalf("生成 file_sd 失败: %v", err)
+	}
+
+	var groups []*targetgroup.Group
+	if err := json.Unmarshal(data, &groups); err != nil {
+		t.Fatalf("file_sd 内容不符合 Prometheus 格式: %v, data=%s", err, data)
+	}
+	if len(groups) != 2 {
+		t.Fatalf("期望 2 个目标组, 实际 %d", len(groups))
+	}
+
+	first := groups[0]
+	if len(first.Targets) != 1 || first.Targets[0]["__address__"] != "10.0.0.1:9100" {
+		t.Fatalf("目标地址不符合预期: %+v", first.Targets)
+	}
+	if first.Labels["env"] != "prod" || first.Labels["region"] != "bj" {
+		t.Fatalf("目标组应包含实例标签和采集池外部标签: %+v", first.Labels)
+	}
+	if groups[1].Labels["region"] != "bj" {
+		t.Fatalf("无实例标签的目标组也应包含外部标签: %+v", groups[1].Labels)
+	}
+}
+
+// newReloadingPromCache 创建指向测试 Prometheus 实例的配置缓存，返回采集池和重新加载次数
+func newReloadingPromCache(t *testing.T) (*promConfigCache, *model.MonitorScrapePool, *int32) {
+	t.Helper()
+	var reloads int32
+	srv := httptest.NewServer(nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		if r.Method == nethttp.MethodPost && r.URL.Path == "/-/reload" {
+			atomic.AddInt32(&reloads, 1)
+		}
+		w.WriteHeader(nethttp.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	host, portStr, err := net.SplitHostPort(srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("解析测试服务地址失败: %v", err)
+	}
+	port, _ := strconv.Atoi(portStr)
+
+	pool := &model.MonitorScrapePool{
+		ID:                  1,
+		Name:                "pool",
+		PrometheusInstances: model.StringList{host},
+		PrometheusPort:      port,
+		ScrapeInterval:      30,
+		ScrapeTimeout:       10,
+	}
+	job := &model.MonitorScrapeJob{
+		Name:                 "node",
+		ServiceDiscoveryType: "http",
+		Scheme:               "http",
+		MetricsPath:          "/metrics",
+		ScrapeInterval:       30,
+		ScrapeTimeout:        10,
+		RefreshInterval:      300,
+		Port:                 9100,
+	}
+
+	p := NewPromConfigCache(zap.NewNop(), &stubScrapePoolDAO{pools: []*model.MonitorScrapePool{pool}}, &stubScrapeJobDAO{jobs: []*model.MonitorScrapeJob{job}}, nil, srv.Client()).(*promConfigCache)
+	p.localYamlDir = t.TempDir()
+	p.httpSdAPI = "http://sd.example.com/api"
+	return p, pool, &reloads
+}
+
+func TestGeneratePrometheusMainConfigReloadsPrometheus(t *testing.T) {
+	p, _, reloads := newReloadingPromCache(t)
+
+	if err := p.GeneratePrometheusMainConfig(context.Background()); err != nil {
+		t.Fatalf("生成配置失败: %v", err)
+	}
+	if _, err := os.Stat(p.localYamlDir + "/pool/prometheus_pool_pool_0.yaml"); err != nil {
+		t.Fatalf("配置文件未写入: %v", err)
+	}
+	if got := atomic.LoadInt32(reloads); got != 1 {
+		t.Fatalf("写入配置后应重新加载 Prometheus 一次，实际 %d 次", got)
+	}
+
+	// 配置未变化时不重复写入，也不触发重新加载
+	if err := p.GeneratePrometheusMainConfig(context.Background()); err != nil {
+		t.Fatalf("生成配置失败: %v", err)
+	}
+	if got := atomic.LoadInt32(reloads); got != 1 {
+		t.Fatalf("配置未变化时不应重新加载，实际 %d 次", got)
+	}
+}
+
+func TestPreviewPrometheusMainConfigHasNoSideEffects(t *testing.T) {
+	p, pool, reloads := newReloadingPromCache(t)
+	ctx := context.Background()
+	host := pool.PrometheusInstances[0]
+
+	previews, err := p.PreviewPrometheusMainConfig(ctx)
+	if err != nil {
+		t.Fatalf("预览配置失败: %v", err)
+	}
+	if len(previews) != 1 || previews[0].Pool != "pool" || previews[0].Instance != host {
+		t.Fatalf("应返回采集池实例的 1 个预览, 实际 %+v", previews)
+	}
+	if !previews[0].Changed || previews[0].Config == "" || previews[0].Deployed != "" {
+		t.Fatalf("首次预览应为新增配置, 实际 %+v", previews[0])
+	}
+
+	// 预览不写文件、不更新缓存、不触发重新加载
+	entries, err := os.ReadDir(p.localYamlDir)
+	if err != nil {
+		t.Fatalf("读取配置目录失败: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("预览不应写入配置文件, 实际 %d 个", len(entries))
+	}
+	if got := p.GetPrometheusMainConfigByIP(host); got != "" {
+		t.Fatalf("预览不应更新配置缓存, 实际 %q", got)
+	}
+	if got := atomic.LoadInt32(reloads); got != 0 {
+		t.Fatalf("预览不应重新加载 Prometheus, 实际 %d 次", got)
+	}
+
+	if err := p.GeneratePrometheusMainConfig(ctx); err != nil {
+		t.Fatalf("生成配置失败: %v", err)
+	}
+	deployed := p.GetPrometheusMainConfigByIP(host)
+	if deployed != previews[0].Config {
+		t.Fatal("预览的配置应与实际下发的配置一致")
+	}
+
+	// 配置未变化时预览无差异
+	previews, err = p.PreviewPrometheusMainConfig(ctx)
+	if err != nil {
+		t.Fatalf("预览配置失败: %v", err)
+	}
+	if previews[0].Changed || previews[0].Diff != "" {
+		t.Fatalf("配置未变化时不应有差异, 实际 %+v", previews[0])
+	}
+
+	// 修改采集池后预览给出差异，但已下发的配置保持不变
+	pool.ScrapeInterval = 60
+	previews, err = p.PreviewPrometheusMainConfig(ctx)
+	if err != nil {
+		t.Fatalf("预览配置失败: %v", err)
+	}
+	if !previews[0].Changed || !strings.Contains(previews[0].Diff, "- ") || !strings.Contains(previews[0].Diff, "+ ") {
+		t.Fatalf("配置变化时应返回差异, 实际 %+v", previews[0])
+	}
+	if p.GetPrometheusMainConfigByIP(host) != deployed {
+		t.Fatal("预览不应覆盖已下发的配置")
+	}
+	if got := atomic.LoadInt32(reloads); got != 1 {
+		t.Fatalf("预览不应额外触发重新加载, 实际 %d 次", got)
+	}
+}
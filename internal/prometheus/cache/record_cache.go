@@ -55,6 +55,7 @@ type recordConfigCache struct {
 	localYamlDir   string
 	scrapePoolDao  scrapePoolDao.ScrapePoolDAO
 	alertRecordDao alertRecordDao.AlertManagerRecordDAO
+	promConfig     PromConfigCache
 	recordHashes   map[string]string
 }
 
@@ -69,7 +70,7 @@ type RecordGroups struct {
 	Groups []RecordGroup `yaml:"groups"`
 }
 
-func NewRecordConfig(l *zap.Logger, scrapePoolDao scrapePoolDao.ScrapePoolDAO, alertRecordDao alertRecordDao.AlertManagerRecordDAO) RecordConfigCache {
+func NewRecordConfig(l *zap.Logger, scrapePoolDao scrapePoolDao.ScrapePoolDAO, alertRecordDao alertRecordDao.AlertManagerRecordDAO, promConfig PromConfigCache) RecordConfigCache {
 	return &recordConfigCache{
 		l:              l,
 		localYamlDir:   viper.GetString("prometheus.local_yaml_dir"),
@@ -77,6 +78,7 @@ func NewRecordConfig(l *zap.Logger, scrapePoolDao scrapePoolDao.ScrapePoolDAO, a
 		RecordRuleMap:  make(map[string]string),
 		scrapePoolDao:  scrapePoolDao,
 		alertRecordDao: alertRecordDao,
+		promConfig:     promConfig,
 		recordHashes:   make(map[string]string),
 	}
 }
@@ -132,6 +134,9 @@ func (r *recordConfigCache) GenerateRecordRuleConfigYaml(ctx context.Context) er
 				validIPs[ip] = struct{}{}
 			}
 			tempPoolHashes[pool.Name] = currentHash
+
+			// 预聚合规则文件已落盘，通知 Prometheus 重新加载
+			_ = r.promConfig.ReloadPoolPrometheus(ctx, pool)
 		}
 	}
 
@@ -27,6 +27,7 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"os"
@@ -48,16 +49,17 @@ type RuleConfigCache interface {
 	GetPrometheusAlertRuleConfigYamlByIp(ip string) string
 	GenerateAlertRuleConfigYaml(ctx context.Context) error
 	GeneratePrometheusAlertRuleConfigYamlOnePool(ctx context.Context, pool *model.MonitorScrapePool) map[string]string
+	ExportRulesToYAML(ctx context.Context, poolID int) ([]byte, error)
 }
 
 type ruleConfigCache struct {
-
 	AlertRuleMap  map[string]string
 	mu            sync.RWMutex
 	l             *zap.Logger
 	localYamlDir  string
 	scrapePoolDao scrapePoolDao.ScrapePoolDAO
 	alertRuleDao  alertRuleDao.AlertManagerRuleDAO
+	promConfig    PromConfigCache
 	ruleHashes    map[string]string
 }
 
@@ -72,7 +74,7 @@ type RuleGroups struct {
 	Groups []RuleGroup `yaml:"groups"`
 }
 
-func NewRuleConfigCache(l *zap.Logger, scrapePoolDao scrapePoolDao.ScrapePoolDAO, alertRuleDao alertRuleDao.AlertManagerRuleDAO) RuleConfigCache {
+func NewRuleConfigCache(l *zap.Logger, scrapePoolDao scrapePoolDao.ScrapePoolDAO, alertRuleDao alertRuleDao.AlertManagerRuleDAO, promConfig PromConfigCache) RuleConfigCache {
 	return &ruleConfigCache{
 		l:             l,
 		AlertRuleMap:  make(map[string]string),
@@ -80,6 +82,7 @@ func NewRuleConfigCache(l *zap.Logger, scrapePoolDao scrapePoolDao.ScrapePoolDAO
 		mu:            sync.RWMutex{},
 		scrapePoolDao: scrapePoolDao,
 		alertRuleDao:  alertRuleDao,
+		promConfig:    promConfig,
 		ruleHashes:    make(map[string]string),
 	}
 }
@@ -135,6 +138,11 @@ func (r *ruleConfigCache) GenerateAlertRuleConfigYaml(ctx context.Context) error
 				validIPs[ip] = struct{}{}
 			}
 			tempPoolHashes[pool.Name] = currentHash
+
+			// 规则文件已落盘，通知 Prometheus 重新加载
+			if err := r.promConfig.ReloadPoolPrometheus(ctx, pool); err != nil {
+				r.l.Error("重载Prometheus失败", zap.String("池子", pool.Name), zap.Error(err))
+			}
 		}
 	}
 
@@ -164,34 +172,7 @@ func (r *ruleConfigCache) GeneratePrometheusAlertRuleConfigYamlOnePool(ctx conte
 		return nil
 	}
 
-	var ruleGroups RuleGroups
-
-	// 构建规则组
-	for _, rule := range rules {
-		ft, err := pm.ParseDuration(rule.ForTime)
-		if err != nil {
-			r.l.Warn("[监控模块] 解析告警规则持续时间失败，使用默认值",
-				zap.Error(err),
-				zap.String("规则", rule.Name),
-			)
-			ft, _ = pm.ParseDuration("5s")
-		}
-
-		oneRule := rulefmt.Rule{
-			Alert:       rule.Name,
-			Expr:        rule.Expr,
-			For:         ft,
-			Labels:      utils.FromSliceTuMap(rule.Labels),
-			Annotations: utils.FromSliceTuMap(rule.Annotations),
-		}
-
-		ruleGroup := RuleGroup{
-			Name:  rule.Name,
-
-			Rules: []rulefmt.Rule{oneRule},
-		}
-		ruleGroups.Groups = append(ruleGroups.Groups, ruleGroup)
-	}
+	ruleGroups := r.buildRuleGroups(rules)
 
 	numInstances := len(pool.PrometheusInstances)
 	if numInstances == 0 {
@@ -202,7 +183,6 @@ func (r *ruleConfigCache) GeneratePrometheusAlertRuleConfigYamlOnePool(ctx conte
 	ruleMap := make(map[string]string)
 	success := true
 
-
 	// 分片逻辑，将规则分配给不同的Prometheus实例
 	for i, ip := range pool.PrometheusInstances {
 		var myRuleGroups RuleGroups
@@ -225,7 +205,6 @@ func (r *ruleConfigCache) GeneratePrometheusAlertRuleConfigYamlOnePool(ctx conte
 			break
 		}
 
-
 		// 生成文件路径并写入
 		dir := fmt.Sprintf("%s/%s", r.localYamlDir, pool.Name)
 		if err := os.MkdirAll(dir, 0755); err != nil {
@@ -258,3 +237,60 @@ func (r *ruleConfigCache) GeneratePrometheusAlertRuleConfigYamlOnePool(ctx conte
 
 	return ruleMap
 }
+
+// ExportRulesToYAML 导出采集池下已启用的告警规则为 Prometheus 规则组 YAML
+func (r *ruleConfigCache) ExportRulesToYAML(ctx context.Context, poolID int) ([]byte, error) {
+	rules, err := r.alertRuleDao.GetMonitorAlertRuleByPoolId(ctx, poolID)
+	if err != nil {
+		r.l.Error("[监控模块] 根据采集池ID获取告警规则失败", zap.Error(err), zap.Int("poolID", poolID))
+		return nil, err
+	}
+
+	ruleGroups := r.buildRuleGroups(rules)
+
+	yamlData, err := yaml.Marshal(&ruleGroups)
+	if err != nil {
+		r.l.Error("[监控模块] 序列化告警规则YAML失败", zap.Error(err), zap.Int("poolID", poolID))
+		return nil, fmt.Errorf("序列化告警规则YAML失败: %w", err)
+	}
+
+	// 使用 rulefmt 校验生成的规则文件
+	if _, errs := rulefmt.Parse(yamlData); len(errs) > 0 {
+		r.l.Error("[监控模块] 告警规则YAML校验失败", zap.Errors("errors", errs), zap.Int("poolID", poolID))
+		return nil, fmt.Errorf("告警规则YAML校验失败: %w", errors.Join(errs...))
+	}
+
+	return yamlData, nil
+}
+
+// buildRuleGroups 将告警规则构建为规则组，每条规则单独成组
+func (r *ruleConfigCache) buildRuleGroups(rules []*model.MonitorAlertRule) RuleGroups {
+	var ruleGroups RuleGroups
+
+	for _, rule := range rules {
+		ft, err := pm.ParseDuration(rule.ForTime)
+		if err != nil {
+			r.l.Warn("[监控模块] 解析告警规则持续时间失败，使用默认值",
+				zap.Error(err),
+				zap.String("规则", rule.Name),
+			)
+			ft, _ = pm.ParseDuration("5s")
+		}
+
+		oneRule := rulefmt.Rule{
+			Alert:       rule.Name,
+			Expr:        rule.Expr,
+			For:         ft,
+			Labels:      utils.FromSliceTuMap(rule.Labels),
+			Annotations: utils.FromSliceTuMap(rule.Annotations),
+		}
+
+		ruleGroup := RuleGroup{
+			Name:  rule.Name,
+			Rules: []rulefmt.Rule{oneRule},
+		}
+		ruleGroups.Groups = append(ruleGroups.Groups, ruleGroup)
+	}
+
+	return ruleGroups
+}
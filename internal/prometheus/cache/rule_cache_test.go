@@ -0,0 +1,108 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	alertDao "github.com/GoSimplicity/AI-CloudOps/internal/prometheus/dao/alert"
+	"github.com/glebarez/sqlite"
+	"github.com/prometheus/prometheus/model/rulefmt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestRuleDB 创建内存 sqlite 数据库并写入告警规则
+func newTestRuleDB(t *testing.T, rules ...*model.MonitorAlertRule) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("打开测试数据库失败: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取数据库连接失败: %v", err)
+	}
+	// 内存库每个连接独立，限制为单连接保证数据可见
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	if err := db.Migrator().CreateTable(&model.MonitorAlertRule{}); err != nil {
+		t.Fatalf("迁移测试表失败: %v", err)
+	}
+	for _, rule := range rules {
+		enable := rule.Enable
+		if err := db.Create(rule).Error; err != nil {
+			t.Fatalf("写入测试告警规则失败: %v", err)
+		}
+		// enable 列带默认值，false 不会随 Create 写入
+		if err := db.Model(rule).UpdateColumn("enable", enable).Error; err != nil {
+			t.Fatalf("设置告警规则启用状态失败: %v", err)
+		}
+	}
+	return db
+}
+
+// TestExportRulesToYAMLOnlyEnabled 导出结果只包含采集池下已启用的规则，且能被 rulefmt 解析
+func TestExportRulesToYAMLOnlyEnabled(t *testing.T) {
+	db := newTestRuleDB(t,
+		&model.MonitorAlertRule{
+			Name:        "cpu_high",
+			PoolID:      1,
+			Enable:      true,
+			Expr:        "node_load1 > 10",
+			ForTime:     "2m",
+			Labels:      model.StringList{"severity=critical"},
+			Annotations: model.StringList{"summary=CPU 负载过高"},
+		},
+		&model.MonitorAlertRule{Name: "disk_full", PoolID: 1, Enable: false, Expr: "disk_used > 90", ForTime: "5m"},
+		&model.MonitorAlertRule{Name: "mem_high", PoolID: 2, Enable: true, Expr: "mem_used > 90", ForTime: "5m"},
+	)
+	r := NewRuleConfigCache(zap.NewNop(), nil, alertDao.NewAlertManagerRuleDAO(db, zap.NewNop(), nil), nil)
+
+	data, err := r.ExportRulesToYAML(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("ExportRulesToYAML 返回错误: %v", err)
+	}
+
+	groups, errs := rulefmt.Parse(data)
+	if len(errs) > 0 {
+		t.Fatalf("导出的规则无法被 rulefmt 解析: %v\n%s", errs, data)
+	}
+	if len(groups.Groups) != 1 || len(groups.Groups[0].Rules) != 1 {
+		t.Fatalf("期望只导出 1 条已启用规则, 实际 %s", data)
+	}
+	rule := groups.Groups[0].Rules[0]
+	if rule.Alert.Value != "cpu_high" || rule.Expr.Value != "node_load1 > 10" {
+		t.Fatalf("导出的规则不符合预期: alert=%s expr=%s", rule.Alert.Value, rule.Expr.Value)
+	}
+	if rule.Labels["severity"] != "critical" || rule.Annotations["summary"] != "CPU 负载过高" {
+		t.Fatalf("导出的规则应包含标签和注解, 实际 labels=%v annotations=%v", rule.Labels, rule.Annotations)
+	}
+}
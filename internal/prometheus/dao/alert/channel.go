@@ -0,0 +1,162 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+package alert
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"go.uber.org/zap"
+)
+
+// channelSendFunc 通知渠道的发送函数，通过 DAO 复用 HTTP 客户端、请求头和发送指标，返回渠道的响应内容
+type channelSendFunc func(a *alertManagerEventDAO, ctx context.Context, url string, message string) ([]byte, error)
+
+// notifyChannel 已注册的通知渠道
+type notifyChannel struct {
+	send       channelSendFunc
+	webhookURL func(token string) string // 由发送组配置的群机器人 Token 构建 webhook 地址
+}
+
+// channelRegistry 按名称注册的通知渠道，新增渠道只需在 init 中注册，无需修改发送逻辑
+var channelRegistry = struct {
+	sync.RWMutex
+	channels map[string]notifyChannel
+}{channels: make(map[string]notifyChannel)}
+
+func init() {
+	registerChannel(WebhookProviderFeishu, func(a *alertManagerEventDAO, ctx context.Context, url string, message string) ([]byte, error) {
+		return a.sendGroupMessage(ctx, url, message, "")
+	}, func(token string) string {
+		return "https://open.feishu.cn/open-apis/bot/v2/hook/" + token
+	})
+	registerChannel(WebhookProviderDingTalk, (*alertManagerEventDAO).sendDingTalkText, func(token string) string {
+		return "https://oapi.dingtalk.com/robot/send?access_token=" + token
+	})
+}
+
+// registerChannel 注册通知渠道，名称为空、函数为空或重复注册时 panic
+func registerChannel(name string, send channelSendFunc, webhookURL func(token string) string) {
+	if name == "" || send == nil || webhookURL == nil {
+		panic("alert: 注册通知渠道时名称、发送函数和地址构建函数不能为空")
+	}
+
+	channelRegistry.Lock()
+	defer channelRegistry.Unlock()
+
+	if _, exists := channelRegistry.channels[name]; exists {
+		panic(fmt.Sprintf("alert: 通知渠道 %s 重复注册", name))
+	}
+	channelRegistry.channels[name] = notifyChannel{send: send, webhookURL: webhookURL}
+}
+
+// lookupChannel 按名称查找已注册的通知渠道
+func lookupChannel(name string) (notifyChannel, bool) {
+	channelRegistry.RLock()
+	defer channelRegistry.RUnlock()
+
+	ch, ok := channelRegistry.channels[name]
+	return ch, ok
+}
+
+// IsRegisteredChannel 判断通知渠道是否已注册
+func IsRegisteredChannel(name string) bool {
+	_, ok := lookupChannel(name)
+	return ok
+}
+
+// sendGroupChannel 返回发送组配置的通知渠道，未配置时使用飞书
+func sendGroupChannel(sendGroup *model.MonitorSendGroup) string {
+	if sendGroup.ChannelType == "" {
+		return WebhookProviderFeishu
+	}
+	return sendGroup.ChannelType
+}
+
+// RegisteredChannels 返回已注册的通知渠道名称，按名称排序
+func RegisteredChannels() []string {
+	channelRegistry.RLock()
+	defer channelRegistry.RUnlock()
+
+	names := make([]string, 0, len(channelRegistry.channels))
+	for name := range channelRegistry.channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SendByChannel 按发送组配置的渠道类型查找已注册渠道并发送消息
+func (a *alertManagerEventDAO) SendByChannel(ctx context.Context, channel string, url string, message string) error {
+	if url == "" {
+		return fmt.Errorf("url不能为空")
+	}
+
+	ch, ok := lookupChannel(channel)
+	if !ok {
+		return fmt.Errorf("不支持的通知渠道: %s", channel)
+	}
+
+	_, err := ch.send(a, ctx, url, message)
+	return err
+}
+
+// SendGroupNotification 按发送组配置的渠道发送告警事件通知，并记录投递日志，记录失败不影响发送结果；
+// 飞书渠道会记录返回的消息ID
+func (a *alertManagerEventDAO) SendGroupNotification(ctx context.Context, eventID int, sendGroup *model.MonitorSendGroup, message string) error {
+	if sendGroup == nil {
+		return fmt.Errorf("发送组不能为空")
+	}
+	if sendGroup.FeiShuQunRobotToken == "" {
+		return fmt.Errorf("发送组 %s 未配置群机器人 Token", sendGroup.Name)
+	}
+
+	channel := sendGroupChannel(sendGroup)
+	ch, ok := lookupChannel(channel)
+	if !ok {
+		return fmt.Errorf("不支持的通知渠道: %s", channel)
+	}
+
+	body, err := ch.send(a, ctx, ch.webhookURL(sendGroup.FeiShuQunRobotToken), message)
+	if err != nil {
+		return err
+	}
+
+	var messageID string
+	if channel == WebhookProviderFeishu {
+		if messageID, err = ParseFeishuMessageID(body); err != nil {
+			a.logger(ctx).Warn("解析飞书消息ID失败", zap.Error(err), zap.Int("eventID", eventID))
+		}
+	}
+
+	if err := a.RecordNotification(ctx, eventID, channel, messageID); err != nil {
+		a.logger(ctx).Warn("记录告警通知失败", zap.Error(err), zap.Int("eventID", eventID))
+	}
+
+	return nil
+}
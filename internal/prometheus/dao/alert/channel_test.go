@@ -0,0 +1,95 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package alert
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+)
+
+// fakeChannelSent 记录测试渠道收到的请求
+var fakeChannelSent struct {
+	sync.Mutex
+	url     string
+	message string
+}
+
+var registerFakeChannelOnce sync.Once
+
+// registerFakeChannel 注册测试渠道，注册表为全局变量，多次运行测试时只注册一次
+func registerFakeChannel() {
+	registerFakeChannelOnce.Do(func() {
+		registerChannel("fake", func(_ *alertManagerEventDAO, _ context.Context, url string, message string) ([]byte, error) {
+			fakeChannelSent.Lock()
+			defer fakeChannelSent.Unlock()
+			fakeChannelSent.url, fakeChannelSent.message = url, message
+			return nil, nil
+		}, func(token string) string {
+			return "fake://" + token
+		})
+	})
+}
+
+func TestSendGroupNotificationDispatchesThroughRegistry(t *testing.T) {
+	registerFakeChannel()
+	d, db := newTestEventDAO(t)
+	ctx := context.Background()
+
+	sendGroup := &model.MonitorSendGroup{Name: "ops", ChannelType: "fake", FeiShuQunRobotToken: "tok"}
+	if err := d.SendGroupNotification(ctx, 1, sendGroup, "认领通知"); err != nil {
+		t.Fatalf("SendGroupNotification 返回错误: %v", err)
+	}
+
+	fakeChannelSent.Lock()
+	url, message := fakeChannelSent.url, fakeChannelSent.message
+	fakeChannelSent.Unlock()
+	if url != "fake://tok" || message != "认领通知" {
+		t.Fatalf("应通过发送组配置的渠道发送, 实际 url=%q message=%q", url, message)
+	}
+
+	var logs []model.NotificationLog
+	if err := db.Where("event_id = ?", 1).Find(&logs).Error; err != nil {
+		t.Fatalf("查询通知记录失败: %v", err)
+	}
+	if len(logs) != 1 || logs[0].Channel != "fake" {
+		t.Fatalf("应记录一条 fake 渠道的通知, 实际 %+v", logs)
+	}
+}
+
+func TestSendGroupNotificationRejectsUnknownChannel(t *testing.T) {
+	d, _ := newTestEventDAO(t)
+
+	sendGroup := &model.MonitorSendGroup{Name: "ops", ChannelType: "pigeon", FeiShuQunRobotToken: "tok"}
+	if err := d.SendGroupNotification(context.Background(), 1, sendGroup, "msg"); err == nil {
+		t.Fatal("未注册的渠道应返回错误")
+	}
+	if IsRegisteredChannel("pigeon") || !IsRegisteredChannel(WebhookProviderFeishu) {
+		t.Fatal("渠道注册状态不正确")
+	}
+}
@@ -0,0 +1,137 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+package alert
+
+import (
+	"sync"
+	"time"
+)
+
+// dedupeSweepInterval 批量清理过期令牌的最小间隔，两次清理之间过期令牌在访问时惰性判定
+const dedupeSweepInterval = time.Minute
+
+// dedupeEntry 去重令牌的发送时间、过期时间以及合并发送时保存的响应
+type dedupeEntry struct {
+	sentAt    time.Time
+	expiresAt time.Time
+	response  []byte
+}
+
+// dedupeTokens 带过期时间的去重令牌表，去重令牌和相同消息的合并发送共用该结构
+type dedupeTokens struct {
+	mu        sync.Mutex
+	tokens    map[string]dedupeEntry
+	lastSweep time.Time
+}
+
+func newDedupeTokens() *dedupeTokens {
+	return &dedupeTokens{
+		tokens:    make(map[string]dedupeEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+// reserve 尝试占用令牌，令牌在有效期内已被占用时返回上次发送时间和 false
+func (d *dedupeTokens) reserve(token string, window time.Duration) (time.Time, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	if entry, ok := d.lookupLocked(token, now); ok {
+		return entry.sentAt, false
+	}
+
+	d.setLocked(token, now, window, nil)
+	return now, true
+}
+
+// active 判断令牌是否仍在有效期内
+func (d *dedupeTokens) active(token string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	_, ok := d.lookupLocked(token, time.Now())
+	return ok
+}
+
+// mark 记录令牌在 window 时间窗口内有效，已存在时刷新发送时间
+func (d *dedupeTokens) mark(token string, window time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.setLocked(token, time.Now(), window, nil)
+}
+
+// markResponse 记录令牌在 window 时间窗口内有效，并保存本次发送的响应供窗口内合并的调用方返回
+func (d *dedupeTokens) markResponse(token string, window time.Duration, response []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.setLocked(token, time.Now(), window, response)
+}
+
+// response 返回有效令牌保存的响应，令牌不存在或已过期时返回 false
+func (d *dedupeTokens) response(token string) ([]byte, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	entry, ok := d.lookupLocked(token, time.Now())
+	return entry.response, ok
+}
+
+// release 释放令牌，使其可以被再次占用
+func (d *dedupeTokens) release(token string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.tokens, token)
+}
+
+// lookupLocked 查找有效令牌，访问到的过期令牌直接删除
+func (d *dedupeTokens) lookupLocked(token string, now time.Time) (dedupeEntry, bool) {
+	entry, ok := d.tokens[token]
+	if !ok {
+		return dedupeEntry{}, false
+	}
+	if !now.Before(entry.expiresAt) {
+		delete(d.tokens, token)
+		return dedupeEntry{}, false
+	}
+	return entry, true
+}
+
+// setLocked 写入令牌，距上次清理超过 dedupeSweepInterval 时顺带清理过期令牌，避免每次写入都遍历整张表
+func (d *dedupeTokens) setLocked(token string, now time.Time, window time.Duration, response []byte) {
+	if now.Sub(d.lastSweep) >= dedupeSweepInterval {
+		for k, entry := range d.tokens {
+			if !now.Before(entry.expiresAt) {
+				delete(d.tokens, k)
+			}
+		}
+		d.lastSweep = now
+	}
+
+	d.tokens[token] = dedupeEntry{sentAt: now, expiresAt: now.Add(window), response: response}
+}
@@ -0,0 +1,115 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package alert
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDedupeTokensExpireLazily(t *testing.T) {
+	d := newDedupeTokens()
+
+	if _, ok := d.reserve("a", 20*time.Millisecond); !ok {
+		t.Fatal("首次占用令牌应成功")
+	}
+	if _, ok := d.reserve("a", 20*time.Millisecond); ok {
+		t.Fatal("时间窗口内重复占用应被拒绝")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if d.active("a") {
+		t.Fatal("超出时间窗口的令牌应视为过期")
+	}
+	if _, ok := d.tokens["a"]; ok {
+		t.Fatal("访问到的过期令牌应被删除")
+	}
+	if _, ok := d.reserve("a", time.Minute); !ok {
+		t.Fatal("令牌过期后应可再次占用")
+	}
+
+	d.release("a")
+	if d.active("a") {
+		t.Fatal("释放后的令牌不应有效")
+	}
+}
+
+func TestDedupeTokensSweepOnlyAfterInterval(t *testing.T) {
+	d := newDedupeTokens()
+	d.mark("stale", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	// 未到清理间隔时写入其他令牌不遍历整张表
+	d.mark("fresh", time.Minute)
+	if _, ok := d.tokens["stale"]; !ok {
+		t.Fatal("未到清理间隔时不应批量清理")
+	}
+
+	d.lastSweep = time.Now().Add(-dedupeSweepInterval)
+	d.mark("another", time.Minute)
+	if _, ok := d.tokens["stale"]; ok {
+		t.Fatal("超过清理间隔后写入应清理过期令牌")
+	}
+	if len(d.tokens) != 2 {
+		t.Fatalf("未过期的令牌应保留, 实际 %d 个", len(d.tokens))
+	}
+}
+
+// TestDedupeAndCoalesceShareWindowMechanism 去重令牌和相同消息合并使用同一套时间窗口记录，事务副本共享合并记录
+func TestDedupeAndCoalesceShareWindowMechanism(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		_, _ = w.Write([]byte(`{"code":0}`))
+	}))
+	defer srv.Close()
+
+	d, db := newTestEventDAO(t)
+	ctx := context.Background()
+
+	for _, message := range []string{"msg-a", "msg-b", "msg-c"} {
+		if err := d.SendMessageToGroupWithDedupe(ctx, srv.URL, message, "token", time.Minute); err != nil {
+			t.Fatalf("发送消息失败: %v", err)
+		}
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Fatalf("相同去重令牌在时间窗口内应只发送一次, 实际 %d 次", n)
+	}
+
+	if err := d.SendMessageToGroup(ctx, srv.URL, "same"); err != nil {
+		t.Fatalf("发送消息失败: %v", err)
+	}
+	if err := d.withDB(db).SendMessageToGroup(ctx, srv.URL, "same"); err != nil {
+		t.Fatalf("发送消息失败: %v", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Fatalf("事务副本应共享合并记录，相同消息只发送一次, 实际共 %d 次", n)
+	}
+}
@@ -0,0 +1,41 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+package alert
+
+import "errors"
+
+// DAO 层哨兵错误，调用方通过 errors.Is 判断错误类型，不应依赖错误信息字符串
+var (
+	// ErrInvalidID ID 参数无效
+	ErrInvalidID = errors.New("无效的ID")
+	// ErrEventNotFound 告警事件不存在或已被删除
+	ErrEventNotFound = errors.New("告警事件不存在")
+	// ErrSilenced 告警事件已被屏蔽
+	ErrSilenced = errors.New("告警事件已被屏蔽")
+	// ErrAlertEventVersionConflict 告警事件已被并发修改，需重新读取后再更新
+	ErrAlertEventVersionConflict = errors.New("告警事件版本冲突")
+	// ErrPermissionDenied 调用方无权执行该操作
+	ErrPermissionDenied = errors.New("无权限执行该操作")
+)
@@ -0,0 +1,130 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// alertEventCacheKeyPrefix 告警事件指纹缓存的 key 前缀
+	alertEventCacheKeyPrefix = "cloudops:alert_event:fingerprint:"
+	// alertEventCacheTTL 告警事件指纹缓存有效期，告警风暴期间足以合并重复查询
+	alertEventCacheTTL = 30 * time.Second
+)
+
+// AlertEventCache 按指纹缓存告警事件，未命中时返回 nil
+type AlertEventCache interface {
+	Get(ctx context.Context, fingerprint string) (*model.MonitorAlertEvent, error)
+	Set(ctx context.Context, event *model.MonitorAlertEvent) error
+	Delete(ctx context.Context, fingerprint string) error
+}
+
+type redisAlertEventCache struct {
+	client redis.Cmdable
+	ttl    time.Duration
+}
+
+// NewRedisAlertEventCache 创建基于 Redis 的告警事件缓存，client 为 nil 时返回空实现
+func NewRedisAlertEventCache(client redis.Cmdable) AlertEventCache {
+	if client == nil {
+		return NewNoopAlertEventCache()
+	}
+
+	return &redisAlertEventCache{
+		client: client,
+		ttl:    alertEventCacheTTL,
+	}
+}
+
+func alertEventCacheKey(fingerprint string) string {
+	return alertEventCacheKeyPrefix + fingerprint
+}
+
+// Get 读取缓存的告警事件，未命中时返回 nil, nil
+func (c *redisAlertEventCache) Get(ctx context.Context, fingerprint string) (*model.MonitorAlertEvent, error) {
+	data, err := c.client.Get(ctx, alertEventCacheKey(fingerprint)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var event model.MonitorAlertEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+// Set 写入告警事件缓存
+func (c *redisAlertEventCache) Set(ctx context.Context, event *model.MonitorAlertEvent) error {
+	if event == nil || event.Fingerprint == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return c.client.Set(ctx, alertEventCacheKey(event.Fingerprint), data, c.ttl).Err()
+}
+
+// Delete 删除告警事件缓存
+func (c *redisAlertEventCache) Delete(ctx context.Context, fingerprint string) error {
+	if fingerprint == "" {
+		return nil
+	}
+
+	return c.client.Del(ctx, alertEventCacheKey(fingerprint)).Err()
+}
+
+type noopAlertEventCache struct{}
+
+// NewNoopAlertEventCache 创建不做任何缓存的空实现，未配置缓存时使用
+func NewNoopAlertEventCache() AlertEventCache {
+	return noopAlertEventCache{}
+}
+
+func (noopAlertEventCache) Get(context.Context, string) (*model.MonitorAlertEvent, error) {
+	return nil, nil
+}
+
+func (noopAlertEventCache) Set(context.Context, *model.MonitorAlertEvent) error {
+	return nil
+}
+
+func (noopAlertEventCache) Delete(context.Context, string) error {
+	return nil
+}
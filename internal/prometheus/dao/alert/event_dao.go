@@ -27,27 +27,110 @@ package alert
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	pkg "github.com/GoSimplicity/AI-CloudOps/pkg/utils"
 
 	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/GoSimplicity/AI-CloudOps/internal/prometheus/metrics"
 	userDao "github.com/GoSimplicity/AI-CloudOps/internal/user/dao"
+	"github.com/GoSimplicity/AI-CloudOps/pkg/cursor"
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/viper"
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 	"gorm.io/gorm"
 )
 
+const (
+	// defaultMaxMessageBytes 飞书消息默认最大字节数
+	defaultMaxMessageBytes = 4096
+	// truncatedMarker 飞书消息被截断时追加的标记，便于区分被截断的消息
+	truncatedMarker = "…(truncated)"
+	// defaultCoalesceWindow 相同消息合并发送的默认时间窗口
+	defaultCoalesceWindow = 500 * time.Millisecond
+	// coalesceSendTimeout 合并发送的外部请求超时时间，不随任一调用方取消
+	coalesceSendTimeout = 10 * time.Second
+	// sentMessageKeyCacheSize 已发送消息幂等键的缓存容量
+	sentMessageKeyCacheSize = 4096
+	// webhookTestMessage 测试 webhook 连通性时发送的消息
+	webhookTestMessage = "CloudOps connectivity test"
+	// defaultPurgeBatchSize 清理已恢复告警事件时每批处理的默认行数
+	defaultPurgeBatchSize = 500
+)
+
+// defaultFeishuCardColors 告警级别到飞书卡片标题栏颜色的默认映射
+var defaultFeishuCardColors = map[string]string{
+	"critical": "red",
+	"warning":  "orange",
+	"info":     "blue",
+}
+
+// defaultFeishuCardColor 未知告警级别使用的卡片颜色
+const defaultFeishuCardColor = "blue"
+
+const (
+	WebhookProviderFeishu   = "feishu"
+	WebhookProviderDingTalk = "dingtalk"
+)
+
 type AlertManagerEventDAO interface {
+	WithTransaction(ctx context.Context, fn func(txDAO AlertManagerEventDAO) error) error
 	GetMonitorAlertEventById(ctx context.Context, id int) (*model.MonitorAlertEvent, error)
-	SearchMonitorAlertEventByName(ctx context.Context, name string) ([]*model.MonitorAlertEvent, error)
-	GetMonitorAlertEventList(ctx context.Context, offset, limit int) ([]*model.MonitorAlertEvent, error)
+	GetAlertEventsByIDs(ctx context.Context, ids []int) (map[int]*model.MonitorAlertEvent, error)
+	SearchMonitorAlertEventByName(ctx context.Context, teamID int, name string, severity string) ([]*model.MonitorAlertEvent, error)
+	GetMonitorAlertEventList(ctx context.Context, teamID int, severity string, offset, limit int) ([]*model.MonitorAlertEvent, error)
+	GetMonitorAlertEventListAfter(ctx context.Context, teamID int, after *cursor.Cursor, limit int) ([]*model.MonitorAlertEvent, error)
+	GetMonitorAlertEventSummaryList(ctx context.Context, teamID int, offset, limit int) ([]*model.MonitorAlertEvent, error)
+	GetMonitorAlertEventListWithStats(ctx context.Context, teamID int, offset, limit int) (*model.AlertEventListWithStats, error)
+	SearchMonitorAlertEvents(ctx context.Context, teamID int, filter *model.AlertEventFilter, offset, limit int) ([]*model.MonitorAlertEvent, int64, error)
 	EventAlertClaim(ctx context.Context, event *model.MonitorAlertEvent) error
+	EventAlertUnclaim(ctx context.Context, id, userID int) error
+	GetEventAuditTrail(ctx context.Context, eventID int) ([]*model.AlertEventAudit, error)
+	AckAlertEvent(ctx context.Context, id, userID int) error
 	GetAlertEventByID(ctx context.Context, id int) (*model.MonitorAlertEvent, error)
 	UpdateAlertEvent(ctx context.Context, alertEvent *model.MonitorAlertEvent) error
+	UpdateAlertEventStatus(ctx context.Context, id int, status string) error
+	GetMTTR(ctx context.Context, start, end int64) (time.Duration, error)
+	BulkUpdateAlertEventStatus(ctx context.Context, ids []int, status string) (int64, error)
+	PurgeResolvedEventsBefore(ctx context.Context, cutoff int64) (int64, error)
 	SendMessageToGroup(ctx context.Context, url string, message string) error
-	GetMonitorAlertEventTotal(ctx context.Context) (int, error)
+	SendMessageToGroupWithKey(ctx context.Context, url string, message string, idempotencyKey string) error
+	SendMessageToGroupWithDedupe(ctx context.Context, url string, message string, dedupeToken string, window time.Duration) error
+	SendCardToGroup(ctx context.Context, url string, card model.FeishuCard) error
+	TestWebhook(ctx context.Context, provider string, url string, secret string) (string, error)
+	SendByChannel(ctx context.Context, channel string, url string, message string) error
+	SendGroupNotification(ctx context.Context, eventID int, sendGroup *model.MonitorSendGroup, message string) error
+	RecordNotification(ctx context.Context, eventID int, channel string, messageID string) error
+	GetNotificationsForEvent(ctx context.Context, eventID int) ([]*model.NotificationLog, error)
+	NewWebhookNotifier(provider string, url string) (Notifier, error)
+	FanOutSend(ctx context.Context, notifiers []Notifier, message string) ([]NotifyResult, error)
+	HealthCheck(ctx context.Context) *model.HealthStatus
+	GetMonitorAlertEventTotal(ctx context.Context, teamID int) (int, error)
+	GetAlertEventHistory(ctx context.Context, fingerprint string) ([]*model.MonitorAlertEvent, error)
+	GetEventCountByRule(ctx context.Context, start, end int64, limit int) ([]model.RuleEventCount, error)
+	CountEventsByRule(ctx context.Context, since int64) ([]model.RuleEventCount, error)
+	GetGroupedAlertEvents(ctx context.Context, offset, limit int) ([]*model.AlertEventGroup, int64, error)
+	GetUnclaimedFiringEvents(ctx context.Context, offset, limit int) ([]*model.MonitorAlertEvent, int64, error)
+	GetAlertEventsSinceID(ctx context.Context, sinceID int, limit int, includeDeleted bool) ([]*model.MonitorAlertEvent, error)
+	ExportEventsCSV(ctx context.Context, teamID int, start, end int64, w io.Writer) error
+	ImportSilencesFromAlertmanager(ctx context.Context, data []byte) (*model.SilenceImportResult, error)
+	GetEventsOverlappingWindow(ctx context.Context, start, end int64) ([]*model.MonitorAlertEvent, error)
+	GetEventsByLabelKV(ctx context.Context, teamID int, key, value string, limit int) ([]*model.MonitorAlertEvent, error)
 }
 
 type alertManagerEventDAO struct {
@@ -55,17 +138,148 @@ type alertManagerEventDAO struct {
 	l          *zap.Logger
 	userDao    userDao.UserDAO
 	httpClient *http.Client
+	sentKeys   *lru.Cache[string, struct{}]
+	metrics    *metrics.SendMetrics
+	eventCache AlertEventCache
+	headers    NotifyHeaders
+	dedupe     *dedupeTokens
+	inTx       bool // db 是否为事务连接
+
+	// 事务中变更的告警事件指纹，事务提交后统一删除缓存，为 nil 时立即删除
+	staleFingerprints *[]string
+
+	// 合并时间窗口内相同 (url, message) 的并发发送
+	sendGroup   singleflight.Group
+	recentSends *dedupeTokens
 }
 
-func NewAlertManagerEventDAO(db *gorm.DB, l *zap.Logger, userDao userDao.UserDAO) AlertManagerEventDAO {
+func NewAlertManagerEventDAO(db *gorm.DB, l *zap.Logger, userDao userDao.UserDAO, reg prometheus.Registerer, eventCache AlertEventCache, headers NotifyHeaders, httpClient *http.Client) AlertManagerEventDAO {
+	if eventCache == nil {
+		eventCache = NewNoopAlertEventCache()
+	}
+
+	sentKeys, _ := lru.New[string, struct{}](sentMessageKeyCacheSize)
+
 	return &alertManagerEventDAO{
-		db:      db,
-		l:       l,
-		userDao: userDao,
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		db:          db,
+		l:           l,
+		userDao:     userDao,
+		httpClient:  httpClient,
+		metrics:     metrics.NewSendMetrics(reg),
+		eventCache:  eventCache,
+		headers:     headers,
+		sentKeys:    sentKeys,
+		dedupe:      newDedupeTokens(),
+		recentSends: newDedupeTokens(),
+	}
+}
+
+// WithTransaction 在事务中执行 fn，fn 中通过 txDAO 发起的调用共用同一事务，
+// fn 返回错误或发生 panic 时回滚，否则提交
+func (a *alertManagerEventDAO) WithTransaction(ctx context.Context, fn func(txDAO AlertManagerEventDAO) error) error {
+	var stale []string
+	if err := a.runInTx(ctx, func(tx *gorm.DB) error {
+		txDAO := a.withDB(tx)
+		if txDAO.staleFingerprints == nil {
+			txDAO.staleFingerprints = &stale
+		}
+		return fn(txDAO)
+	}); err != nil {
+		return err
+	}
+
+	a.invalidateEventCache(ctx, stale...)
+	return nil
+}
+
+// invalidateEventCache 删除告警事件的指纹缓存，避免 webhook 读到旧数据；
+// 在事务中调用时推迟到提交后删除，防止提交前其他请求用旧数据回填缓存
+func (a *alertManagerEventDAO) invalidateEventCache(ctx context.Context, fingerprints ...string) {
+	if a.staleFingerprints != nil {
+		*a.staleFingerprints = append(*a.staleFingerprints, fingerprints...)
+		return
+	}
+
+	for _, fingerprint := range fingerprints {
+		if err := a.eventCache.Delete(ctx, fingerprint); err != nil {
+			a.logger(ctx).Warn("删除告警事件缓存失败", zap.Error(err), zap.String("fingerprint", fingerprint))
+		}
+	}
+}
+
+// eventFingerprints 查询指定告警事件的指纹，用于变更后删除缓存
+func eventFingerprints(db *gorm.DB, ids ...int) ([]string, error) {
+	var fingerprints []string
+	if err := db.Model(&model.MonitorAlertEvent{}).Where("id IN ?", ids).Pluck("fingerprint", &fingerprints).Error; err != nil {
+		return nil, fmt.Errorf("查询告警事件指纹失败: %w", err)
+	}
+	return fingerprints, nil
+}
+
+// logger 返回附带请求ID字段的日志器，上下文中没有请求ID时返回基础日志器
+func (a *alertManagerEventDAO) logger(ctx context.Context) *zap.Logger {
+	if requestID := pkg.RequestIDFromContext(ctx); requestID != "" {
+		return a.l.With(zap.String(pkg.RequestIDKey, requestID))
+	}
+	return a.l
+}
+
+// withDB 返回绑定到事务连接的 DAO 副本，共享日志、缓存、指标等依赖
+func (a *alertManagerEventDAO) withDB(tx *gorm.DB) *alertManagerEventDAO {
+	return &alertManagerEventDAO{
+		db:          tx,
+		l:           a.l,
+		userDao:     a.userDao,
+		httpClient:  a.httpClient,
+		sentKeys:    a.sentKeys,
+		metrics:     a.metrics,
+		eventCache:  a.eventCache,
+		headers:     a.headers,
+		dedupe:      a.dedupe,
+		inTx:        true,
+		recentSends: a.recentSends,
+
+		staleFingerprints: a.staleFingerprints,
+	}
+}
+
+// runInTx 在事务中执行 fn，已处于事务中时直接复用当前事务，
+// fn 返回错误或发生 panic 时回滚，否则提交
+func (a *alertManagerEventDAO) runInTx(ctx context.Context, fn func(tx *gorm.DB) error) (err error) {
+	if a.inTx {
+		return fn(a.db.WithContext(ctx))
+	}
+
+	tx := a.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		a.logger(ctx).Error("开启事务失败", zap.Error(tx.Error))
+		return tx.Error
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			if rbErr := tx.Rollback().Error; rbErr != nil {
+				a.logger(ctx).Error("事务回滚失败", zap.Error(rbErr))
+			}
+			a.logger(ctx).Error("事务执行发生panic，已回滚", zap.Any("panic", r), zap.Stack("stack"))
+			err = fmt.Errorf("事务执行发生panic: %v", r)
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		if rbErr := tx.Rollback().Error; rbErr != nil {
+			a.logger(ctx).Error("事务回滚失败", zap.Error(rbErr))
+			return errors.Join(err, rbErr)
+		}
+		return err
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		a.logger(ctx).Error("提交事务失败", zap.Error(err))
+		return err
 	}
+
+	return nil
 }
 
 // 获取当前时间戳
@@ -76,183 +290,1408 @@ func getTime() int64 {
 // GetMonitorAlertEventById 获取告警事件
 func (a *alertManagerEventDAO) GetMonitorAlertEventById(ctx context.Context, id int) (*model.MonitorAlertEvent, error) {
 	if id <= 0 {
-		a.l.Error("GetMonitorAlertEventById 失败: 无效的 ID", zap.Int("id", id))
-		return nil, fmt.Errorf("无效的 ID: %d", id)
+		a.logger(ctx).Error("GetMonitorAlertEventById 失败: 无效的 ID", zap.Int("id", id))
+		return nil, fmt.Errorf("%w: 事件ID=%d", ErrInvalidID, id)
 	}
 
 	var alertEvent model.MonitorAlertEvent
 
-	if err := a.db.WithContext(ctx).Where("deleted_at = ?", 0).First(&alertEvent, id).Error; err != nil {
+	if err := a.db.WithContext(ctx).Scopes(notDeleted).First(&alertEvent, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("未找到ID为 %d 的告警事件", id)
+			return nil, fmt.Errorf("%w: id=%d", ErrEventNotFound, id)
 		}
-		a.l.Error("获取 MonitorAlertEvent 失败", zap.Error(err), zap.Int("id", id))
+		a.logger(ctx).Error("获取 MonitorAlertEvent 失败", zap.Error(err), zap.Int("id", id))
 		return nil, err
 	}
 
 	return &alertEvent, nil
 }
 
-// SearchMonitorAlertEventByName 通过名称搜索告警事件
-func (a *alertManagerEventDAO) SearchMonitorAlertEventByName(ctx context.Context, name string) ([]*model.MonitorAlertEvent, error) {
+// GetAlertEventsByIDs 批量获取告警事件，返回以ID为键的映射，不存在或已删除的ID不会出现在结果中
+func (a *alertManagerEventDAO) GetAlertEventsByIDs(ctx context.Context, ids []int) (map[int]*model.MonitorAlertEvent, error) {
+	alertEventMap := make(map[int]*model.MonitorAlertEvent, len(ids))
+	if len(ids) == 0 {
+		return alertEventMap, nil
+	}
+
+	var alertEvents []*model.MonitorAlertEvent
+
+	if err := a.db.WithContext(ctx).
+		Scopes(notDeleted).
+		Where("id IN ?", ids).
+		Find(&alertEvents).Error; err != nil {
+		a.logger(ctx).Error("批量获取 MonitorAlertEvent 失败", zap.Error(err), zap.Ints("ids", ids))
+		return nil, err
+	}
+
+	for _, alertEvent := range alertEvents {
+		alertEventMap[alertEvent.ID] = alertEvent
+	}
+
+	return alertEventMap, nil
+}
+
+// SearchMonitorAlertEventByName 通过名称搜索告警事件，severity 不为空时只返回该级别的事件
+func (a *alertManagerEventDAO) SearchMonitorAlertEventByName(ctx context.Context, teamID int, name string, severity string) ([]*model.MonitorAlertEvent, error) {
 	if name == "" {
 		return nil, fmt.Errorf("搜索名称不能为空")
 	}
+	if err := checkTeamID(teamID); err != nil {
+		return nil, err
+	}
+
+	var alertEvents []*model.MonitorAlertEvent
+
+	if err := a.db.WithContext(ctx).
+		Scopes(notDeleted, teamScoped(teamID), containsLike("alert_name", name), severityIs(severity)).
+		Find(&alertEvents).Error; err != nil {
+		a.logger(ctx).Error("通过名称搜索 MonitorAlertEvent 失败", zap.Error(err), zap.String("name", name))
+		return nil, err
+	}
+
+	return alertEvents, nil
+}
+
+// GetMonitorAlertEventList 获取告警事件列表，severity 不为空时只返回该级别的事件
+func (a *alertManagerEventDAO) GetMonitorAlertEventList(ctx context.Context, teamID int, severity string, offset, limit int) ([]*model.MonitorAlertEvent, error) {
+	if offset < 0 {
+		return nil, fmt.Errorf("offset不能为负数")
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit必须大于0")
+	}
+	if err := checkTeamID(teamID); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("获取告警事件列表已取消: %w", err)
+	}
 
 	var alertEvents []*model.MonitorAlertEvent
 
 	if err := a.db.WithContext(ctx).
-		Where("deleted_at = ?", 0).
-		Where("alert_name LIKE ?", "%"+name+"%").
+		Scopes(notDeleted, teamScoped(teamID), severityIs(severity)).
+		Order("created_at DESC, id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&alertEvents).Error; err != nil {
+		a.logger(ctx).Error("获取 MonitorAlertEvent 列表失败", zap.Error(err))
+		return nil, err
+	}
+
+	return alertEvents, nil
+}
+
+// GetMonitorAlertEventListAfter 按创建时间倒序键集分页获取告警事件，after 为 nil 时从第一页开始
+func (a *alertManagerEventDAO) GetMonitorAlertEventListAfter(ctx context.Context, teamID int, after *cursor.Cursor, limit int) ([]*model.MonitorAlertEvent, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit必须大于0")
+	}
+	if err := checkTeamID(teamID); err != nil {
+		return nil, err
+	}
+
+	query := a.db.WithContext(ctx).Scopes(notDeleted, teamScoped(teamID))
+	if after != nil {
+		query = query.Where("created_at < ? OR (created_at = ? AND id < ?)", after.SortKey, after.SortKey, after.LastID)
+	}
+
+	var alertEvents []*model.MonitorAlertEvent
+	if err := query.
+		Order("created_at DESC, id DESC").
+		Limit(limit).
 		Find(&alertEvents).Error; err != nil {
-		a.l.Error("通过名称搜索 MonitorAlertEvent 失败", zap.Error(err), zap.String("name", name))
+		a.logger(ctx).Error("按游标获取 MonitorAlertEvent 列表失败", zap.Error(err))
 		return nil, err
 	}
 
 	return alertEvents, nil
 }
 
-// GetMonitorAlertEventList 获取告警事件列表
-func (a *alertManagerEventDAO) GetMonitorAlertEventList(ctx context.Context, offset, limit int) ([]*model.MonitorAlertEvent, error) {
+// GetMonitorAlertEventListWithStats 在同一事务中获取告警事件分页列表和按状态统计的总数，保证统计与列表来自同一快照
+func (a *alertManagerEventDAO) GetMonitorAlertEventListWithStats(ctx context.Context, teamID int, offset, limit int) (*model.AlertEventListWithStats, error) {
+	if offset < 0 {
+		return nil, fmt.Errorf("offset不能为负数")
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit必须大于0")
+	}
+	if err := checkTeamID(teamID); err != nil {
+		return nil, err
+	}
+
+	result := &model.AlertEventListWithStats{
+		Items:        make([]*model.MonitorAlertEvent, 0),
+		StatusCounts: make(map[string]int64),
+	}
+
+	err := a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var counts []struct {
+			Status string
+			Count  int64
+		}
+		if err := tx.Model(&model.MonitorAlertEvent{}).
+			Select("status, COUNT(*) AS count").
+			Scopes(notDeleted, teamScoped(teamID)).
+			Group("status").
+			Scan(&counts).Error; err != nil {
+			return fmt.Errorf("按状态统计告警事件失败: %w", err)
+		}
+
+		for _, c := range counts {
+			result.StatusCounts[c.Status] = c.Count
+			result.Total += c.Count
+		}
+		if result.Total == 0 {
+			return nil
+		}
+
+		if err := tx.Scopes(notDeleted, teamScoped(teamID)).
+			Order("created_at DESC, id DESC").
+			Offset(offset).
+			Limit(limit).
+			Find(&result.Items).Error; err != nil {
+			return fmt.Errorf("获取告警事件列表失败: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		a.logger(ctx).Error("获取告警事件列表及状态统计失败", zap.Error(err), zap.Int("teamID", teamID))
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// alertEventSummaryColumns 告警事件摘要列表查询的字段
+var alertEventSummaryColumns = []string{"id", "alert_name", "status", "event_times", "created_at"}
+
+// GetMonitorAlertEventSummaryList 获取告警事件摘要列表，仅查询 id、alert_name、status、event_times、created_at，
+// 不返回 labels 等大字段，适用于概览页面
+func (a *alertManagerEventDAO) GetMonitorAlertEventSummaryList(ctx context.Context, teamID int, offset, limit int) ([]*model.MonitorAlertEvent, error) {
 	if offset < 0 {
 		return nil, fmt.Errorf("offset不能为负数")
 	}
 	if limit <= 0 {
 		return nil, fmt.Errorf("limit必须大于0")
 	}
+	if err := checkTeamID(teamID); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("获取告警事件摘要列表已取消: %w", err)
+	}
 
 	var alertEvents []*model.MonitorAlertEvent
 
 	if err := a.db.WithContext(ctx).
-		Where("deleted_at = ?", 0).
-		Order("created_at DESC").
+		Select(alertEventSummaryColumns).
+		Scopes(notDeleted, teamScoped(teamID)).
+		Order("created_at DESC, id DESC").
 		Offset(offset).
 		Limit(limit).
 		Find(&alertEvents).Error; err != nil {
-		a.l.Error("获取 MonitorAlertEvent 列表失败", zap.Error(err))
+		a.logger(ctx).Error("获取 MonitorAlertEvent 摘要列表失败", zap.Error(err))
 		return nil, err
 	}
 
 	return alertEvents, nil
 }
 
-// EventAlertClaim 认领告警事件
+// SearchMonitorAlertEvents 按名称子串、状态（单个或多个）、告警级别和创建时间范围组合搜索告警事件，返回分页结果和过滤后的总数，
+// filter.IncludeDeleted 为 true 时同时返回已软删除的事件
+func (a *alertManagerEventDAO) SearchMonitorAlertEvents(ctx context.Context, teamID int, filter *model.AlertEventFilter, offset, limit int) ([]*model.MonitorAlertEvent, int64, error) {
+	if offset < 0 {
+		return nil, 0, fmt.Errorf("offset不能为负数")
+	}
+	if limit <= 0 {
+		return nil, 0, fmt.Errorf("limit必须大于0")
+	}
+	if err := checkTeamID(teamID); err != nil {
+		return nil, 0, err
+	}
+	if filter == nil {
+		filter = &model.AlertEventFilter{}
+	}
+	if filter.StartTime > 0 && filter.EndTime > 0 && filter.EndTime < filter.StartTime {
+		return nil, 0, fmt.Errorf("结束时间不能早于开始时间")
+	}
+	for _, status := range filter.Statuses {
+		if !model.IsValidStatus(status) {
+			return nil, 0, fmt.Errorf("无效的告警状态: %s", status)
+		}
+	}
+
+	query := a.db.WithContext(ctx).
+		Model(&model.MonitorAlertEvent{}).
+		Scopes(teamScoped(teamID))
+
+	if !filter.IncludeDeleted {
+		query = query.Scopes(notDeleted)
+	}
+	if filter.Name != "" {
+		query = query.Scopes(containsLike("alert_name", filter.Name))
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+	if filter.Severity != "" {
+		query = query.Scopes(severityIs(filter.Severity))
+	}
+	if filter.StartTime > 0 {
+		query = query.Where("created_at >= ?", filter.StartTime)
+	}
+	if filter.EndTime > 0 {
+		query = query.Where("created_at <= ?", filter.EndTime)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		a.logger(ctx).Error("统计告警事件搜索结果失败", zap.Error(err), zap.Any("filter", filter))
+		return nil, 0, err
+	}
+
+	var alertEvents []*model.MonitorAlertEvent
+	if total == 0 {
+		return alertEvents, 0, nil
+	}
+
+	if err := query.
+		Order("created_at DESC, id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&alertEvents).Error; err != nil {
+		a.logger(ctx).Error("组合搜索告警事件失败", zap.Error(err), zap.Any("filter", filter))
+		return nil, 0, err
+	}
+
+	return alertEvents, total, nil
+}
+
+// EventAlertClaim 认领告警事件，并在同一事务中写入认领审计记录
 func (a *alertManagerEventDAO) EventAlertClaim(ctx context.Context, event *model.MonitorAlertEvent) error {
 	if event.ID <= 0 {
-		return fmt.Errorf("无效的事件ID")
+		return fmt.Errorf("%w: 事件ID=%d", ErrInvalidID, event.ID)
+	}
+
+	var fingerprints []string
+	err := a.runInTx(ctx, func(tx *gorm.DB) error {
+		result := tx.Model(&model.MonitorAlertEvent{}).
+			Scopes(notDeleted).Where("id = ?", event.ID).
+			Updates(event)
+
+		if result.Error != nil {
+			a.logger(ctx).Error("EventAlertClaim 更新失败", zap.Error(result.Error), zap.Int("id", event.ID))
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: id=%d", ErrEventNotFound, event.ID)
+		}
+
+		var err error
+		if fingerprints, err = eventFingerprints(tx, event.ID); err != nil {
+			return err
+		}
+
+		return a.createEventAudit(tx, event.ID, event.RenLingUserID, model.AlertEventAuditActionClaim)
+	})
+	if err != nil {
+		return err
+	}
+
+	a.invalidateEventCache(ctx, fingerprints...)
+	return nil
+}
+
+// EventAlertUnclaim 取消认领告警事件，恢复为告警中状态，并在同一事务中写入审计记录
+func (a *alertManagerEventDAO) EventAlertUnclaim(ctx context.Context, id, userID int) error {
+	if id <= 0 {
+		return fmt.Errorf("%w: 事件ID=%d", ErrInvalidID, id)
+	}
+
+	var fingerprints []string
+	err := a.runInTx(ctx, func(tx *gorm.DB) error {
+		result := tx.Model(&model.MonitorAlertEvent{}).
+			Scopes(notDeleted).Where("id = ? AND ren_ling_user_id <> ?", id, 0).
+			UpdateColumns(map[string]interface{}{
+				"ren_ling_user_id": 0,
+				"status":           model.AlertStatusFiring,
+				"version":          gorm.Expr("version + 1"),
+				"updated_at":       getTime(),
+			})
+
+		if result.Error != nil {
+			a.logger(ctx).Error("EventAlertUnclaim 更新失败", zap.Error(result.Error), zap.Int("id", id))
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: 未找到已认领的事件 id=%d", ErrEventNotFound, id)
+		}
+
+		var err error
+		if fingerprints, err = eventFingerprints(tx, id); err != nil {
+			return err
+		}
+
+		return a.createEventAudit(tx, id, userID, model.AlertEventAuditActionUnclaim)
+	})
+	if err != nil {
+		return err
+	}
+
+	a.invalidateEventCache(ctx, fingerprints...)
+	return nil
+}
+
+// GetEventAuditTrail 获取告警事件的认领审计记录，按操作时间升序排列
+func (a *alertManagerEventDAO) GetEventAuditTrail(ctx context.Context, eventID int) ([]*model.AlertEventAudit, error) {
+	if eventID <= 0 {
+		return nil, fmt.Errorf("%w: 事件ID=%d", ErrInvalidID, eventID)
+	}
+
+	var audits []*model.AlertEventAudit
+	if err := a.db.WithContext(ctx).
+		Where("event_id = ?", eventID).
+		Order("created_at ASC, id ASC").
+		Find(&audits).Error; err != nil {
+		a.logger(ctx).Error("获取告警事件审计记录失败", zap.Error(err), zap.Int("eventID", eventID))
+		return nil, err
+	}
+
+	return audits, nil
+}
+
+// createEventAudit 在给定事务中写入告警事件审计记录
+func (a *alertManagerEventDAO) createEventAudit(tx *gorm.DB, eventID, userID int, action string) error {
+	audit := &model.AlertEventAudit{
+		EventID: eventID,
+		UserID:  userID,
+		Action:  action,
+	}
+
+	if err := tx.Create(audit).Error; err != nil {
+		a.logger(tx.Statement.Context).Error("写入告警事件审计记录失败", zap.Error(err), zap.Int("eventID", eventID), zap.String("action", action))
+		return err
+	}
+
+	return nil
+}
+
+// AckAlertEvent 确认告警事件，仅记录确认人和确认时间，不改变认领人
+func (a *alertManagerEventDAO) AckAlertEvent(ctx context.Context, id, userID int) error {
+	if id <= 0 {
+		return fmt.Errorf("%w: 事件ID=%d", ErrInvalidID, id)
+	}
+	if userID <= 0 {
+		return fmt.Errorf("%w: 用户ID=%d", ErrInvalidID, userID)
 	}
 
 	result := a.db.WithContext(ctx).
 		Model(&model.MonitorAlertEvent{}).
-		Where("id = ? AND deleted_at = ?", event.ID, 0).
-		Updates(event)
+		Scopes(notDeleted).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"ack_user_id": userID,
+			"ack_at":      getTime(),
+		})
 
 	if result.Error != nil {
-		a.l.Error("EventAlertClaim 更新失败", zap.Error(result.Error), zap.Int("id", event.ID))
+		a.logger(ctx).Error("AckAlertEvent 更新失败", zap.Error(result.Error), zap.Int("id", id))
 		return result.Error
 	}
 
+	// 同一用户在同一秒内重复确认时数据未变化，需要再确认事件是否存在
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("未找到ID为 %d 的告警事件或已被删除", event.ID)
+		return a.checkEventExists(ctx, id)
+	}
+
+	return a.invalidateEventsByID(ctx, id)
+}
+
+// checkEventExists 更新影响行数为 0 时确认事件是否存在：MySQL 对数据未变化的行不计入影响行数，
+// 不能据此判定事件不存在
+func (a *alertManagerEventDAO) checkEventExists(ctx context.Context, id int) error {
+	var count int64
+	if err := a.db.WithContext(ctx).Model(&model.MonitorAlertEvent{}).Scopes(notDeleted).Where("id = ?", id).Count(&count).Error; err != nil {
+		a.logger(ctx).Error("查询告警事件是否存在失败", zap.Error(err), zap.Int("id", id))
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("%w: id=%d", ErrEventNotFound, id)
+	}
+	return nil
+}
+
+// invalidateEventsByID 查询告警事件指纹并删除对应缓存
+func (a *alertManagerEventDAO) invalidateEventsByID(ctx context.Context, ids ...int) error {
+	fingerprints, err := eventFingerprints(a.db.WithContext(ctx), ids...)
+	if err != nil {
+		a.logger(ctx).Error("查询告警事件指纹失败", zap.Error(err), zap.Ints("ids", ids))
+		return err
 	}
 
+	a.invalidateEventCache(ctx, fingerprints...)
 	return nil
 }
 
 // GetAlertEventByID 通过ID获取告警事件
 func (a *alertManagerEventDAO) GetAlertEventByID(ctx context.Context, id int) (*model.MonitorAlertEvent, error) {
 	if id <= 0 {
-		a.l.Error("GetAlertEventByID 失败: 无效的 ID", zap.Int("id", id))
-		return nil, fmt.Errorf("无效的 ID: %d", id)
+		a.logger(ctx).Error("GetAlertEventByID 失败: 无效的 ID", zap.Int("id", id))
+		return nil, fmt.Errorf("%w: 事件ID=%d", ErrInvalidID, id)
 	}
 
 	var alertEvent model.MonitorAlertEvent
 
-	if err := a.db.WithContext(ctx).Where("deleted_at = ?", 0).First(&alertEvent, id).Error; err != nil {
+	if err := a.db.WithContext(ctx).Scopes(notDeleted).First(&alertEvent, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("未找到ID为 %d 的告警事件", id)
+			return nil, fmt.Errorf("%w: id=%d", ErrEventNotFound, id)
 		}
-		a.l.Error("获取 AlertEvent 失败", zap.Error(err), zap.Int("id", id))
+		a.logger(ctx).Error("获取 AlertEvent 失败", zap.Error(err), zap.Int("id", id))
 		return nil, err
 	}
 
 	return &alertEvent, nil
 }
 
-// UpdateAlertEvent 更新告警事件
+// UpdateAlertEvent 更新告警事件，基于 Version 做乐观锁校验，版本过期时返回 ErrAlertEventVersionConflict。
+// 可更新字段为 alert_name、fingerprint、status、rule_id、send_group_id、event_times、silence_id、
+// ren_ling_user_id、labels，零值字段不会写入，避免部分更新时误清空数据；仅修改状态请使用 UpdateAlertEventStatus
 func (a *alertManagerEventDAO) UpdateAlertEvent(ctx context.Context, alertEvent *model.MonitorAlertEvent) error {
 	if alertEvent.ID <= 0 {
-		return fmt.Errorf("无效的事件ID")
+		return fmt.Errorf("%w: 事件ID=%d", ErrInvalidID, alertEvent.ID)
+	}
+
+	// 部分更新时调用方可能未携带指纹，指纹变更时调用方携带的是新值，需按库中存储的指纹删除缓存
+	fingerprints, err := eventFingerprints(a.db.WithContext(ctx), alertEvent.ID)
+	if err != nil {
+		a.logger(ctx).Error("查询告警事件指纹失败", zap.Error(err), zap.Int("id", alertEvent.ID))
+		return err
 	}
 
+	updates := buildAlertEventUpdates(alertEvent)
+	updates["version"] = gorm.Expr("version + 1")
+	updates["updated_at"] = getTime()
+
+	// 以读取时的版本号作为更新条件，版本不一致说明事件已被他人修改
 	result := a.db.WithContext(ctx).
-		Where("id = ? AND deleted_at = ?", alertEvent.ID, 0).
-		Updates(map[string]interface{}{
-			"alert_name":       alertEvent.AlertName,
-			"fingerprint":      alertEvent.Fingerprint,
-			"status":           alertEvent.Status,
-			"rule_id":          alertEvent.RuleID,
-			"send_group_id":    alertEvent.SendGroupID,
-			"event_times":      alertEvent.EventTimes,
-			"silence_id":       alertEvent.SilenceID,
-			"ren_ling_user_id": alertEvent.RenLingUserID,
-			"labels":           alertEvent.Labels,
-			"updated_at":       getTime(),
-		})
+		Model(&model.MonitorAlertEvent{}).
+		Scopes(notDeleted).Where("id = ? AND version = ?", alertEvent.ID, alertEvent.Version).
+		UpdateColumns(updates)
 
 	if result.Error != nil {
-		a.l.Error("更新 AlertEvent 失败", zap.Error(result.Error), zap.Int("id", alertEvent.ID))
+		a.logger(ctx).Error("更新 AlertEvent 失败", zap.Error(result.Error), zap.Int("id", alertEvent.ID))
 		return result.Error
 	}
 
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("未找到ID为 %d 的告警事件或已被删除", alertEvent.ID)
+		var count int64
+		if err := a.db.WithContext(ctx).Model(&model.MonitorAlertEvent{}).Scopes(notDeleted).Where("id = ?", alertEvent.ID).Count(&count).Error; err != nil {
+			a.logger(ctx).Error("UpdateAlertEvent 查询失败", zap.Error(err), zap.Int("id", alertEvent.ID))
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("%w: id=%d", ErrEventNotFound, alertEvent.ID)
+		}
+		return fmt.Errorf("%w: 告警事件 %d 的版本 %d 已过期", ErrAlertEventVersionConflict, alertEvent.ID, alertEvent.Version)
+	}
+
+	alertEvent.Version++
+
+	if alertEvent.Fingerprint != "" && !slices.Contains(fingerprints, alertEvent.Fingerprint) {
+		fingerprints = append(fingerprints, alertEvent.Fingerprint)
 	}
+	a.invalidateEventCache(ctx, fingerprints...)
 
 	return nil
 }
 
-// SendMessageToGroup 发送飞书群聊消息
-func (a *alertManagerEventDAO) SendMessageToGroup(ctx context.Context, url string, message string) error {
-	if url == "" {
-		return fmt.Errorf("url不能为空")
+// buildAlertEventUpdates 收集告警事件中可更新的非零值字段
+func buildAlertEventUpdates(alertEvent *model.MonitorAlertEvent) map[string]interface{} {
+	updates := make(map[string]interface{})
+
+	if alertEvent.AlertName != "" {
+		updates["alert_name"] = alertEvent.AlertName
 	}
-	if message == "" {
-		return fmt.Errorf("message不能为空")
+	if alertEvent.Fingerprint != "" {
+		updates["fingerprint"] = alertEvent.Fingerprint
+	}
+	if alertEvent.Status != "" {
+		updates["status"] = alertEvent.Status
+	}
+	if alertEvent.RuleID != 0 {
+		updates["rule_id"] = alertEvent.RuleID
+	}
+	if alertEvent.SendGroupID != 0 {
+		updates["send_group_id"] = alertEvent.SendGroupID
+	}
+	if alertEvent.EventTimes != 0 {
+		updates["event_times"] = alertEvent.EventTimes
+	}
+	if alertEvent.SilenceID != "" {
+		updates["silence_id"] = alertEvent.SilenceID
+	}
+	if alertEvent.RenLingUserID != 0 {
+		updates["ren_ling_user_id"] = alertEvent.RenLingUserID
+	}
+	if len(alertEvent.Labels) > 0 {
+		updates["labels"] = alertEvent.Labels
 	}
 
-	// 拼接发送内容
-	content := fmt.Sprintf(`{"msg_type":"text","content":{"text":"%s"}}`, message)
+	return updates
+}
 
-	// 发送消息到群组
-	body, err := pkg.PostWithJson(ctx, a.httpClient, a.l, url, content, nil, nil)
-	if err != nil {
-		a.l.Error("发送飞书群聊消息失败",
-			zap.Error(err),
-			zap.String("url", url),
-			zap.String("message", message),
+// UpdateAlertEventStatus 仅更新告警事件状态，避免覆盖其他并发更新的字段
+func (a *alertManagerEventDAO) UpdateAlertEventStatus(ctx context.Context, id int, status string) error {
+	if id <= 0 {
+		return fmt.Errorf("%w: 事件ID=%d", ErrInvalidID, id)
+	}
+	if status == "" {
+		return fmt.Errorf("status不能为空")
+	}
+
+	result := a.db.WithContext(ctx).
+		Model(&model.MonitorAlertEvent{}).
+		Scopes(notDeleted).Where("id = ?", id).
+		UpdateColumns(statusUpdateColumns(status, map[string]interface{}{
+			"updated_at": getTime(),
+		}))
+
+	if result.Error != nil {
+		a.logger(ctx).Error("更新告警事件状态失败", zap.Error(result.Error), zap.Int("id", id), zap.String("status", status))
+		return result.Error
+	}
+
+	// 同一秒内重复设置相同状态时数据未变化，需要再确认事件是否存在
+	if result.RowsAffected == 0 {
+		return a.checkEventExists(ctx, id)
+	}
+
+	return a.invalidateEventsByID(ctx, id)
+}
+
+// statusUpdateColumns 在 columns 中加入状态及恢复时间的更新：转为 resolved 时记录首次恢复时间，
+// 已恢复的事件重新 firing 时以当前时间开启新一轮告警并清零恢复时间
+func statusUpdateColumns(status string, columns map[string]interface{}) map[string]interface{} {
+	now := getTime()
+	columns["status"] = status
+	switch status {
+	case string(model.AlertStatusResolved):
+		columns["resolved_at"] = gorm.Expr("CASE WHEN resolved_at = 0 THEN ? ELSE resolved_at END", now)
+	case string(model.AlertStatusFiring):
+		// gorm 按列名排序生成 SET 子句，fired_at 先于 resolved_at 赋值，MySQL 按顺序求值时仍读到清零前的 resolved_at
+		columns["fired_at"] = gorm.Expr("CASE WHEN resolved_at > 0 THEN ? ELSE fired_at END", now)
+		columns["resolved_at"] = 0
+	}
+	return columns
+}
+
+// incidentStartExpr 本轮告警开始时间，早于 fired_at 字段写入的历史事件退回到 created_at
+const incidentStartExpr = "CASE WHEN fired_at > 0 THEN fired_at ELSE created_at END"
+
+// GetMTTR 计算恢复时间在 [start, end] 内的告警事件从本轮开始到恢复的平均时长，没有已恢复事件时返回0
+func (a *alertManagerEventDAO) GetMTTR(ctx context.Context, start, end int64) (time.Duration, error) {
+	if end < start {
+		return 0, fmt.Errorf("结束时间不能早于开始时间")
+	}
+
+	var avgSeconds *float64
+	if err := a.db.WithContext(ctx).
+		Model(&model.MonitorAlertEvent{}).
+		Select("AVG(resolved_at - "+incidentStartExpr+")").
+		Scopes(notDeleted).
+		Where("resolved_at > 0 AND resolved_at BETWEEN ? AND ?", start, end).
+		Scan(&avgSeconds).Error; err != nil {
+		a.logger(ctx).Error("计算平均恢复时长失败", zap.Error(err), zap.Int64("start", start), zap.Int64("end", end))
+		return 0, err
+	}
+
+	if avgSeconds == nil {
+		return 0, nil
+	}
+
+	return time.Duration(*avgSeconds * float64(time.Second)), nil
+}
+
+// BulkUpdateAlertEventStatus 批量更新告警事件状态，返回实际更新的行数
+func (a *alertManagerEventDAO) BulkUpdateAlertEventStatus(ctx context.Context, ids []int, status string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, fmt.Errorf("ids不能为空")
+	}
+	if !model.IsValidStatus(status) {
+		return 0, fmt.Errorf("无效的告警状态: %s", status)
+	}
+
+	result := a.db.WithContext(ctx).
+		Model(&model.MonitorAlertEvent{}).
+		Scopes(notDeleted).Where("id IN ?", ids).
+		UpdateColumns(statusUpdateColumns(status, map[string]interface{}{
+			"version":    gorm.Expr("version + 1"),
+			"updated_at": getTime(),
+		}))
+
+	if result.Error != nil {
+		a.logger(ctx).Error("批量更新告警事件状态失败", zap.Error(result.Error), zap.Ints("ids", ids), zap.String("status", status))
+		return 0, result.Error
+	}
+
+	if err := a.invalidateEventsByID(ctx, ids...); err != nil {
+		return result.RowsAffected, err
+	}
+
+	return result.RowsAffected, nil
+}
+
+// PurgeResolvedEventsBefore 分批清理 updated_at 早于 cutoff 的已恢复告警事件，返回清理的总行数。
+// prometheus.purge_hard_delete 为 true 时物理删除，否则软删除；firing、silenced 等状态的事件不会被清理
+func (a *alertManagerEventDAO) PurgeResolvedEventsBefore(ctx context.Context, cutoff int64) (int64, error) {
+	if cutoff <= 0 {
+		return 0, fmt.Errorf("cutoff必须大于0")
+	}
+
+	hardDelete := viper.GetBool("prometheus.purge_hard_delete")
+	batchSize := getPurgeBatchSize()
+
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, fmt.Errorf("清理已恢复告警事件已取消: %w", err)
+		}
+
+		query := a.db.WithContext(ctx).
+			Model(&model.MonitorAlertEvent{}).
+			Where("status = ? AND updated_at < ?", model.AlertStatusResolved, cutoff)
+		if !hardDelete {
+			query = query.Scopes(notDeleted)
+		}
+
+		var candidates []struct {
+			ID          int
+			Fingerprint string
+		}
+		if err := query.Select("id, fingerprint").Order("id ASC").Limit(batchSize).Scan(&candidates).Error; err != nil {
+			a.logger(ctx).Error("查询待清理的告警事件失败", zap.Error(err), zap.Int64("cutoff", cutoff))
+			return total, err
+		}
+		if len(candidates) == 0 {
+			break
+		}
+
+		ids := make([]int, 0, len(candidates))
+		fingerprints := make([]string, 0, len(candidates))
+		for _, c := range candidates {
+			ids = append(ids, c.ID)
+			fingerprints = append(fingerprints, c.Fingerprint)
+		}
+
+		// 删除时再次限定状态，避免清理在查询后被重新触发的事件
+		batch := a.db.WithContext(ctx).Where("id IN ? AND status = ?", ids, model.AlertStatusResolved)
+		var result *gorm.DB
+		if hardDelete {
+			result = batch.Delete(&model.MonitorAlertEvent{})
+		} else {
+			result = batch.Model(&model.MonitorAlertEvent{}).
+				Scopes(notDeleted).
+				UpdateColumn("deleted_at", getTime())
+		}
+		if result.Error != nil {
+			a.logger(ctx).Error("清理已恢复告警事件失败", zap.Error(result.Error), zap.Int64("cutoff", cutoff))
+			return total, result.Error
+		}
+		if hardDelete && result.RowsAffected > 0 {
+			// 清理已物理删除事件的标签索引，被重新触发而未删除的事件保留索引
+			if err := a.db.WithContext(ctx).
+				Where("event_id IN ?", ids).
+				Where("event_id NOT IN (?)", a.db.Model(&model.MonitorAlertEvent{}).Select("id").Where("id IN ?", ids)).
+				Delete(&model.AlertEventLabel{}).Error; err != nil {
+				a.logger(ctx).Error("清理告警事件标签索引失败", zap.Error(err), zap.Int64("cutoff", cutoff))
+				return total, err
+			}
+		}
+
+		a.invalidateEventCache(ctx, fingerprints...)
+
+		total += result.RowsAffected
+		if len(ids) < batchSize {
+			break
+		}
+	}
+
+	a.logger(ctx).Info("清理已恢复告警事件完成", zap.Int64("cutoff", cutoff), zap.Int64("total", total), zap.Bool("hardDelete", hardDelete))
+
+	return total, nil
+}
+
+// getPurgeBatchSize 获取清理告警事件时每批处理的行数，未配置时使用默认值
+func getPurgeBatchSize() int {
+	if size := viper.GetInt("prometheus.purge_batch_size"); size > 0 {
+		return size
+	}
+	return defaultPurgeBatchSize
+}
+
+// SendMessageToGroup 发送飞书群聊消息
+func (a *alertManagerEventDAO) SendMessageToGroup(ctx context.Context, url string, message string) error {
+	return a.SendMessageToGroupWithKey(ctx, url, message, "")
+}
+
+// SendMessageToGroupWithKey 发送飞书群聊消息，相同幂等键的消息发送成功（或超时结果未知）后不会重复投递
+func (a *alertManagerEventDAO) SendMessageToGroupWithKey(ctx context.Context, url string, message string, idempotencyKey string) error {
+	_, err := a.sendGroupMessage(ctx, url, message, idempotencyKey)
+	return err
+}
+
+// sendGroupMessage 发送飞书群聊消息并返回飞书的响应内容，因幂等键跳过发送时响应为空
+func (a *alertManagerEventDAO) sendGroupMessage(ctx context.Context, url string, message string, idempotencyKey string) ([]byte, error) {
+	if url == "" {
+		return nil, fmt.Errorf("url不能为空")
+	}
+	if message == "" {
+		return nil, fmt.Errorf("message不能为空")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("发送飞书群聊消息已取消: %w", err)
+	}
+
+	// 原子地占用幂等键，并发的相同幂等键只有一个调用方真正发送
+	if idempotencyKey != "" {
+		if found, _ := a.sentKeys.ContainsOrAdd(idempotencyKey, struct{}{}); found {
+			a.logger(ctx).Info("消息已发送，跳过重复投递", zap.String("url", url), zap.String("idempotencyKey", idempotencyKey))
+			return nil, nil
+		}
+	}
+
+	// 先截断原文再序列化，截断不会拆开转义序列，引号、反斜杠等字符由 json.Marshal 转义
+	message = pkg.TruncateMessageWithSuffix(message, getMaxMessageBytes(), truncatedMarker)
+	content, err := json.Marshal(map[string]interface{}{
+		"msg_type": "text",
+		"content":  map[string]string{"text": message},
+	})
+	if err != nil {
+		a.releaseIdempotencyKey(idempotencyKey)
+		return nil, fmt.Errorf("序列化飞书消息失败: %w", err)
+	}
+
+	// 发送消息到群组，时间窗口内相同的消息只发送一次
+	start := time.Now()
+	body, err := a.coalesceSend(ctx, url, message, string(content))
+	a.metrics.Observe(WebhookProviderFeishu, start, err)
+	if err != nil {
+		a.logger(ctx).Error("发送飞书群聊消息失败",
+			zap.Error(err),
+			zap.String("url", url),
+			zap.String("message", message),
 			zap.Any("结果", string(body)),
 		)
-		return fmt.Errorf("发送飞书群聊消息失败: %w", err)
+		// 请求超时时消息可能已送达，保留幂等键避免重试时重复投递，其他失败释放幂等键以便重试
+		if !isTimeoutError(err) {
+			a.releaseIdempotencyKey(idempotencyKey)
+		}
+		return nil, fmt.Errorf("发送飞书群聊消息失败: %w", err)
 	}
 
-	a.l.Info("发送飞书群聊消息成功",
+	a.logger(ctx).Info("发送飞书群聊消息成功",
 		zap.String("url", url),
 		zap.String("message", message),
 		zap.Any("结果", string(body)),
 	)
 
+	return body, nil
+}
+
+// releaseIdempotencyKey 发送失败时释放已占用的幂等键
+func (a *alertManagerEventDAO) releaseIdempotencyKey(idempotencyKey string) {
+	if idempotencyKey != "" {
+		a.sentKeys.Remove(idempotencyKey)
+	}
+}
+
+// SendMessageToGroupWithDedupe 发送飞书群聊消息，相同去重令牌在 window 时间窗口内只发送一次，
+// 被抑制的消息会记录原因后直接返回，发送失败时释放令牌以便重试
+func (a *alertManagerEventDAO) SendMessageToGroupWithDedupe(ctx context.Context, url string, message string, dedupeToken string, window time.Duration) error {
+	if dedupeToken == "" || window <= 0 {
+		return a.SendMessageToGroupWithKey(ctx, url, message, "")
+	}
+
+	if sentAt, ok := a.dedupe.reserve(dedupeToken, window); !ok {
+		a.logger(ctx).Info("去重令牌在时间窗口内已发送，抑制重复消息",
+			zap.String("url", url),
+			zap.String("dedupeToken", dedupeToken),
+			zap.Duration("window", window),
+			zap.Time("lastSentAt", sentAt),
+		)
+		return nil
+	}
+
+	if err := a.SendMessageToGroupWithKey(ctx, url, message, ""); err != nil {
+		a.dedupe.release(dedupeToken)
+		return err
+	}
+
 	return nil
 }
 
+// SendCardToGroup 发送飞书群聊 interactive 卡片消息，标题栏颜色由告警级别决定
+func (a *alertManagerEventDAO) SendCardToGroup(ctx context.Context, url string, card model.FeishuCard) error {
+	if url == "" {
+		return fmt.Errorf("url不能为空")
+	}
+	if card.Title == "" {
+		return fmt.Errorf("title不能为空")
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("发送飞书群聊卡片已取消: %w", err)
+	}
+
+	card.Content = pkg.TruncateMessageWithSuffix(card.Content, getMaxMessageBytes(), truncatedMarker)
+
+	content, err := buildFeishuCard(card)
+	if err != nil {
+		return fmt.Errorf("构建飞书卡片失败: %w", err)
+	}
+
+	start := time.Now()
+	body, err := a.coalesceSend(ctx, url, content, content)
+	a.metrics.Observe(WebhookProviderFeishu, start, err)
+	if err != nil {
+		a.logger(ctx).Error("发送飞书群聊卡片失败",
+			zap.Error(err),
+			zap.String("url", url),
+			zap.String("title", card.Title),
+			zap.Any("结果", string(body)),
+		)
+		return fmt.Errorf("发送飞书群聊卡片失败: %w", err)
+	}
+
+	a.logger(ctx).Info("发送飞书群聊卡片成功", zap.String("url", url), zap.String("title", card.Title))
+
+	return nil
+}
+
+// feishuCardColor 根据告警级别获取卡片标题栏颜色，支持通过 prometheus.feishu_card_colors 覆盖默认映射
+func feishuCardColor(severity string) string {
+	severity = strings.ToLower(severity)
+	if color := viper.GetStringMapString("prometheus.feishu_card_colors")[severity]; color != "" {
+		return color
+	}
+	if color, ok := defaultFeishuCardColors[severity]; ok {
+		return color
+	}
+	return defaultFeishuCardColor
+}
+
+// buildFeishuCard 构建飞书 interactive 卡片消息体
+func buildFeishuCard(card model.FeishuCard) (string, error) {
+	elements := make([]map[string]interface{}, 0, 3)
+
+	var fields []map[string]interface{}
+	if card.Instance != "" {
+		fields = append(fields, feishuCardField("实例", card.Instance))
+	}
+	if card.Summary != "" {
+		fields = append(fields, feishuCardField("摘要", card.Summary))
+	}
+	if len(fields) > 0 {
+		elements = append(elements, map[string]interface{}{
+			"tag":    "div",
+			"fields": fields,
+		})
+	}
+
+	if card.Content != "" {
+		elements = append(elements, map[string]interface{}{
+			"tag":     "markdown",
+			"content": card.Content,
+		})
+	}
+
+	if card.ActionURL != "" {
+		elements = append(elements, map[string]interface{}{
+			"tag": "action",
+			"actions": []map[string]interface{}{
+				{
+					"tag":  "button",
+					"type": "primary",
+					"url":  card.ActionURL,
+					"text": map[string]string{
+						"tag":     "plain_text",
+						"content": "查看详情",
+					},
+				},
+			},
+		})
+	}
+
+	payload := map[string]interface{}{
+		"msg_type": "interactive",
+		"card": map[string]interface{}{
+			"config": map[string]bool{
+				"wide_screen_mode": true,
+			},
+			"header": map[string]interface{}{
+				"template": feishuCardColor(card.Severity),
+				"title": map[string]string{
+					"tag":     "plain_text",
+					"content": card.Title,
+				},
+			},
+			"elements": elements,
+		},
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// feishuCardField 构建卡片中的短字段
+func feishuCardField(name string, value string) map[string]interface{} {
+	return map[string]interface{}{
+		"is_short": true,
+		"text": map[string]string{
+			"tag":     "lark_md",
+			"content": fmt.Sprintf("**%s**\n%s", name, value),
+		},
+	}
+}
+
+// coalesceSend 合并时间窗口内发往同一地址的相同消息，只产生一次外部请求，被合并的调用方返回该次请求的响应
+func (a *alertManagerEventDAO) coalesceSend(ctx context.Context, url string, message string, content string) ([]byte, error) {
+	window := getCoalesceWindow()
+	key := url + "\x00" + message
+
+	if body, ok := a.recentSends.response(key); ok {
+		a.logger(ctx).Debug("时间窗口内已发送相同消息，跳过", zap.String("url", url))
+		return body, nil
+	}
+
+	resultCh := a.sendGroup.DoChan(key, func() (interface{}, error) {
+		// 等待期间可能已有其他请求完成发送
+		if body, ok := a.recentSends.response(key); ok {
+			return body, nil
+		}
+
+		// 请求由所有合并的调用方共享，不能因发起方 ctx 取消而中断，只保留 ctx 中的值并使用独立的超时
+		sendCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), coalesceSendTimeout)
+		defer cancel()
+
+		body, err := pkg.PostWithJson(sendCtx, a.httpClient, a.l, url, content, nil, a.requestHeaders(ctx))
+		if err == nil {
+			a.recentSends.markResponse(key, window, body)
+		}
+		return body, err
+	})
+
+	// 合并到其他调用方的请求上时，自身 ctx 取消也需立即返回
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-resultCh:
+		body, _ := res.Val.([]byte)
+		return body, res.Err
+	}
+}
+
+// getCoalesceWindow 获取相同消息合并发送的时间窗口，未配置时使用默认值
+func getCoalesceWindow() time.Duration {
+	if ms := viper.GetInt("prometheus.message_coalesce_window_ms"); ms > 0 {
+		return time.Duration(ms) * time.Millisecond
+	}
+	return defaultCoalesceWindow
+}
+
+// isTimeoutError 判断请求是否因超时失败
+func isTimeoutError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// TestWebhook 向飞书/钉钉 webhook 发送测试消息，校验地址可用并返回服务商响应
+func (a *alertManagerEventDAO) TestWebhook(ctx context.Context, provider string, webhookURL string, secret string) (string, error) {
+	if webhookURL == "" {
+		return "", fmt.Errorf("url不能为空")
+	}
+
+	timestamp := time.Now().Unix()
+	payload := map[string]interface{}{
+		"msg_type": "text",
+		"content":  map[string]string{"text": webhookTestMessage},
+	}
+
+	switch provider {
+	case WebhookProviderFeishu:
+		// 飞书签名校验：签名放在请求体中
+		if secret != "" {
+			sign, err := signWebhook(fmt.Sprintf("%d\n%s", timestamp, secret), "")
+			if err != nil {
+				return "", fmt.Errorf("生成飞书签名失败: %w", err)
+			}
+			payload["timestamp"] = strconv.FormatInt(timestamp, 10)
+			payload["sign"] = sign
+		}
+	case WebhookProviderDingTalk:
+		payload = map[string]interface{}{
+			"msgtype": "text",
+			"text":    map[string]string{"content": webhookTestMessage},
+		}
+		// 钉钉签名校验：签名放在查询参数中
+		if secret != "" {
+			millis := time.Now().UnixMilli()
+			sign, err := signWebhook(secret, fmt.Sprintf("%d\n%s", millis, secret))
+			if err != nil {
+				return "", fmt.Errorf("生成钉钉签名失败: %w", err)
+			}
+			u, err := url.Parse(webhookURL)
+			if err != nil {
+				return "", fmt.Errorf("解析url失败: %w", err)
+			}
+			q := u.Query()
+			q.Set("timestamp", strconv.FormatInt(millis, 10))
+			q.Set("sign", sign)
+			u.RawQuery = q.Encode()
+			webhookURL = u.String()
+		}
+	default:
+		return "", fmt.Errorf("不支持的 webhook 类型: %s", provider)
+	}
+
+	content, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("序列化测试消息失败: %w", err)
+	}
+
+	body, err := pkg.PostWithJson(ctx, a.httpClient, a.l, webhookURL, string(content), nil, a.requestHeaders(ctx))
+	if err != nil {
+		a.logger(ctx).Error("webhook 连通性测试失败",
+			zap.Error(err),
+			zap.String("provider", provider),
+			zap.String("url", webhookURL),
+		)
+		return string(body), fmt.Errorf("webhook 地址不可用: %w", err)
+	}
+
+	// 飞书和钉钉在拒绝请求时仍返回 200，需要检查响应中的错误码
+	var resp struct {
+		Code    int    `json:"code"`
+		Msg     string `json:"msg"`
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return string(body), fmt.Errorf("解析 webhook 响应失败: %w", err)
+	}
+	if resp.Code != 0 {
+		return string(body), fmt.Errorf("webhook 拒绝了测试消息: code=%d, msg=%s", resp.Code, resp.Msg)
+	}
+	if resp.ErrCode != 0 {
+		return string(body), fmt.Errorf("webhook 拒绝了测试消息: errcode=%d, errmsg=%s", resp.ErrCode, resp.ErrMsg)
+	}
+
+	return string(body), nil
+}
+
+// signWebhook 使用 HmacSHA256 计算签名并进行 Base64 编码
+func signWebhook(key string, data string) (string, error) {
+	h := hmac.New(sha256.New, []byte(key))
+	if _, err := h.Write([]byte(data)); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// getMaxMessageBytes 获取消息最大字节数，未配置时使用默认值
+func getMaxMessageBytes() int {
+	if limit := viper.GetInt("prometheus.max_message_bytes"); limit > 0 {
+		return limit
+	}
+	return defaultMaxMessageBytes
+}
+
+// GetEventCountByRule 统计时间窗口内产生事件最多的前 limit 条告警规则，按事件数降序排列
+func (a *alertManagerEventDAO) GetEventCountByRule(ctx context.Context, start, end int64, limit int) ([]model.RuleEventCount, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit必须大于0")
+	}
+	if end < start {
+		return nil, fmt.Errorf("结束时间不能早于开始时间")
+	}
+
+	counts, err := a.countEventsByRule(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("created_at BETWEEN ? AND ?", start, end)
+	}, limit)
+	if err != nil {
+		a.logger(ctx).Error("按规则统计告警事件失败", zap.Error(err), zap.Int64("start", start), zap.Int64("end", end))
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// CountEventsByRule 统计 since 之后创建的告警事件在各规则下的数量，按事件数降序排列，并关联返回规则名称
+func (a *alertManagerEventDAO) CountEventsByRule(ctx context.Context, since int64) ([]model.RuleEventCount, error) {
+	if since < 0 {
+		return nil, fmt.Errorf("since不能为负数")
+	}
+
+	counts, err := a.countEventsByRule(ctx, func(db *gorm.DB) *gorm.DB {
+		return db.Where("created_at > ?", since)
+	}, 0)
+	if err != nil {
+		a.logger(ctx).Error("按规则统计告警事件数量失败", zap.Error(err), zap.Int64("since", since))
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// countEventsByRule 按规则统计满足 window 条件的未删除事件数，按事件数降序排列并填充规则名称，limit 为 0 时不限制条数
+func (a *alertManagerEventDAO) countEventsByRule(ctx context.Context, window func(db *gorm.DB) *gorm.DB, limit int) ([]model.RuleEventCount, error) {
+	query := a.db.WithContext(ctx).
+		Model(&model.MonitorAlertEvent{}).
+		Select("rule_id, COUNT(*) AS count").
+		Scopes(notDeleted, window).
+		Group("rule_id").
+		Order("count DESC, rule_id ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	counts := make([]model.RuleEventCount, 0)
+	if err := query.Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	if len(counts) == 0 {
+		return counts, nil
+	}
+
+	ruleIDs := make([]int, 0, len(counts))
+	for _, c := range counts {
+		ruleIDs = append(ruleIDs, c.RuleID)
+	}
+
+	var rules []model.MonitorAlertRule
+	if err := a.db.WithContext(ctx).
+		Select("id, name").
+		Scopes(notDeleted).
+		Where("id IN ?", ruleIDs).
+		Find(&rules).Error; err != nil {
+		return nil, err
+	}
+
+	names := make(map[int]string, len(rules))
+	for _, r := range rules {
+		names[r.ID] = r.Name
+	}
+	for i := range counts {
+		counts[i].RuleName = names[counts[i].RuleID]
+	}
+
+	return counts, nil
+}
+
+// GetGroupedAlertEvents 按指纹聚合告警事件，每个指纹返回一行，并返回不同指纹的总数用于分页
+func (a *alertManagerEventDAO) GetGroupedAlertEvents(ctx context.Context, offset, limit int) ([]*model.AlertEventGroup, int64, error) {
+	if offset < 0 {
+		return nil, 0, fmt.Errorf("offset不能为负数")
+	}
+	if limit <= 0 {
+		return nil, 0, fmt.Errorf("limit必须大于0")
+	}
+
+	var total int64
+	if err := a.db.WithContext(ctx).
+		Model(&model.MonitorAlertEvent{}).
+		Scopes(notDeleted).
+		Distinct("fingerprint").
+		Count(&total).Error; err != nil {
+		a.logger(ctx).Error("统计告警事件指纹数量失败", zap.Error(err))
+		return nil, 0, err
+	}
+
+	groups := make([]*model.AlertEventGroup, 0)
+	if total == 0 {
+		return groups, 0, nil
+	}
+
+	// 以每个指纹下ID最大的事件作为最新事件
+	latest := a.db.
+		Model(&model.MonitorAlertEvent{}).
+		Select("fingerprint, MAX(id) AS latest_id, SUM(event_times) AS event_times").
+		Scopes(notDeleted).
+		Group("fingerprint")
+
+	if err := a.db.WithContext(ctx).
+		Table("monitor_alert_events AS e").
+		Select("e.fingerprint, e.alert_name, e.status, e.created_at, g.event_times").
+		Joins("JOIN (?) AS g ON e.id = g.latest_id", latest).
+		Order("e.created_at DESC, e.id DESC").
+		Offset(offset).
+		Limit(limit).
+		Scan(&groups).Error; err != nil {
+		a.logger(ctx).Error("按指纹聚合告警事件失败", zap.Error(err))
+		return nil, 0, err
+	}
+
+	return groups, total, nil
+}
+
+// GetUnclaimedFiringEvents 获取未被认领的触发中告警事件，按创建时间升序排列（最早的在前），并返回总数用于分页
+func (a *alertManagerEventDAO) GetUnclaimedFiringEvents(ctx context.Context, offset, limit int) ([]*model.MonitorAlertEvent, int64, error) {
+	if offset < 0 {
+		return nil, 0, fmt.Errorf("offset不能为负数")
+	}
+	if limit <= 0 {
+		return nil, 0, fmt.Errorf("limit必须大于0")
+	}
+
+	query := a.db.WithContext(ctx).
+		Model(&model.MonitorAlertEvent{}).
+		Scopes(notDeleted).
+		Where("status = ?", model.AlertStatusFiring).
+		Where("ren_ling_user_id = 0 OR ren_ling_user_id IS NULL")
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		a.logger(ctx).Error("统计未认领告警事件数量失败", zap.Error(err))
+		return nil, 0, err
+	}
+
+	alertEvents := make([]*model.MonitorAlertEvent, 0)
+	if total == 0 {
+		return alertEvents, 0, nil
+	}
+
+	if err := query.
+		Order("created_at ASC, id ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&alertEvents).Error; err != nil {
+		a.logger(ctx).Error("获取未认领告警事件失败", zap.Error(err))
+		return nil, 0, err
+	}
+
+	return alertEvents, total, nil
+}
+
 // GetMonitorAlertEventTotal 获取监控告警事件总数
-func (a *alertManagerEventDAO) GetMonitorAlertEventTotal(ctx context.Context) (int, error) {
+func (a *alertManagerEventDAO) GetMonitorAlertEventTotal(ctx context.Context, teamID int) (int, error) {
+	if err := checkTeamID(teamID); err != nil {
+		return 0, err
+	}
+
 	var count int64
 
-	if err := a.db.WithContext(ctx).Model(&model.MonitorAlertEvent{}).Where("deleted_at = ?", 0).Count(&count).Error; err != nil {
-		a.l.Error("获取监控告警事件总数失败", zap.Error(err))
+	if err := a.db.WithContext(ctx).Model(&model.MonitorAlertEvent{}).Scopes(notDeleted, teamScoped(teamID)).Count(&count).Error; err != nil {
+		a.logger(ctx).Error("获取监控告警事件总数失败", zap.Error(err))
 		return 0, err
 	}
 
 	return int(count), nil
 }
+
+// GetAlertEventHistory 获取指定指纹的告警事件历史,按创建时间和ID升序排列
+func (a *alertManagerEventDAO) GetAlertEventHistory(ctx context.Context, fingerprint string) ([]*model.MonitorAlertEvent, error) {
+	if fingerprint == "" {
+		return nil, fmt.Errorf("fingerprint不能为空")
+	}
+
+	var alertEvents []*model.MonitorAlertEvent
+
+	if err := a.db.WithContext(ctx).
+		Scopes(notDeleted).Where("fingerprint = ?", fingerprint).
+		Order("created_at ASC, id ASC").
+		Find(&alertEvents).Error; err != nil {
+		a.logger(ctx).Error("获取告警事件历史失败", zap.Error(err), zap.String("fingerprint", fingerprint))
+		return nil, err
+	}
+
+	return alertEvents, nil
+}
+
+// GetAlertEventsSinceID 获取ID大于 sinceID 的告警事件，按ID升序排列，用于增量同步；
+// includeDeleted 为 true 时包含已软删除的事件，便于下游同步删除
+func (a *alertManagerEventDAO) GetAlertEventsSinceID(ctx context.Context, sinceID int, limit int, includeDeleted bool) ([]*model.MonitorAlertEvent, error) {
+	if sinceID < 0 {
+		return nil, fmt.Errorf("sinceID不能为负数")
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit必须大于0")
+	}
+
+	query := a.db.WithContext(ctx).Where("id > ?", sinceID)
+	if !includeDeleted {
+		query = query.Scopes(notDeleted)
+	}
+
+	alertEvents := make([]*model.MonitorAlertEvent, 0)
+	if err := query.
+		Order("id ASC").
+		Limit(limit).
+		Find(&alertEvents).Error; err != nil {
+		a.logger(ctx).Error("增量获取告警事件失败", zap.Error(err), zap.Int("sinceID", sinceID), zap.Int("limit", limit))
+		return nil, err
+	}
+
+	return alertEvents, nil
+}
+
+// GetEventsOverlappingWindow 获取在 [start, end] 窗口内处于触发状态的告警事件，包括窗口开始前触发、
+// 窗口结束后才恢复的事件；按本轮告警开始时间判断触发时刻，resolved_at 为0表示尚未恢复，按创建时间升序排列
+func (a *alertManagerEventDAO) GetEventsOverlappingWindow(ctx context.Context, start, end int64) ([]*model.MonitorAlertEvent, error) {
+	if end < start {
+		return nil, fmt.Errorf("结束时间不能早于开始时间")
+	}
+
+	alertEvents := make([]*model.MonitorAlertEvent, 0)
+	if err := a.db.WithContext(ctx).
+		Scopes(notDeleted).
+		Where(incidentStartExpr+" <= ?", end).
+		Where("resolved_at = 0 OR resolved_at >= ?", start).
+		Order("created_at ASC, id ASC").
+		Find(&alertEvents).Error; err != nil {
+		a.logger(ctx).Error("获取时间窗口内的告警事件失败", zap.Error(err), zap.Int64("start", start), zap.Int64("end", end))
+		return nil, err
+	}
+
+	return alertEvents, nil
+}
+
+// GetEventsByLabelKV 通过标签索引表查询带有指定标签键值的未删除告警事件，按创建时间倒序返回最多 limit 条
+func (a *alertManagerEventDAO) GetEventsByLabelKV(ctx context.Context, teamID int, key, value string, limit int) ([]*model.MonitorAlertEvent, error) {
+	if key == "" {
+		return nil, fmt.Errorf("标签键不能为空")
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit必须大于0")
+	}
+	if err := checkTeamID(teamID); err != nil {
+		return nil, err
+	}
+
+	events := make([]*model.MonitorAlertEvent, 0)
+	if err := a.db.WithContext(ctx).
+		Table("monitor_alert_events AS e").
+		Select("e.*").
+		Joins("JOIN alert_event_labels AS l ON l.event_id = e.id").
+		Scopes(notDeleted, teamScoped(teamID)).
+		Where("l.label_key = ? AND l.label_value = ?", key, model.LabelIndexValue(value)).
+		Order("e.created_at DESC, e.id DESC").
+		Limit(limit).
+		Find(&events).Error; err != nil {
+		a.logger(ctx).Error("按标签查询告警事件失败", zap.Error(err), zap.String("key", key), zap.String("value", value))
+		return nil, err
+	}
+
+	return events, nil
+}
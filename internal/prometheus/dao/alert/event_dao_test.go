@@ -0,0 +1,1366 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package alert
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	pkg "github.com/GoSimplicity/AI-CloudOps/pkg/utils"
+	"github.com/glebarez/sqlite"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB 创建内存 sqlite 数据库并迁移告警相关的表
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("打开测试数据库失败: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取数据库连接失败: %v", err)
+	}
+	// 内存库每个连接独立，限制为单连接保证数据可见
+	sqlDB.SetMaxOpenConns(1)
+	migrateTestTables(t, db,
+		&model.MonitorAlertEvent{},
+		&model.MonitorAlertRule{},
+		&model.MonitorSendGroup{},
+		&model.AlertEventLabel{},
+		&model.AlertEventAudit{},
+		&model.NotificationLog{},
+	)
+	return db
+}
+
+// migrateTestTables 逐个建表。sqlite 的索引名在整个库内唯一，多张表同名的 idx_deleted_at 等索引会冲突，
+// 测试只依赖表结构，因此忽略索引重名错误
+func migrateTestTables(t *testing.T, db *gorm.DB, models ...interface{}) {
+	t.Helper()
+	for _, m := range models {
+		if err := db.Migrator().CreateTable(m); err != nil && !strings.Contains(err.Error(), "already exists") {
+			t.Fatalf("迁移测试表失败: %v", err)
+		}
+		if !db.Migrator().HasTable(m) {
+			t.Fatalf("测试表 %T 未创建", m)
+		}
+	}
+}
+
+func newTestEventDAO(t *testing.T) (*alertManagerEventDAO, *gorm.DB) {
+	t.Helper()
+	db := newTestDB(t)
+	d := NewAlertManagerEventDAO(db, zap.NewNop(), nil, prometheus.NewRegistry(), nil, nil, &http.Client{Timeout: 10 * time.Second})
+	return d.(*alertManagerEventDAO), db
+}
+
+// enableTenantScope 在测试期间开启团队隔离
+func enableTenantScope(t *testing.T) {
+	t.Helper()
+	viper.Set("prometheus.enable_tenant_scope", 1)
+	t.Cleanup(func() { viper.Set("prometheus.enable_tenant_scope", 0) })
+}
+
+// seedEvents 写入测试告警事件
+func seedEvents(t *testing.T, db *gorm.DB, events ...*model.MonitorAlertEvent) {
+	t.Helper()
+	for _, event := range events {
+		if event.Labels == nil {
+			event.Labels = model.Labels{"alertname": event.AlertName}
+		}
+		if err := db.Create(event).Error; err != nil {
+			t.Fatalf("写入测试告警事件失败: %v", err)
+		}
+	}
+}
+
+func TestTenantScopeNeverLeaksOtherTeams(t *testing.T) {
+	enableTenantScope(t)
+	d, db := newTestEventDAO(t)
+	ctx := context.Background()
+
+	seedEvents(t, db,
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-1", Status: "firing", TeamID: 1},
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-2", Status: "resolved", TeamID: 1},
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-3", Status: "firing", TeamID: 2},
+	)
+
+	list, err := d.GetMonitorAlertEventList(ctx, 1, "", 0, 10)
+	if err != nil {
+		t.Fatalf("GetMonitorAlertEventList 返回错误: %v", err)
+	}
+	for _, event := range list {
+		if event.TeamID != 1 {
+			t.Fatalf("列表返回了其他团队的事件: %+v", event)
+		}
+	}
+	if len(list) != 2 {
+		t.Fatalf("团队1应有2条事件, 实际 %d", len(list))
+	}
+
+	found, err := d.SearchMonitorAlertEventByName(ctx, 2, "cpu", "")
+	if err != nil {
+		t.Fatalf("SearchMonitorAlertEventByName 返回错误: %v", err)
+	}
+	if len(found) != 1 || found[0].Fingerprint != "fp-3" {
+		t.Fatalf("搜索返回了其他团队的事件: %+v", found)
+	}
+
+	stats, err := d.GetMonitorAlertEventListWithStats(ctx, 2, 0, 10)
+	if err != nil {
+		t.Fatalf("GetMonitorAlertEventListWithStats 返回错误: %v", err)
+	}
+	if stats.Total != 1 || len(stats.Items) != 1 || stats.Items[0].TeamID != 2 {
+		t.Fatalf("统计包含了其他团队的事件: %+v", stats)
+	}
+
+	page, err := d.GetMonitorAlertEventListAfter(ctx, 2, nil, 10)
+	if err != nil {
+		t.Fatalf("GetMonitorAlertEventListAfter 返回错误: %v", err)
+	}
+	if len(page) != 1 || page[0].TeamID != 2 {
+		t.Fatalf("游标分页返回了其他团队的事件: %+v", page)
+	}
+
+	total, err := d.GetMonitorAlertEventTotal(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetMonitorAlertEventTotal 返回错误: %v", err)
+	}
+	if total != 2 {
+		t.Fatalf("团队1事件总数应为2, 实际 %d", total)
+	}
+}
+
+func TestTenantScopeRejectsZeroTeam(t *testing.T) {
+	enableTenantScope(t)
+	d, _ := newTestEventDAO(t)
+
+	if _, err := d.GetMonitorAlertEventList(context.Background(), 0, "", 0, 10); err == nil {
+		t.Fatalf("开启团队隔离时团队ID为0应返回错误")
+	}
+	if _, err := d.GetMonitorAlertEventListWithStats(context.Background(), 0, 0, 10); err == nil {
+		t.Fatalf("开启团队隔离时团队ID为0应返回错误")
+	}
+}
+
+func TestGetMTTRUsesIncidentStart(t *testing.T) {
+	d, db := newTestEventDAO(t)
+
+	// 首次创建于 0，本轮于 1000 重新触发、1060 恢复，恢复时长应为 60 秒
+	seedEvents(t, db,
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-1", Status: "resolved", FiredAt: 1000, ResolvedAt: 1060},
+		&model.MonitorAlertEvent{AlertName: "mem", Fingerprint: "fp-2", Status: "resolved", FiredAt: 1000, ResolvedAt: 1120},
+	)
+	if err := db.Model(&model.MonitorAlertEvent{}).Where("1 = 1").UpdateColumn("created_at", 1).Error; err != nil {
+		t.Fatalf("更新创建时间失败: %v", err)
+	}
+
+	mttr, err := d.GetMTTR(context.Background(), 0, 2000)
+	if err != nil {
+		t.Fatalf("GetMTTR 返回错误: %v", err)
+	}
+	if mttr != 90*time.Second {
+		t.Fatalf("平均恢复时长应为 90s, 实际 %s", mttr)
+	}
+}
+
+func TestGetEventsOverlappingWindowIncludesRefiredEvents(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	ctx := context.Background()
+
+	seedEvents(t, db,
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-1", Status: "resolved", FiredAt: 100, ResolvedAt: 200},
+	)
+
+	// 恢复后重新触发，resolved_at 清零并以当前时间开启新一轮告警
+	var event model.MonitorAlertEvent
+	if err := db.Where("fingerprint = ?", "fp-1").First(&event).Error; err != nil {
+		t.Fatalf("查询告警事件失败: %v", err)
+	}
+	if err := d.UpdateAlertEventStatus(ctx, event.ID, "firing"); err != nil {
+		t.Fatalf("UpdateAlertEventStatus 返回错误: %v", err)
+	}
+	if err := db.First(&event, event.ID).Error; err != nil {
+		t.Fatalf("查询告警事件失败: %v", err)
+	}
+	if event.ResolvedAt != 0 || event.FiredAt <= 200 {
+		t.Fatalf("重新触发后应清零 resolved_at 并更新 fired_at, 实际 fired_at=%d resolved_at=%d", event.FiredAt, event.ResolvedAt)
+	}
+
+	now := time.Now().Unix()
+	events, err := d.GetEventsOverlappingWindow(ctx, now-60, now+60)
+	if err != nil {
+		t.Fatalf("GetEventsOverlappingWindow 返回错误: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("正在触发的告警应出现在当前窗口内, 实际 %d 条", len(events))
+	}
+
+	events, err = d.GetEventsOverlappingWindow(ctx, 300, 400)
+	if err != nil {
+		t.Fatalf("GetEventsOverlappingWindow 返回错误: %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("上一轮恢复与本轮触发之间的窗口不应包含该告警, 实际 %d 条", len(events))
+	}
+}
+
+func TestGetEventsOverlappingWindow(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	ctx := context.Background()
+
+	// 窗口为 [1000, 2000]，CreatedAt 决定返回顺序
+	for i, event := range []*model.MonitorAlertEvent{
+		{AlertName: "before", FiredAt: 100, ResolvedAt: 900},
+		{AlertName: "overlap-start", FiredAt: 500, ResolvedAt: 1200},
+		{AlertName: "spanning", FiredAt: 500, ResolvedAt: 2500},
+		{AlertName: "still-firing", FiredAt: 500},
+		{AlertName: "resolved-at-start", FiredAt: 600, ResolvedAt: 1000},
+		{AlertName: "inside", FiredAt: 1100, ResolvedAt: 1500},
+		{AlertName: "legacy-inside", CreatedAt: 1200, ResolvedAt: 1300},
+		{AlertName: "deleted-inside", FiredAt: 1300, ResolvedAt: 1400, DeletedAt: 1},
+		{AlertName: "overlap-end", FiredAt: 1800, ResolvedAt: 2500},
+		{AlertName: "fired-at-end", FiredAt: 2000},
+		{AlertName: "after", FiredAt: 2100},
+	} {
+		event.Fingerprint = "fp-" + strconv.Itoa(i)
+		event.Status = "firing"
+		if event.CreatedAt == 0 {
+			event.CreatedAt = event.FiredAt
+		}
+		seedEvents(t, db, event)
+	}
+
+	events, err := d.GetEventsOverlappingWindow(ctx, 1000, 2000)
+	if err != nil {
+		t.Fatalf("GetEventsOverlappingWindow 返回错误: %v", err)
+	}
+	got := make([]string, 0, len(events))
+	for _, event := range events {
+		got = append(got, event.AlertName)
+	}
+	want := []string{"overlap-start", "spanning", "still-firing", "resolved-at-start", "inside", "legacy-inside", "overlap-end", "fired-at-end"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("窗口内的告警期望 %v, 实际 %v", want, got)
+	}
+
+	if _, err := d.GetEventsOverlappingWindow(ctx, 2000, 1000); err == nil {
+		t.Fatal("结束时间早于开始时间应返回错误")
+	}
+}
+
+// recordingEventCache 记录被删除的指纹，用于断言缓存失效
+type recordingEventCache struct {
+	noopAlertEventCache
+	deleted []string
+}
+
+func (c *recordingEventCache) Delete(_ context.Context, fingerprint string) error {
+	c.deleted = append(c.deleted, fingerprint)
+	return nil
+}
+
+func TestEventMutationsInvalidateCache(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	cache := &recordingEventCache{}
+	d.eventCache = cache
+	ctx := context.Background()
+
+	event := &model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-1", Status: "firing"}
+	seedEvents(t, db, event)
+
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		{"UpdateAlertEvent", func() error {
+			return d.UpdateAlertEvent(ctx, &model.MonitorAlertEvent{ID: event.ID, Fingerprint: "fp-1", SilenceID: "silence-1"})
+		}},
+		{"EventAlertClaim", func() error {
+			return d.EventAlertClaim(ctx, &model.MonitorAlertEvent{ID: event.ID, RenLingUserID: 1, Status: "claimed"})
+		}},
+		{"EventAlertUnclaim", func() error { return d.EventAlertUnclaim(ctx, event.ID, 1) }},
+		{"AckAlertEvent", func() error { return d.AckAlertEvent(ctx, event.ID, 1) }},
+		{"UpdateAlertEventStatus", func() error { return d.UpdateAlertEventStatus(ctx, event.ID, "resolved") }},
+		{"BulkUpdateAlertEventStatus", func() error {
+			_, err := d.BulkUpdateAlertEventStatus(ctx, []int{event.ID}, "resolved")
+			return err
+		}},
+		{"PurgeResolvedEventsBefore", func() error {
+			_, err := d.PurgeResolvedEventsBefore(ctx, time.Now().Unix()+1)
+			return err
+		}},
+	}
+
+	for _, step := range steps {
+		cache.deleted = nil
+		if err := step.run(); err != nil {
+			t.Fatalf("%s 返回错误: %v", step.name, err)
+		}
+		if len(cache.deleted) != 1 || cache.deleted[0] != "fp-1" {
+			t.Fatalf("%s 后应删除指纹缓存 fp-1, 实际删除 %v", step.name, cache.deleted)
+		}
+	}
+}
+
+// mapEventCache 基于 map 的告警事件缓存，用于断言按指纹查询时不会读到过期数据
+type mapEventCache struct {
+	events map[string]*model.MonitorAlertEvent
+}
+
+func (c *mapEventCache) Get(_ context.Context, fingerprint string) (*model.MonitorAlertEvent, error) {
+	return c.events[fingerprint], nil
+}
+
+func (c *mapEventCache) Set(_ context.Context, event *model.MonitorAlertEvent) error {
+	copied := *event
+	c.events[event.Fingerprint] = &copied
+	return nil
+}
+
+func (c *mapEventCache) Delete(_ context.Context, fingerprint string) error {
+	delete(c.events, fingerprint)
+	return nil
+}
+
+func TestUpdateAlertEventInvalidatesStoredFingerprint(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	cache := &mapEventCache{events: map[string]*model.MonitorAlertEvent{}}
+	d.eventCache = cache
+	ctx := context.Background()
+
+	event := &model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-1", Status: "firing"}
+	seedEvents(t, db, event)
+	_ = cache.Set(ctx, event)
+
+	// 部分更新不携带指纹，仍应删除库中指纹对应的缓存
+	if err := d.UpdateAlertEvent(ctx, &model.MonitorAlertEvent{ID: event.ID, Version: event.Version, SilenceID: "silence-1"}); err != nil {
+		t.Fatalf("UpdateAlertEvent 返回错误: %v", err)
+	}
+	cached, _ := cache.Get(ctx, "fp-1")
+	if cached != nil {
+		t.Fatalf("部分更新后按指纹查询不应命中过期缓存, 实际 %+v", cached)
+	}
+
+	// 指纹变更时删除旧指纹的缓存
+	stored, err := d.GetAlertEventByID(ctx, event.ID)
+	if err != nil {
+		t.Fatalf("GetAlertEventByID 返回错误: %v", err)
+	}
+	if stored.SilenceID != "silence-1" {
+		t.Fatalf("部分更新应写入静默ID, 实际 %q", stored.SilenceID)
+	}
+	_ = cache.Set(ctx, stored)
+	if err := d.UpdateAlertEvent(ctx, &model.MonitorAlertEvent{ID: event.ID, Version: stored.Version, Fingerprint: "fp-2"}); err != nil {
+		t.Fatalf("UpdateAlertEvent 返回错误: %v", err)
+	}
+	if cached, _ := cache.Get(ctx, "fp-1"); cached != nil {
+		t.Fatalf("指纹变更后旧指纹不应命中过期缓存, 实际 %+v", cached)
+	}
+}
+
+func TestTransactionInvalidatesCacheAfterCommit(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	cache := &recordingEventCache{}
+	d.eventCache = cache
+	ctx := context.Background()
+
+	event := &model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-1", Status: "firing"}
+	seedEvents(t, db, event)
+
+	err := d.WithTransaction(ctx, func(txDAO AlertManagerEventDAO) error {
+		if err := txDAO.UpdateAlertEventStatus(ctx, event.ID, "resolved"); err != nil {
+			return err
+		}
+		if len(cache.deleted) != 0 {
+			t.Fatalf("事务提交前不应删除缓存, 实际删除 %v", cache.deleted)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTransaction 返回错误: %v", err)
+	}
+	if len(cache.deleted) != 1 || cache.deleted[0] != "fp-1" {
+		t.Fatalf("事务提交后应删除指纹缓存 fp-1, 实际删除 %v", cache.deleted)
+	}
+
+	cache.deleted = nil
+	_ = d.WithTransaction(ctx, func(txDAO AlertManagerEventDAO) error {
+		if err := txDAO.UpdateAlertEventStatus(ctx, event.ID, "firing"); err != nil {
+			return err
+		}
+		return errors.New("回滚")
+	})
+	if len(cache.deleted) != 0 {
+		t.Fatalf("事务回滚时不应删除缓存, 实际删除 %v", cache.deleted)
+	}
+}
+
+func TestGetEventsByLabelKVScopedAndLimited(t *testing.T) {
+	enableTenantScope(t)
+	d, db := newTestEventDAO(t)
+	ctx := context.Background()
+
+	longValue := strings.Repeat("v", model.AlertEventLabelValueMaxLen+5)
+	events := []*model.MonitorAlertEvent{
+		{AlertName: "a", Fingerprint: "fp-1", Status: "firing", TeamID: 1},
+		{AlertName: "b", Fingerprint: "fp-2", Status: "firing", TeamID: 1},
+		{AlertName: "c", Fingerprint: "fp-3", Status: "firing", TeamID: 2},
+	}
+	seedEvents(t, db, events...)
+	for _, event := range events {
+		row := &model.AlertEventLabel{EventID: event.ID, Key: "desc", Value: model.LabelIndexValue(longValue)}
+		if err := db.Create(row).Error; err != nil {
+			t.Fatalf("写入标签索引失败: %v", err)
+		}
+	}
+
+	found, err := d.GetEventsByLabelKV(ctx, 1, "desc", longValue, 10)
+	if err != nil {
+		t.Fatalf("GetEventsByLabelKV 返回错误: %v", err)
+	}
+	if len(found) != 2 {
+		t.Fatalf("团队1应查到2条事件, 实际 %d", len(found))
+	}
+	for _, event := range found {
+		if event.TeamID != 1 {
+			t.Fatalf("按标签查询返回了其他团队的事件: %+v", event)
+		}
+	}
+
+	found, err = d.GetEventsByLabelKV(ctx, 1, "desc", longValue, 1)
+	if err != nil {
+		t.Fatalf("GetEventsByLabelKV 返回错误: %v", err)
+	}
+	if len(found) != 1 {
+		t.Fatalf("limit=1 时应只返回1条事件, 实际 %d", len(found))
+	}
+
+	if _, err := d.GetEventsByLabelKV(ctx, 0, "desc", longValue, 10); err == nil {
+		t.Fatalf("开启团队隔离时团队ID为0应返回错误")
+	}
+}
+
+func TestCountEventsByRuleMatchesGetEventCountByRule(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	ctx := context.Background()
+
+	for _, rule := range []*model.MonitorAlertRule{
+		{ID: 1, Name: "cpu-high"},
+		{ID: 2, Name: "disk-full", DeletedAt: 100},
+	} {
+		if err := db.Create(rule).Error; err != nil {
+			t.Fatalf("写入测试告警规则失败: %v", err)
+		}
+	}
+	seedEvents(t, db,
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-1", Status: "firing", RuleID: 1, CreatedAt: 2000},
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-2", Status: "firing", RuleID: 1, CreatedAt: 2000},
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-3", Status: "firing", RuleID: 1, CreatedAt: 2000, DeletedAt: 3000},
+		&model.MonitorAlertEvent{AlertName: "disk", Fingerprint: "fp-4", Status: "firing", RuleID: 2, CreatedAt: 2000},
+		&model.MonitorAlertEvent{AlertName: "disk", Fingerprint: "fp-5", Status: "firing", RuleID: 2, CreatedAt: 500},
+	)
+
+	since, err := d.CountEventsByRule(ctx, 1000)
+	if err != nil {
+		t.Fatalf("CountEventsByRule 返回错误: %v", err)
+	}
+	want := []model.RuleEventCount{
+		{RuleID: 1, RuleName: "cpu-high", Count: 2},
+		{RuleID: 2, Count: 1},
+	}
+	if len(since) != len(want) || since[0] != want[0] || since[1] != want[1] {
+		t.Fatalf("应排除已删除事件和窗口外事件，已删除规则名称为空, 实际 %+v", since)
+	}
+
+	window, err := d.GetEventCountByRule(ctx, 1001, 3000, 10)
+	if err != nil {
+		t.Fatalf("GetEventCountByRule 返回错误: %v", err)
+	}
+	if len(window) != len(since) || window[0] != since[0] || window[1] != since[1] {
+		t.Fatalf("相同窗口下两种统计结果应一致, 实际 %+v 与 %+v", window, since)
+	}
+
+	top, err := d.GetEventCountByRule(ctx, 1001, 3000, 1)
+	if err != nil || len(top) != 1 || top[0].RuleID != 1 {
+		t.Fatalf("limit 应只返回事件数最多的规则, 实际 %+v, err=%v", top, err)
+	}
+}
+
+func TestUpdateAlertEventStatusUnchanged(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	ctx := context.Background()
+
+	event := &model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-1", Status: "firing"}
+	seedEvents(t, db, event)
+
+	// 模拟 MySQL 对数据未变化的行不计入影响行数的行为
+	if err := db.Callback().Update().After("gorm:update").Register("test:zero_rows_affected", func(tx *gorm.DB) {
+		tx.RowsAffected = 0
+	}); err != nil {
+		t.Fatalf("注册更新回调失败: %v", err)
+	}
+
+	if err := d.UpdateAlertEventStatus(ctx, event.ID, string(model.AlertStatusFiring)); err != nil {
+		t.Fatalf("状态未变化时不应返回错误, 实际 %v", err)
+	}
+	if err := d.UpdateAlertEventStatus(ctx, event.ID+100, string(model.AlertStatusFiring)); !errors.Is(err, ErrEventNotFound) {
+		t.Fatalf("事件不存在时应返回 ErrEventNotFound, 实际 %v", err)
+	}
+}
+
+func TestEventAlertClaimWritesSingleAudit(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	ctx := context.Background()
+
+	event := &model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-1", Status: "firing"}
+	seedEvents(t, db, event)
+
+	if err := d.EventAlertClaim(ctx, &model.MonitorAlertEvent{ID: event.ID, RenLingUserID: 7, Status: string(model.AlertStatusClaimed)}); err != nil {
+		t.Fatalf("EventAlertClaim 返回错误: %v", err)
+	}
+
+	var audits []model.AlertEventAudit
+	if err := db.Where("event_id = ?", event.ID).Find(&audits).Error; err != nil {
+		t.Fatalf("查询审计记录失败: %v", err)
+	}
+	if len(audits) != 1 || audits[0].Action != model.AlertEventAuditActionClaim || audits[0].UserID != 7 {
+		t.Fatalf("认领应只写入一条审计记录, 实际 %+v", audits)
+	}
+}
+
+func TestSeverityFilterAppliesToListAndSearch(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	ctx := context.Background()
+
+	seedEvents(t, db,
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-1", Status: "firing", Severity: "critical"},
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-2", Status: "firing", Severity: "warning"},
+		&model.MonitorAlertEvent{AlertName: "disk", Fingerprint: "fp-3", Status: "firing", Severity: "critical"},
+	)
+
+	list, err := d.GetMonitorAlertEventList(ctx, 0, "CRITICAL", 0, 10)
+	if err != nil {
+		t.Fatalf("GetMonitorAlertEventList 返回错误: %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("列表应只返回2条 critical 事件, 实际 %d", len(list))
+	}
+
+	found, err := d.SearchMonitorAlertEventByName(ctx, 0, "cpu", "critical")
+	if err != nil {
+		t.Fatalf("SearchMonitorAlertEventByName 返回错误: %v", err)
+	}
+	if len(found) != 1 || found[0].Fingerprint != "fp-1" {
+		t.Fatalf("按名称搜索应同时按级别过滤: %+v", found)
+	}
+
+	events, total, err := d.SearchMonitorAlertEvents(ctx, 0, &model.AlertEventFilter{Name: "cpu", Severity: "warning"}, 0, 10)
+	if err != nil {
+		t.Fatalf("SearchMonitorAlertEvents 返回错误: %v", err)
+	}
+	if total != 1 || len(events) != 1 || events[0].Fingerprint != "fp-2" {
+		t.Fatalf("组合搜索应按级别过滤: total=%d, %+v", total, events)
+	}
+}
+
+func TestGetAlertEventHistory(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	ctx := context.Background()
+
+	// 多个指纹的触发和恢复交替写入，fp-c 已软删除
+	seedEvents(t, db,
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-a", Status: "firing"},
+		&model.MonitorAlertEvent{AlertName: "disk", Fingerprint: "fp-b", Status: "resolved"},
+		&model.MonitorAlertEvent{AlertName: "mem", Fingerprint: "fp-c", Status: "firing", DeletedAt: 1},
+		&model.MonitorAlertEvent{AlertName: "net", Fingerprint: "fp-d", Status: "resolved"},
+	)
+
+	for fingerprint, wantID := range map[string]int{"fp-a": 1, "fp-b": 2, "fp-d": 4} {
+		history, err := d.GetAlertEventHistory(ctx, fingerprint)
+		if err != nil {
+			t.Fatalf("GetAlertEventHistory 返回错误: %v", err)
+		}
+		if len(history) != 1 || history[0].ID != wantID || history[0].Fingerprint != fingerprint {
+			t.Fatalf("%s 的历史只应包含该指纹的事件: %+v", fingerprint, history)
+		}
+	}
+
+	history, err := d.GetAlertEventHistory(ctx, "fp-c")
+	if err != nil {
+		t.Fatalf("GetAlertEventHistory 返回错误: %v", err)
+	}
+	if len(history) != 0 {
+		t.Fatalf("历史不应包含已删除的事件: %+v", history)
+	}
+
+	if _, err := d.GetAlertEventHistory(ctx, ""); err == nil {
+		t.Fatal("空指纹应返回错误")
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	d, _ := newTestEventDAO(t)
+	ctx := context.Background()
+
+	var gotBody string
+	var gotQuery map[string][]string
+	respBody := `{"code":0,"msg":"success"}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		gotQuery = r.URL.Query()
+		_, _ = w.Write([]byte(respBody))
+	}))
+	defer srv.Close()
+
+	resp, err := d.TestWebhook(ctx, WebhookProviderFeishu, srv.URL, "secret")
+	if err != nil {
+		t.Fatalf("飞书连通性测试应成功: %v", err)
+	}
+	if resp != respBody {
+		t.Fatalf("应返回服务端响应, 实际 %q", resp)
+	}
+	if !strings.Contains(gotBody, webhookTestMessage) || !strings.Contains(gotBody, `"sign"`) {
+		t.Fatalf("飞书测试消息应包含测试文本和签名: %s", gotBody)
+	}
+
+	respBody = `{"errcode":0,"errmsg":"ok"}`
+	if _, err := d.TestWebhook(ctx, WebhookProviderDingTalk, srv.URL, "secret"); err != nil {
+		t.Fatalf("钉钉连通性测试应成功: %v", err)
+	}
+	if gotQuery["sign"] == nil || gotQuery["timestamp"] == nil {
+		t.Fatalf("钉钉签名应放在查询参数中: %v", gotQuery)
+	}
+
+	// 飞书和钉钉拒绝请求时仍返回 200，需按错误码识别
+	respBody = `{"code":19021,"msg":"sign match fail"}`
+	resp, err = d.TestWebhook(ctx, WebhookProviderFeishu, srv.URL, "wrong")
+	if err == nil || !strings.Contains(err.Error(), "sign match fail") {
+		t.Fatalf("飞书拒绝时应返回错误, 实际 %v", err)
+	}
+	if resp != respBody {
+		t.Fatalf("拒绝时也应返回服务端响应, 实际 %q", resp)
+	}
+	respBody = `{"errcode":310000,"errmsg":"keywords not in content"}`
+	if _, err := d.TestWebhook(ctx, WebhookProviderDingTalk, srv.URL, ""); err == nil {
+		t.Fatal("钉钉拒绝时应返回错误")
+	}
+
+	if _, err := d.TestWebhook(ctx, "slack", srv.URL, ""); err == nil {
+		t.Fatal("不支持的 webhook 类型应返回错误")
+	}
+	if _, err := d.TestWebhook(ctx, WebhookProviderFeishu, "", ""); err == nil {
+		t.Fatal("空 url 应返回错误")
+	}
+
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+	if _, err := d.TestWebhook(ctx, WebhookProviderFeishu, unreachable.URL, ""); err == nil || !strings.Contains(err.Error(), "webhook 地址不可用") {
+		t.Fatalf("地址不可达时应返回明确错误, 实际 %v", err)
+	}
+}
+
+func TestAckThenClaimByDifferentUsers(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	ctx := context.Background()
+
+	event := &model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-1", Status: "firing"}
+	seedEvents(t, db, event)
+
+	if err := d.AckAlertEvent(ctx, event.ID, 3); err != nil {
+		t.Fatalf("AckAlertEvent 返回错误: %v", err)
+	}
+	if err := d.EventAlertClaim(ctx, &model.MonitorAlertEvent{ID: event.ID, RenLingUserID: 7, Status: string(model.AlertStatusClaimed)}); err != nil {
+		t.Fatalf("EventAlertClaim 返回错误: %v", err)
+	}
+
+	got, err := d.GetAlertEventByID(ctx, event.ID)
+	if err != nil {
+		t.Fatalf("GetAlertEventByID 返回错误: %v", err)
+	}
+	if got.AckUserID != 3 || got.AckAt == 0 {
+		t.Fatalf("认领不应覆盖确认信息: ack_user_id=%d, ack_at=%d", got.AckUserID, got.AckAt)
+	}
+	if got.RenLingUserID != 7 || got.Status != string(model.AlertStatusClaimed) {
+		t.Fatalf("确认不应占有事件, 认领人应为 7: ren_ling_user_id=%d, status=%s", got.RenLingUserID, got.Status)
+	}
+
+	// 同一用户重复确认应刷新确认时间
+	if err := db.Model(&model.MonitorAlertEvent{}).Where("id = ?", event.ID).Update("ack_at", 1).Error; err != nil {
+		t.Fatalf("重置确认时间失败: %v", err)
+	}
+	if err := d.AckAlertEvent(ctx, event.ID, 3); err != nil {
+		t.Fatalf("重复确认返回错误: %v", err)
+	}
+	if got, err = d.GetAlertEventByID(ctx, event.ID); err != nil {
+		t.Fatalf("GetAlertEventByID 返回错误: %v", err)
+	}
+	if got.AckUserID != 3 || got.AckAt <= 1 {
+		t.Fatalf("重复确认应刷新确认时间: ack_user_id=%d, ack_at=%d", got.AckUserID, got.AckAt)
+	}
+
+	if err := d.AckAlertEvent(ctx, 999, 3); !errors.Is(err, ErrEventNotFound) {
+		t.Fatalf("确认不存在的事件应返回 ErrEventNotFound, 实际 %v", err)
+	}
+}
+
+func TestUpdateAlertEventStatusLeavesOtherColumns(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	ctx := context.Background()
+
+	event := &model.MonitorAlertEvent{
+		AlertName: "cpu", Fingerprint: "fp-1", Status: "firing", Severity: "critical",
+		RuleID: 3, SendGroupID: 4, TeamID: 5, EventTimes: 6, SilenceID: "silence-1",
+		RenLingUserID: 7, AckUserID: 8, AckAt: 9, Version: 2, LastNotifiedAt: 10, FiredAt: 11,
+		Labels: model.Labels{"alertname": "cpu", "instance": "node-1"}, Annotations: model.Labels{"summary": "cpu high"},
+	}
+	seedEvents(t, db, event)
+	// 回拨更新时间，保证同一秒内更新也能观察到 updated_at 变化
+	if err := db.Model(&model.MonitorAlertEvent{}).Where("id = ?", event.ID).UpdateColumn("updated_at", 1).Error; err != nil {
+		t.Fatalf("重置更新时间失败: %v", err)
+	}
+
+	var before model.MonitorAlertEvent
+	if err := db.First(&before, event.ID).Error; err != nil {
+		t.Fatalf("查询告警事件失败: %v", err)
+	}
+
+	if err := d.UpdateAlertEventStatus(ctx, event.ID, "silenced"); err != nil {
+		t.Fatalf("UpdateAlertEventStatus 返回错误: %v", err)
+	}
+
+	var after model.MonitorAlertEvent
+	if err := db.First(&after, event.ID).Error; err != nil {
+		t.Fatalf("查询告警事件失败: %v", err)
+	}
+	if after.Status != "silenced" || after.UpdatedAt <= before.UpdatedAt {
+		t.Fatalf("应更新 status 和 updated_at: status=%s, updated_at=%d", after.Status, after.UpdatedAt)
+	}
+
+	after.Status, after.UpdatedAt = before.Status, before.UpdatedAt
+	if !reflect.DeepEqual(before, after) {
+		t.Fatalf("除 status 和 updated_at 外的字段不应变化:\n更新前 %+v\n更新后 %+v", before, after)
+	}
+}
+
+func TestSearchMonitorAlertEventsCombinedFilters(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	ctx := context.Background()
+
+	seedEvents(t, db,
+		&model.MonitorAlertEvent{AlertName: "cpu-high", Fingerprint: "fp-1", Status: "firing", CreatedAt: 100},
+		&model.MonitorAlertEvent{AlertName: "cpu-low", Fingerprint: "fp-2", Status: "resolved", CreatedAt: 200},
+		&model.MonitorAlertEvent{AlertName: "disk", Fingerprint: "fp-3", Status: "firing", CreatedAt: 300},
+		&model.MonitorAlertEvent{AlertName: "cpu-high", Fingerprint: "fp-4", Status: "firing", CreatedAt: 400},
+	)
+
+	for _, c := range []struct {
+		name   string
+		filter model.AlertEventFilter
+		want   []int
+	}{
+		{"名称", model.AlertEventFilter{Name: "cpu"}, []int{4, 2, 1}},
+		{"状态", model.AlertEventFilter{Status: "firing"}, []int{4, 3, 1}},
+		{"时间范围", model.AlertEventFilter{StartTime: 150, EndTime: 350}, []int{3, 2}},
+		{"名称+状态", model.AlertEventFilter{Name: "cpu", Status: "firing"}, []int{4, 1}},
+		{"名称+时间", model.AlertEventFilter{Name: "cpu", StartTime: 150, EndTime: 450}, []int{4, 2}},
+		{"状态+时间", model.AlertEventFilter{Status: "firing", StartTime: 250}, []int{4, 3}},
+		{"名称+状态+时间", model.AlertEventFilter{Name: "cpu", Status: "firing", EndTime: 150}, []int{1}},
+		{"无匹配", model.AlertEventFilter{Name: "cpu", Status: "resolved", StartTime: 300}, []int{}},
+	} {
+		filter := c.filter
+		events, total, err := d.SearchMonitorAlertEvents(ctx, 0, &filter, 0, 10)
+		if err != nil {
+			t.Fatalf("%s: SearchMonitorAlertEvents 返回错误: %v", c.name, err)
+		}
+		got := make([]int, 0, len(events))
+		for _, event := range events {
+			got = append(got, event.ID)
+		}
+		if total != int64(len(c.want)) || !reflect.DeepEqual(got, c.want) {
+			t.Fatalf("%s: 期望 %v (共 %d 条), 实际 %v (共 %d 条)", c.name, c.want, len(c.want), got, total)
+		}
+	}
+
+	// 分页时 total 为过滤后的总数
+	events, total, err := d.SearchMonitorAlertEvents(ctx, 0, &model.AlertEventFilter{Name: "cpu"}, 1, 1)
+	if err != nil {
+		t.Fatalf("SearchMonitorAlertEvents 返回错误: %v", err)
+	}
+	if total != 3 || len(events) != 1 || events[0].ID != 2 {
+		t.Fatalf("分页结果不正确: total=%d, %+v", total, events)
+	}
+
+	if _, _, err := d.SearchMonitorAlertEvents(ctx, 0, &model.AlertEventFilter{StartTime: 300, EndTime: 100}, 0, 10); err == nil {
+		t.Fatal("结束时间早于开始时间应返回错误")
+	}
+}
+
+func TestUpdateAlertEventRejectsStaleVersion(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	ctx := context.Background()
+
+	seedEvents(t, db, &model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-1", Status: "firing"})
+
+	// 两名运维人员读取到同一版本的事件
+	first, err := d.GetAlertEventByID(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetAlertEventByID 返回错误: %v", err)
+	}
+	second := *first
+
+	first.SilenceID = "silence-a"
+	if err := d.UpdateAlertEvent(ctx, first); err != nil {
+		t.Fatalf("首次更新应成功: %v", err)
+	}
+	if first.Version != second.Version+1 {
+		t.Fatalf("更新成功后版本号应加 1, 实际 %d", first.Version)
+	}
+
+	second.SilenceID = "silence-b"
+	if err := d.UpdateAlertEvent(ctx, &second); !errors.Is(err, ErrAlertEventVersionConflict) {
+		t.Fatalf("旧版本更新应返回 ErrAlertEventVersionConflict, 实际 %v", err)
+	}
+
+	got, err := d.GetAlertEventByID(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetAlertEventByID 返回错误: %v", err)
+	}
+	if got.SilenceID != "silence-a" || got.Version != first.Version {
+		t.Fatalf("旧版本更新不应覆盖已提交的修改: silence_id=%s, version=%d", got.SilenceID, got.Version)
+	}
+
+	if err := d.UpdateAlertEvent(ctx, &model.MonitorAlertEvent{ID: 99, Status: "firing"}); !errors.Is(err, ErrEventNotFound) {
+		t.Fatalf("事件不存在时应返回 ErrEventNotFound, 实际 %v", err)
+	}
+}
+
+// cancelledContext 返回已取消的上下文
+func cancelledContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
+
+func TestGetMonitorAlertEventListCancelledContext(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	seedEvents(t, db, &model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-1", Status: "firing"})
+
+	start := time.Now()
+	if _, err := d.GetMonitorAlertEventList(cancelledContext(), 0, "", 0, 10); !errors.Is(err, context.Canceled) {
+		t.Fatalf("上下文已取消时应返回 context.Canceled, 实际 %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("上下文已取消时应立即返回, 实际耗时 %v", elapsed)
+	}
+}
+
+func TestClaimThenUnclaimAuditTrail(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	ctx := context.Background()
+
+	event := &model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-1", Status: "firing"}
+	seedEvents(t, db, event)
+
+	if err := d.EventAlertClaim(ctx, &model.MonitorAlertEvent{ID: event.ID, RenLingUserID: 7, Status: string(model.AlertStatusClaimed)}); err != nil {
+		t.Fatalf("EventAlertClaim 返回错误: %v", err)
+	}
+	if err := d.EventAlertUnclaim(ctx, event.ID, 8); err != nil {
+		t.Fatalf("EventAlertUnclaim 返回错误: %v", err)
+	}
+
+	trail, err := d.GetEventAuditTrail(ctx, event.ID)
+	if err != nil {
+		t.Fatalf("GetEventAuditTrail 返回错误: %v", err)
+	}
+	if len(trail) != 2 {
+		t.Fatalf("认领后取消认领应产生两条审计记录, 实际 %d", len(trail))
+	}
+	if trail[0].Action != model.AlertEventAuditActionClaim || trail[0].UserID != 7 {
+		t.Fatalf("第一条审计记录应为用户 7 认领: %+v", trail[0])
+	}
+	if trail[1].Action != model.AlertEventAuditActionUnclaim || trail[1].UserID != 8 {
+		t.Fatalf("第二条审计记录应为用户 8 取消认领: %+v", trail[1])
+	}
+	if trail[0].CreatedAt == 0 || trail[1].CreatedAt < trail[0].CreatedAt || trail[0].EventID != event.ID {
+		t.Fatalf("审计记录应包含事件ID和时间并按时间排序: %+v, %+v", trail[0], trail[1])
+	}
+
+	// 未认领的事件取消认领失败时不写入审计记录
+	if err := d.EventAlertUnclaim(ctx, event.ID, 8); err == nil {
+		t.Fatal("未认领的事件取消认领应返回错误")
+	}
+	if trail, _ = d.GetEventAuditTrail(ctx, event.ID); len(trail) != 2 {
+		t.Fatalf("失败的操作不应写入审计记录, 实际 %d 条", len(trail))
+	}
+}
+
+func TestSearchMonitorAlertEventsIncludeDeleted(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	ctx := context.Background()
+
+	seedEvents(t, db,
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-1", Status: "firing"},
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-2", Status: "firing", DeletedAt: 100},
+	)
+
+	events, total, err := d.SearchMonitorAlertEvents(ctx, 0, &model.AlertEventFilter{}, 0, 10)
+	if err != nil {
+		t.Fatalf("SearchMonitorAlertEvents 返回错误: %v", err)
+	}
+	if total != 1 || len(events) != 1 || events[0].Fingerprint != "fp-1" {
+		t.Fatalf("默认不应返回已删除事件: total=%d, %+v", total, events)
+	}
+
+	events, total, err = d.SearchMonitorAlertEvents(ctx, 0, &model.AlertEventFilter{IncludeDeleted: true}, 0, 10)
+	if err != nil {
+		t.Fatalf("SearchMonitorAlertEvents 返回错误: %v", err)
+	}
+	if total != 2 || len(events) != 2 {
+		t.Fatalf("开启 IncludeDeleted 时应返回已删除事件: total=%d, %+v", total, events)
+	}
+	for _, event := range events {
+		if event.Fingerprint == "fp-2" && event.DeletedAt != 100 {
+			t.Fatalf("已删除事件应带上 deleted_at, 实际 %d", event.DeletedAt)
+		}
+	}
+}
+
+func TestWithTransactionRollsBack(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	ctx := context.Background()
+
+	event := &model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-1", Status: "firing"}
+	seedEvents(t, db, event)
+
+	assertStatus := func(want string) {
+		t.Helper()
+		got, err := d.GetAlertEventByID(ctx, event.ID)
+		if err != nil {
+			t.Fatalf("GetAlertEventByID 返回错误: %v", err)
+		}
+		if got.Status != want {
+			t.Fatalf("事件状态期望 %s, 实际 %s", want, got.Status)
+		}
+	}
+
+	errRollback := errors.New("回滚")
+	err := d.WithTransaction(ctx, func(txDAO AlertManagerEventDAO) error {
+		if err := txDAO.UpdateAlertEventStatus(ctx, event.ID, "resolved"); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("应返回 fn 的错误, 实际 %v", err)
+	}
+	assertStatus("firing")
+
+	err = d.WithTransaction(ctx, func(txDAO AlertManagerEventDAO) error {
+		if err := txDAO.UpdateAlertEventStatus(ctx, event.ID, "resolved"); err != nil {
+			return err
+		}
+		panic("意外错误")
+	})
+	if err == nil || !strings.Contains(err.Error(), "panic") {
+		t.Fatalf("fn panic 时应返回错误, 实际 %v", err)
+	}
+	assertStatus("firing")
+
+	if err := d.WithTransaction(ctx, func(txDAO AlertManagerEventDAO) error {
+		return txDAO.UpdateAlertEventStatus(ctx, event.ID, "resolved")
+	}); err != nil {
+		t.Fatalf("WithTransaction 返回错误: %v", err)
+	}
+	assertStatus("resolved")
+}
+
+func TestGetMonitorAlertEventSummaryListProjection(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	ctx := context.Background()
+
+	seedEvents(t, db, &model.MonitorAlertEvent{
+		AlertName: "cpu", Fingerprint: "fp-1", Status: "firing", EventTimes: 3, Severity: "critical",
+		RuleID: 2, SendGroupID: 3, SilenceID: "silence-1",
+		Labels: model.Labels{"alertname": "cpu", "instance": "node-1"}, Annotations: model.Labels{"summary": "cpu high"},
+	})
+
+	events, err := d.GetMonitorAlertEventSummaryList(ctx, 0, 0, 10)
+	if err != nil {
+		t.Fatalf("GetMonitorAlertEventSummaryList 返回错误: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("应返回 1 条事件, 实际 %d", len(events))
+	}
+
+	got := events[0]
+	if got.ID == 0 || got.AlertName != "cpu" || got.Status != "firing" || got.EventTimes != 3 || got.CreatedAt == 0 {
+		t.Fatalf("投影字段应被填充: %+v", got)
+	}
+	want := model.MonitorAlertEvent{ID: got.ID, AlertName: got.AlertName, Status: got.Status, EventTimes: got.EventTimes, CreatedAt: got.CreatedAt}
+	if !reflect.DeepEqual(*got, want) {
+		t.Fatalf("投影外的字段不应被填充:\n期望 %+v\n实际 %+v", want, *got)
+	}
+}
+
+func TestGetMonitorAlertEventListPagesWithSameCreatedAt(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	ctx := context.Background()
+
+	// 批量写入的事件 created_at 相同
+	const total = 25
+	for i := 0; i < total; i++ {
+		seedEvents(t, db, &model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-" + strconv.Itoa(i), Status: "firing", CreatedAt: 1000})
+	}
+
+	seen := make(map[int]bool, total)
+	var order []int
+	for offset := 0; ; offset += 4 {
+		page, err := d.GetMonitorAlertEventList(ctx, 0, "", offset, 4)
+		if err != nil {
+			t.Fatalf("GetMonitorAlertEventList 返回错误: %v", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		for _, event := range page {
+			if seen[event.ID] {
+				t.Fatalf("事件 %d 出现在多个分页中", event.ID)
+			}
+			seen[event.ID] = true
+			order = append(order, event.ID)
+		}
+	}
+	if len(seen) != total {
+		t.Fatalf("遍历所有分页应得到 %d 条事件, 实际 %d", total, len(seen))
+	}
+	for i := 1; i < len(order); i++ {
+		if order[i] >= order[i-1] {
+			t.Fatalf("created_at 相同时应按 id 降序排列: %v", order)
+		}
+	}
+}
+
+func TestGetUnclaimedFiringEvents(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	ctx := context.Background()
+
+	seedEvents(t, db,
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-1", Status: "firing", CreatedAt: 300},
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-2", Status: "claimed", RenLingUserID: 7, CreatedAt: 100},
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-3", Status: "firing", CreatedAt: 100},
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-4", Status: "resolved", CreatedAt: 50},
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-5", Status: "firing", CreatedAt: 200, DeletedAt: 1},
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-6", Status: "firing", RenLingUserID: 8, CreatedAt: 150},
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-7", Status: "firing", CreatedAt: 200},
+	)
+
+	events, total, err := d.GetUnclaimedFiringEvents(ctx, 0, 10)
+	if err != nil {
+		t.Fatalf("GetUnclaimedFiringEvents 返回错误: %v", err)
+	}
+	got := make([]string, 0, len(events))
+	for _, event := range events {
+		got = append(got, event.Fingerprint)
+	}
+	// 只包含未删除、未认领的 firing 事件，按创建时间从早到晚排列
+	if want := []string{"fp-3", "fp-7", "fp-1"}; total != 3 || !reflect.DeepEqual(got, want) {
+		t.Fatalf("期望 %v (共 3 条), 实际 %v (共 %d 条)", want, got, total)
+	}
+
+	events, total, err = d.GetUnclaimedFiringEvents(ctx, 1, 1)
+	if err != nil {
+		t.Fatalf("GetUnclaimedFiringEvents 返回错误: %v", err)
+	}
+	if total != 3 || len(events) != 1 || events[0].Fingerprint != "fp-7" {
+		t.Fatalf("分页时 total 应为过滤后的总数: total=%d, %+v", total, events)
+	}
+}
+
+func TestWithTransactionRollsBackComposedCalls(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	ctx := context.Background()
+
+	event := &model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-1", Status: "firing"}
+	seedEvents(t, db, event)
+
+	// 认领、确认在同一事务中，后续步骤失败时全部回滚
+	err := d.WithTransaction(ctx, func(txDAO AlertManagerEventDAO) error {
+		if err := txDAO.EventAlertClaim(ctx, &model.MonitorAlertEvent{ID: event.ID, RenLingUserID: 7, Status: string(model.AlertStatusClaimed)}); err != nil {
+			return err
+		}
+		if err := txDAO.AckAlertEvent(ctx, event.ID, 7); err != nil {
+			return err
+		}
+		return txDAO.AckAlertEvent(ctx, event.ID+100, 7)
+	})
+	if !errors.Is(err, ErrEventNotFound) {
+		t.Fatalf("应返回失败步骤的错误, 实际 %v", err)
+	}
+
+	got, err := d.GetAlertEventByID(ctx, event.ID)
+	if err != nil {
+		t.Fatalf("GetAlertEventByID 返回错误: %v", err)
+	}
+	if got.RenLingUserID != 0 || got.AckUserID != 0 || got.Status != "firing" {
+		t.Fatalf("事务失败后事件应保持原样: %+v", got)
+	}
+	trail, err := d.GetEventAuditTrail(ctx, event.ID)
+	if err != nil {
+		t.Fatalf("GetEventAuditTrail 返回错误: %v", err)
+	}
+	if len(trail) != 0 {
+		t.Fatalf("事务失败后不应保留审计记录, 实际 %d 条", len(trail))
+	}
+}
+
+func TestGetAlertEventsByIDs(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	ctx := context.Background()
+
+	seedEvents(t, db,
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-1", Status: "firing"},
+		&model.MonitorAlertEvent{AlertName: "disk", Fingerprint: "fp-2", Status: "firing", DeletedAt: 100},
+		&model.MonitorAlertEvent{AlertName: "mem", Fingerprint: "fp-3", Status: "firing"},
+	)
+
+	var queries int64
+	if err := db.Callback().Query().Before("gorm:query").Register("test:count_event_query", func(tx *gorm.DB) {
+		atomic.AddInt64(&queries, 1)
+	}); err != nil {
+		t.Fatalf("注册查询回调失败: %v", err)
+	}
+
+	events, err := d.GetAlertEventsByIDs(ctx, []int{1, 2, 3, 99, 1})
+	if err != nil {
+		t.Fatalf("GetAlertEventsByIDs 返回错误: %v", err)
+	}
+	if len(events) != 2 || events[1] == nil || events[3] == nil || events[1].Fingerprint != "fp-1" || events[3].Fingerprint != "fp-3" {
+		t.Fatalf("应只返回存在且未删除的事件: %+v", events)
+	}
+	if _, ok := events[2]; ok {
+		t.Fatal("不应返回已删除的事件")
+	}
+	if n := atomic.LoadInt64(&queries); n != 1 {
+		t.Fatalf("应只查询一次数据库, 实际 %d 次", n)
+	}
+
+	events, err = d.GetAlertEventsByIDs(ctx, nil)
+	if err != nil || len(events) != 0 {
+		t.Fatalf("空ID列表应返回空结果, 实际 %+v, %v", events, err)
+	}
+}
+
+func TestSearchMonitorAlertEventsMultipleStatuses(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	ctx := context.Background()
+
+	firing, silenced, resolved := string(model.AlertStatusFiring), string(model.AlertStatusSilenced), string(model.AlertStatusResolved)
+	seedEvents(t, db,
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-1", Status: firing, CreatedAt: 100},
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-2", Status: silenced, CreatedAt: 200},
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-3", Status: resolved, CreatedAt: 300},
+		&model.MonitorAlertEvent{AlertName: "disk", Fingerprint: "fp-4", Status: firing, CreatedAt: 400},
+	)
+
+	for _, c := range []struct {
+		name   string
+		filter model.AlertEventFilter
+		want   []int
+	}{
+		{"触发中或已屏蔽", model.AlertEventFilter{Statuses: []string{firing, silenced}}, []int{4, 2, 1}},
+		{"空列表不过滤", model.AlertEventFilter{Statuses: []string{}}, []int{4, 3, 2, 1}},
+		{"多状态+名称", model.AlertEventFilter{Name: "cpu", Statuses: []string{firing, silenced}}, []int{2, 1}},
+	} {
+		filter := c.filter
+		events, total, err := d.SearchMonitorAlertEvents(ctx, 0, &filter, 0, 10)
+		if err != nil {
+			t.Fatalf("%s: SearchMonitorAlertEvents 返回错误: %v", c.name, err)
+		}
+		got := make([]int, 0, len(events))
+		for _, event := range events {
+			got = append(got, event.ID)
+		}
+		if total != int64(len(c.want)) || !reflect.DeepEqual(got, c.want) {
+			t.Fatalf("%s: 期望 %v (共 %d 条), 实际 %v (共 %d 条)", c.name, c.want, len(c.want), got, total)
+		}
+	}
+
+	if _, _, err := d.SearchMonitorAlertEvents(ctx, 0, &model.AlertEventFilter{Statuses: []string{firing, "unknown"}}, 0, 10); err == nil {
+		t.Fatal("包含无效状态时应返回错误")
+	}
+}
+
+func TestGetAlertEventsSinceIDIncrementalPaging(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	ctx := context.Background()
+
+	for i := 1; i <= 5; i++ {
+		event := &model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-" + strconv.Itoa(i), Status: "firing"}
+		if i == 3 {
+			event.DeletedAt = 1
+		}
+		seedEvents(t, db, event)
+	}
+
+	// pull 按 limit 分页拉取全部增量，返回依次拿到的ID
+	pull := func(sinceID int, includeDeleted bool) ([]int, int) {
+		var got []int
+		for {
+			events, err := d.GetAlertEventsSinceID(ctx, sinceID, 2, includeDeleted)
+			if err != nil {
+				t.Fatalf("GetAlertEventsSinceID 返回错误: %v", err)
+			}
+			if len(events) == 0 {
+				return got, sinceID
+			}
+			for _, event := range events {
+				got = append(got, event.ID)
+				sinceID = event.ID
+			}
+		}
+	}
+
+	got, last := pull(0, false)
+	if !reflect.DeepEqual(got, []int{1, 2, 4, 5}) {
+		t.Fatalf("默认不包含已删除事件, 期望 [1 2 4 5], 实际 %v", got)
+	}
+	got, _ = pull(0, true)
+	if !reflect.DeepEqual(got, []int{1, 2, 3, 4, 5}) {
+		t.Fatalf("包含已删除事件时应同步墓碑, 期望 [1 2 3 4 5], 实际 %v", got)
+	}
+
+	// 上次同步后新增的事件从断点继续拉取
+	seedEvents(t, db, &model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-6", Status: "firing"})
+	got, _ = pull(last, false)
+	if !reflect.DeepEqual(got, []int{6}) {
+		t.Fatalf("增量同步应只返回新事件, 期望 [6], 实际 %v", got)
+	}
+
+	if _, err := d.GetAlertEventsSinceID(ctx, -1, 2, false); err == nil {
+		t.Fatal("sinceID 为负数时应返回错误")
+	}
+	if _, err := d.GetAlertEventsSinceID(ctx, 0, 0, false); err == nil {
+		t.Fatal("limit 不大于 0 时应返回错误")
+	}
+}
+
+func TestLoggerAttachesRequestID(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	db := newTestDB(t)
+	migrateTestTables(t, db, &model.MonitorSilence{})
+	d := NewAlertManagerEventDAO(db, zap.New(core), nil, prometheus.NewRegistry(), nil, nil, &http.Client{Timeout: 10 * time.Second})
+
+	// 上下文携带请求ID时，DAO 方法的日志附带 request_id 字段
+	ctx := pkg.WithRequestID(context.Background(), "req-123")
+	if _, err := d.ImportSilencesFromAlertmanager(ctx, []byte("[]")); err != nil {
+		t.Fatalf("导入静默失败: %v", err)
+	}
+	entries := logs.TakeAll()
+	if len(entries) == 0 {
+		t.Fatal("应输出日志")
+	}
+	for _, entry := range entries {
+		if got := entry.ContextMap()[pkg.RequestIDKey]; got != "req-123" {
+			t.Fatalf("日志 %q 应包含 request_id=req-123, 实际 %v", entry.Message, entry.ContextMap())
+		}
+	}
+
+	// 上下文没有请求ID时照常输出，不附带该字段
+	if _, err := d.ImportSilencesFromAlertmanager(context.Background(), []byte("[]")); err != nil {
+		t.Fatalf("导入静默失败: %v", err)
+	}
+	entries = logs.TakeAll()
+	if len(entries) == 0 {
+		t.Fatal("没有请求ID时也应输出日志")
+	}
+	for _, entry := range entries {
+		if _, ok := entry.ContextMap()[pkg.RequestIDKey]; ok {
+			t.Fatalf("没有请求ID时日志不应包含 request_id, 实际 %v", entry.ContextMap())
+		}
+	}
+}
+
+func TestGetMonitorAlertEventListWithStatsSumsToTotal(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	ctx := context.Background()
+	enableTenantScope(t)
+
+	statuses := []string{"firing", "firing", "firing", "resolved", "resolved", string(model.AlertStatusClaimed)}
+	for i, status := range statuses {
+		seedEvents(t, db, &model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-" + strconv.Itoa(i), Status: status, TeamID: 1, CreatedAt: int64(i + 1)})
+	}
+	seedEvents(t, db,
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-deleted", Status: "firing", TeamID: 1, DeletedAt: 1},
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-other-team", Status: "firing", TeamID: 2},
+	)
+
+	result, err := d.GetMonitorAlertEventListWithStats(ctx, 1, 0, 2)
+	if err != nil {
+		t.Fatalf("GetMonitorAlertEventListWithStats 返回错误: %v", err)
+	}
+	var sum int64
+	for _, count := range result.StatusCounts {
+		sum += count
+	}
+	if sum != result.Total || result.Total != int64(len(statuses)) {
+		t.Fatalf("各状态数量之和应等于总数 %d, 实际 sum=%d total=%d", len(statuses), sum, result.Total)
+	}
+	want := map[string]int64{"firing": 3, "resolved": 2, string(model.AlertStatusClaimed): 1}
+	if !reflect.DeepEqual(result.StatusCounts, want) {
+		t.Fatalf("按状态统计期望 %v, 实际 %v", want, result.StatusCounts)
+	}
+	if len(result.Items) != 2 || result.Items[0].Fingerprint != "fp-5" || result.Items[1].Fingerprint != "fp-4" {
+		t.Fatalf("分页结果应为最新的 2 条, 实际 %+v", result.Items)
+	}
+
+	// 没有事件时返回空列表和空统计
+	result, err = d.GetMonitorAlertEventListWithStats(ctx, 3, 0, 2)
+	if err != nil {
+		t.Fatalf("GetMonitorAlertEventListWithStats 返回错误: %v", err)
+	}
+	if result.Total != 0 || len(result.StatusCounts) != 0 || result.Items == nil || len(result.Items) != 0 {
+		t.Fatalf("没有事件时应返回空结果, 实际 %+v", result)
+	}
+}
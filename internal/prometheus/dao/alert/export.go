@@ -0,0 +1,115 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+package alert
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"go.uber.org/zap"
+)
+
+// exportBatchSize 导出告警事件时每页读取的行数
+const exportBatchSize = 500
+
+// eventCSVHeader 告警事件 CSV 导出的表头
+var eventCSVHeader = []string{"id", "alert_name", "status", "fingerprint", "claimed_by", "event_times", "created_at"}
+
+// csvFormulaPrefixes 以这些字符开头的单元格会被表格软件当作公式执行
+const csvFormulaPrefixes = "=+-@"
+
+// escapeCSVCell 为可能被当作公式的单元格加上单引号前缀，防止 CSV 公式注入
+func escapeCSVCell(value string) string {
+	if value != "" && strings.ContainsRune(csvFormulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// ExportEventsCSV 将团队内创建时间在 [start, end] 内的告警事件以 CSV 格式流式写入 w，
+// 按ID键集分页读取，避免一次性加载全表；claimed_by 为认领人用户ID，未认领时为空
+func (a *alertManagerEventDAO) ExportEventsCSV(ctx context.Context, teamID int, start, end int64, w io.Writer) error {
+	if end < start {
+		return fmt.Errorf("结束时间不能早于开始时间")
+	}
+	if err := checkTeamID(teamID); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(eventCSVHeader); err != nil {
+		return fmt.Errorf("写入CSV表头失败: %w", err)
+	}
+
+	lastID := 0
+	for {
+		var events []*model.MonitorAlertEvent
+		if err := a.db.WithContext(ctx).
+			Select("id", "alert_name", "status", "fingerprint", "ren_ling_user_id", "event_times", "created_at").
+			Scopes(notDeleted, teamScoped(teamID)).
+			Where("created_at BETWEEN ? AND ?", start, end).
+			Where("id > ?", lastID).
+			Order("id ASC").
+			Limit(exportBatchSize).
+			Find(&events).Error; err != nil {
+			a.logger(ctx).Error("导出告警事件失败", zap.Error(err), zap.Int("lastID", lastID))
+			return err
+		}
+
+		for _, event := range events {
+			claimedBy := ""
+			if event.RenLingUserID > 0 {
+				claimedBy = strconv.Itoa(event.RenLingUserID)
+			}
+			if err := writer.Write([]string{
+				strconv.Itoa(event.ID),
+				escapeCSVCell(event.AlertName),
+				escapeCSVCell(event.Status),
+				escapeCSVCell(event.Fingerprint),
+				claimedBy,
+				strconv.Itoa(event.EventTimes),
+				strconv.FormatInt(event.CreatedAt, 10),
+			}); err != nil {
+				return fmt.Errorf("写入CSV失败: %w", err)
+			}
+		}
+
+		// 每页写完立即刷新，避免在内存中累积
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return fmt.Errorf("写入CSV失败: %w", err)
+		}
+
+		if len(events) < exportBatchSize {
+			return nil
+		}
+		lastID = events[len(events)-1].ID
+	}
+}
@@ -0,0 +1,95 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"testing"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+)
+
+// exportRows 导出 CSV 并解析为行，不含表头
+func exportRows(t *testing.T, d *alertManagerEventDAO, teamID int) [][]string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := d.ExportEventsCSV(context.Background(), teamID, 0, 1<<40, &buf); err != nil {
+		t.Fatalf("ExportEventsCSV 返回错误: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("解析导出的 CSV 失败: %v", err)
+	}
+	return rows[1:]
+}
+
+func TestExportEventsCSVEscapesFormulaCells(t *testing.T) {
+	d, db := newTestEventDAO(t)
+
+	seedEvents(t, db,
+		&model.MonitorAlertEvent{AlertName: "=HYPERLINK(\"http://evil\")", Fingerprint: "fp-1", Status: "firing"},
+		&model.MonitorAlertEvent{AlertName: "+cmd", Fingerprint: "-fp", Status: "firing"},
+		&model.MonitorAlertEvent{AlertName: "@sum", Fingerprint: "fp-3", Status: "firing"},
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-4", Status: "firing"},
+	)
+
+	rows := exportRows(t, d, 0)
+	want := []struct{ alertName, fingerprint string }{
+		{"'=HYPERLINK(\"http://evil\")", "fp-1"},
+		{"'+cmd", "'-fp"},
+		{"'@sum", "fp-3"},
+		{"cpu", "fp-4"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("应导出 %d 行, 实际 %d", len(want), len(rows))
+	}
+	for i, w := range want {
+		if rows[i][1] != w.alertName || rows[i][3] != w.fingerprint {
+			t.Fatalf("第 %d 行未正确转义: %q", i, rows[i])
+		}
+	}
+}
+
+func TestExportEventsCSVTenantScope(t *testing.T) {
+	enableTenantScope(t)
+	d, db := newTestEventDAO(t)
+
+	seedEvents(t, db,
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-1", Status: "firing", TeamID: 1},
+		&model.MonitorAlertEvent{AlertName: "disk", Fingerprint: "fp-2", Status: "firing", TeamID: 2},
+	)
+
+	rows := exportRows(t, d, 1)
+	if len(rows) != 1 || rows[0][1] != "cpu" {
+		t.Fatalf("只应导出本团队的事件, 实际 %q", rows)
+	}
+
+	if err := d.ExportEventsCSV(context.Background(), 0, 0, 1<<40, &bytes.Buffer{}); err == nil {
+		t.Fatal("开启团队隔离时未指定团队应返回错误")
+	}
+}
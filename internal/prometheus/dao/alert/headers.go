@@ -0,0 +1,54 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+package alert
+
+import "context"
+
+// NotifyHeaders 通知请求的默认请求头，应用于所有发往通知渠道的请求
+type NotifyHeaders map[string]string
+
+type notifyHeadersKey struct{}
+
+// WithNotifyHeaders 返回携带单次请求头的上下文，发送时这些请求头覆盖同名的默认请求头
+func WithNotifyHeaders(ctx context.Context, headers map[string]string) context.Context {
+	return context.WithValue(ctx, notifyHeadersKey{}, headers)
+}
+
+// requestHeaders 合并默认请求头和上下文中的单次请求头，单次请求头优先
+func (a *alertManagerEventDAO) requestHeaders(ctx context.Context) map[string]string {
+	perCall, _ := ctx.Value(notifyHeadersKey{}).(map[string]string)
+	if len(a.headers) == 0 && len(perCall) == 0 {
+		return nil
+	}
+
+	headers := make(map[string]string, len(a.headers)+len(perCall))
+	for k, v := range a.headers {
+		headers[k] = v
+	}
+	for k, v := range perCall {
+		headers[k] = v
+	}
+	return headers
+}
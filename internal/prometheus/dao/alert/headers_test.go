@@ -0,0 +1,84 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package alert
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+func TestNotifyHeadersReachServer(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		_, _ = w.Write([]byte(`{"code":0}`))
+	}))
+	defer srv.Close()
+
+	db := newTestDB(t)
+	headers := NotifyHeaders{"User-Agent": "CloudOps-Notifier/1.0", "X-Env": "prod"}
+	d := NewAlertManagerEventDAO(db, zap.NewNop(), nil, prometheus.NewRegistry(), nil, headers, &http.Client{Timeout: 10 * time.Second})
+	ctx := context.Background()
+
+	// 默认请求头应用于所有通知请求
+	if err := d.SendMessageToGroup(ctx, srv.URL, "默认请求头"); err != nil {
+		t.Fatalf("发送消息失败: %v", err)
+	}
+	if got.Get("User-Agent") != "CloudOps-Notifier/1.0" || got.Get("X-Env") != "prod" {
+		t.Fatalf("服务端应收到默认请求头, 实际 %v", got)
+	}
+
+	// 单次请求头覆盖同名默认请求头
+	callCtx := WithNotifyHeaders(ctx, map[string]string{"X-Env": "staging", "X-Trace": "abc"})
+	if err := d.SendMessageToGroup(callCtx, srv.URL, "单次请求头"); err != nil {
+		t.Fatalf("发送消息失败: %v", err)
+	}
+	if got.Get("User-Agent") != "CloudOps-Notifier/1.0" || got.Get("X-Env") != "staging" || got.Get("X-Trace") != "abc" {
+		t.Fatalf("单次请求头应覆盖默认请求头, 实际 %v", got)
+	}
+
+	if _, err := d.TestWebhook(ctx, WebhookProviderFeishu, srv.URL, ""); err != nil {
+		t.Fatalf("连通性测试失败: %v", err)
+	}
+	if got.Get("User-Agent") != "CloudOps-Notifier/1.0" {
+		t.Fatalf("连通性测试请求也应携带默认请求头, 实际 %v", got)
+	}
+
+	// 未配置请求头时不影响默认行为
+	plain, _ := newTestEventDAO(t)
+	if err := plain.SendMessageToGroup(ctx, srv.URL, "无请求头"); err != nil {
+		t.Fatalf("发送消息失败: %v", err)
+	}
+	if got.Get("X-Env") != "" {
+		t.Fatalf("未配置时不应携带自定义请求头, 实际 %v", got)
+	}
+}
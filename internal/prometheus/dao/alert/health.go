@@ -0,0 +1,105 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+package alert
+
+import (
+	"context"
+	"time"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/GoSimplicity/AI-CloudOps/internal/prometheus/webhook/robot"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// defaultHealthCheckTimeout 单个组件健康检查的默认超时时间
+const defaultHealthCheckTimeout = 2 * time.Second
+
+// HealthCheck 检查数据库连通性，并在配置了 prometheus.health_canary_webhook 时探测该 webhook 是否可达。
+// 每个组件的检查都受 prometheus.health_check_timeout_ms 限制，不会阻塞健康检查
+func (a *alertManagerEventDAO) HealthCheck(ctx context.Context) *model.HealthStatus {
+	status := &model.HealthStatus{
+		Healthy:   true,
+		CheckedAt: getTime(),
+	}
+
+	timeout := getHealthCheckTimeout()
+
+	components := []*model.ComponentHealth{
+		a.checkComponent(ctx, "database", timeout, a.pingDB),
+	}
+
+	if canaryURL := viper.GetString("prometheus.health_canary_webhook"); canaryURL != "" {
+		components = append(components, a.checkComponent(ctx, "webhook", timeout, func(ctx context.Context) error {
+			return robot.PingURL(ctx, a.httpClient, canaryURL, a.requestHeaders(ctx))
+		}))
+	} else {
+		components = append(components, &model.ComponentHealth{Name: "webhook", Status: model.HealthStatusSkipped})
+	}
+
+	for _, component := range components {
+		if component.Status == model.HealthStatusFail {
+			status.Healthy = false
+		}
+	}
+	status.Components = components
+
+	return status
+}
+
+// checkComponent 在超时时间内执行检查函数并记录结果和耗时
+func (a *alertManagerEventDAO) checkComponent(ctx context.Context, name string, timeout time.Duration, check func(ctx context.Context) error) *model.ComponentHealth {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	component := &model.ComponentHealth{
+		Name:      name,
+		Status:    model.HealthStatusOK,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		a.logger(ctx).Warn("健康检查失败", zap.String("component", name), zap.Error(err))
+		component.Status = model.HealthStatusFail
+		component.Error = err.Error()
+	}
+
+	return component
+}
+
+// pingDB 执行 SELECT 1 检查数据库连通性
+func (a *alertManagerEventDAO) pingDB(ctx context.Context) error {
+	var result int
+	return a.db.WithContext(ctx).Raw("SELECT 1").Scan(&result).Error
+}
+
+// getHealthCheckTimeout 获取单个组件健康检查的超时时间，未配置时使用默认值
+func getHealthCheckTimeout() time.Duration {
+	if ms := viper.GetInt("prometheus.health_check_timeout_ms"); ms > 0 {
+		return time.Duration(ms) * time.Millisecond
+	}
+	return defaultHealthCheckTimeout
+}
@@ -0,0 +1,93 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package alert
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/spf13/viper"
+)
+
+// withCanaryWebhook 将健康检查的 canary webhook 指向一个返回 statusCode 的测试服务
+func withCanaryWebhook(t *testing.T, statusCode int) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("健康检查只应发送 HEAD 请求, 实际 %s", r.Method)
+		}
+		w.WriteHeader(statusCode)
+	}))
+	t.Cleanup(srv.Close)
+
+	viper.Set("prometheus.health_canary_webhook", srv.URL)
+	t.Cleanup(func() { viper.Set("prometheus.health_canary_webhook", "") })
+}
+
+// componentStatus 返回健康检查结果中指定组件的状态
+func componentStatus(status *model.HealthStatus, name string) string {
+	for _, c := range status.Components {
+		if c.Name == name {
+			return c.Status
+		}
+	}
+	return ""
+}
+
+func TestHealthCheckSkipsWebhookWithoutCanary(t *testing.T) {
+	d, _ := newTestEventDAO(t)
+
+	status := d.HealthCheck(context.Background())
+	if !status.Healthy {
+		t.Fatalf("数据库可用且未配置 canary 时应健康, 实际 %+v", status.Components)
+	}
+	if got := componentStatus(status, "webhook"); got != model.HealthStatusSkipped {
+		t.Fatalf("未配置 canary 时 webhook 检查应跳过, 实际 %q", got)
+	}
+}
+
+func TestHealthCheckTreatsClientErrorAsReachable(t *testing.T) {
+	withCanaryWebhook(t, http.StatusMethodNotAllowed)
+	d, _ := newTestEventDAO(t)
+
+	status := d.HealthCheck(context.Background())
+	if !status.Healthy || componentStatus(status, "webhook") != model.HealthStatusOK {
+		t.Fatalf("webhook 返回 4xx 仍说明地址可达, 实际 %+v", status.Components)
+	}
+}
+
+func TestHealthCheckFailsOnServerError(t *testing.T) {
+	withCanaryWebhook(t, http.StatusBadGateway)
+	d, _ := newTestEventDAO(t)
+
+	status := d.HealthCheck(context.Background())
+	if status.Healthy || componentStatus(status, "webhook") != model.HealthStatusFail {
+		t.Fatalf("webhook 返回 5xx 时应判定为不健康, 实际 %+v", status.Components)
+	}
+}
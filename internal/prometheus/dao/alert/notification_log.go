@@ -0,0 +1,92 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"go.uber.org/zap"
+)
+
+// RecordNotification 记录告警事件的通知投递，messageID 为空表示渠道未返回消息ID
+func (a *alertManagerEventDAO) RecordNotification(ctx context.Context, eventID int, channel string, messageID string) error {
+	if eventID <= 0 {
+		return fmt.Errorf("%w: 事件ID=%d", ErrInvalidID, eventID)
+	}
+	if channel == "" {
+		return fmt.Errorf("通知渠道不能为空")
+	}
+
+	record := &model.NotificationLog{
+		EventID:   eventID,
+		Channel:   channel,
+		MessageID: messageID,
+	}
+	if err := a.db.WithContext(ctx).Create(record).Error; err != nil {
+		a.logger(ctx).Error("写入告警通知记录失败", zap.Error(err), zap.Int("eventID", eventID), zap.String("channel", channel))
+		return err
+	}
+
+	return nil
+}
+
+// GetNotificationsForEvent 获取告警事件的通知投递记录，按发送时间升序排列
+func (a *alertManagerEventDAO) GetNotificationsForEvent(ctx context.Context, eventID int) ([]*model.NotificationLog, error) {
+	if eventID <= 0 {
+		return nil, fmt.Errorf("%w: 事件ID=%d", ErrInvalidID, eventID)
+	}
+
+	logs := make([]*model.NotificationLog, 0)
+	if err := a.db.WithContext(ctx).
+		Where("event_id = ?", eventID).
+		Order("created_at ASC, id ASC").
+		Find(&logs).Error; err != nil {
+		a.logger(ctx).Error("获取告警通知记录失败", zap.Error(err), zap.Int("eventID", eventID))
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+// ParseFeishuMessageID 从飞书响应中解析 data.message_id，响应为空或不含消息ID时返回空字符串
+func ParseFeishuMessageID(body []byte) (string, error) {
+	if len(body) == 0 {
+		return "", nil
+	}
+
+	var resp struct {
+		Data struct {
+			MessageID string `json:"message_id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("解析飞书响应失败: %w", err)
+	}
+
+	return resp.Data.MessageID, nil
+}
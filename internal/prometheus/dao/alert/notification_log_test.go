@@ -0,0 +1,84 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package alert
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseFeishuMessageID(t *testing.T) {
+	cases := []struct {
+		name    string
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{name: "包含消息ID", body: `{"code":0,"data":{"message_id":"om_123"}}`, want: "om_123"},
+		{name: "缺少消息ID", body: `{"code":0,"msg":"success"}`, want: ""},
+		{name: "空响应", body: "", want: ""},
+		{name: "非法响应", body: `not json`, wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseFeishuMessageID([]byte(tc.body))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("错误不符合预期: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("期望消息ID %q, 实际 %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRecordNotificationAndGetNotificationsForEvent(t *testing.T) {
+	d, _ := newTestEventDAO(t)
+	ctx := context.Background()
+
+	if err := d.RecordNotification(ctx, 7, WebhookProviderFeishu, "om_1"); err != nil {
+		t.Fatalf("记录通知失败: %v", err)
+	}
+	if err := d.RecordNotification(ctx, 7, WebhookProviderFeishu, ""); err != nil {
+		t.Fatalf("记录缺少消息ID的通知失败: %v", err)
+	}
+	if err := d.RecordNotification(ctx, 8, WebhookProviderFeishu, "om_2"); err != nil {
+		t.Fatalf("记录通知失败: %v", err)
+	}
+
+	logs, err := d.GetNotificationsForEvent(ctx, 7)
+	if err != nil {
+		t.Fatalf("查询通知记录失败: %v", err)
+	}
+	if len(logs) != 2 || logs[0].MessageID != "om_1" || logs[1].MessageID != "" {
+		t.Fatalf("通知记录不符合预期: %+v", logs)
+	}
+
+	if err := d.RecordNotification(ctx, 0, WebhookProviderFeishu, ""); err == nil {
+		t.Fatal("无效事件ID应返回错误")
+	}
+}
@@ -0,0 +1,180 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	pkg "github.com/GoSimplicity/AI-CloudOps/pkg/utils"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// defaultFanOutConcurrency 并发分发通知时默认的最大并发数
+const defaultFanOutConcurrency = 4
+
+// Notifier 通知渠道
+type Notifier interface {
+	// Provider 返回通知渠道名称，用于结果和错误中区分渠道
+	Provider() string
+	// Notify 发送通知消息
+	Notify(ctx context.Context, message string) error
+}
+
+// NotifyResult 单个通知渠道的发送结果
+type NotifyResult struct {
+	Provider string        `json:"provider"`
+	Duration time.Duration `json:"duration"`
+	Err      error         `json:"-"`
+}
+
+// ProviderError 携带渠道名称的发送错误
+type ProviderError struct {
+	Provider string
+	Err      error
+}
+
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Provider, e.Err)
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// webhookNotifier 基于群机器人 webhook 的通知渠道，按 provider 从渠道注册表中分发
+type webhookNotifier struct {
+	dao      *alertManagerEventDAO
+	provider string
+	url      string
+}
+
+func (n *webhookNotifier) Provider() string {
+	return n.provider
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, message string) error {
+	return n.dao.SendByChannel(ctx, n.provider, n.url, message)
+}
+
+// NewWebhookNotifier 创建指定类型的群机器人通知渠道
+func (a *alertManagerEventDAO) NewWebhookNotifier(provider string, url string) (Notifier, error) {
+	if url == "" {
+		return nil, fmt.Errorf("url不能为空")
+	}
+	if _, ok := lookupChannel(provider); !ok {
+		return nil, fmt.Errorf("不支持的 webhook 类型: %s", provider)
+	}
+
+	return &webhookNotifier{dao: a, provider: provider, url: url}, nil
+}
+
+// FanOutSend 并发向所有通知渠道发送消息，并发数由 prometheus.notify_fanout_concurrency 限制。
+// 单个渠道失败不影响其他渠道，上下文截止后仍未完成的渠道记为超时；返回按渠道顺序排列的结果，
+// 存在失败渠道时返回由 ProviderError 合并而成的错误
+func (a *alertManagerEventDAO) FanOutSend(ctx context.Context, notifiers []Notifier, message string) ([]NotifyResult, error) {
+	results := make([]NotifyResult, len(notifiers))
+
+	g := new(errgroup.Group)
+	g.SetLimit(getFanOutConcurrency())
+
+	for i, notifier := range notifiers {
+		g.Go(func() error {
+			start := time.Now()
+			results[i] = NotifyResult{
+				Provider: notifier.Provider(),
+				Err:      notifyWithContext(ctx, notifier, message),
+				Duration: time.Since(start),
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	var errs []error
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, &ProviderError{Provider: result.Provider, Err: result.Err})
+		}
+	}
+	if len(errs) > 0 {
+		a.logger(ctx).Warn("部分通知渠道发送失败", zap.Int("failed", len(errs)), zap.Int("total", len(notifiers)))
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// notifyWithContext 发送通知，上下文结束时立即返回而不等待慢渠道
+func notifyWithContext(ctx context.Context, notifier Notifier, message string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- notifier.Notify(ctx, message)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sendDingTalkText 发送钉钉群机器人文本消息
+func (a *alertManagerEventDAO) sendDingTalkText(ctx context.Context, url string, message string) ([]byte, error) {
+	content, err := json.Marshal(map[string]interface{}{
+		"msgtype": "text",
+		"text":    map[string]string{"content": pkg.TruncateMessage(message, getMaxMessageBytes())},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("序列化钉钉消息失败: %w", err)
+	}
+
+	start := time.Now()
+	body, err := pkg.PostWithJson(ctx, a.httpClient, a.l, url, string(content), nil, a.requestHeaders(ctx))
+	a.metrics.Observe(WebhookProviderDingTalk, start, err)
+	if err != nil {
+		a.logger(ctx).Error("发送钉钉群聊消息失败", zap.Error(err), zap.String("url", url), zap.String("结果", string(body)))
+		return nil, fmt.Errorf("发送钉钉群聊消息失败: %w", err)
+	}
+
+	return body, nil
+}
+
+// getFanOutConcurrency 获取并发分发通知的最大并发数，未配置时使用默认值
+func getFanOutConcurrency() int {
+	if n := viper.GetInt("prometheus.notify_fanout_concurrency"); n > 0 {
+		return n
+	}
+	return defaultFanOutConcurrency
+}
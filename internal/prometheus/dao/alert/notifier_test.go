@@ -0,0 +1,140 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package alert
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// fakeNotifier 测试通知渠道，release 非空时阻塞到 release 关闭，忽略上下文以模拟不响应取消的慢渠道
+type fakeNotifier struct {
+	provider string
+	err      error
+	release  chan struct{}
+	running  *int32
+	peak     *int32
+}
+
+func (n *fakeNotifier) Provider() string {
+	return n.provider
+}
+
+func (n *fakeNotifier) Notify(context.Context, string) error {
+	if n.running != nil {
+		cur := atomic.AddInt32(n.running, 1)
+		defer atomic.AddInt32(n.running, -1)
+		for {
+			peak := atomic.LoadInt32(n.peak)
+			if cur <= peak || atomic.CompareAndSwapInt32(n.peak, peak, cur) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n.release != nil {
+		<-n.release
+	}
+	return n.err
+}
+
+func TestFanOutSendSlowAndFastNotifiers(t *testing.T) {
+	a, _ := newTestEventDAO(t)
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+
+	notifiers := []Notifier{
+		&fakeNotifier{provider: "slow", release: release},
+		&fakeNotifier{provider: "fast"},
+		&fakeNotifier{provider: "broken", err: errors.New("status 500")},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	results, err := a.FanOutSend(ctx, notifiers, "告警")
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("慢渠道不应拖住截止时间之后的返回, 耗时 %v", elapsed)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("应返回 3 个渠道结果, 实际 %d", len(results))
+	}
+	for i, want := range []string{"slow", "fast", "broken"} {
+		if results[i].Provider != want {
+			t.Fatalf("结果应按渠道顺序返回, 第 %d 个期望 %s, 实际 %s", i, want, results[i].Provider)
+		}
+	}
+	if results[1].Err != nil {
+		t.Fatalf("快渠道应发送成功, 实际 %v", results[1].Err)
+	}
+	if !errors.Is(results[0].Err, context.DeadlineExceeded) {
+		t.Fatalf("慢渠道应记为超时, 实际 %v", results[0].Err)
+	}
+
+	// 合并错误保留失败渠道名称
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("合并错误应包含超时错误, 实际 %v", err)
+	}
+	var providerErr *ProviderError
+	if !errors.As(err, &providerErr) {
+		t.Fatalf("合并错误应包含 ProviderError, 实际 %v", err)
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, "slow:") || !strings.Contains(msg, "broken: status 500") || strings.Contains(msg, "fast") {
+		t.Fatalf("合并错误应只包含失败渠道, 实际 %q", msg)
+	}
+
+	// 全部成功时不返回错误
+	if _, err := a.FanOutSend(context.Background(), notifiers[1:2], "告警"); err != nil {
+		t.Fatalf("全部成功时不应返回错误, 实际 %v", err)
+	}
+}
+
+func TestFanOutSendRespectsConcurrencyLimit(t *testing.T) {
+	a, _ := newTestEventDAO(t)
+	viper.Set("prometheus.notify_fanout_concurrency", 2)
+	t.Cleanup(func() { viper.Set("prometheus.notify_fanout_concurrency", 0) })
+
+	var running, peak int32
+	notifiers := make([]Notifier, 6)
+	for i := range notifiers {
+		notifiers[i] = &fakeNotifier{provider: "p", running: &running, peak: &peak}
+	}
+
+	if _, err := a.FanOutSend(context.Background(), notifiers, "告警"); err != nil {
+		t.Fatalf("FanOutSend 返回错误: %v", err)
+	}
+	if got := atomic.LoadInt32(&peak); got > 2 {
+		t.Fatalf("并发数不应超过 2, 实际 %d", got)
+	}
+}
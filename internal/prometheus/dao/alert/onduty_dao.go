@@ -73,7 +73,7 @@ func (a *alertManagerOnDutyDAO) GetAllMonitorOnDutyGroup(ctx context.Context) ([
 	var groups []*model.MonitorOnDutyGroup
 
 	if err := a.db.WithContext(ctx).
-		Where("deleted_at = ?", 0).
+		Scopes(notDeleted).
 		Preload("Members").
 		Find(&groups).Error; err != nil {
 		a.l.Error("获取所有值班组失败", zap.Error(err))
@@ -105,7 +105,7 @@ func (a *alertManagerOnDutyDAO) GetMonitorOnDutyGroupById(ctx context.Context, i
 	var group model.MonitorOnDutyGroup
 
 	if err := a.db.WithContext(ctx).
-		Where("id = ? AND deleted_at = ?", id, 0).
+		Scopes(notDeleted).Where("id = ?", id).
 		Preload("Members").
 		First(&group).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -130,7 +130,7 @@ func (a *alertManagerOnDutyDAO) UpdateMonitorOnDutyGroup(ctx context.Context, mo
 	return a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// 先获取原有的值班组信息,确保记录存在
 		var existingGroup model.MonitorOnDutyGroup
-		if err := tx.Where("id = ? AND deleted_at = ?", monitorOnDutyGroup.ID, 0).First(&existingGroup).Error; err != nil {
+		if err := tx.Scopes(notDeleted).Where("id = ?", monitorOnDutyGroup.ID).First(&existingGroup).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
 				return fmt.Errorf("未找到ID为%d的值班组", monitorOnDutyGroup.ID)
 			}
@@ -165,7 +165,7 @@ func (a *alertManagerOnDutyDAO) DeleteMonitorOnDutyGroup(ctx context.Context, id
 	}
 
 	result := a.db.WithContext(ctx).Model(&model.MonitorOnDutyGroup{}).
-		Where("id = ? AND deleted_at = ?", id, 0).
+		Scopes(notDeleted).Where("id = ?", id).
 		Update("deleted_at", getTime())
 	if err := result.Error; err != nil {
 		a.l.Error("删除值班组失败", zap.Error(err), zap.Int("id", id))
@@ -188,7 +188,7 @@ func (a *alertManagerOnDutyDAO) SearchMonitorOnDutyGroupByName(ctx context.Conte
 	var groups []*model.MonitorOnDutyGroup
 
 	if err := a.db.WithContext(ctx).
-		Where("deleted_at = ?", 0).
+		Scopes(notDeleted).
 		Where("LOWER(name) LIKE ?", "%"+strings.ToLower(name)+"%").
 		Preload("Members").
 		Find(&groups).Error; err != nil {
@@ -225,7 +225,7 @@ func (a *alertManagerOnDutyDAO) GetMonitorOnDutyChangesByGroupAndTimeRange(ctx c
 	var changes []*model.MonitorOnDutyChange
 
 	if err := a.db.WithContext(ctx).
-		Where("on_duty_group_id = ? AND date BETWEEN ? AND ? AND deleted_at = ?", groupID, startTime, endTime, 0).
+		Scopes(notDeleted).Where("on_duty_group_id = ? AND date BETWEEN ? AND ?", groupID, startTime, endTime).
 		Find(&changes).Error; err != nil {
 		a.l.Error("获取值班组变更记录失败", zap.Error(err), zap.Int("groupID", groupID))
 		return nil, fmt.Errorf("获取值班组变更记录失败: %w", err)
@@ -240,7 +240,7 @@ func (a *alertManagerOnDutyDAO) CheckMonitorOnDutyGroupExists(ctx context.Contex
 
 	if err := a.db.WithContext(ctx).
 		Model(&model.MonitorOnDutyGroup{}).
-		Where("name = ? AND deleted_at = ?", onDutyGroup.Name, 0).
+		Scopes(notDeleted).Where("name = ?", onDutyGroup.Name).
 		Count(&count).Error; err != nil {
 		a.l.Error("检查值班组存在性失败", zap.Error(err), zap.String("name", onDutyGroup.Name))
 		return false, fmt.Errorf("检查值班组存在性失败: %w", err)
@@ -262,7 +262,7 @@ func (a *alertManagerOnDutyDAO) GetMonitorOnDutyHistoryByGroupIdAndTimeRange(ctx
 	var historyList []*model.MonitorOnDutyHistory
 
 	if err := a.db.WithContext(ctx).
-		Where("on_duty_group_id = ? AND date_string BETWEEN ? AND ? AND deleted_at = ?", groupID, startTime, endTime, 0).
+		Scopes(notDeleted).Where("on_duty_group_id = ? AND date_string BETWEEN ? AND ?", groupID, startTime, endTime).
 		Find(&historyList).Error; err != nil {
 		a.l.Error("获取值班历史记录失败", zap.Error(err), zap.Int("groupID", groupID))
 		return nil, fmt.Errorf("获取值班历史记录失败: %w", err)
@@ -297,7 +297,7 @@ func (a *alertManagerOnDutyDAO) GetMonitorOnDutyHistoryByGroupIdAndDay(ctx conte
 	var history model.MonitorOnDutyHistory
 
 	if err := a.db.WithContext(ctx).
-		Where("on_duty_group_id = ? AND date_string = ? AND deleted_at = ?", groupID, day, 0).
+		Scopes(notDeleted).Where("on_duty_group_id = ? AND date_string = ?", groupID, day).
 		First(&history).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil
@@ -323,7 +323,7 @@ func (a *alertManagerOnDutyDAO) ExistsMonitorOnDutyHistory(ctx context.Context,
 
 	if err := a.db.WithContext(ctx).
 		Model(&model.MonitorOnDutyHistory{}).
-		Where("on_duty_group_id = ? AND date_string = ? AND deleted_at = ?", groupID, day, 0).
+		Scopes(notDeleted).Where("on_duty_group_id = ? AND date_string = ?", groupID, day).
 		Count(&count).Error; err != nil {
 		a.l.Error("检查值班历史记录存在性失败", zap.Error(err), zap.Int("groupID", groupID), zap.String("day", day))
 		return false, fmt.Errorf("检查值班历史记录存在性失败: %w", err)
@@ -345,7 +345,7 @@ func (a *alertManagerOnDutyDAO) GetMonitorOnDutyList(ctx context.Context, offset
 	var groups []*model.MonitorOnDutyGroup
 
 	if err := a.db.WithContext(ctx).
-		Where("deleted_at = ?", 0).
+		Scopes(notDeleted).
 		Preload("Members").
 		Offset(offset).
 		Limit(limit).
@@ -361,7 +361,7 @@ func (a *alertManagerOnDutyDAO) GetMonitorOnDutyList(ctx context.Context, offset
 func (a *alertManagerOnDutyDAO) GetMonitorOnDutyTotal(ctx context.Context) (int, error) {
 	var count int64
 
-	if err := a.db.WithContext(ctx).Model(&model.MonitorOnDutyGroup{}).Where("deleted_at = ?", 0).Count(&count).Error; err != nil {
+	if err := a.db.WithContext(ctx).Model(&model.MonitorOnDutyGroup{}).Scopes(notDeleted).Count(&count).Error; err != nil {
 		a.l.Error("获取监控告警事件总数失败", zap.Error(err))
 		return 0, err
 	}
@@ -66,7 +66,7 @@ func NewAlertManagerPoolDAO(db *gorm.DB, l *zap.Logger, userDao userDao.UserDAO)
 func (a *alertManagerPoolDAO) GetAllAlertManagerPools(ctx context.Context) ([]*model.MonitorAlertManagerPool, error) {
 	var pools []*model.MonitorAlertManagerPool
 
-	if err := a.db.WithContext(ctx).Where("deleted_at = ?", 0).Find(&pools).Error; err != nil {
+	if err := a.db.WithContext(ctx).Scopes(notDeleted).Find(&pools).Error; err != nil {
 		a.l.Error("获取所有 MonitorAlertManagerPool 失败", zap.Error(err))
 		return nil, err
 	}
@@ -98,7 +98,7 @@ func (a *alertManagerPoolDAO) UpdateMonitorAlertManagerPool(ctx context.Context,
 
 	if err := a.db.WithContext(ctx).
 		Model(&model.MonitorAlertManagerPool{}).
-		Where("id = ? AND deleted_at = ?", monitorAlertManagerPool.ID, 0).
+		Scopes(notDeleted).Where("id = ?", monitorAlertManagerPool.ID).
 		Updates(map[string]interface{}{
 			"name":                    monitorAlertManagerPool.Name,
 			"alert_manager_instances": monitorAlertManagerPool.AlertManagerInstances,
@@ -126,7 +126,7 @@ func (a *alertManagerPoolDAO) DeleteMonitorAlertManagerPool(ctx context.Context,
 
 	if err := a.db.WithContext(ctx).
 		Model(&model.MonitorAlertManagerPool{}).
-		Where("id = ? AND deleted_at = ?", id, 0).
+		Scopes(notDeleted).Where("id = ?", id).
 		Update("deleted_at", getTime()).
 		Error; err != nil {
 		a.l.Error("删除 MonitorAlertManagerPool 失败", zap.Error(err), zap.Int("id", id))
@@ -145,7 +145,7 @@ func (a *alertManagerPoolDAO) SearchMonitorAlertManagerPoolByName(ctx context.Co
 	var pools []*model.MonitorAlertManagerPool
 
 	if err := a.db.WithContext(ctx).
-		Where("LOWER(name) LIKE ? AND deleted_at = ?", "%"+strings.ToLower(name)+"%", 0).
+		Scopes(notDeleted).Where("LOWER(name) LIKE ?", "%"+strings.ToLower(name)+"%").
 		Find(&pools).Error; err != nil {
 		a.l.Error("通过名称搜索 MonitorAlertManagerPool 失败", zap.Error(err))
 		return nil, err
@@ -163,7 +163,7 @@ func (a *alertManagerPoolDAO) GetAlertPoolByID(ctx context.Context, poolID int)
 
 	var alertPool model.MonitorAlertManagerPool
 
-	if err := a.db.WithContext(ctx).Where("id = ? AND deleted_at = ?", poolID, 0).First(&alertPool).Error; err != nil {
+	if err := a.db.WithContext(ctx).Scopes(notDeleted).Where("id = ?", poolID).First(&alertPool).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, fmt.Errorf("未找到 ID 为 %d 的 AlertPool", poolID)
 		}
@@ -184,7 +184,7 @@ func (a *alertManagerPoolDAO) CheckMonitorAlertManagerPoolExists(ctx context.Con
 
 	if err := a.db.WithContext(ctx).
 		Model(&model.MonitorAlertManagerPool{}).
-		Where("name = ? AND deleted_at = ?", alertManagerPool.Name, 0).
+		Scopes(notDeleted).Where("name = ?", alertManagerPool.Name).
 		Count(&count).Error; err != nil {
 		a.l.Error("检查 MonitorAlertManagerPool 是否存在失败", zap.Error(err))
 		return false, err
@@ -201,7 +201,7 @@ func (a *alertManagerPoolDAO) GetMonitorAlertManagerPoolList(ctx context.Context
 
 	var pools []*model.MonitorAlertManagerPool
 
-	if err := a.db.WithContext(ctx).Where("deleted_at = ?", 0).Offset(offset).Limit(limit).Find(&pools).Error; err != nil {
+	if err := a.db.WithContext(ctx).Scopes(notDeleted).Offset(offset).Limit(limit).Find(&pools).Error; err != nil {
 		a.l.Error("获取 MonitorAlertManagerPool 列表失败", zap.Error(err))
 		return nil, err
 	}
@@ -213,7 +213,7 @@ func (a *alertManagerPoolDAO) GetMonitorAlertManagerPoolList(ctx context.Context
 func (a *alertManagerPoolDAO) GetMonitorAlertManagerPoolTotal(ctx context.Context) (int, error) {
 	var count int64
 
-	if err := a.db.WithContext(ctx).Model(&model.MonitorAlertManagerPool{}).Where("deleted_at = ?", 0).Count(&count).Error; err != nil {
+	if err := a.db.WithContext(ctx).Model(&model.MonitorAlertManagerPool{}).Scopes(notDeleted).Count(&count).Error; err != nil {
 		a.l.Error("获取 MonitorAlertManagerPool 总数失败", zap.Error(err))
 		return 0, err
 	}
@@ -73,7 +73,7 @@ func (a *alertManagerRecordDAO) GetMonitorRecordRuleByPoolId(ctx context.Context
 	var recordRules []*model.MonitorRecordRule
 
 	if err := a.db.WithContext(ctx).
-		Where("enable = ? AND deleted_at = ?", true, 0).
+		Scopes(notDeleted).Where("enable = ?", true).
 		Where("pool_id = ?", poolId).
 		Find(&recordRules).Error; err != nil {
 		a.l.Error("获取 MonitorRecordRule 失败", zap.Error(err), zap.Int("poolId", poolId))
@@ -92,7 +92,7 @@ func (a *alertManagerRecordDAO) SearchMonitorRecordRuleByName(ctx context.Contex
 	var recordRules []*model.MonitorRecordRule
 
 	if err := a.db.WithContext(ctx).
-		Where("name LIKE ? AND deleted_at = ?", "%"+name+"%", 0).
+		Scopes(notDeleted).Where("name LIKE ?", "%"+name+"%").
 		Find(&recordRules).Error; err != nil {
 		a.l.Error("通过名称搜索 MonitorRecordRule 失败", zap.Error(err), zap.String("name", name))
 		return nil, err
@@ -109,7 +109,7 @@ func (a *alertManagerRecordDAO) GetMonitorRecordRuleList(ctx context.Context, of
 		return nil, fmt.Errorf("无效的分页参数: offset=%d, limit=%d", offset, limit)
 	}
 
-	if err := a.db.WithContext(ctx).Where("deleted_at = ?", 0).Offset(offset).Limit(limit).Find(&recordRules).Error; err != nil {
+	if err := a.db.WithContext(ctx).Scopes(notDeleted).Offset(offset).Limit(limit).Find(&recordRules).Error; err != nil {
 		a.l.Error("获取所有 MonitorRecordRule 失败", zap.Error(err))
 		return nil, err
 	}
@@ -139,7 +139,7 @@ func (a *alertManagerRecordDAO) GetMonitorRecordRuleById(ctx context.Context, id
 
 	var recordRule model.MonitorRecordRule
 
-	if err := a.db.WithContext(ctx).Where("id = ? AND deleted_at = ?", id, 0).First(&recordRule).Error; err != nil {
+	if err := a.db.WithContext(ctx).Scopes(notDeleted).Where("id = ?", id).First(&recordRule).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, fmt.Errorf("未找到 ID 为 %d 的 MonitorRecordRule", id)
 		}
@@ -161,7 +161,7 @@ func (a *alertManagerRecordDAO) UpdateMonitorRecordRule(ctx context.Context, rec
 
 	if err := a.db.WithContext(ctx).
 		Model(&model.MonitorRecordRule{}).
-		Where("id = ? AND deleted_at = ?", recordRule.ID, 0).
+		Scopes(notDeleted).Where("id = ?", recordRule.ID).
 		Updates(map[string]interface{}{
 			"name":         recordRule.Name,
 			"pool_id":      recordRule.PoolID,
@@ -189,7 +189,7 @@ func (a *alertManagerRecordDAO) DeleteMonitorRecordRule(ctx context.Context, rul
 
 	result := a.db.WithContext(ctx).
 		Model(&model.MonitorRecordRule{}).
-		Where("id = ? AND deleted_at = ?", ruleID, 0).
+		Scopes(notDeleted).Where("id = ?", ruleID).
 		Updates(map[string]interface{}{
 			"deleted_at": getTime(),
 		})
@@ -218,7 +218,7 @@ func (a *alertManagerRecordDAO) EnableSwitchMonitorRecordRule(ctx context.Contex
 
 	if err := a.db.WithContext(ctx).
 		Model(&model.MonitorRecordRule{}).
-		Where("id = ? AND deleted_at = ?", ruleID, 0).
+		Scopes(notDeleted).Where("id = ?", ruleID).
 		First(&rule).Error; err != nil {
 		a.l.Error("查询 MonitorRecordRule 失败", zap.Error(err), zap.Int("ruleID", ruleID))
 		return err
@@ -230,7 +230,7 @@ func (a *alertManagerRecordDAO) EnableSwitchMonitorRecordRule(ctx context.Contex
 	// 更新状态
 	if err := a.db.WithContext(ctx).
 		Model(&model.MonitorRecordRule{}).
-		Where("id = ? AND deleted_at = ?", ruleID, 0).
+		Scopes(notDeleted).Where("id = ?", ruleID).
 		Updates(map[string]interface{}{
 			"enable":     newEnable,
 			"updated_at": getTime(),
@@ -248,7 +248,7 @@ func (a *alertManagerRecordDAO) CheckMonitorRecordRuleExists(ctx context.Context
 
 	if err := a.db.WithContext(ctx).
 		Model(&model.MonitorRecordRule{}).
-		Where("id = ? AND deleted_at = ?", recordRule.ID, 0).
+		Scopes(notDeleted).Where("id = ?", recordRule.ID).
 		Count(&count).Error; err != nil {
 		a.l.Error("检查 MonitorRecordRule 是否存在失败", zap.Error(err))
 		return false, err
@@ -263,7 +263,7 @@ func (a *alertManagerRecordDAO) CheckMonitorRecordRuleNameExists(ctx context.Con
 
 	if err := a.db.WithContext(ctx).
 		Model(&model.MonitorRecordRule{}).
-		Where("name = ? AND deleted_at = ?", recordRule.Name, 0).
+		Scopes(notDeleted).Where("name = ?", recordRule.Name).
 		Count(&count).Error; err != nil {
 		a.l.Error("检查 MonitorRecordRule 名称是否存在失败", zap.Error(err))
 		return false, err
@@ -276,7 +276,7 @@ func (a *alertManagerRecordDAO) CheckMonitorRecordRuleNameExists(ctx context.Con
 func (a *alertManagerRecordDAO) GetMonitorRecordRuleTotal(ctx context.Context) (int, error) {
 	var count int64
 
-	if err := a.db.WithContext(ctx).Model(&model.MonitorRecordRule{}).Where("deleted_at = ?", 0).Count(&count).Error; err != nil {
+	if err := a.db.WithContext(ctx).Model(&model.MonitorRecordRule{}).Scopes(notDeleted).Count(&count).Error; err != nil {
 		a.l.Error("获取监控告警事件总数失败", zap.Error(err))
 		return 0, err
 	}
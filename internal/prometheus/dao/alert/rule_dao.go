@@ -77,7 +77,7 @@ func (a *alertManagerRuleDAO) GetMonitorAlertRuleByPoolId(ctx context.Context, p
 
 	if err := a.db.WithContext(ctx).
 		Where("enable = ?", true).
-		Where("pool_id = ? AND deleted_at = ?", poolId, 0).
+		Scopes(notDeleted).Where("pool_id = ?", poolId).
 		Find(&alertRules).Error; err != nil {
 		a.l.Error("获取 MonitorAlertRule 失败", zap.Error(err), zap.Int("poolId", poolId))
 		return nil, err
@@ -91,7 +91,7 @@ func (a *alertManagerRuleDAO) SearchMonitorAlertRuleByName(ctx context.Context,
 	var alertRules []*model.MonitorAlertRule
 
 	if err := a.db.WithContext(ctx).
-		Where("LOWER(name) LIKE ? AND deleted_at = ?", "%"+strings.ToLower(name)+"%", 0).
+		Scopes(notDeleted).Where("LOWER(name) LIKE ?", "%"+strings.ToLower(name)+"%").
 		Find(&alertRules).Error; err != nil {
 		a.l.Error("通过名称搜索 MonitorAlertRule 失败", zap.Error(err))
 		return nil, err
@@ -104,7 +104,7 @@ func (a *alertManagerRuleDAO) SearchMonitorAlertRuleByName(ctx context.Context,
 func (a *alertManagerRuleDAO) GetMonitorAlertRuleList(ctx context.Context, offset, limit int) ([]*model.MonitorAlertRule, error) {
 	var alertRules []*model.MonitorAlertRule
 
-	if err := a.db.WithContext(ctx).Where("deleted_at = ?", 0).Offset(offset).Limit(limit).Find(&alertRules).Error; err != nil {
+	if err := a.db.WithContext(ctx).Scopes(notDeleted).Offset(offset).Limit(limit).Find(&alertRules).Error; err != nil {
 		a.l.Error("获取所有 MonitorAlertRule 失败", zap.Error(err))
 		return nil, err
 	}
@@ -134,7 +134,7 @@ func (a *alertManagerRuleDAO) GetMonitorAlertRuleById(ctx context.Context, id in
 
 	var alertRule model.MonitorAlertRule
 
-	if err := a.db.WithContext(ctx).Where("id = ? AND deleted_at = ?", id, 0).First(&alertRule).Error; err != nil {
+	if err := a.db.WithContext(ctx).Scopes(notDeleted).Where("id = ?", id).First(&alertRule).Error; err != nil {
 		a.l.Error("获取 MonitorAlertRule 失败", zap.Error(err), zap.Int("id", id))
 		return nil, err
 	}
@@ -151,7 +151,7 @@ func (a *alertManagerRuleDAO) UpdateMonitorAlertRule(ctx context.Context, monito
 
 	if err := a.db.WithContext(ctx).
 		Model(&model.MonitorAlertRule{}).
-		Where("id = ? AND deleted_at = ?", monitorAlertRule.ID, 0).
+		Scopes(notDeleted).Where("id = ?", monitorAlertRule.ID).
 		Updates(map[string]interface{}{
 			"name":          monitorAlertRule.Name,
 			"pool_id":       monitorAlertRule.PoolID,
@@ -182,7 +182,7 @@ func (a *alertManagerRuleDAO) EnableSwitchMonitorAlertRule(ctx context.Context,
 
 	if err := a.db.WithContext(ctx).
 		Model(&model.MonitorAlertRule{}).
-		Where("id = ? AND deleted_at = ?", ruleID, 0).
+		Scopes(notDeleted).Where("id = ?", ruleID).
 		Update("enable", gorm.Expr("NOT enable")).Error; err != nil {
 		a.l.Error("更新 MonitorAlertRule 状态失败", zap.Error(err), zap.Int("ruleID", ruleID))
 		return err
@@ -200,7 +200,7 @@ func (a *alertManagerRuleDAO) BatchEnableSwitchMonitorAlertRule(ctx context.Cont
 
 	if err := a.db.WithContext(ctx).
 		Model(&model.MonitorAlertRule{}).
-		Where("id IN ? AND deleted_at = ?", ruleIDs, 0).
+		Scopes(notDeleted).Where("id IN ?", ruleIDs).
 		Update("enable", gorm.Expr("NOT enable")).Error; err != nil {
 		a.l.Error("批量更新 MonitorAlertRule 状态失败", zap.Error(err), zap.Ints("ruleIDs", ruleIDs))
 		return err
@@ -216,7 +216,7 @@ func (a *alertManagerRuleDAO) DeleteMonitorAlertRule(ctx context.Context, ruleID
 		return fmt.Errorf("无效的 ruleID: %d", ruleID)
 	}
 
-	result := a.db.WithContext(ctx).Model(&model.MonitorAlertRule{}).Where("id = ? AND deleted_at = ?", ruleID, 0).Updates(map[string]interface{}{
+	result := a.db.WithContext(ctx).Model(&model.MonitorAlertRule{}).Scopes(notDeleted).Where("id = ?", ruleID).Updates(map[string]interface{}{
 		"deleted_at": getTime(),
 	})
 	if err := result.Error; err != nil {
@@ -241,7 +241,7 @@ func (a *alertManagerRuleDAO) GetAssociatedResourcesBySendGroupId(ctx context.Co
 	var alertRules []*model.MonitorAlertRule
 
 	if err := a.db.WithContext(ctx).
-		Where("send_group_id = ? AND deleted_at = ?", sendGroupId, 0).
+		Scopes(notDeleted).Where("send_group_id = ?", sendGroupId).
 		Find(&alertRules).Error; err != nil {
 		a.l.Error("获取关联资源失败", zap.Error(err), zap.Int("sendGroupId", sendGroupId))
 		return nil, err
@@ -261,7 +261,7 @@ func (a *alertManagerRuleDAO) CheckMonitorAlertRuleExists(ctx context.Context, a
 
 	if err := a.db.WithContext(ctx).
 		Model(&model.MonitorAlertRule{}).
-		Where("id = ? AND deleted_at = ?", alertRule.ID, 0).
+		Scopes(notDeleted).Where("id = ?", alertRule.ID).
 		Count(&count).Error; err != nil {
 		a.l.Error("检查 MonitorAlertRule 是否存在失败", zap.Error(err))
 		return false, err
@@ -276,7 +276,7 @@ func (a *alertManagerRuleDAO) CheckMonitorAlertRuleNameExists(ctx context.Contex
 
 	if err := a.db.WithContext(ctx).
 		Model(&model.MonitorAlertRule{}).
-		Where("name = ? AND deleted_at = ?", alertRule.Name, 0).
+		Scopes(notDeleted).Where("name = ?", alertRule.Name).
 		Count(&count).Error; err != nil {
 		a.l.Error("检查 MonitorAlertRule 名称是否存在失败", zap.Error(err))
 		return false, err
@@ -289,7 +289,7 @@ func (a *alertManagerRuleDAO) CheckMonitorAlertRuleNameExists(ctx context.Contex
 func (a *alertManagerRuleDAO) GetMonitorAlertRuleTotal(ctx context.Context) (int, error) {
 	var count int64
 
-	if err := a.db.WithContext(ctx).Model(&model.MonitorAlertRule{}).Where("deleted_at = ?", 0).Count(&count).Error; err != nil {
+	if err := a.db.WithContext(ctx).Model(&model.MonitorAlertRule{}).Scopes(notDeleted).Count(&count).Error; err != nil {
 		a.l.Error("获取监控告警事件总数失败", zap.Error(err))
 		return 0, err
 	}
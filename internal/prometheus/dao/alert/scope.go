@@ -0,0 +1,88 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package alert
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GoSimplicity/AI-CloudOps/pkg/utils"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+// notDeleted 过滤已软删除的记录，通过 Scopes(notDeleted) 统一复用
+var notDeleted = utils.NotDeleted()
+
+// tenantScopeEnabled 是否开启告警事件的团队隔离
+func tenantScopeEnabled() bool {
+	return viper.GetInt("prometheus.enable_tenant_scope") == 1
+}
+
+// checkTeamID 开启团队隔离时拒绝未指定团队的查询
+func checkTeamID(teamID int) error {
+	if tenantScopeEnabled() && teamID <= 0 {
+		return fmt.Errorf("已开启团队隔离，团队ID不能为空")
+	}
+	return nil
+}
+
+// teamScoped 开启团队隔离时按团队过滤记录
+func teamScoped(teamID int) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if !tenantScopeEnabled() {
+			return db
+		}
+		return db.Where("team_id = ?", teamID)
+	}
+}
+
+// severityIs severity 不为空时按告警级别过滤记录，级别统一按小写匹配
+func severityIs(severity string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if severity == "" {
+			return db
+		}
+		return db.Where("severity = ?", strings.ToLower(severity))
+	}
+}
+
+// likeEscapeChar LIKE 查询使用的转义字符，避免反斜杠在不同数据库 SQL 模式下语义不一致
+const likeEscapeChar = "!"
+
+// likeEscaper 转义 LIKE 通配符和转义字符本身
+var likeEscaper = strings.NewReplacer(
+	likeEscapeChar, likeEscapeChar+likeEscapeChar,
+	"%", likeEscapeChar+"%",
+	"_", likeEscapeChar+"_",
+)
+
+// containsLike 按字面子串匹配指定列，term 中的 % 和 _ 不作为通配符
+func containsLike(column, term string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(column+" LIKE ? ESCAPE '"+likeEscapeChar+"'", "%"+likeEscaper.Replace(term)+"%")
+	}
+}
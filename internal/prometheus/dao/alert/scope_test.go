@@ -0,0 +1,138 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package alert
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+)
+
+func TestNotDeletedScopeOmitsDeletedRows(t *testing.T) {
+	_, db := newTestEventDAO(t)
+
+	seedEvents(t, db,
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-live", Status: "firing"},
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-gone", Status: "firing", DeletedAt: 1},
+	)
+
+	var scoped []*model.MonitorAlertEvent
+	if err := db.Scopes(notDeleted).Find(&scoped).Error; err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if len(scoped) != 1 || scoped[0].Fingerprint != "fp-live" {
+		t.Fatalf("使用 notDeleted 的查询应只返回未删除事件: %+v", scoped)
+	}
+
+	// 未挂 notDeleted 的查询会把已删除记录一并查出，新增查询路径必须显式复用该 scope
+	var unscoped []*model.MonitorAlertEvent
+	if err := db.Find(&unscoped).Error; err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if len(unscoped) != 2 {
+		t.Fatalf("未使用 notDeleted 的查询应包含已删除事件, 实际 %d 条", len(unscoped))
+	}
+}
+
+// TestEventReadPathsOmitDeletedRows 新增的事件查询方法需加入此表，确保不会漏掉 notDeleted
+func TestEventReadPathsOmitDeletedRows(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	ctx := context.Background()
+
+	seedEvents(t, db,
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-live", Status: "firing", Severity: "critical"},
+		&model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-gone", Status: "firing", Severity: "critical", DeletedAt: 1},
+	)
+
+	paths := map[string]func() ([]*model.MonitorAlertEvent, error){
+		"GetMonitorAlertEventList": func() ([]*model.MonitorAlertEvent, error) {
+			return d.GetMonitorAlertEventList(ctx, 0, "", 0, 10)
+		},
+		"GetMonitorAlertEventListAfter": func() ([]*model.MonitorAlertEvent, error) {
+			return d.GetMonitorAlertEventListAfter(ctx, 0, nil, 10)
+		},
+		"GetMonitorAlertEventSummaryList": func() ([]*model.MonitorAlertEvent, error) {
+			return d.GetMonitorAlertEventSummaryList(ctx, 0, 0, 10)
+		},
+		"SearchMonitorAlertEventByName": func() ([]*model.MonitorAlertEvent, error) {
+			return d.SearchMonitorAlertEventByName(ctx, 0, "cpu", "")
+		},
+		"SearchMonitorAlertEvents": func() ([]*model.MonitorAlertEvent, error) {
+			events, _, err := d.SearchMonitorAlertEvents(ctx, 0, &model.AlertEventFilter{Name: "cpu"}, 0, 10)
+			return events, err
+		},
+		"GetUnclaimedFiringEvents": func() ([]*model.MonitorAlertEvent, error) {
+			events, _, err := d.GetUnclaimedFiringEvents(ctx, 0, 10)
+			return events, err
+		},
+		"GetAlertEventsSinceID": func() ([]*model.MonitorAlertEvent, error) {
+			return d.GetAlertEventsSinceID(ctx, 0, 10, false)
+		},
+		"GetEventsByLabelKV": func() ([]*model.MonitorAlertEvent, error) {
+			return d.GetEventsByLabelKV(ctx, 0, "alertname", "cpu", 10)
+		},
+	}
+
+	for name, query := range paths {
+		events, err := query()
+		if err != nil {
+			t.Fatalf("%s 返回错误: %v", name, err)
+		}
+		for _, event := range events {
+			if event.Fingerprint == "fp-gone" {
+				t.Fatalf("%s 返回了已删除的事件", name)
+			}
+		}
+	}
+}
+
+func TestSearchByNameEscapesLikeWildcards(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	ctx := context.Background()
+
+	seedEvents(t, db,
+		&model.MonitorAlertEvent{AlertName: "cpu_usage", Fingerprint: "fp-1", Status: "firing"},
+		&model.MonitorAlertEvent{AlertName: "cpuXusage", Fingerprint: "fp-2", Status: "firing"},
+		&model.MonitorAlertEvent{AlertName: "disk 100% full", Fingerprint: "fp-3", Status: "firing"},
+		&model.MonitorAlertEvent{AlertName: "disk 1000 full", Fingerprint: "fp-4", Status: "firing"},
+		&model.MonitorAlertEvent{AlertName: "path!raw", Fingerprint: "fp-5", Status: "firing"},
+	)
+
+	for term, want := range map[string]string{
+		"cpu_usage": "fp-1",
+		"100%":      "fp-3",
+		"h!r":       "fp-5",
+	} {
+		events, err := d.SearchMonitorAlertEventByName(ctx, 0, term, "")
+		if err != nil {
+			t.Fatalf("SearchMonitorAlertEventByName(%s) 返回错误: %v", term, err)
+		}
+		if len(events) != 1 || events[0].Fingerprint != want {
+			t.Fatalf("搜索 %q 应只匹配字面子串 %s, 实际 %+v", term, want, events)
+		}
+	}
+}
@@ -76,7 +76,7 @@ func (a *alertManagerSendDAO) GetMonitorSendGroupByPoolId(ctx context.Context, p
 	var sendGroups []*model.MonitorSendGroup
 
 	if err := a.db.WithContext(ctx).
-		Where("pool_id = ? AND deleted_at = ?", poolId, 0).
+		Scopes(notDeleted).Where("pool_id = ?", poolId).
 		Find(&sendGroups).Error; err != nil {
 		a.l.Error("获取 MonitorSendGroup 失败", zap.Error(err), zap.Int("poolId", poolId))
 		return nil, err
@@ -95,7 +95,7 @@ func (a *alertManagerSendDAO) GetMonitorSendGroupByOnDutyGroupId(ctx context.Con
 	var sendGroups []*model.MonitorSendGroup
 
 	if err := a.db.WithContext(ctx).
-		Where("on_duty_group_id = ? AND deleted_at = ?", onDutyGroupID, 0).
+		Scopes(notDeleted).Where("on_duty_group_id = ?", onDutyGroupID).
 		Find(&sendGroups).Error; err != nil {
 		a.l.Error("获取 MonitorSendGroup 失败", zap.Error(err), zap.Int("onDutyGroupID", onDutyGroupID))
 		return nil, err
@@ -109,7 +109,7 @@ func (a *alertManagerSendDAO) SearchMonitorSendGroupByName(ctx context.Context,
 	var sendGroups []*model.MonitorSendGroup
 
 	if err := a.db.WithContext(ctx).
-		Where("LOWER(name) LIKE ? AND deleted_at = ?", "%"+strings.ToLower(name)+"%", 0).
+		Scopes(notDeleted).Where("LOWER(name) LIKE ?", "%"+strings.ToLower(name)+"%").
 		Find(&sendGroups).Error; err != nil {
 		a.l.Error("通过名称搜索 MonitorSendGroup 失败", zap.Error(err))
 		return nil, err
@@ -123,7 +123,7 @@ func (a *alertManagerSendDAO) GetMonitorSendGroupList(ctx context.Context, offse
 	var sendGroups []*model.MonitorSendGroup
 
 	if err := a.db.WithContext(ctx).
-		Where("deleted_at = ?", 0).
+		Scopes(notDeleted).
 		Offset(offset).
 		Limit(limit).
 		Find(&sendGroups).Error; err != nil {
@@ -144,7 +144,7 @@ func (a *alertManagerSendDAO) GetMonitorSendGroupById(ctx context.Context, id in
 	var sendGroup model.MonitorSendGroup
 
 	if err := a.db.WithContext(ctx).
-		Where("id = ? AND deleted_at = ?", id, 0).
+		Scopes(notDeleted).Where("id = ?", id).
 		First(&sendGroup).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, fmt.Errorf("未找到 ID 为 %d 的记录", id)
@@ -178,7 +178,7 @@ func (a *alertManagerSendDAO) DeleteMonitorSendGroup(ctx context.Context, id int
 
 	result := a.db.WithContext(ctx).
 		Model(&model.MonitorSendGroup{}).
-		Where("id = ? AND deleted_at = ?", id, 0).
+		Scopes(notDeleted).Where("id = ?", id).
 		Updates(map[string]interface{}{
 			"deleted_at": getTime(),
 		})
@@ -204,7 +204,7 @@ func (a *alertManagerSendDAO) CheckMonitorSendGroupExists(ctx context.Context, s
 
 	if err := a.db.WithContext(ctx).
 		Model(&model.MonitorSendGroup{}).
-		Where("id = ? AND deleted_at = ?", sendGroup.ID, 0).
+		Scopes(notDeleted).Where("id = ?", sendGroup.ID).
 		Count(&count).Error; err != nil {
 		a.l.Error("检查 MonitorSendGroup 是否存在失败", zap.Error(err))
 		return false, err
@@ -222,7 +222,7 @@ func (a *alertManagerSendDAO) CheckMonitorSendGroupNameExists(ctx context.Contex
 	var count int64
 	if err := a.db.WithContext(ctx).
 		Model(&model.MonitorSendGroup{}).
-		Where("name = ? AND deleted_at = ?", sendGroup.Name, 0).
+		Scopes(notDeleted).Where("name = ?", sendGroup.Name).
 		Count(&count).Error; err != nil {
 		a.l.Error("检查 MonitorSendGroup 名称是否存在失败", zap.Error(err))
 		return false, err
@@ -244,7 +244,15 @@ func (a *alertManagerSendDAO) UpdateMonitorSendGroup(ctx context.Context, tx *go
 		"enable":                  monitorSendGroup.Enable,
 		"pool_id":                 monitorSendGroup.PoolID,
 		"on_duty_group_id":        monitorSendGroup.OnDutyGroupID,
+		"team_id":                 monitorSendGroup.TeamID,
 		"fei_shu_qun_robot_token": monitorSendGroup.FeiShuQunRobotToken,
+		"channel_type":            monitorSendGroup.ChannelType,
+		"fallback_robot_tokens":   monitorSendGroup.FallbackRobotTokens,
+		"robot_tokens":            monitorSendGroup.RobotTokens,
+		"robot_select_strategy":   monitorSendGroup.RobotSelectStrategy,
+		"message_template":        monitorSendGroup.MessageTemplate,
+		"route_matchers":          monitorSendGroup.RouteMatchers,
+		"route_priority":          monitorSendGroup.RoutePriority,
 		"repeat_interval":         monitorSendGroup.RepeatInterval,
 		"send_resolved":           monitorSendGroup.SendResolved,
 		"notify_methods":          monitorSendGroup.NotifyMethods,
@@ -258,7 +266,7 @@ func (a *alertManagerSendDAO) UpdateMonitorSendGroup(ctx context.Context, tx *go
 func (a *alertManagerSendDAO) GetMonitorSendGroupTotal(ctx context.Context) (int, error) {
 	var count int64
 
-	if err := a.db.WithContext(ctx).Model(&model.MonitorSendGroup{}).Where("deleted_at = ?", 0).Count(&count).Error; err != nil {
+	if err := a.db.WithContext(ctx).Model(&model.MonitorSendGroup{}).Scopes(notDeleted).Count(&count).Error; err != nil {
 		a.l.Error("获取监控告警事件总数失败", zap.Error(err))
 		return 0, err
 	}
@@ -270,7 +278,7 @@ func (a *alertManagerSendDAO) GetMonitorSendGroupTotal(ctx context.Context) (int
 func (a *alertManagerSendDAO) GetMonitorSendGroups(ctx context.Context) ([]*model.MonitorSendGroup, error) {
 	var sendGroups []*model.MonitorSendGroup
 
-	if err := a.db.WithContext(ctx).Where("deleted_at = ?", 0).Find(&sendGroups).Error; err != nil {
+	if err := a.db.WithContext(ctx).Scopes(notDeleted).Find(&sendGroups).Error; err != nil {
 		a.l.Error("获取所有发送组失败", zap.Error(err))
 		return nil, err
 	}
@@ -0,0 +1,424 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/spf13/viper"
+)
+
+// TestCoalesceSendSurvivesCallerCancel 发起方取消不应中断合并请求，其他等待的调用方仍能拿到发送结果
+func TestCoalesceSendSurvivesCallerCancel(t *testing.T) {
+	received := make(chan struct{})
+	release := make(chan struct{})
+	var requests int32
+	var requestCanceled atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			close(received)
+		}
+		<-release
+		requestCanceled.Store(r.Context().Err() != nil)
+		_, _ = w.Write([]byte(`{"code":0}`))
+	}))
+	defer srv.Close()
+
+	d, _ := newTestEventDAO(t)
+
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := d.coalesceSend(leaderCtx, srv.URL, "msg", `{"text":"msg"}`)
+		leaderErr <- err
+	}()
+	<-received
+
+	type result struct {
+		body []byte
+		err  error
+	}
+	followerRes := make(chan result, 1)
+	go func() {
+		body, err := d.coalesceSend(context.Background(), srv.URL, "msg", `{"text":"msg"}`)
+		followerRes <- result{body, err}
+	}()
+	// 等待跟随方合并到进行中的请求上
+	time.Sleep(50 * time.Millisecond)
+
+	cancelLeader()
+	if err := <-leaderErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("发起方取消后应立即返回 context.Canceled, 实际 %v", err)
+	}
+
+	close(release)
+	res := <-followerRes
+	if res.err != nil {
+		t.Fatalf("发起方取消不应导致跟随方失败: %v", res.err)
+	}
+	if string(res.body) != `{"code":0}` {
+		t.Fatalf("跟随方应拿到合并请求的响应, 实际 %q", res.body)
+	}
+	if requestCanceled.Load() {
+		t.Fatal("发起方取消不应中断进行中的 HTTP 请求")
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Fatalf("相同消息应只发送一次, 实际 %d 次", n)
+	}
+}
+
+// TestSendGroupMessageEscapesAndTruncates 消息中的引号、反斜杠和换行需正确转义，截断后请求体仍是合法 JSON
+func TestSendGroupMessageEscapesAndTruncates(t *testing.T) {
+	bodies := make(chan []byte, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+		_, _ = w.Write([]byte(`{"code":0}`))
+	}))
+	defer srv.Close()
+
+	viper.Set("prometheus.max_message_bytes", 64)
+	t.Cleanup(func() { viper.Set("prometheus.max_message_bytes", 0) })
+
+	d, _ := newTestEventDAO(t)
+
+	for _, message := range []string{
+		`磁盘 "/data" 使用率过高\n路径: C:\temp`,
+		strings.Repeat("告警", 40) + `"\`,
+	} {
+		if _, err := d.sendGroupMessage(context.Background(), srv.URL, message, ""); err != nil {
+			t.Fatalf("sendGroupMessage 返回错误: %v", err)
+		}
+
+		var payload struct {
+			MsgType string `json:"msg_type"`
+			Content struct {
+				Text string `json:"text"`
+			} `json:"content"`
+		}
+		body := <-bodies
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Fatalf("请求体不是合法 JSON: %v, body=%s", err, body)
+		}
+		text := payload.Content.Text
+		if payload.MsgType != "text" || !utf8.ValidString(text) || len(text) > 64 {
+			t.Fatalf("消息应为不超过 64 字节的合法 UTF-8 文本, 实际 %q", text)
+		}
+		if len(message) <= 64 && text != message {
+			t.Fatalf("未截断的消息应原样发送, 期望 %q, 实际 %q", message, text)
+		}
+		if len(message) > 64 && !strings.HasSuffix(text, truncatedMarker) {
+			t.Fatalf("截断的消息应以截断标记结尾, 实际 %q", text)
+		}
+	}
+}
+
+// TestConcurrentIdenticalSendsCoalesce 时间窗口内 100 个并发的相同消息只产生一次外部请求
+func TestConcurrentIdenticalSendsCoalesce(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"code":0}`))
+	}))
+	defer srv.Close()
+
+	d, _ := newTestEventDAO(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := d.SendMessageToGroup(context.Background(), srv.URL, "storm"); err != nil {
+				t.Errorf("发送消息失败: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Fatalf("100 个相同消息应只发送一次, 实际 %d 次", n)
+	}
+}
+
+// TestCoalescedSendReturnsResponse 时间窗口内被合并的发送返回首次请求的响应，而不是空响应
+func TestCoalescedSendReturnsResponse(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		_, _ = fmt.Fprintf(w, `{"code":0,"data":{"message_id":"om_%d"}}`, n)
+	}))
+	defer srv.Close()
+
+	viper.Set("prometheus.message_coalesce_window_ms", 60000)
+	t.Cleanup(func() { viper.Set("prometheus.message_coalesce_window_ms", 0) })
+
+	d, _ := newTestEventDAO(t)
+	want := `{"code":0,"data":{"message_id":"om_1"}}`
+	for i := 0; i < 3; i++ {
+		body, err := d.sendGroupMessage(context.Background(), srv.URL, "storm", "")
+		if err != nil {
+			t.Fatalf("第 %d 次发送返回错误: %v", i+1, err)
+		}
+		if string(body) != want {
+			t.Fatalf("第 %d 次发送期望返回 %s, 实际 %q", i+1, want, body)
+		}
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Fatalf("时间窗口内相同消息应只发送一次, 实际 %d 次", n)
+	}
+}
+
+// TestIdempotencyKeyReservedAtomically 并发的相同幂等键只有一个调用方真正发送
+func TestIdempotencyKeyReservedAtomically(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"code":0}`))
+	}))
+	defer srv.Close()
+
+	d, _ := newTestEventDAO(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// 消息内容各不相同，避免被相同消息合并掩盖
+			message := "notify-" + strings.Repeat("x", i)
+			if err := d.SendMessageToGroupWithKey(context.Background(), srv.URL, message, "event-1"); err != nil {
+				t.Errorf("发送消息失败: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Fatalf("相同幂等键应只发送一次, 实际 %d 次", n)
+	}
+}
+
+// TestIdempotencyKeyRetryAfterTimedOutDelivery 首次请求已送达但超时，使用相同幂等键重试不应重复投递；
+// 明确失败的发送会释放幂等键，重试时重新发送
+func TestIdempotencyKeyRetryAfterTimedOutDelivery(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		switch {
+		case n == 1:
+			// 消息已送达，但响应晚于客户端超时
+			time.Sleep(200 * time.Millisecond)
+		case r.URL.Path == "/fail" && n == 2:
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"code":0}`))
+	}))
+	defer srv.Close()
+
+	d, _ := newTestEventDAO(t)
+	d.httpClient = &http.Client{Timeout: 50 * time.Millisecond}
+	ctx := context.Background()
+
+	if err := d.SendMessageToGroupWithKey(ctx, srv.URL, "first", "event-1"); err == nil {
+		t.Fatal("首次发送应超时")
+	}
+	if err := d.SendMessageToGroupWithKey(ctx, srv.URL, "first-retry", "event-1"); err != nil {
+		t.Fatalf("重试应直接跳过: %v", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Fatalf("超时后重试不应重复投递, 实际请求 %d 次", n)
+	}
+
+	if err := d.SendMessageToGroupWithKey(ctx, srv.URL+"/fail", "second", "event-2"); err == nil {
+		t.Fatal("服务端返回 500 时应返回错误")
+	}
+	if err := d.SendMessageToGroupWithKey(ctx, srv.URL+"/fail", "second-retry", "event-2"); err != nil {
+		t.Fatalf("失败释放幂等键后重试应成功: %v", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 3 {
+		t.Fatalf("失败后重试应重新发送, 实际请求 %d 次", n)
+	}
+}
+
+func TestSendMessageToGroupCancelledContext(t *testing.T) {
+	var requests int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-release
+		_, _ = w.Write([]byte(`{"code":0}`))
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	d, _ := newTestEventDAO(t)
+
+	// 已取消的上下文不发出请求
+	if err := d.SendMessageToGroup(cancelledContext(), srv.URL, "msg"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("上下文已取消时应返回 context.Canceled, 实际 %v", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 0 {
+		t.Fatalf("上下文已取消时不应发出请求, 实际 %d 次", n)
+	}
+
+	// 请求进行中取消时立即返回，而不是等待服务端响应或 10s 超时
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := d.SendMessageToGroup(ctx, srv.URL, "msg"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("请求进行中取消时应返回 context.DeadlineExceeded, 实际 %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("取消后应立即返回, 实际耗时 %v", elapsed)
+	}
+}
+
+func TestSendCardToGroupBuildsInteractiveCard(t *testing.T) {
+	bodies := make(chan []byte, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+		_, _ = w.Write([]byte(`{"code":0}`))
+	}))
+	defer srv.Close()
+
+	d, _ := newTestEventDAO(t)
+	card := model.FeishuCard{
+		Title:     "CPU 使用率过高",
+		Severity:  "critical",
+		Instance:  "node-1",
+		Summary:   "CPU > 90%",
+		Content:   "**当前值**: 95%",
+		ActionURL: "https://example.com/alerts/1",
+	}
+	if err := d.SendCardToGroup(context.Background(), srv.URL, card); err != nil {
+		t.Fatalf("SendCardToGroup 返回错误: %v", err)
+	}
+
+	var payload struct {
+		MsgType string `json:"msg_type"`
+		Card    struct {
+			Header struct {
+				Template string `json:"template"`
+				Title    struct {
+					Tag     string `json:"tag"`
+					Content string `json:"content"`
+				} `json:"title"`
+			} `json:"header"`
+			Elements []struct {
+				Tag     string `json:"tag"`
+				Content string `json:"content"`
+				Fields  []struct {
+					Text struct {
+						Content string `json:"content"`
+					} `json:"text"`
+				} `json:"fields"`
+				Actions []struct {
+					Tag string `json:"tag"`
+					URL string `json:"url"`
+				} `json:"actions"`
+			} `json:"elements"`
+		} `json:"card"`
+	}
+	if err := json.Unmarshal(<-bodies, &payload); err != nil {
+		t.Fatalf("卡片消息体不是合法 JSON: %v", err)
+	}
+	if payload.MsgType != "interactive" {
+		t.Fatalf("msg_type 应为 interactive, 实际 %q", payload.MsgType)
+	}
+	if payload.Card.Header.Title.Tag != "plain_text" || payload.Card.Header.Title.Content != card.Title || payload.Card.Header.Template != feishuCardColor("critical") {
+		t.Fatalf("卡片标题不符合预期: %+v", payload.Card.Header)
+	}
+	elements := payload.Card.Elements
+	if len(elements) != 3 || elements[0].Tag != "div" || elements[1].Tag != "markdown" || elements[2].Tag != "action" {
+		t.Fatalf("卡片元素应依次为字段、markdown 正文和按钮: %+v", elements)
+	}
+	if len(elements[0].Fields) != 2 || !strings.Contains(elements[0].Fields[0].Text.Content, "node-1") {
+		t.Fatalf("字段应包含实例和摘要: %+v", elements[0].Fields)
+	}
+	if elements[1].Content != card.Content {
+		t.Fatalf("markdown 正文不一致: %q", elements[1].Content)
+	}
+	if len(elements[2].Actions) != 1 || elements[2].Actions[0].Tag != "button" || elements[2].Actions[0].URL != card.ActionURL {
+		t.Fatalf("按钮应指向详情链接: %+v", elements[2].Actions)
+	}
+
+	// 无链接时不展示按钮
+	if err := d.SendCardToGroup(context.Background(), srv.URL, model.FeishuCard{Title: "磁盘告警", Content: "磁盘剩余 5%"}); err != nil {
+		t.Fatalf("SendCardToGroup 返回错误: %v", err)
+	}
+	if body := <-bodies; strings.Contains(string(body), `"action"`) {
+		t.Fatalf("无链接时不应包含按钮: %s", body)
+	}
+}
+
+func TestFeishuCardColorConfigurable(t *testing.T) {
+	for severity, want := range map[string]string{
+		"critical": "red",
+		"WARNING":  "orange",
+		"info":     "blue",
+		"unknown":  defaultFeishuCardColor,
+	} {
+		if got := feishuCardColor(severity); got != want {
+			t.Fatalf("默认映射下 %s 的颜色期望 %s, 实际 %s", severity, want, got)
+		}
+	}
+
+	viper.Set("prometheus.feishu_card_colors", map[string]string{"critical": "carmine"})
+	defer viper.Set("prometheus.feishu_card_colors", nil)
+
+	if got := feishuCardColor("critical"); got != "carmine" {
+		t.Fatalf("配置的颜色应覆盖默认映射, 实际 %s", got)
+	}
+	if got := feishuCardColor("warning"); got != "orange" {
+		t.Fatalf("未配置的级别应使用默认映射, 实际 %s", got)
+	}
+
+	content, err := buildFeishuCard(model.FeishuCard{Title: "CPU", Severity: "critical"})
+	if err != nil {
+		t.Fatalf("buildFeishuCard 返回错误: %v", err)
+	}
+	if !strings.Contains(content, `"template":"carmine"`) {
+		t.Fatalf("卡片标题栏应使用配置的颜色: %s", content)
+	}
+}
@@ -0,0 +1,147 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// alertmanagerSilence Alertmanager 静默 API（GET /api/v2/silences）导出的静默结构
+type alertmanagerSilence struct {
+	ID       string `json:"id"`
+	Matchers []struct {
+		Name    string `json:"name"`
+		Value   string `json:"value"`
+		IsRegex bool   `json:"isRegex"`
+		IsEqual *bool  `json:"isEqual"` // 旧版本 Alertmanager 不返回该字段，缺省视为相等匹配
+	} `json:"matchers"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	CreatedBy string    `json:"createdBy"`
+	Comment   string    `json:"comment"`
+}
+
+// ImportSilencesFromAlertmanager 解析 Alertmanager 导出的静默 JSON 数组并创建静默记录，
+// 已过期或已导入过（静默ID相同）的静默会被跳过，任一静默格式非法时整体不导入
+func (a *alertManagerEventDAO) ImportSilencesFromAlertmanager(ctx context.Context, data []byte) (*model.SilenceImportResult, error) {
+	var silences []alertmanagerSilence
+	if err := json.Unmarshal(data, &silences); err != nil {
+		return nil, fmt.Errorf("解析 Alertmanager 静默JSON失败: %w", err)
+	}
+
+	now := time.Now()
+	result := &model.SilenceImportResult{}
+	records := make([]*model.MonitorSilence, 0, len(silences))
+
+	for i, silence := range silences {
+		record, err := convertAlertmanagerSilence(silence)
+		if err != nil {
+			return nil, fmt.Errorf("第%d条静默不合法: %w", i+1, err)
+		}
+
+		if !silence.EndsAt.After(now) {
+			result.Skipped++
+			continue
+		}
+		records = append(records, record)
+	}
+
+	err := a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, record := range records {
+			if record.SilenceID != "" {
+				var count int64
+				if err := tx.Model(&model.MonitorSilence{}).
+					Scopes(notDeleted).
+					Where("silence_id = ?", record.SilenceID).
+					Count(&count).Error; err != nil {
+					return err
+				}
+				if count > 0 {
+					result.Skipped++
+					continue
+				}
+			}
+
+			if err := tx.Create(record).Error; err != nil {
+				return err
+			}
+			result.Imported++
+		}
+		return nil
+	})
+	if err != nil {
+		a.logger(ctx).Error("导入 Alertmanager 静默失败", zap.Error(err))
+		return nil, fmt.Errorf("导入静默失败: %w", err)
+	}
+
+	a.logger(ctx).Info("导入 Alertmanager 静默完成", zap.Int("imported", result.Imported), zap.Int("skipped", result.Skipped))
+	return result, nil
+}
+
+// convertAlertmanagerSilence 校验 Alertmanager 静默并转换为静默记录
+func convertAlertmanagerSilence(silence alertmanagerSilence) (*model.MonitorSilence, error) {
+	if len(silence.Matchers) == 0 {
+		return nil, fmt.Errorf("matchers不能为空")
+	}
+	if silence.StartsAt.IsZero() || silence.EndsAt.IsZero() {
+		return nil, fmt.Errorf("startsAt和endsAt不能为空")
+	}
+	if !silence.EndsAt.After(silence.StartsAt) {
+		return nil, fmt.Errorf("endsAt必须晚于startsAt")
+	}
+
+	matchers := make(model.SilenceMatchers, 0, len(silence.Matchers))
+	for _, m := range silence.Matchers {
+		if m.Name == "" {
+			return nil, fmt.Errorf("matcher名称不能为空")
+		}
+		isEqual := true
+		if m.IsEqual != nil {
+			isEqual = *m.IsEqual
+		}
+		matchers = append(matchers, model.SilenceMatcher{
+			Name:    m.Name,
+			Value:   m.Value,
+			IsRegex: m.IsRegex,
+			IsEqual: isEqual,
+		})
+	}
+
+	return &model.MonitorSilence{
+		SilenceID: silence.ID,
+		Matchers:  matchers,
+		StartsAt:  silence.StartsAt.Unix(),
+		EndsAt:    silence.EndsAt.Unix(),
+		CreatedBy: silence.CreatedBy,
+		Comment:   silence.Comment,
+	}, nil
+}
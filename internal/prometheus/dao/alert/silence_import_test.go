@@ -0,0 +1,159 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package alert
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+)
+
+// sampleAlertmanagerSilences 模拟 GET /api/v2/silences 的导出结果：两条生效中的静默和一条已过期的静默
+func sampleAlertmanagerSilences(now time.Time) string {
+	ts := func(d time.Duration) string { return now.Add(d).UTC().Format(time.RFC3339) }
+	return fmt.Sprintf(`[
+  {
+    "id": "0f2f5a9e-1111",
+    "status": {"state": "active"},
+    "matchers": [
+      {"name": "alertname", "value": "NodeDown", "isRegex": false},
+      {"name": "instance", "value": "10.0.0.*", "isRegex": true, "isEqual": false}
+    ],
+    "startsAt": %q,
+    "endsAt": %q,
+    "updatedAt": %q,
+    "createdBy": "alice",
+    "comment": "机房维护"
+  },
+  {
+    "id": "0f2f5a9e-2222",
+    "status": {"state": "pending"},
+    "matchers": [{"name": "severity", "value": "info", "isRegex": false, "isEqual": true}],
+    "startsAt": %q,
+    "endsAt": %q,
+    "createdBy": "bob",
+    "comment": "降噪"
+  },
+  {
+    "id": "0f2f5a9e-3333",
+    "status": {"state": "expired"},
+    "matchers": [{"name": "job", "value": "node", "isRegex": false, "isEqual": true}],
+    "startsAt": %q,
+    "endsAt": %q,
+    "createdBy": "carol",
+    "comment": "已过期"
+  }
+]`, ts(-time.Hour), ts(time.Hour), ts(-time.Hour),
+		ts(time.Hour), ts(2*time.Hour),
+		ts(-2*time.Hour), ts(-time.Hour))
+}
+
+func TestImportSilencesFromAlertmanager(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	migrateTestTables(t, db, &model.MonitorSilence{})
+	ctx := context.Background()
+	now := time.Now()
+	data := []byte(sampleAlertmanagerSilences(now))
+
+	result, err := d.ImportSilencesFromAlertmanager(ctx, data)
+	if err != nil {
+		t.Fatalf("导入静默失败: %v", err)
+	}
+	if result.Imported != 2 || result.Skipped != 1 {
+		t.Fatalf("应导入 2 条、跳过 1 条已过期静默, 实际 %+v", result)
+	}
+
+	var silences []*model.MonitorSilence
+	if err := db.Order("id").Find(&silences).Error; err != nil {
+		t.Fatalf("查询静默失败: %v", err)
+	}
+	if len(silences) != 2 {
+		t.Fatalf("应写入 2 条静默, 实际 %d", len(silences))
+	}
+	first := silences[0]
+	if first.SilenceID != "0f2f5a9e-1111" || first.CreatedBy != "alice" || first.Comment != "机房维护" {
+		t.Fatalf("静默基本信息不符合预期: %+v", first)
+	}
+	if first.StartsAt != now.Add(-time.Hour).Unix() || first.EndsAt != now.Add(time.Hour).Unix() {
+		t.Fatalf("静默时间不符合预期: %d-%d", first.StartsAt, first.EndsAt)
+	}
+	want := model.SilenceMatchers{
+		{Name: "alertname", Value: "NodeDown", IsEqual: true},
+		{Name: "instance", Value: "10.0.0.*", IsRegex: true, IsEqual: false},
+	}
+	if len(first.Matchers) != len(want) || first.Matchers[0] != want[0] || first.Matchers[1] != want[1] {
+		t.Fatalf("匹配器期望 %+v, 实际 %+v", want, first.Matchers)
+	}
+
+	// 重复导入时按静默ID跳过已导入的静默
+	result, err = d.ImportSilencesFromAlertmanager(ctx, data)
+	if err != nil {
+		t.Fatalf("重复导入静默失败: %v", err)
+	}
+	if result.Imported != 0 || result.Skipped != 3 {
+		t.Fatalf("重复导入应全部跳过, 实际 %+v", result)
+	}
+}
+
+func TestImportSilencesFromAlertmanagerRejectsInvalidInput(t *testing.T) {
+	d, db := newTestEventDAO(t)
+	migrateTestTables(t, db, &model.MonitorSilence{})
+	ctx := context.Background()
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	past := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+
+	for _, c := range []struct {
+		name string
+		data string
+		want string
+	}{
+		{"非法JSON", `{"id": `, "解析 Alertmanager 静默JSON失败"},
+		{"非数组", `{"id": "x"}`, "解析 Alertmanager 静默JSON失败"},
+		{"缺少matchers", fmt.Sprintf(`[{"id": "a", "startsAt": %q, "endsAt": %q}]`, past, future), "第1条静默不合法: matchers不能为空"},
+		{"结束早于开始", fmt.Sprintf(`[{"id": "a", "matchers": [{"name": "job", "value": "x"}], "startsAt": %q, "endsAt": %q}]`, future, past), "endsAt必须晚于startsAt"},
+	} {
+		if _, err := d.ImportSilencesFromAlertmanager(ctx, []byte(c.data)); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Fatalf("%s: 期望包含 %q 的错误, 实际 %v", c.name, c.want, err)
+		}
+	}
+
+	// 任一静默非法时整体不导入
+	valid := fmt.Sprintf(`{"id": "ok", "matchers": [{"name": "job", "value": "x"}], "startsAt": %q, "endsAt": %q}`, past, future)
+	if _, err := d.ImportSilencesFromAlertmanager(ctx, []byte(`[`+valid+`, {"id": "bad"}]`)); err == nil {
+		t.Fatal("包含非法静默时应返回错误")
+	}
+	var count int64
+	if err := db.Model(&model.MonitorSilence{}).Count(&count).Error; err != nil {
+		t.Fatalf("统计静默失败: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("导入失败时不应写入静默, 实际 %d 条", count)
+	}
+}
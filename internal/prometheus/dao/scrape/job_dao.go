@@ -27,6 +27,7 @@ package scrape
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -36,6 +37,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// ErrScrapeJobNameConflict 同一采集池下采集任务名称重复
+var ErrScrapeJobNameConflict = errors.New("采集任务名称冲突")
+
 type ScrapeJobDAO interface {
 	GetMonitorScrapeJobList(ctx context.Context, offset, limit int) ([]*model.MonitorScrapeJob, error)
 	CreateMonitorScrapeJob(ctx context.Context, monitorScrapeJob *model.MonitorScrapeJob) error
@@ -74,7 +78,7 @@ func (s *scrapeJobDAO) GetMonitorScrapeJobList(ctx context.Context, offset, limi
 
 	var jobs []*model.MonitorScrapeJob
 
-	if err := s.db.WithContext(ctx).Where("deleted_at = ?", 0).Offset(offset).Limit(limit).Find(&jobs).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(notDeleted).Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&jobs).Error; err != nil {
 		s.l.Error("获取监控采集作业列表失败", zap.Error(err))
 		return nil, err
 	}
@@ -84,6 +88,10 @@ func (s *scrapeJobDAO) GetMonitorScrapeJobList(ctx context.Context, offset, limi
 
 // CreateMonitorScrapeJob 创建监控采集作业
 func (s *scrapeJobDAO) CreateMonitorScrapeJob(ctx context.Context, monitorScrapeJob *model.MonitorScrapeJob) error {
+	if err := s.checkJobNameConflict(ctx, monitorScrapeJob.PoolID, monitorScrapeJob.Name, 0); err != nil {
+		return err
+	}
+
 	monitorScrapeJob.CreatedAt = getTime()
 	monitorScrapeJob.UpdatedAt = getTime()
 
@@ -95,7 +103,7 @@ func (s *scrapeJobDAO) CreateMonitorScrapeJob(ctx context.Context, monitorScrape
 	return nil
 }
 
-// GetMonitorScrapeJobsByPoolId 获取监控采集作业列表
+// GetMonitorScrapeJobsByPoolId 获取采集池下已启用的监控采集作业列表，用于生成 file_sd 目标文件
 func (s *scrapeJobDAO) GetMonitorScrapeJobsByPoolId(ctx context.Context, poolId int) ([]*model.MonitorScrapeJob, error) {
 	if poolId <= 0 {
 		s.l.Error("GetMonitorScrapeJobsByPoolId 失败: 无效的 poolId", zap.Int("poolId", poolId))
@@ -105,8 +113,9 @@ func (s *scrapeJobDAO) GetMonitorScrapeJobsByPoolId(ctx context.Context, poolId
 	var jobs []*model.MonitorScrapeJob
 
 	if err := s.db.WithContext(ctx).
-		Where("deleted_at = ? AND enable = ?", 0, 1).
+		Scopes(notDeleted).Where("enable = ?", 1).
 		Where("pool_id = ?", poolId).
+		Order("created_at DESC, id DESC").
 		Find(&jobs).Error; err != nil {
 		s.l.Error("获取 MonitorScrapeJob 失败", zap.Error(err), zap.Int("poolId", poolId))
 		return nil, err
@@ -122,11 +131,15 @@ func (s *scrapeJobDAO) UpdateMonitorScrapeJob(ctx context.Context, monitorScrape
 		return fmt.Errorf("monitorScrapeJob 的 ID 必须大于 0")
 	}
 
+	if err := s.checkJobNameConflict(ctx, monitorScrapeJob.PoolID, monitorScrapeJob.Name, monitorScrapeJob.ID); err != nil {
+		return err
+	}
+
 	monitorScrapeJob.UpdatedAt = getTime()
 
 	if err := s.db.WithContext(ctx).
 		Model(&model.MonitorScrapeJob{}).
-		Where("id = ? AND deleted_at = ?", monitorScrapeJob.ID, 0).
+		Scopes(notDeleted).Where("id = ?", monitorScrapeJob.ID).
 		Updates(map[string]interface{}{
 			"name":                        monitorScrapeJob.Name,
 			"enable":                      monitorScrapeJob.Enable,
@@ -164,7 +177,7 @@ func (s *scrapeJobDAO) DeleteMonitorScrapeJob(ctx context.Context, jobId int) er
 
 	result := s.db.WithContext(ctx).
 		Model(&model.MonitorScrapeJob{}).
-		Where("id = ? AND deleted_at = ?", jobId, 0).
+		Scopes(notDeleted).Where("id = ?", jobId).
 		Updates(map[string]interface{}{
 			"deleted_at": getTime(),
 		})
@@ -189,7 +202,8 @@ func (s *scrapeJobDAO) SearchMonitorScrapeJobsByName(ctx context.Context, name s
 	var jobs []*model.MonitorScrapeJob
 
 	if err := s.db.WithContext(ctx).
-		Where("deleted_at = ? AND LOWER(name) LIKE ?", 0, "%"+strings.ToLower(name)+"%").
+		Scopes(notDeleted).Where("LOWER(name) LIKE ?", "%"+strings.ToLower(name)+"%").
+		Order("created_at DESC, id DESC").
 		Find(&jobs).Error; err != nil {
 		s.l.Error("通过名称搜索 MonitorScrapeJob 失败", zap.Error(err))
 		return nil, err
@@ -208,7 +222,7 @@ func (s *scrapeJobDAO) CheckMonitorScrapeJobExists(ctx context.Context, name str
 
 	if err := s.db.WithContext(ctx).
 		Model(&model.MonitorScrapeJob{}).
-		Where("deleted_at = ? AND name = ?", 0, name).
+		Scopes(notDeleted).Where("name = ?", name).
 		Count(&count).Error; err != nil {
 		s.l.Error("检查 MonitorScrapeJob 是否存在失败", zap.Error(err))
 		return false, err
@@ -227,7 +241,7 @@ func (s *scrapeJobDAO) GetMonitorScrapeJobById(ctx context.Context, id int) (*mo
 	var scrapeJob model.MonitorScrapeJob
 
 	if err := s.db.WithContext(ctx).
-		Where("id = ? AND deleted_at = ?", id, 0).
+		Scopes(notDeleted).Where("id = ?", id).
 		First(&scrapeJob).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, fmt.Errorf("未找到ID为 %d 的记录", id)
@@ -249,7 +263,7 @@ func (s *scrapeJobDAO) CheckMonitorInstanceExists(ctx context.Context, poolID in
 
 	if err := s.db.WithContext(ctx).
 		Model(&model.MonitorScrapePool{}).
-		Where("id = ? AND deleted_at = ?", poolID, 0).
+		Scopes(notDeleted).Where("id = ?", poolID).
 		Count(&count).Error; err != nil {
 		s.l.Error("检查监控实例是否存在失败", zap.Error(err))
 		return false, err
@@ -262,10 +276,30 @@ func (s *scrapeJobDAO) CheckMonitorInstanceExists(ctx context.Context, poolID in
 func (s *scrapeJobDAO) GetMonitorScrapeJobTotal(ctx context.Context) (int, error) {
 	var count int64
 
-	if err := s.db.WithContext(ctx).Model(&model.MonitorScrapeJob{}).Where("deleted_at = ?", 0).Count(&count).Error; err != nil {
+	if err := s.db.WithContext(ctx).Model(&model.MonitorScrapeJob{}).Scopes(notDeleted).Count(&count).Error; err != nil {
 		s.l.Error("获取监控采集作业总数失败", zap.Error(err))
 		return 0, err
 	}
 
 	return int(count), nil
 }
+
+// checkJobNameConflict 检查同一采集池下是否已存在同名采集任务，excludeID 用于更新时排除自身
+func (s *scrapeJobDAO) checkJobNameConflict(ctx context.Context, poolID int, name string, excludeID int) error {
+	var count int64
+
+	if err := s.db.WithContext(ctx).
+		Model(&model.MonitorScrapeJob{}).
+		Scopes(notDeleted).
+		Where("pool_id = ? AND name = ? AND id != ?", poolID, name, excludeID).
+		Count(&count).Error; err != nil {
+		s.l.Error("检查采集任务名称冲突失败", zap.Error(err), zap.Int("poolID", poolID), zap.String("name", name))
+		return err
+	}
+
+	if count > 0 {
+		return fmt.Errorf("%w: 采集池 %d 中已存在名为 %s 的采集任务", ErrScrapeJobNameConflict, poolID, name)
+	}
+
+	return nil
+}
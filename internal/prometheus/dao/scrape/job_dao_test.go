@@ -0,0 +1,99 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package scrape
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/glebarez/sqlite"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestJobDAO 创建基于内存 sqlite 的采集任务 DAO
+func newTestJobDAO(t *testing.T) (ScrapeJobDAO, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("打开测试数据库失败: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取数据库连接失败: %v", err)
+	}
+	// 内存库每个连接独立，限制为单连接保证数据可见
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(&model.MonitorScrapeJob{}); err != nil {
+		t.Fatalf("迁移测试表失败: %v", err)
+	}
+	return NewScrapeJobDAO(db, zap.NewNop(), nil), db
+}
+
+func TestScrapeJobNameUniqueWithinPool(t *testing.T) {
+	d, _ := newTestJobDAO(t)
+	ctx := context.Background()
+
+	first := &model.MonitorScrapeJob{Name: "node", PoolID: 1, UserID: 1}
+	if err := d.CreateMonitorScrapeJob(ctx, first); err != nil {
+		t.Fatalf("创建采集任务失败: %v", err)
+	}
+
+	err := d.CreateMonitorScrapeJob(ctx, &model.MonitorScrapeJob{Name: "node", PoolID: 1, UserID: 1})
+	if !errors.Is(err, ErrScrapeJobNameConflict) {
+		t.Fatalf("同一采集池下重名应返回 ErrScrapeJobNameConflict, 实际 %v", err)
+	}
+
+	// 不同采集池允许同名
+	other := &model.MonitorScrapeJob{Name: "node", PoolID: 2, UserID: 1}
+	if err := d.CreateMonitorScrapeJob(ctx, other); err != nil {
+		t.Fatalf("不同采集池下同名任务应允许创建: %v", err)
+	}
+
+	// 改名为同池内已有名称应冲突，更新自身不算冲突
+	second := &model.MonitorScrapeJob{Name: "mysql", PoolID: 1, UserID: 1}
+	if err := d.CreateMonitorScrapeJob(ctx, second); err != nil {
+		t.Fatalf("创建采集任务失败: %v", err)
+	}
+	second.Name = "node"
+	if err := d.UpdateMonitorScrapeJob(ctx, second); !errors.Is(err, ErrScrapeJobNameConflict) {
+		t.Fatalf("更新为同池内已有名称应返回冲突, 实际 %v", err)
+	}
+	if err := d.UpdateMonitorScrapeJob(ctx, first); err != nil {
+		t.Fatalf("更新自身不应视为冲突: %v", err)
+	}
+
+	// 已删除的任务不参与唯一性校验
+	if err := d.DeleteMonitorScrapeJob(ctx, first.ID); err != nil {
+		t.Fatalf("删除采集任务失败: %v", err)
+	}
+	if err := d.CreateMonitorScrapeJob(ctx, &model.MonitorScrapeJob{Name: "node", PoolID: 1, UserID: 1}); err != nil {
+		t.Fatalf("删除后应允许重新创建同名任务: %v", err)
+	}
+}
@@ -74,7 +74,7 @@ func getTime() int64 {
 func (s *scrapePoolDAO) GetAllMonitorScrapePool(ctx context.Context) ([]*model.MonitorScrapePool, error) {
 	var pools []*model.MonitorScrapePool
 
-	if err := s.db.WithContext(ctx).Where("deleted_at = ?", 0).Find(&pools).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(notDeleted).Find(&pools).Error; err != nil {
 		s.l.Error("获取所有 MonitorScrapePool 记录失败", zap.Error(err))
 		return nil, err
 	}
@@ -86,7 +86,7 @@ func (s *scrapePoolDAO) GetAllMonitorScrapePool(ctx context.Context) ([]*model.M
 func (s *scrapePoolDAO) GetMonitorScrapePoolList(ctx context.Context, offset, limit int) ([]*model.MonitorScrapePool, error) {
 	var pools []*model.MonitorScrapePool
 
-	if err := s.db.WithContext(ctx).Where("deleted_at = ?", 0).Offset(offset).Limit(limit).Find(&pools).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(notDeleted).Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&pools).Error; err != nil {
 		s.l.Error("获取所有 MonitorScrapePool 记录失败", zap.Error(err))
 		return nil, err
 	}
@@ -99,7 +99,7 @@ func (s *scrapePoolDAO) CreateMonitorScrapePool(ctx context.Context, monitorScra
 	// 检查是否已存在相同名称的pool
 	var count int64
 	if err := s.db.WithContext(ctx).Model(&model.MonitorScrapePool{}).
-		Where("name = ? AND deleted_at = ?", monitorScrapePool.Name, 0).
+		Scopes(notDeleted).Where("name = ?", monitorScrapePool.Name).
 		Count(&count).Error; err != nil {
 		s.l.Error("检查 MonitorScrapePool 是否存在失败", zap.Error(err))
 		return err
@@ -129,7 +129,7 @@ func (s *scrapePoolDAO) GetMonitorScrapePoolById(ctx context.Context, id int) (*
 
 	var pool model.MonitorScrapePool
 
-	if err := s.db.WithContext(ctx).Where("deleted_at = ?", 0).First(&pool, id).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(notDeleted).First(&pool, id).Error; err != nil {
 		s.l.Error("根据 ID 获取 MonitorScrapePool 失败", zap.Error(err), zap.Int("id", id))
 		return nil, err
 	}
@@ -148,10 +148,11 @@ func (s *scrapePoolDAO) UpdateMonitorScrapePool(ctx context.Context, monitorScra
 
 	if err := s.db.WithContext(ctx).
 		Model(&model.MonitorScrapePool{}).
-		Where("id = ? AND deleted_at = ?", monitorScrapePool.ID, 0).
+		Scopes(notDeleted).Where("id = ?", monitorScrapePool.ID).
 		Updates(map[string]interface{}{
 			"name":                    monitorScrapePool.Name,
 			"prometheus_instances":    monitorScrapePool.PrometheusInstances,
+			"prometheus_port":         monitorScrapePool.PrometheusPort,
 			"alert_manager_instances": monitorScrapePool.AlertManagerInstances,
 			"scrape_interval":         monitorScrapePool.ScrapeInterval,
 			"scrape_timeout":          monitorScrapePool.ScrapeTimeout,
@@ -182,7 +183,7 @@ func (s *scrapePoolDAO) DeleteMonitorScrapePool(ctx context.Context, poolId int)
 
 	result := s.db.WithContext(ctx).
 		Model(&model.MonitorScrapePool{}).
-		Where("id = ? AND deleted_at = ?", poolId, 0).
+		Scopes(notDeleted).Where("id = ?", poolId).
 		Update("deleted_at", getTime())
 
 	if result.Error != nil {
@@ -206,7 +207,8 @@ func (s *scrapePoolDAO) SearchMonitorScrapePoolsByName(ctx context.Context, name
 	var pools []*model.MonitorScrapePool
 
 	if err := s.db.WithContext(ctx).
-		Where("LOWER(name) LIKE ? AND deleted_at = ?", "%"+strings.ToLower(name)+"%", 0).
+		Scopes(notDeleted).Where("LOWER(name) LIKE ?", "%"+strings.ToLower(name)+"%").
+		Order("created_at DESC, id DESC").
 		Find(&pools).Error; err != nil {
 		s.l.Error("通过名称搜索 MonitorScrapePool 失败", zap.Error(err))
 		return nil, err
@@ -220,7 +222,7 @@ func (s *scrapePoolDAO) GetMonitorScrapePoolSupportedAlert(ctx context.Context)
 	var pools []*model.MonitorScrapePool
 
 	if err := s.db.WithContext(ctx).
-		Where("support_alert = ? AND deleted_at = ?", true, 0).
+		Scopes(notDeleted).Where("support_alert = ?", true).
 		Find(&pools).Error; err != nil {
 		s.l.Error("获取支持警报的 MonitorScrapePool 失败", zap.Error(err))
 		return nil, err
@@ -234,7 +236,7 @@ func (s *scrapePoolDAO) GetMonitorScrapePoolSupportedRecord(ctx context.Context)
 	var pools []*model.MonitorScrapePool
 
 	if err := s.db.WithContext(ctx).
-		Where("support_record = ? AND deleted_at = ?", true, 0).
+		Scopes(notDeleted).Where("support_record = ?", true).
 		Find(&pools).Error; err != nil {
 		s.l.Error("获取支持记录规则的 MonitorScrapePool 失败", zap.Error(err))
 		return nil, err
@@ -253,7 +255,7 @@ func (s *scrapePoolDAO) CheckMonitorScrapePoolExists(ctx context.Context, scrape
 
 	if err := s.db.WithContext(ctx).
 		Model(&model.MonitorScrapePool{}).
-		Where("name = ? AND deleted_at = ?", scrapePool.Name, 0).
+		Scopes(notDeleted).Where("name = ?", scrapePool.Name).
 		Count(&count).Error; err != nil {
 		s.l.Error("检查 MonitorScrapePool 是否存在失败", zap.Error(err))
 		return false, err
@@ -266,7 +268,7 @@ func (s *scrapePoolDAO) CheckMonitorScrapePoolExists(ctx context.Context, scrape
 func (s *scrapePoolDAO) GetMonitorScrapePoolTotal(ctx context.Context) (int, error) {
 	var count int64
 
-	if err := s.db.WithContext(ctx).Model(&model.MonitorScrapePool{}).Where("deleted_at = ?", 0).Count(&count).Error; err != nil {
+	if err := s.db.WithContext(ctx).Model(&model.MonitorScrapePool{}).Scopes(notDeleted).Count(&count).Error; err != nil {
 		s.l.Error("获取监控采集池总数失败", zap.Error(err))
 		return 0, err
 	}
@@ -37,6 +37,15 @@ import (
 	"go.uber.org/zap"
 )
 
+// renotifySchedule 持续告警的重复通知间隔，按触发次数逐级拉长，超出后固定为最后一级
+var renotifySchedule = []time.Duration{
+	time.Minute,
+	5 * time.Minute,
+	15 * time.Minute,
+	30 * time.Minute,
+	time.Hour,
+}
+
 type AlertEventDomain struct {
 	Event      *model.MonitorAlertEvent
 	User       *model.User
@@ -82,22 +91,26 @@ func (d *AlertEventDomain) BuildSilence(ctx context.Context, silenceReq *model.A
 
 // MarkAsSilenced 标记为已静默
 func (d *AlertEventDomain) MarkAsSilenced(silenceID string) {
-	d.Event.Status = "已屏蔽"
+	d.Event.Status = string(model.AlertStatusSilenced)
 	d.Event.SilenceID = silenceID
 }
 
 // MarkAsClaimed 标记为已认领
 func (d *AlertEventDomain) MarkAsClaimed() {
 	d.Event.RenLingUserID = int(d.User.ID)
-	d.Event.Status = "已认领"
+	d.Event.Status = string(model.AlertStatusClaimed)
 }
 
-// BuildClaimMessage 构建认领消息
+// BuildClaimMessage 构建认领消息，用户未设置真实姓名时使用登录名
 func (d *AlertEventDomain) BuildClaimMessage() string {
+	name := d.User.RealName
+	if name == "" {
+		name = d.User.Username
+	}
 	return fmt.Sprintf(
-		"**%s** 认领了告警事件: %s, 当前时间: %s",
-		d.User.RealName,
+		"告警事件: %s 已被 **%s** 认领, 当前时间: %s",
 		d.Event.AlertName,
+		name,
 		time.Now().Format("2006-01-02 15:04:05"),
 	)
 }
@@ -112,3 +125,24 @@ func (d *AlertEventDomain) Validate() error {
 	}
 	return nil
 }
+
+// ShouldRenotify 根据触发次数和最近通知时间判断持续告警是否需要再次通知
+func ShouldRenotify(event *model.MonitorAlertEvent, now time.Time) bool {
+	if event == nil {
+		return false
+	}
+	// 从未通知过的事件立即通知
+	if event.LastNotifiedAt <= 0 {
+		return true
+	}
+
+	step := event.EventTimes - 1
+	if step < 0 {
+		step = 0
+	}
+	if step >= len(renotifySchedule) {
+		step = len(renotifySchedule) - 1
+	}
+
+	return now.Sub(time.Unix(event.LastNotifiedAt, 0)) >= renotifySchedule[step]
+}
@@ -0,0 +1,100 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+)
+
+// TestMarkedStatusesAreValid 领域对象写入的状态必须能通过状态校验
+func TestMarkedStatusesAreValid(t *testing.T) {
+	d := &AlertEventDomain{Event: &model.MonitorAlertEvent{}, User: &model.User{}}
+
+	d.MarkAsSilenced("silence-1")
+	if d.Event.Status != string(model.AlertStatusSilenced) || !model.IsValidStatus(d.Event.Status) {
+		t.Fatalf("屏蔽后状态 %q 不是合法状态", d.Event.Status)
+	}
+
+	d.MarkAsClaimed()
+	if d.Event.Status != string(model.AlertStatusClaimed) || !model.IsValidStatus(d.Event.Status) {
+		t.Fatalf("认领后状态 %q 不是合法状态", d.Event.Status)
+	}
+}
+
+func TestIsValidStatus(t *testing.T) {
+	for _, s := range []model.AlertStatus{
+		model.AlertStatusFiring,
+		model.AlertStatusResolved,
+		model.AlertStatusUpgraded,
+		model.AlertStatusSilenced,
+		model.AlertStatusClaimed,
+	} {
+		if !model.IsValidStatus(string(s)) {
+			t.Errorf("状态 %q 应当合法", s)
+		}
+	}
+	for _, s := range []string{"", "silenced", "claimed", "renlinged"} {
+		if model.IsValidStatus(s) {
+			t.Errorf("状态 %q 不应合法", s)
+		}
+	}
+}
+
+func TestShouldRenotifySchedule(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+
+	for _, c := range []struct {
+		eventTimes int
+		interval   time.Duration
+	}{
+		{1, time.Minute},
+		{2, 5 * time.Minute},
+		{3, 15 * time.Minute},
+		{4, 30 * time.Minute},
+		{5, time.Hour},
+		{20, time.Hour},  // 超出后固定为最后一级
+		{0, time.Minute}, // 触发次数缺失时按第一级处理
+	} {
+		due := &model.MonitorAlertEvent{EventTimes: c.eventTimes, LastNotifiedAt: now.Add(-c.interval).Unix()}
+		if !ShouldRenotify(due, now) {
+			t.Fatalf("第 %d 次触发距上次通知 %v 时应再次通知", c.eventTimes, c.interval)
+		}
+		early := &model.MonitorAlertEvent{EventTimes: c.eventTimes, LastNotifiedAt: now.Add(-c.interval + time.Second).Unix()}
+		if ShouldRenotify(early, now) {
+			t.Fatalf("第 %d 次触发距上次通知不足 %v 时不应通知", c.eventTimes, c.interval)
+		}
+	}
+
+	if !ShouldRenotify(&model.MonitorAlertEvent{EventTimes: 3}, now) {
+		t.Fatal("从未通知过的事件应立即通知")
+	}
+	if ShouldRenotify(nil, now) {
+		t.Fatal("空事件不应通知")
+	}
+}
@@ -0,0 +1,134 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+package domain
+
+import (
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+)
+
+// InhibitEvaluator 根据抑制规则判断告警事件是否被其他活跃告警抑制
+type InhibitEvaluator struct {
+	rules []*model.MonitorInhibitRule
+}
+
+// NewInhibitEvaluator 创建抑制规则评估器，未启用或缺少匹配标签的规则会被忽略
+func NewInhibitEvaluator(rules []*model.MonitorInhibitRule) *InhibitEvaluator {
+	enabled := make([]*model.MonitorInhibitRule, 0, len(rules))
+	for _, rule := range rules {
+		if rule != nil && rule.Enable && len(rule.SourceMatchers) > 0 && len(rule.TargetMatchers) > 0 {
+			enabled = append(enabled, rule)
+		}
+	}
+	return &InhibitEvaluator{rules: enabled}
+}
+
+// IsInhibited 判断事件是否被 activeEvents 中的任一源告警抑制
+func (e *InhibitEvaluator) IsInhibited(event *model.MonitorAlertEvent, activeEvents []*model.MonitorAlertEvent) bool {
+	if event == nil {
+		return false
+	}
+
+	for _, rule := range e.rules {
+		if !event.Labels.Contains(rule.TargetMatchers) {
+			continue
+		}
+		// 同时匹配源和目标的告警不能被同样同时匹配两侧的告警抑制（包括自身），避免互相抑制
+		targetIsSource := event.Labels.Contains(rule.SourceMatchers)
+
+		for _, source := range activeEvents {
+			if source == nil || sameEvent(source, event) || source.Status == string(model.AlertStatusResolved) {
+				continue
+			}
+			if !source.Labels.Contains(rule.SourceMatchers) {
+				continue
+			}
+			if targetIsSource && source.Labels.Contains(rule.TargetMatchers) {
+				continue
+			}
+			if equalLabels(rule.Equal, source.Labels, event.Labels) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// SourceCandidateLabels 返回可能抑制 event 的源告警必须携带的标签集合，每条以 event 为目标的规则对应一组：
+// 规则的源匹配标签加上 event 在 equal 标签上的非空取值。事件不是任何规则的目标时返回空，调用方无需查询源告警
+func (e *InhibitEvaluator) SourceCandidateLabels(event *model.MonitorAlertEvent) []model.Labels {
+	if event == nil {
+		return nil
+	}
+
+	var candidates []model.Labels
+	for _, rule := range e.rules {
+		if !event.Labels.Contains(rule.TargetMatchers) {
+			continue
+		}
+
+		required := make(model.Labels, len(rule.SourceMatchers)+len(rule.Equal))
+		for key, val := range rule.SourceMatchers {
+			required[key] = val
+		}
+		for _, key := range rule.Equal {
+			// 事件缺少该标签时源告警也必须缺少，无法通过标签索引筛选
+			if val := event.Labels[key]; val != "" {
+				required[key] = val
+			}
+		}
+		candidates = append(candidates, required)
+	}
+	return candidates
+}
+
+// MarkInhibited 返回 activeEvents 中被抑制的事件ID集合，这些事件不应发送通知
+func (e *InhibitEvaluator) MarkInhibited(activeEvents []*model.MonitorAlertEvent) map[int]struct{} {
+	inhibited := make(map[int]struct{})
+	for _, event := range activeEvents {
+		if e.IsInhibited(event, activeEvents) {
+			inhibited[event.ID] = struct{}{}
+		}
+	}
+	return inhibited
+}
+
+// equalLabels 判断两组标签在 keys 上的取值是否全部相同，缺失的标签按空字符串比较
+func equalLabels(keys []string, a, b model.Labels) bool {
+	for _, key := range keys {
+		if a[key] != b[key] {
+			return false
+		}
+	}
+	return true
+}
+
+// sameEvent 判断两个事件是否为同一告警
+func sameEvent(a, b *model.MonitorAlertEvent) bool {
+	if a.ID > 0 && b.ID > 0 {
+		return a.ID == b.ID
+	}
+	return a.Fingerprint != "" && a.Fingerprint == b.Fingerprint
+}
@@ -0,0 +1,100 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package domain
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+)
+
+func newNodeDownInhibitor() *InhibitEvaluator {
+	return NewInhibitEvaluator([]*model.MonitorInhibitRule{{
+		Enable:         true,
+		SourceMatchers: model.Labels{"alertname": "NodeDown"},
+		TargetMatchers: model.Labels{"severity": "warning"},
+		Equal:          model.StringList{"instance"},
+	}})
+}
+
+func TestSourceCandidateLabels(t *testing.T) {
+	e := newNodeDownInhibitor()
+
+	target := &model.MonitorAlertEvent{Labels: model.Labels{"severity": "warning", "instance": "n1"}}
+	want := []model.Labels{{"alertname": "NodeDown", "instance": "n1"}}
+	if got := e.SourceCandidateLabels(target); !reflect.DeepEqual(got, want) {
+		t.Fatalf("源告警候选标签期望 %v, 实际 %v", want, got)
+	}
+
+	// 缺少 equal 标签时不以空值筛选
+	noInstance := &model.MonitorAlertEvent{Labels: model.Labels{"severity": "warning"}}
+	want = []model.Labels{{"alertname": "NodeDown"}}
+	if got := e.SourceCandidateLabels(noInstance); !reflect.DeepEqual(got, want) {
+		t.Fatalf("源告警候选标签期望 %v, 实际 %v", want, got)
+	}
+
+	notTarget := &model.MonitorAlertEvent{Labels: model.Labels{"severity": "critical", "instance": "n1"}}
+	if got := e.SourceCandidateLabels(notTarget); len(got) != 0 {
+		t.Fatalf("不是任何规则目标的事件无需查询源告警, 实际 %v", got)
+	}
+}
+
+func TestIsInhibited(t *testing.T) {
+	e := newNodeDownInhibitor()
+	target := &model.MonitorAlertEvent{ID: 1, Labels: model.Labels{"severity": "warning", "instance": "n1"}}
+
+	cases := []struct {
+		name   string
+		source *model.MonitorAlertEvent
+		want   bool
+	}{
+		{"同实例源告警抑制", &model.MonitorAlertEvent{ID: 2, Status: string(model.AlertStatusFiring), Labels: model.Labels{"alertname": "NodeDown", "instance": "n1"}}, true},
+		{"不同实例不抑制", &model.MonitorAlertEvent{ID: 2, Status: string(model.AlertStatusFiring), Labels: model.Labels{"alertname": "NodeDown", "instance": "n2"}}, false},
+		{"已恢复的源告警不抑制", &model.MonitorAlertEvent{ID: 2, Status: string(model.AlertStatusResolved), Labels: model.Labels{"alertname": "NodeDown", "instance": "n1"}}, false},
+		{"源匹配标签不满足不抑制", &model.MonitorAlertEvent{ID: 2, Status: string(model.AlertStatusFiring), Labels: model.Labels{"alertname": "HighCPU", "instance": "n1"}}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := e.IsInhibited(target, []*model.MonitorAlertEvent{c.source}); got != c.want {
+				t.Fatalf("抑制结果期望 %v, 实际 %v", c.want, got)
+			}
+		})
+	}
+}
+
+func TestLabelsContains(t *testing.T) {
+	labels := model.Labels{"alertname": "NodeDown", "instance": "n1"}
+	if !labels.Contains(nil) {
+		t.Fatal("空匹配标签应匹配任意标签")
+	}
+	if !labels.Contains(model.Labels{"instance": "n1"}) {
+		t.Fatal("包含全部匹配标签时应匹配")
+	}
+	if labels.Contains(model.Labels{"instance": "n2"}) || labels.Contains(model.Labels{"team": "ops"}) {
+		t.Fatal("取值不同或缺少标签时不应匹配")
+	}
+}
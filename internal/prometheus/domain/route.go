@@ -0,0 +1,74 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package domain
+
+import (
+	"context"
+	"sort"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+)
+
+// SendGroupRouter 按发送组的标签路由规则为缺少 alert_send_group 标签的告警选择发送组，
+// 路由顺序在构建时排好，匹配时只做标签比较
+type SendGroupRouter struct {
+	routes         []*model.MonitorSendGroup
+	defaultGroupID int
+}
+
+// NewSendGroupRouter 创建发送组路由器，defaultGroupID 为均未匹配时使用的默认发送组，0 表示不设默认发送组；
+// 已删除、未启用或未配置匹配标签的发送组不参与路由，避免匹配所有告警；
+// 按 route_priority 升序排列，同优先级时匹配标签更多的规则优先，再按ID升序
+func NewSendGroupRouter(sendGroups []*model.MonitorSendGroup, defaultGroupID int) *SendGroupRouter {
+	routes := make([]*model.MonitorSendGroup, 0, len(sendGroups))
+	for _, sendGroup := range sendGroups {
+		if sendGroup != nil && sendGroup.DeletedAt == 0 && sendGroup.Enable && len(sendGroup.RouteMatchers) > 0 {
+			routes = append(routes, sendGroup)
+		}
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].RoutePriority != routes[j].RoutePriority {
+			return routes[i].RoutePriority < routes[j].RoutePriority
+		}
+		if len(routes[i].RouteMatchers) != len(routes[j].RouteMatchers) {
+			return len(routes[i].RouteMatchers) > len(routes[j].RouteMatchers)
+		}
+		return routes[i].ID < routes[j].ID
+	})
+
+	return &SendGroupRouter{routes: routes, defaultGroupID: defaultGroupID}
+}
+
+// ResolveSendGroup 返回第一个匹配告警标签的发送组ID，均未匹配时返回默认发送组，未设置默认发送组时返回0
+func (r *SendGroupRouter) ResolveSendGroup(_ context.Context, labels model.Labels) int {
+	for _, route := range r.routes {
+		if labels.Contains(route.RouteMatchers) {
+			return route.ID
+		}
+	}
+	return r.defaultGroupID
+}
@@ -0,0 +1,79 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package domain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+)
+
+func TestSendGroupRouterRoute(t *testing.T) {
+	router := NewSendGroupRouter([]*model.MonitorSendGroup{
+		{ID: 1, Enable: true, RoutePriority: 10, RouteMatchers: model.Labels{"team": "db"}},
+		{ID: 2, Enable: true, RoutePriority: 10, RouteMatchers: model.Labels{"team": "db", "env": "prod"}},
+		{ID: 3, Enable: true, RoutePriority: 1, RouteMatchers: model.Labels{"service": "payment"}},
+		{ID: 4, Enable: false, RoutePriority: 0, RouteMatchers: model.Labels{"team": "db"}},
+		{ID: 5, Enable: true, DeletedAt: 1, RoutePriority: 0, RouteMatchers: model.Labels{"team": "db"}},
+		{ID: 6, Enable: true, RoutePriority: 0},
+	}, 0)
+
+	cases := []struct {
+		name   string
+		labels model.Labels
+		want   int
+	}{
+		{"同优先级匹配标签多的优先", model.Labels{"team": "db", "env": "prod"}, 2},
+		{"仅匹配部分规则", model.Labels{"team": "db", "env": "dev"}, 1},
+		{"优先级高的规则优先", model.Labels{"team": "db", "env": "prod", "service": "payment"}, 3},
+		{"未匹配返回0", model.Labels{"team": "web"}, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := router.ResolveSendGroup(context.Background(), c.labels); got != c.want {
+				t.Fatalf("路由结果期望 %d, 实际 %d", c.want, got)
+			}
+		})
+	}
+}
+
+func TestSendGroupRouterDefaultGroup(t *testing.T) {
+	ctx := context.Background()
+	router := NewSendGroupRouter([]*model.MonitorSendGroup{
+		{ID: 1, Enable: true, RouteMatchers: model.Labels{"team": "payments"}},
+	}, 9)
+
+	if got := router.ResolveSendGroup(ctx, model.Labels{"team": "payments"}); got != 1 {
+		t.Fatalf("匹配路由规则时期望 1, 实际 %d", got)
+	}
+	if got := router.ResolveSendGroup(ctx, model.Labels{"team": "web"}); got != 9 {
+		t.Fatalf("未匹配时期望默认发送组 9, 实际 %d", got)
+	}
+	if got := NewSendGroupRouter(nil, 9).ResolveSendGroup(ctx, nil); got != 9 {
+		t.Fatalf("没有路由规则时期望默认发送组 9, 实际 %d", got)
+	}
+}
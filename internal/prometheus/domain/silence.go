@@ -0,0 +1,81 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package domain
+
+import (
+	"fmt"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/prometheus/alertmanager/pkg/labels"
+)
+
+// CompiledSilence 预编译匹配器的静默，匹配器在加载时构建一次，匹配每条告警时不再重复编译正则
+type CompiledSilence struct {
+	Silence  *model.MonitorSilence
+	matchers labels.Matchers
+}
+
+// CompileSilence 将静默的匹配器编译为 Alertmanager 匹配器，正则非法时返回错误
+func CompileSilence(silence *model.MonitorSilence) (*CompiledSilence, error) {
+	if silence == nil {
+		return nil, fmt.Errorf("静默不能为空")
+	}
+
+	matchers := make(labels.Matchers, 0, len(silence.Matchers))
+	for _, m := range silence.Matchers {
+		matcher, err := labels.NewMatcher(silenceMatchType(m), m.Name, m.Value)
+		if err != nil {
+			return nil, fmt.Errorf("静默 %d 的匹配器 %s 非法: %w", silence.ID, m.Name, err)
+		}
+		matchers = append(matchers, matcher)
+	}
+
+	return &CompiledSilence{Silence: silence, matchers: matchers}, nil
+}
+
+// ActiveAt 判断静默在 now 时刻是否生效
+func (c *CompiledSilence) ActiveAt(now int64) bool {
+	return c.Silence.StartsAt <= now && c.Silence.EndsAt > now
+}
+
+// Matches 判断事件标签是否满足静默的全部匹配器
+func (c *CompiledSilence) Matches(event *model.MonitorAlertEvent) bool {
+	return matchLabels(event, c.matchers)
+}
+
+// silenceMatchType 将静默 API 的 isRegex/isEqual 组合转换为匹配器类型
+func silenceMatchType(m model.SilenceMatcher) labels.MatchType {
+	switch {
+	case m.IsRegex && m.IsEqual:
+		return labels.MatchRegexp
+	case m.IsRegex:
+		return labels.MatchNotRegexp
+	case !m.IsEqual:
+		return labels.MatchNotEqual
+	default:
+		return labels.MatchEqual
+	}
+}
@@ -0,0 +1,104 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+package domain
+
+import (
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/types"
+)
+
+// LabelMatcher 标签匹配器，Op 取值为 =、!=、=~、!~，正则需匹配整个标签值
+type LabelMatcher struct {
+	Name  string
+	Op    string
+	Value string
+}
+
+// labelMatchTypes 匹配操作符到 Alertmanager 匹配器类型的映射
+var labelMatchTypes = map[string]labels.MatchType{
+	labels.MatchEqual.String():     labels.MatchEqual,
+	labels.MatchNotEqual.String():  labels.MatchNotEqual,
+	labels.MatchRegexp.String():    labels.MatchRegexp,
+	labels.MatchNotRegexp.String(): labels.MatchNotRegexp,
+}
+
+// MatchSilence 判断告警事件的标签是否满足全部标签匹配器，语义与 MatchesSilence 相同；
+// 没有匹配器、操作符不支持或正则非法时视为不匹配
+func MatchSilence(event *model.MonitorAlertEvent, matchers []LabelMatcher) bool {
+	compiled := make(labels.Matchers, 0, len(matchers))
+	for _, m := range matchers {
+		matchType, ok := labelMatchTypes[m.Op]
+		if !ok {
+			return false
+		}
+
+		matcher, err := labels.NewMatcher(matchType, m.Name, m.Value)
+		if err != nil {
+			return false
+		}
+		compiled = append(compiled, matcher)
+	}
+
+	return matchLabels(event, compiled)
+}
+
+// MatchesSilence 判断告警事件的标签是否满足静默的全部匹配器
+// 事件中不存在的标签按空字符串参与匹配，与 Alertmanager 语义一致；静默没有匹配器或匹配器非法时视为不匹配
+func MatchesSilence(event *model.MonitorAlertEvent, silence *types.Silence) bool {
+	if silence == nil {
+		return false
+	}
+
+	matchers := make(labels.Matchers, 0, len(silence.Matchers))
+	for _, m := range silence.Matchers {
+		if m == nil {
+			return false
+		}
+
+		// 重新构建匹配器，确保正则类型的匹配器已编译
+		matcher, err := labels.NewMatcher(m.Type, m.Name, m.Value)
+		if err != nil {
+			return false
+		}
+		matchers = append(matchers, matcher)
+	}
+
+	return matchLabels(event, matchers)
+}
+
+// matchLabels 判断事件标签是否满足全部匹配器，没有匹配器时不匹配任何事件，缺失的标签按空字符串处理
+func matchLabels(event *model.MonitorAlertEvent, matchers labels.Matchers) bool {
+	if event == nil || len(matchers) == 0 {
+		return false
+	}
+
+	for _, m := range matchers {
+		if !m.Matches(event.Labels[m.Name]) {
+			return false
+		}
+	}
+	return true
+}
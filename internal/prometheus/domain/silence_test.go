@@ -0,0 +1,143 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package domain
+
+import (
+	"testing"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/types"
+)
+
+func TestCompiledSilenceMatcherTypes(t *testing.T) {
+	event := &model.MonitorAlertEvent{Labels: map[string]string{"alertname": "HighCPU", "env": "prod"}}
+
+	cases := []struct {
+		name    string
+		matcher model.SilenceMatcher
+		want    bool
+	}{
+		{"相等命中", model.SilenceMatcher{Name: "env", Value: "prod", IsEqual: true}, true},
+		{"相等未命中", model.SilenceMatcher{Name: "env", Value: "dev", IsEqual: true}, false},
+		{"不等命中", model.SilenceMatcher{Name: "env", Value: "dev"}, true},
+		{"不等未命中", model.SilenceMatcher{Name: "env", Value: "prod"}, false},
+		{"正则整值匹配", model.SilenceMatcher{Name: "alertname", Value: "High.*", IsRegex: true, IsEqual: true}, true},
+		{"正则不做部分匹配", model.SilenceMatcher{Name: "alertname", Value: "CPU", IsRegex: true, IsEqual: true}, false},
+		{"反向正则命中", model.SilenceMatcher{Name: "env", Value: "dev|test", IsRegex: true}, true},
+		{"反向正则未命中", model.SilenceMatcher{Name: "env", Value: "prod|test", IsRegex: true}, false},
+		{"缺失标签按空值匹配", model.SilenceMatcher{Name: "team", Value: "", IsEqual: true}, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cs, err := CompileSilence(&model.MonitorSilence{Matchers: model.SilenceMatchers{c.matcher}})
+			if err != nil {
+				t.Fatalf("编译静默失败: %v", err)
+			}
+			if got := cs.Matches(event); got != c.want {
+				t.Fatalf("匹配结果期望 %v, 实际 %v", c.want, got)
+			}
+		})
+	}
+}
+
+func TestCompileSilenceRejectsInvalidRegex(t *testing.T) {
+	_, err := CompileSilence(&model.MonitorSilence{Matchers: model.SilenceMatchers{{Name: "env", Value: "(", IsRegex: true, IsEqual: true}}})
+	if err == nil {
+		t.Fatal("非法正则应返回错误")
+	}
+}
+
+func TestCompiledSilenceWithoutMatchersMatchesNothing(t *testing.T) {
+	cs, err := CompileSilence(&model.MonitorSilence{})
+	if err != nil {
+		t.Fatalf("编译静默失败: %v", err)
+	}
+	if cs.Matches(&model.MonitorAlertEvent{Labels: map[string]string{"env": "prod"}}) {
+		t.Fatal("没有匹配器的静默不应匹配任何事件")
+	}
+}
+
+func TestCompiledSilenceActiveAt(t *testing.T) {
+	cs, err := CompileSilence(&model.MonitorSilence{StartsAt: 100, EndsAt: 200})
+	if err != nil {
+		t.Fatalf("编译静默失败: %v", err)
+	}
+	for now, want := range map[int64]bool{99: false, 100: true, 199: true, 200: false} {
+		if got := cs.ActiveAt(now); got != want {
+			t.Errorf("ActiveAt(%d) 期望 %v, 实际 %v", now, want, got)
+		}
+	}
+}
+
+// TestMatchesSilenceSharesMatcherSemantics Alertmanager 静默与本地静默的匹配语义一致
+func TestMatchesSilenceSharesMatcherSemantics(t *testing.T) {
+	event := &model.MonitorAlertEvent{Labels: map[string]string{"alertname": "HighCPU"}}
+	silence := &types.Silence{Matchers: labels.Matchers{{Type: labels.MatchRegexp, Name: "alertname", Value: "High.*"}}}
+	if !MatchesSilence(event, silence) {
+		t.Fatal("正则匹配器应命中")
+	}
+	if MatchesSilence(event, &types.Silence{}) {
+		t.Fatal("没有匹配器的静默不应命中")
+	}
+}
+
+func TestMatchSilenceLabelMatchers(t *testing.T) {
+	event := &model.MonitorAlertEvent{Labels: map[string]string{"alertname": "HighCPU", "env": "prod"}}
+
+	cases := []struct {
+		name     string
+		matchers []LabelMatcher
+		want     bool
+	}{
+		{"相等命中", []LabelMatcher{{Name: "env", Op: "=", Value: "prod"}}, true},
+		{"相等未命中", []LabelMatcher{{Name: "env", Op: "=", Value: "dev"}}, false},
+		{"不等命中", []LabelMatcher{{Name: "env", Op: "!=", Value: "dev"}}, true},
+		{"不等未命中", []LabelMatcher{{Name: "env", Op: "!=", Value: "prod"}}, false},
+		{"正则整值匹配", []LabelMatcher{{Name: "alertname", Op: "=~", Value: "High.*"}}, true},
+		{"正则不做部分匹配", []LabelMatcher{{Name: "alertname", Op: "=~", Value: "CPU"}}, false},
+		{"反向正则命中", []LabelMatcher{{Name: "env", Op: "!~", Value: "dev|test"}}, true},
+		{"反向正则未命中", []LabelMatcher{{Name: "env", Op: "!~", Value: "prod|test"}}, false},
+		{"缺失标签按空值相等匹配", []LabelMatcher{{Name: "team", Op: "=", Value: ""}}, true},
+		{"缺失标签不等于非空值", []LabelMatcher{{Name: "team", Op: "!=", Value: "ops"}}, true},
+		{"缺失标签不满足非空相等", []LabelMatcher{{Name: "team", Op: "=", Value: "ops"}}, false},
+		{"缺失标签按空值正则匹配", []LabelMatcher{{Name: "team", Op: "=~", Value: "ops|"}}, true},
+		{"全部匹配器满足", []LabelMatcher{{Name: "env", Op: "=", Value: "prod"}, {Name: "alertname", Op: "=~", Value: "High.*"}}, true},
+		{"任一匹配器不满足", []LabelMatcher{{Name: "env", Op: "=", Value: "prod"}, {Name: "alertname", Op: "!~", Value: "High.*"}}, false},
+		{"没有匹配器", nil, false},
+		{"不支持的操作符", []LabelMatcher{{Name: "env", Op: "==", Value: "prod"}}, false},
+		{"非法正则", []LabelMatcher{{Name: "env", Op: "=~", Value: "("}}, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := MatchSilence(event, c.matchers); got != c.want {
+				t.Fatalf("期望 %v, 实际 %v", c.want, got)
+			}
+		})
+	}
+}
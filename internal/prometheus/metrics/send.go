@@ -0,0 +1,93 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// provider 标签取值，与告警通知的 webhook 提供方一致
+const (
+	ProviderFeishu        = "feishu"         // 飞书群聊机器人
+	ProviderFeishuPrivate = "feishu_private" // 飞书应用私聊消息
+)
+
+// SendMetrics 通知发送相关的监控指标，cloudops 和 webhook 两个进程共用同一组指标定义
+type SendMetrics struct {
+	attempts  *prometheus.CounterVec
+	successes *prometheus.CounterVec
+	failures  *prometheus.CounterVec
+	latency   *prometheus.HistogramVec
+}
+
+// NewSendMetrics 创建通知发送指标并注册到 reg，reg 为 nil 时只创建不注册
+func NewSendMetrics(reg prometheus.Registerer) *SendMetrics {
+	m := &SendMetrics{
+		attempts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "cloudops",
+			Subsystem: "notification",
+			Name:      "send_attempts_total",
+			Help:      "通知发送尝试次数",
+		}, []string{"provider"}),
+		successes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "cloudops",
+			Subsystem: "notification",
+			Name:      "send_successes_total",
+			Help:      "通知发送成功次数",
+		}, []string{"provider"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "cloudops",
+			Subsystem: "notification",
+			Name:      "send_failures_total",
+			Help:      "通知发送失败次数",
+		}, []string{"provider"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "cloudops",
+			Subsystem: "notification",
+			Name:      "send_duration_seconds",
+			Help:      "通知发送耗时",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"provider"}),
+	}
+
+	if reg != nil {
+		reg.MustRegister(m.attempts, m.successes, m.failures, m.latency)
+	}
+
+	return m
+}
+
+// Observe 记录一次发送的结果和耗时
+func (m *SendMetrics) Observe(provider string, start time.Time, err error) {
+	m.attempts.WithLabelValues(provider).Inc()
+	m.latency.WithLabelValues(provider).Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.failures.WithLabelValues(provider).Inc()
+		return
+	}
+	m.successes.WithLabelValues(provider).Inc()
+}
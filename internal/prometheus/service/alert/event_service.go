@@ -28,7 +28,9 @@ package alert
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"sync"
 
 	pkg "github.com/GoSimplicity/AI-CloudOps/pkg/utils"
@@ -38,43 +40,59 @@ import (
 	"github.com/GoSimplicity/AI-CloudOps/internal/prometheus/dao/alert"
 	"github.com/GoSimplicity/AI-CloudOps/internal/prometheus/domain"
 	userDao "github.com/GoSimplicity/AI-CloudOps/internal/user/dao"
+	"github.com/GoSimplicity/AI-CloudOps/pkg/cursor"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 // AlertManagerEventService 定义告警事件管理服务接口
 type AlertManagerEventService interface {
-	GetMonitorAlertEventList(ctx context.Context, listReq *model.ListReq) ([]*model.MonitorAlertEvent, error)
+	GetMonitorAlertEventList(ctx context.Context, teamID int, severity string, listReq *model.ListReq) ([]*model.MonitorAlertEvent, error)
+	GetMonitorAlertEventPage(ctx context.Context, teamID int, pageCursor string, size int) (*model.AlertEventPage, error)
+	GetMonitorAlertEventListWithStats(ctx context.Context, teamID int, listReq *model.ListReq) (*model.AlertEventListWithStats, error)
+	SearchMonitorAlertEvents(ctx context.Context, teamID, userID int, req *model.SearchAlertEventRequest) ([]*model.MonitorAlertEvent, int64, error)
+	GetEventsByLabelKV(ctx context.Context, teamID int, key, value string, limit int) ([]*model.MonitorAlertEvent, error)
+	GetAlertEventWithClaimant(ctx context.Context, id int) (*model.AlertEventWithUser, error)
 	EventAlertSilence(ctx context.Context, id int, event *model.AlertEventSilenceRequest, userId int) error
 	EventAlertClaim(ctx context.Context, id int, userId int) error
+	BatchEventAlertClaim(ctx context.Context, request *model.BatchEventAlertClaimRequest, userId int) ([]model.ClaimResult, error)
+	EventAlertUnclaim(ctx context.Context, id int, userId int) error
+	GetEventAuditTrail(ctx context.Context, id int) ([]*model.AlertEventAudit, error)
+	GetNotificationsForEvent(ctx context.Context, id int) ([]*model.NotificationLog, error)
 	BatchEventAlertSilence(ctx context.Context, request *model.BatchEventAlertSilenceRequest, userId int) error
-	GetMonitorAlertEventTotal(ctx context.Context) (int, error)
+	GetMonitorAlertEventTotal(ctx context.Context, teamID int) (int, error)
+	ExportEventsCSV(ctx context.Context, teamID int, start, end int64, w io.Writer) error
+	ImportSilencesFromAlertmanager(ctx context.Context, data []byte) (*model.SilenceImportResult, error)
+	HealthCheck(ctx context.Context) *model.HealthStatus
 }
 
 // alertManagerEventService 实现告警事件管理服务
 type alertManagerEventService struct {
-	dao     alert.AlertManagerEventDAO
-	sendDao alert.AlertManagerSendDAO
-	poolDao alert.AlertManagerPoolDAO
-	cache   cache.MonitorCache
-	userDao userDao.UserDAO
-	l       *zap.Logger
+	dao         alert.AlertManagerEventDAO
+	sendDao     alert.AlertManagerSendDAO
+	poolDao     alert.AlertManagerPoolDAO
+	cache       cache.MonitorCache
+	userDao     userDao.UserDAO
+	l           *zap.Logger
+	cursorCodec *cursor.Codec
 }
 
 // NewAlertManagerEventService 创建告警事件管理服务实例
-func NewAlertManagerEventService(dao alert.AlertManagerEventDAO, cache cache.MonitorCache, l *zap.Logger, userDao userDao.UserDAO, sendDao alert.AlertManagerSendDAO) AlertManagerEventService {
+func NewAlertManagerEventService(dao alert.AlertManagerEventDAO, cache cache.MonitorCache, l *zap.Logger, userDao userDao.UserDAO, sendDao alert.AlertManagerSendDAO, cursorCodec *cursor.Codec) AlertManagerEventService {
 	return &alertManagerEventService{
-		dao:     dao,
-		userDao: userDao,
-		sendDao: sendDao,
-		l:       l,
-		cache:   cache,
+		dao:         dao,
+		userDao:     userDao,
+		sendDao:     sendDao,
+		l:           l,
+		cache:       cache,
+		cursorCodec: cursorCodec,
 	}
 }
 
-// GetMonitorAlertEventList 获取告警事件列表
-func (a *alertManagerEventService) GetMonitorAlertEventList(ctx context.Context, listReq *model.ListReq) ([]*model.MonitorAlertEvent, error) {
+// GetMonitorAlertEventList 获取告警事件列表，severity 不为空时按告警级别过滤
+func (a *alertManagerEventService) GetMonitorAlertEventList(ctx context.Context, teamID int, severity string, listReq *model.ListReq) ([]*model.MonitorAlertEvent, error) {
 	if listReq.Search != "" {
-		events, err := a.dao.SearchMonitorAlertEventByName(ctx, listReq.Search)
+		events, err := a.dao.SearchMonitorAlertEventByName(ctx, teamID, listReq.Search, severity)
 		if err != nil {
 			a.l.Error("搜索告警事件失败", zap.String("search", listReq.Search), zap.Error(err))
 			return nil, err
@@ -85,7 +103,7 @@ func (a *alertManagerEventService) GetMonitorAlertEventList(ctx context.Context,
 	offset := (listReq.Page - 1) * listReq.Size
 	limit := listReq.Size
 
-	events, err := a.dao.GetMonitorAlertEventList(ctx, offset, limit)
+	events, err := a.dao.GetMonitorAlertEventList(ctx, teamID, severity, offset, limit)
 	if err != nil {
 		a.l.Error("获取告警事件列表失败", zap.Error(err))
 		return nil, err
@@ -94,26 +112,128 @@ func (a *alertManagerEventService) GetMonitorAlertEventList(ctx context.Context,
 	return events, nil
 }
 
+// GetMonitorAlertEventListWithStats 获取告警事件分页列表及按状态统计的总数
+func (a *alertManagerEventService) GetMonitorAlertEventListWithStats(ctx context.Context, teamID int, listReq *model.ListReq) (*model.AlertEventListWithStats, error) {
+	offset := (listReq.Page - 1) * listReq.Size
+
+	stats, err := a.dao.GetMonitorAlertEventListWithStats(ctx, teamID, offset, listReq.Size)
+	if err != nil {
+		a.l.Error("获取告警事件列表及状态统计失败", zap.Error(err))
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// SearchMonitorAlertEvents 组合条件搜索告警事件，包含已删除事件的查询仅对管理员开放
+func (a *alertManagerEventService) SearchMonitorAlertEvents(ctx context.Context, teamID, userID int, req *model.SearchAlertEventRequest) ([]*model.MonitorAlertEvent, int64, error) {
+	// 已删除的事件只对管理员可见
+	if req.IncludeDeleted {
+		isAdmin, err := a.userDao.IsAdmin(ctx, userID)
+		if err != nil {
+			a.l.Error("组合搜索告警事件失败: 校验管理员失败", zap.Int("userId", userID), zap.Error(err))
+			return nil, 0, err
+		}
+		if !isAdmin {
+			return nil, 0, fmt.Errorf("%w: 仅管理员可查询已删除的告警事件", alert.ErrPermissionDenied)
+		}
+	}
+
+	offset := (req.Page - 1) * req.Size
+
+	events, total, err := a.dao.SearchMonitorAlertEvents(ctx, teamID, &req.AlertEventFilter, offset, req.Size)
+	if err != nil {
+		a.l.Error("组合搜索告警事件失败", zap.Any("req", req), zap.Error(err))
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
+
+// GetAlertEventWithClaimant 获取告警事件并解析认领人信息，认领人已被删除时仅返回事件
+func (a *alertManagerEventService) GetAlertEventWithClaimant(ctx context.Context, id int) (*model.AlertEventWithUser, error) {
+	event, err := a.dao.GetMonitorAlertEventById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.AlertEventWithUser{Event: event}
+	if event.RenLingUserID <= 0 {
+		return result, nil
+	}
+
+	claimant, err := a.userDao.GetUserBriefByID(ctx, event.RenLingUserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			a.l.Warn("告警事件认领人不存在", zap.Int("id", id), zap.Int("userId", event.RenLingUserID))
+			return result, nil
+		}
+		a.l.Error("获取告警事件认领人失败", zap.Int("id", id), zap.Int("userId", event.RenLingUserID), zap.Error(err))
+		return nil, fmt.Errorf("获取认领人信息失败: %w", err)
+	}
+
+	result.Claimant = claimant
+
+	return result, nil
+}
+
+// GetEventsByLabelKV 按标签键值查询告警事件
+func (a *alertManagerEventService) GetEventsByLabelKV(ctx context.Context, teamID int, key, value string, limit int) ([]*model.MonitorAlertEvent, error) {
+	return a.dao.GetEventsByLabelKV(ctx, teamID, key, value, limit)
+}
+
+// GetMonitorAlertEventPage 基于游标分页获取告警事件列表，返回下一页游标
+func (a *alertManagerEventService) GetMonitorAlertEventPage(ctx context.Context, teamID int, pageCursor string, size int) (*model.AlertEventPage, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("每页数量必须大于0")
+	}
+
+	after, err := a.cursorCodec.Decode(pageCursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// 多取一条用于判断是否还有下一页
+	events, err := a.dao.GetMonitorAlertEventListAfter(ctx, teamID, after, size+1)
+	if err != nil {
+		a.l.Error("按游标获取告警事件列表失败", zap.Error(err))
+		return nil, err
+	}
+
+	page := &model.AlertEventPage{Items: events}
+	if len(events) > size {
+		page.Items = events[:size]
+		last := page.Items[size-1]
+		page.NextCursor = a.cursorCodec.Encode(cursor.Cursor{LastID: last.ID, SortKey: last.CreatedAt})
+	}
+
+	return page, nil
+}
+
 // EventAlertSilence 设置告警事件静默
 func (a *alertManagerEventService) EventAlertSilence(ctx context.Context, id int, event *model.AlertEventSilenceRequest, userId int) error {
 	// 参数校验
 	if id <= 0 {
 		a.l.Error("设置静默失败: 无效的 ID", zap.Int("id", id))
-		return fmt.Errorf("无效的 ID: %d", id)
+		return fmt.Errorf("%w: %d", alert.ErrInvalidID, id)
 	}
 
 	// 获取告警事件信息
 	alertEvent, err := a.dao.GetAlertEventByID(ctx, id)
 	if err != nil {
 		a.l.Error("设置静默失败: 无法获取告警事件", zap.Error(err), zap.Int("id", id))
-		return fmt.Errorf("获取告警事件失败: %v", err)
+		return fmt.Errorf("获取告警事件失败: %w", err)
+	}
+	if alertEvent.SilenceID != "" && alertEvent.Status == string(model.AlertStatusSilenced) {
+		a.l.Warn("设置静默失败: 告警事件已被屏蔽", zap.Int("id", id), zap.String("silenceID", alertEvent.SilenceID))
+		return fmt.Errorf("%w: id=%d", alert.ErrSilenced, id)
 	}
 
 	// 获取用户信息
 	user, err := a.userDao.GetUserByID(ctx, userId)
 	if err != nil {
 		a.l.Error("设置静默失败: 无效的用户ID", zap.Int("userId", userId), zap.Error(err))
-		return fmt.Errorf("无效的用户ID: %d, %v", userId, err)
+		return fmt.Errorf("无效的用户ID: %d, %w", userId, err)
 	}
 
 	// 创建领域对象
@@ -129,14 +249,14 @@ func (a *alertManagerEventService) EventAlertSilence(ctx context.Context, id int
 	silenceData, err := json.Marshal(silence)
 	if err != nil {
 		a.l.Error("设置静默失败: 序列化静默规则失败", zap.Error(err))
-		return fmt.Errorf("序列化静默规则失败: %v", err)
+		return fmt.Errorf("序列化静默规则失败: %w", err)
 	}
 
 	// 获取告警管理器实例
 	alertPool, err := a.poolDao.GetAlertPoolByID(ctx, alertEvent.SendGroup.PoolID)
 	if err != nil {
 		a.l.Error("设置静默失败: 无法获取告警管理器实例", zap.Error(err))
-		return fmt.Errorf("获取告警管理器实例失败: %v", err)
+		return fmt.Errorf("获取告警管理器实例失败: %w", err)
 	}
 
 	if len(alertPool.AlertManagerInstances) == 0 {
@@ -152,7 +272,7 @@ func (a *alertManagerEventService) EventAlertSilence(ctx context.Context, id int
 	silenceID, err := pkg.SendSilenceRequest(ctx, a.l, alertUrl, silenceData)
 	if err != nil {
 		a.l.Error("设置静默失败: 发送静默请求失败", zap.Error(err))
-		return fmt.Errorf("发送静默请求失败: %v", err)
+		return fmt.Errorf("发送静默请求失败: %w", err)
 	}
 
 	// 标记为已静默
@@ -161,7 +281,7 @@ func (a *alertManagerEventService) EventAlertSilence(ctx context.Context, id int
 	// 更新告警事件状态
 	if err := a.dao.UpdateAlertEvent(ctx, alertEvent); err != nil {
 		a.l.Error("设置静默失败: 更新告警事件状态失败", zap.Error(err), zap.Int("id", id))
-		return fmt.Errorf("更新告警事件状态失败: %v", err)
+		return fmt.Errorf("更新告警事件状态失败: %w", err)
 	}
 
 	a.l.Info("设置静默成功", zap.Int("id", id), zap.String("silenceID", silenceID))
@@ -174,21 +294,14 @@ func (a *alertManagerEventService) EventAlertClaim(ctx context.Context, id int,
 	event, err := a.dao.GetMonitorAlertEventById(ctx, id)
 	if err != nil {
 		a.l.Error("认领告警事件失败: 获取告警事件失败", zap.Error(err))
-		return fmt.Errorf("获取告警事件失败: %v", err)
-	}
-
-	// 获取发送组信息
-	sendGroup, err := a.sendDao.GetMonitorSendGroupById(ctx, event.SendGroupID)
-	if err != nil {
-		a.l.Error("认领告警事件失败: 获取发送组失败", zap.Error(err))
-		return fmt.Errorf("获取发送组失败: %v", err)
+		return fmt.Errorf("获取告警事件失败: %w", err)
 	}
 
 	// 获取用户信息
 	user, err := a.userDao.GetUserByID(ctx, userId)
 	if err != nil {
 		a.l.Error("认领告警事件失败: 获取用户信息失败", zap.Error(err))
-		return fmt.Errorf("获取用户信息失败: %v", err)
+		return fmt.Errorf("获取用户信息失败: %w", err)
 	}
 
 	// 创建领域对象
@@ -200,23 +313,145 @@ func (a *alertManagerEventService) EventAlertClaim(ctx context.Context, id int,
 	// 更新数据库
 	if err := a.dao.EventAlertClaim(ctx, event); err != nil {
 		a.l.Error("认领告警事件失败: 更新告警事件失败", zap.Error(err))
-		return fmt.Errorf("更新告警事件失败: %v", err)
+		return fmt.Errorf("更新告警事件失败: %w", err)
 	}
 
-	// 构建通知内容
-	content := eventDomain.BuildClaimMessage()
+	// 通知发送组认领人，发送失败不影响认领结果
+	a.notifyClaim(ctx, eventDomain)
+
+	a.l.Info("认领告警事件成功", zap.Int("id", id), zap.Int("userId", userId))
+	return nil
+}
 
-	// 发送飞书通知
-	url := fmt.Sprintf("https://open.feishu.cn/open-apis/bot/v2/hook/%s", sendGroup.FeiShuQunRobotToken)
-	if err = a.dao.SendMessageToGroup(ctx, url, content); err != nil {
-		a.l.Error("发送飞书通知失败", zap.Error(err))
-		// 不影响主流程,仅记录日志
+// BatchEventAlertClaim 批量认领告警事件，返回每个事件的认领结果；
+// AllOrNothing 模式下在同一事务中认领，任一事件失败则全部回滚
+func (a *alertManagerEventService) BatchEventAlertClaim(ctx context.Context, request *model.BatchEventAlertClaimRequest, userId int) ([]model.ClaimResult, error) {
+	if request == nil || len(request.IDs) == 0 {
+		a.l.Error("批量认领失败: 未提供事件ID")
+		return nil, fmt.Errorf("%w: 未提供有效的事件ID列表", alert.ErrInvalidID)
 	}
 
-	a.l.Info("认领告警事件成功", zap.Int("id", id), zap.Int("userId", userId))
+	user, err := a.userDao.GetUserByID(ctx, userId)
+	if err != nil {
+		a.l.Error("批量认领失败: 无效的用户ID", zap.Int("userId", userId), zap.Error(err))
+		return nil, fmt.Errorf("无效的用户ID: %d, %w", userId, err)
+	}
+
+	results := make([]model.ClaimResult, len(request.IDs))
+	claimed := make([]*domain.AlertEventDomain, len(request.IDs))
+
+	// claimAll 逐个认领并记录结果，返回失败数量
+	claimAll := func(eventDao alert.AlertManagerEventDAO) int {
+		failed := 0
+		for i, id := range request.IDs {
+			results[i] = model.ClaimResult{ID: id}
+			eventDomain, err := a.claimOne(ctx, eventDao, id, user)
+			if err != nil {
+				results[i].Error = err.Error()
+				failed++
+				continue
+			}
+			results[i].OK = true
+			claimed[i] = eventDomain
+		}
+		return failed
+	}
+
+	if request.AllOrNothing {
+		errRollback := errors.New("存在认领失败的事件")
+		err := a.dao.WithTransaction(ctx, func(txDAO alert.AlertManagerEventDAO) error {
+			if claimAll(txDAO) > 0 {
+				return errRollback
+			}
+			return nil
+		})
+		if err != nil {
+			if !errors.Is(err, errRollback) {
+				a.l.Error("批量认领失败: 事务提交失败", zap.Error(err))
+				return nil, fmt.Errorf("批量认领事务失败: %w", err)
+			}
+			// 事务已回滚，原本成功的事件也需标记为失败
+			for i := range results {
+				if results[i].OK {
+					results[i].OK = false
+					results[i].Error = "其他事件认领失败，已回滚"
+				}
+			}
+			a.l.Warn("批量认领已回滚", zap.Ints("ids", request.IDs), zap.Int("userId", userId))
+			return results, nil
+		}
+	} else {
+		claimAll(a.dao)
+	}
+
+	// 认领结果落库后再发送通知
+	for _, eventDomain := range claimed {
+		if eventDomain != nil {
+			a.notifyClaim(ctx, eventDomain)
+		}
+	}
+
+	a.l.Info("批量认领告警事件完成", zap.Ints("ids", request.IDs), zap.Int("userId", userId))
+	return results, nil
+}
+
+// claimOne 使用给定的 DAO 认领单个告警事件
+func (a *alertManagerEventService) claimOne(ctx context.Context, eventDao alert.AlertManagerEventDAO, id int, user *model.User) (*domain.AlertEventDomain, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("%w: 事件ID=%d", alert.ErrInvalidID, id)
+	}
+
+	event, err := eventDao.GetMonitorAlertEventById(ctx, id)
+	if err != nil {
+		a.l.Error("批量认领跳过: 获取告警事件失败", zap.Error(err), zap.Int("id", id))
+		return nil, fmt.Errorf("获取告警事件失败: %w", err)
+	}
+
+	eventDomain := domain.NewAlertEventDomain(event, user, a.l)
+	eventDomain.MarkAsClaimed()
+
+	if err := eventDao.EventAlertClaim(ctx, event); err != nil {
+		a.l.Error("批量认领跳过: 更新告警事件失败", zap.Error(err), zap.Int("id", id))
+		return nil, fmt.Errorf("更新告警事件失败: %w", err)
+	}
+
+	return eventDomain, nil
+}
+
+// notifyClaim 向事件所属发送组发送认领通知，发送失败仅记录日志
+func (a *alertManagerEventService) notifyClaim(ctx context.Context, eventDomain *domain.AlertEventDomain) {
+	sendGroup, err := a.sendDao.GetMonitorSendGroupById(ctx, eventDomain.Event.SendGroupID)
+	if err != nil {
+		a.l.Error("发送认领通知失败: 获取发送组失败", zap.Error(err), zap.Int("sendGroupId", eventDomain.Event.SendGroupID))
+		return
+	}
+
+	if err := a.dao.SendGroupNotification(ctx, eventDomain.Event.ID, sendGroup, eventDomain.BuildClaimMessage()); err != nil {
+		a.l.Error("发送认领通知失败", zap.Error(err), zap.Int("sendGroupId", sendGroup.ID))
+	}
+}
+
+// EventAlertUnclaim 取消认领告警事件
+func (a *alertManagerEventService) EventAlertUnclaim(ctx context.Context, id int, userId int) error {
+	if err := a.dao.EventAlertUnclaim(ctx, id, userId); err != nil {
+		a.l.Error("取消认领告警事件失败", zap.Int("id", id), zap.Int("userId", userId), zap.Error(err))
+		return err
+	}
+
+	a.l.Info("取消认领告警事件成功", zap.Int("id", id), zap.Int("userId", userId))
 	return nil
 }
 
+// GetEventAuditTrail 获取告警事件的认领审计记录
+func (a *alertManagerEventService) GetEventAuditTrail(ctx context.Context, id int) ([]*model.AlertEventAudit, error) {
+	return a.dao.GetEventAuditTrail(ctx, id)
+}
+
+// GetNotificationsForEvent 获取告警事件的通知投递记录
+func (a *alertManagerEventService) GetNotificationsForEvent(ctx context.Context, id int) ([]*model.NotificationLog, error) {
+	return a.dao.GetNotificationsForEvent(ctx, id)
+}
+
 // BatchEventAlertSilence 批量设置告警事件静默
 func (a *alertManagerEventService) BatchEventAlertSilence(ctx context.Context, request *model.BatchEventAlertSilenceRequest, userId int) error {
 	// 参数校验
@@ -229,7 +464,7 @@ func (a *alertManagerEventService) BatchEventAlertSilence(ctx context.Context, r
 	user, err := a.userDao.GetUserByID(ctx, userId)
 	if err != nil {
 		a.l.Error("批量设置静默失败: 无效的用户ID", zap.Int("userId", userId), zap.Error(err))
-		return fmt.Errorf("无效的用户ID: %d, %v", userId, err)
+		return fmt.Errorf("无效的用户ID: %d, %w", userId, err)
 	}
 
 	// 并发控制
@@ -259,7 +494,7 @@ func (a *alertManagerEventService) BatchEventAlertSilence(ctx context.Context, r
 
 			if err := a.processSingleSilence(ctx, eventID, request, user); err != nil {
 				mu.Lock()
-				errs = append(errs, fmt.Errorf("事件ID %d: %v", eventID, err))
+				errs = append(errs, fmt.Errorf("事件ID %d: %w", eventID, err))
 				mu.Unlock()
 			}
 		}(id)
@@ -292,7 +527,7 @@ func (a *alertManagerEventService) processSingleSilence(ctx context.Context, eve
 	alertEvent, err := a.dao.GetAlertEventByID(ctx, eventID)
 	if err != nil {
 		a.l.Error("处理单个静默失败: 获取告警事件失败", zap.Error(err), zap.Int("id", eventID))
-		return fmt.Errorf("获取告警事件失败: %v", err)
+		return fmt.Errorf("获取告警事件失败: %w", err)
 	}
 
 	// 创建领域对象
@@ -310,13 +545,13 @@ func (a *alertManagerEventService) processSingleSilence(ctx context.Context, eve
 	// 序列化静默规则
 	silenceData, err := json.Marshal(silence)
 	if err != nil {
-		return fmt.Errorf("序列化静默规则失败: %v", err)
+		return fmt.Errorf("序列化静默规则失败: %w", err)
 	}
 
 	// 获取告警管理器实例
 	alertPool, err := a.poolDao.GetAlertPoolByID(ctx, alertEvent.SendGroup.PoolID)
 	if err != nil {
-		return fmt.Errorf("获取告警管理器实例失败: %v", err)
+		return fmt.Errorf("获取告警管理器实例失败: %w", err)
 	}
 
 	// 发送静默请求
@@ -330,7 +565,7 @@ func (a *alertManagerEventService) processSingleSilence(ctx context.Context, eve
 
 	// 更新告警事件状态
 	if err := a.dao.UpdateAlertEvent(ctx, alertEvent); err != nil {
-		return fmt.Errorf("更新告警事件状态失败: %v", err)
+		return fmt.Errorf("更新告警事件状态失败: %w", err)
 	}
 
 	a.l.Info("处理单个静默成功", zap.Int("id", eventID), zap.String("silenceID", silenceID))
@@ -348,13 +583,32 @@ func (a *alertManagerEventService) sendSilenceRequest(ctx context.Context, alert
 
 	silenceID, err := pkg.SendSilenceRequest(ctx, a.l, alertUrl, silenceData)
 	if err != nil {
-		return "", fmt.Errorf("发送静默请求失败: %v", err)
+		return "", fmt.Errorf("发送静默请求失败: %w", err)
 	}
 
 	return silenceID, nil
 }
 
 // GetMonitorAlertEventTotal 获取监控告警事件总数
-func (a *alertManagerEventService) GetMonitorAlertEventTotal(ctx context.Context) (int, error) {
-	return a.dao.GetMonitorAlertEventTotal(ctx)
+func (a *alertManagerEventService) GetMonitorAlertEventTotal(ctx context.Context, teamID int) (int, error) {
+	return a.dao.GetMonitorAlertEventTotal(ctx, teamID)
+}
+
+// HealthCheck 检查告警子系统依赖的组件是否可用
+func (a *alertManagerEventService) HealthCheck(ctx context.Context) *model.HealthStatus {
+	return a.dao.HealthCheck(ctx)
+}
+
+// ExportEventsCSV 导出团队内指定时间范围的告警事件为 CSV
+func (a *alertManagerEventService) ExportEventsCSV(ctx context.Context, teamID int, start, end int64, w io.Writer) error {
+	return a.dao.ExportEventsCSV(ctx, teamID, start, end, w)
+}
+
+// ImportSilencesFromAlertmanager 导入 Alertmanager 导出的静默
+func (a *alertManagerEventService) ImportSilencesFromAlertmanager(ctx context.Context, data []byte) (*model.SilenceImportResult, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("导入内容不能为空")
+	}
+
+	return a.dao.ImportSilencesFromAlertmanager(ctx, data)
 }
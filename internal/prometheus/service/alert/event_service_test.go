@@ -0,0 +1,375 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package alert
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/GoSimplicity/AI-CloudOps/internal/prometheus/dao/alert"
+	userDao "github.com/GoSimplicity/AI-CloudOps/internal/user/dao"
+	"github.com/GoSimplicity/AI-CloudOps/pkg/cursor"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// stubEventDAO 只实现测试用到的方法，其余方法调用时会因接口为 nil 而 panic
+type stubEventDAO struct {
+	alert.AlertManagerEventDAO
+	searched bool
+	events   []*model.MonitorAlertEvent
+	after    *cursor.Cursor
+	severity string
+}
+
+func (s *stubEventDAO) SearchMonitorAlertEventByName(_ context.Context, _ int, _ string, severity string) ([]*model.MonitorAlertEvent, error) {
+	s.severity = severity
+	return nil, nil
+}
+
+func (s *stubEventDAO) GetMonitorAlertEventListAfter(_ context.Context, _ int, after *cursor.Cursor, limit int) ([]*model.MonitorAlertEvent, error) {
+	s.after = after
+	if limit > len(s.events) {
+		limit = len(s.events)
+	}
+	return s.events[:limit], nil
+}
+
+func (s *stubEventDAO) SearchMonitorAlertEvents(_ context.Context, _ int, _ *model.AlertEventFilter, _, _ int) ([]*model.MonitorAlertEvent, int64, error) {
+	s.searched = true
+	return nil, 0, nil
+}
+
+// stubUserDAO 按 admins 判断用户是否为管理员
+type stubUserDAO struct {
+	userDao.UserDAO
+	admins   map[int]bool
+	briefs   map[int]*model.UserBrief
+	briefErr error
+}
+
+func (s *stubUserDAO) IsAdmin(_ context.Context, userID int) (bool, error) {
+	return s.admins[userID], nil
+}
+
+func TestSearchIncludeDeletedRequiresAdmin(t *testing.T) {
+	eventDAO := &stubEventDAO{}
+	svc := NewAlertManagerEventService(eventDAO, nil, zap.NewNop(), &stubUserDAO{admins: map[int]bool{1: true}}, nil, cursor.NewCodec([]byte("test-secret")))
+	ctx := context.Background()
+
+	req := &model.SearchAlertEventRequest{Page: 1, Size: 10, AlertEventFilter: model.AlertEventFilter{IncludeDeleted: true}}
+	if _, _, err := svc.SearchMonitorAlertEvents(ctx, 0, 2, req); !errors.Is(err, alert.ErrPermissionDenied) {
+		t.Fatalf("非管理员查询已删除事件应返回 ErrPermissionDenied, 实际 %v", err)
+	}
+	if eventDAO.searched {
+		t.Fatal("权限校验失败时不应查询数据库")
+	}
+
+	if _, _, err := svc.SearchMonitorAlertEvents(ctx, 0, 1, req); err != nil || !eventDAO.searched {
+		t.Fatalf("管理员应可查询已删除事件, err=%v", err)
+	}
+
+	eventDAO.searched = false
+	req.IncludeDeleted = false
+	if _, _, err := svc.SearchMonitorAlertEvents(ctx, 0, 2, req); err != nil || !eventDAO.searched {
+		t.Fatalf("不包含已删除事件时普通用户应可查询, err=%v", err)
+	}
+}
+
+func TestGetMonitorAlertEventPageSignsCursorWithInjectedCodec(t *testing.T) {
+	eventDAO := &stubEventDAO{events: []*model.MonitorAlertEvent{
+		{ID: 3, CreatedAt: 300},
+		{ID: 2, CreatedAt: 200},
+		{ID: 1, CreatedAt: 100},
+	}}
+	codec := cursor.NewCodec([]byte("test-secret"))
+	svc := NewAlertManagerEventService(eventDAO, nil, zap.NewNop(), nil, nil, codec)
+	ctx := context.Background()
+
+	page, err := svc.GetMonitorAlertEventPage(ctx, 0, "", 2)
+	if err != nil {
+		t.Fatalf("获取第一页失败: %v", err)
+	}
+	next, err := codec.Decode(page.NextCursor)
+	if err != nil || next == nil || next.LastID != 2 || next.SortKey != 200 {
+		t.Fatalf("下一页游标不符合预期: %+v, %v", next, err)
+	}
+
+	if _, err := svc.GetMonitorAlertEventPage(ctx, 0, page.NextCursor, 2); err != nil || eventDAO.after == nil || eventDAO.after.LastID != 2 {
+		t.Fatalf("使用下一页游标查询失败: after=%+v, err=%v", eventDAO.after, err)
+	}
+
+	forged := cursor.NewCodec([]byte("other-secret")).Encode(cursor.Cursor{LastID: 1})
+	if _, err := svc.GetMonitorAlertEventPage(ctx, 0, forged, 2); !errors.Is(err, cursor.ErrInvalidCursor) {
+		t.Fatalf("其他密钥签名的游标应被拒绝, 实际 %v", err)
+	}
+}
+
+func TestGetMonitorAlertEventListSearchKeepsSeverity(t *testing.T) {
+	eventDAO := &stubEventDAO{}
+	svc := NewAlertManagerEventService(eventDAO, nil, zap.NewNop(), nil, nil, nil)
+
+	if _, err := svc.GetMonitorAlertEventList(context.Background(), 0, "critical", &model.ListReq{Page: 1, Size: 10, Search: "cpu"}); err != nil {
+		t.Fatalf("搜索告警事件失败: %v", err)
+	}
+	if eventDAO.severity != "critical" {
+		t.Fatalf("按名称搜索时应传递告警级别, 实际 %q", eventDAO.severity)
+	}
+}
+
+// claimEventDAO 内存中的认领 DAO，failClaim 中的事件认领时返回错误，WithTransaction 仅在 fn 成功时提交认领结果
+type claimEventDAO struct {
+	alert.AlertManagerEventDAO
+	events    map[int]*model.MonitorAlertEvent
+	failClaim map[int]bool
+	claimed   map[int]int
+	notified  []int
+	messages  []string
+	notifyErr error
+}
+
+func (s *claimEventDAO) GetMonitorAlertEventById(_ context.Context, id int) (*model.MonitorAlertEvent, error) {
+	event, ok := s.events[id]
+	if !ok {
+		return nil, alert.ErrEventNotFound
+	}
+	copied := *event
+	return &copied, nil
+}
+
+func (s *claimEventDAO) EventAlertClaim(_ context.Context, event *model.MonitorAlertEvent) error {
+	if s.failClaim[event.ID] {
+		return errors.New("写入失败")
+	}
+	s.claimed[event.ID] = event.RenLingUserID
+	return nil
+}
+
+func (s *claimEventDAO) WithTransaction(_ context.Context, fn func(txDAO alert.AlertManagerEventDAO) error) error {
+	tx := &claimEventDAO{events: s.events, failClaim: s.failClaim, claimed: map[int]int{}}
+	for id, userID := range s.claimed {
+		tx.claimed[id] = userID
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	s.claimed = tx.claimed
+	return nil
+}
+
+func (s *claimEventDAO) SendGroupNotification(_ context.Context, eventID int, _ *model.MonitorSendGroup, message string) error {
+	s.notified = append(s.notified, eventID)
+	s.messages = append(s.messages, message)
+	return s.notifyErr
+}
+
+// stubSendDAO err 不为空时获取发送组失败
+type stubSendDAO struct {
+	alert.AlertManagerSendDAO
+	err error
+}
+
+func (s *stubSendDAO) GetMonitorSendGroupById(_ context.Context, id int) (*model.MonitorSendGroup, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &model.MonitorSendGroup{ID: id}, nil
+}
+
+// GetUserBriefByID briefErr 不为空时返回该错误，用户不在 briefs 中时视为不存在
+func (s *stubUserDAO) GetUserBriefByID(_ context.Context, id int) (*model.UserBrief, error) {
+	if s.briefErr != nil {
+		return nil, s.briefErr
+	}
+	brief, ok := s.briefs[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return brief, nil
+}
+
+func (s *stubUserDAO) GetUserByID(_ context.Context, id int) (*model.User, error) {
+	return &model.User{ID: id, Username: "zhangsan", RealName: "张三"}, nil
+}
+
+func newClaimEventDAO() *claimEventDAO {
+	return &claimEventDAO{
+		events: map[int]*model.MonitorAlertEvent{
+			1: {ID: 1, Status: string(model.AlertStatusFiring)},
+			2: {ID: 2, Status: string(model.AlertStatusFiring)},
+			3: {ID: 3, Status: string(model.AlertStatusFiring)},
+		},
+		failClaim: map[int]bool{3: true},
+		claimed:   map[int]int{},
+	}
+}
+
+func TestBatchEventAlertClaimMixedResults(t *testing.T) {
+	ctx := context.Background()
+	ids := []int{1, 2, 3, 404}
+
+	// 默认模式下提交成功的事件，逐个返回失败原因
+	eventDAO := newClaimEventDAO()
+	svc := NewAlertManagerEventService(eventDAO, nil, zap.NewNop(), &stubUserDAO{}, &stubSendDAO{}, nil)
+	results, err := svc.BatchEventAlertClaim(ctx, &model.BatchEventAlertClaimRequest{IDs: ids}, 7)
+	if err != nil {
+		t.Fatalf("BatchEventAlertClaim 返回错误: %v", err)
+	}
+	if len(results) != len(ids) {
+		t.Fatalf("应返回 %d 个结果, 实际 %d", len(ids), len(results))
+	}
+	for i, want := range []bool{true, true, false, false} {
+		if results[i].ID != ids[i] || results[i].OK != want || (results[i].Error == "") != want {
+			t.Fatalf("第 %d 个结果不符合预期: %+v", i, results[i])
+		}
+	}
+	if eventDAO.claimed[1] != 7 || eventDAO.claimed[2] != 7 || len(eventDAO.claimed) != 2 {
+		t.Fatalf("成功的事件应已认领, 实际 %v", eventDAO.claimed)
+	}
+	if len(eventDAO.notified) != 2 {
+		t.Fatalf("只应通知认领成功的事件, 实际 %v", eventDAO.notified)
+	}
+
+	// 全部成功或全部回滚模式下，任一失败则不提交任何认领
+	eventDAO = newClaimEventDAO()
+	svc = NewAlertManagerEventService(eventDAO, nil, zap.NewNop(), &stubUserDAO{}, &stubSendDAO{}, nil)
+	results, err = svc.BatchEventAlertClaim(ctx, &model.BatchEventAlertClaimRequest{IDs: ids, AllOrNothing: true}, 7)
+	if err != nil {
+		t.Fatalf("BatchEventAlertClaim 返回错误: %v", err)
+	}
+	for i, result := range results {
+		if result.ID != ids[i] || result.OK || result.Error == "" {
+			t.Fatalf("回滚后所有结果都应失败并给出原因, 第 %d 个: %+v", i, result)
+		}
+	}
+	if results[0].Error != "其他事件认领失败，已回滚" || results[2].Error == results[0].Error {
+		t.Fatalf("应区分回滚和自身失败的原因, 实际 %+v", results)
+	}
+	if len(eventDAO.claimed) != 0 || len(eventDAO.notified) != 0 {
+		t.Fatalf("回滚后不应认领或通知任何事件, 实际 claimed=%v notified=%v", eventDAO.claimed, eventDAO.notified)
+	}
+
+	// 全部成功时正常提交
+	results, err = svc.BatchEventAlertClaim(ctx, &model.BatchEventAlertClaimRequest{IDs: []int{1, 2}, AllOrNothing: true}, 7)
+	if err != nil || !results[0].OK || !results[1].OK {
+		t.Fatalf("全部成功时应提交, 实际 %+v, %v", results, err)
+	}
+	if len(eventDAO.claimed) != 2 || len(eventDAO.notified) != 2 {
+		t.Fatalf("全部成功时应认领并通知所有事件, 实际 claimed=%v notified=%v", eventDAO.claimed, eventDAO.notified)
+	}
+}
+
+func TestEventAlertClaimSucceedsWhenNotifyFails(t *testing.T) {
+	ctx := context.Background()
+
+	for _, c := range []struct {
+		name      string
+		notifyErr error
+		sendDAO   *stubSendDAO
+	}{
+		{"通知成功", nil, &stubSendDAO{}},
+		{"发送通知失败", errors.New("飞书不可用"), &stubSendDAO{}},
+		{"获取发送组失败", nil, &stubSendDAO{err: errors.New("发送组不存在")}},
+	} {
+		eventDAO := newClaimEventDAO()
+		eventDAO.notifyErr = c.notifyErr
+		svc := NewAlertManagerEventService(eventDAO, nil, zap.NewNop(), &stubUserDAO{}, c.sendDAO, nil)
+
+		if err := svc.EventAlertClaim(ctx, 1, 7); err != nil {
+			t.Fatalf("%s: 认领不应因通知失败而失败, 实际 %v", c.name, err)
+		}
+		if eventDAO.claimed[1] != 7 {
+			t.Fatalf("%s: 事件应被用户 7 认领, 实际 %v", c.name, eventDAO.claimed)
+		}
+		if c.sendDAO.err == nil {
+			if len(eventDAO.messages) != 1 || !strings.Contains(eventDAO.messages[0], "已被 **张三** 认领") {
+				t.Fatalf("%s: 应向发送组通知认领人, 实际 %v", c.name, eventDAO.messages)
+			}
+		} else if len(eventDAO.messages) != 0 {
+			t.Fatalf("%s: 获取发送组失败时不应发送通知, 实际 %v", c.name, eventDAO.messages)
+		}
+	}
+
+	// 认领本身失败时返回错误且不发送通知
+	eventDAO := newClaimEventDAO()
+	svc := NewAlertManagerEventService(eventDAO, nil, zap.NewNop(), &stubUserDAO{}, &stubSendDAO{}, nil)
+	if err := svc.EventAlertClaim(ctx, 3, 7); err == nil {
+		t.Fatal("认领写入失败时应返回错误")
+	}
+	if len(eventDAO.notified) != 0 {
+		t.Fatalf("认领失败时不应发送通知, 实际 %v", eventDAO.notified)
+	}
+}
+
+func TestGetAlertEventWithClaimant(t *testing.T) {
+	ctx := context.Background()
+	eventDAO := newClaimEventDAO()
+	eventDAO.events[2].RenLingUserID = 7
+	eventDAO.events[3].RenLingUserID = 8
+	users := &stubUserDAO{briefs: map[int]*model.UserBrief{
+		7: {ID: 7, Username: "zhangsan", RealName: "张三"},
+	}}
+	svc := NewAlertManagerEventService(eventDAO, nil, zap.NewNop(), users, nil, nil)
+
+	// 未认领的事件不查询认领人
+	detail, err := svc.GetAlertEventWithClaimant(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetAlertEventWithClaimant 返回错误: %v", err)
+	}
+	if detail.Event == nil || detail.Event.ID != 1 || detail.Claimant != nil {
+		t.Fatalf("未认领事件期望认领人为空, 实际 %+v", detail)
+	}
+
+	// 已认领的事件带出认领人信息
+	detail, err = svc.GetAlertEventWithClaimant(ctx, 2)
+	if err != nil {
+		t.Fatalf("GetAlertEventWithClaimant 返回错误: %v", err)
+	}
+	if detail.Event.ID != 2 || detail.Claimant == nil || detail.Claimant.RealName != "张三" {
+		t.Fatalf("期望认领人为张三, 实际 %+v", detail)
+	}
+
+	// 认领人已被删除时仅返回事件
+	detail, err = svc.GetAlertEventWithClaimant(ctx, 3)
+	if err != nil {
+		t.Fatalf("认领人不存在时不应返回错误, 实际 %v", err)
+	}
+	if detail.Event.ID != 3 || detail.Claimant != nil {
+		t.Fatalf("认领人不存在时期望认领人为空, 实际 %+v", detail)
+	}
+
+	// 事件不存在或查询认领人失败时返回错误
+	if _, err := svc.GetAlertEventWithClaimant(ctx, 404); !errors.Is(err, alert.ErrEventNotFound) {
+		t.Fatalf("期望 ErrEventNotFound, 实际 %v", err)
+	}
+	users.briefErr = errors.New("数据库不可用")
+	if _, err := svc.GetAlertEventWithClaimant(ctx, 2); err == nil {
+		t.Fatal("查询认领人失败时应返回错误")
+	}
+}
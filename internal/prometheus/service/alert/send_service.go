@@ -92,6 +92,10 @@ func (a *alertManagerSendService) GetMonitorSendGroupList(ctx context.Context, l
 
 // CreateMonitorSendGroup 创建发送组
 func (a *alertManagerSendService) CreateMonitorSendGroup(ctx context.Context, monitorSendGroup *model.MonitorSendGroup) error {
+	if err := validateChannelType(monitorSendGroup.ChannelType); err != nil {
+		return err
+	}
+
 	// 检查发送组是否已存在
 	exists, err := a.dao.CheckMonitorSendGroupNameExists(ctx, monitorSendGroup)
 	if err != nil {
@@ -114,6 +118,10 @@ func (a *alertManagerSendService) CreateMonitorSendGroup(ctx context.Context, mo
 
 // UpdateMonitorSendGroup 更新发送组
 func (a *alertManagerSendService) UpdateMonitorSendGroup(ctx context.Context, group *model.MonitorSendGroup) error {
+	if err := validateChannelType(group.ChannelType); err != nil {
+		return err
+	}
+
 	// 检查发送组是否存在
 	exists, err := a.dao.CheckMonitorSendGroupExists(ctx, group)
 	if err != nil {
@@ -256,3 +264,11 @@ func (a *alertManagerSendService) GetMonitorSendGroupTotal(ctx context.Context)
 func (a *alertManagerSendService) GetMonitorSendGroupAll(ctx context.Context) ([]*model.MonitorSendGroup, error) {
 	return a.dao.GetMonitorSendGroups(ctx)
 }
+
+// validateChannelType 校验发送组配置的通知渠道已注册，为空时使用默认的飞书渠道
+func validateChannelType(channel string) error {
+	if channel != "" && !alert.IsRegisteredChannel(channel) {
+		return fmt.Errorf("不支持的通知渠道: %s, 可选值: %s", channel, strings.Join(alert.RegisteredChannels(), ","))
+	}
+	return nil
+}
@@ -107,18 +107,7 @@ func (s *scrapeJobService) GetMonitorScrapeJobList(ctx context.Context, listReq
 
 // CreateMonitorScrapeJob 创建监控采集 Job
 func (s *scrapeJobService) CreateMonitorScrapeJob(ctx context.Context, monitorScrapeJob *model.MonitorScrapeJob) error {
-	// 检查抓取作业是否已存在
-	exists, err := s.dao.CheckMonitorScrapeJobExists(ctx, monitorScrapeJob.Name)
-	if err != nil {
-		s.l.Error("创建抓取作业失败：检查抓取作业是否存在时出错", zap.Error(err))
-		return err
-	}
-
-	if exists {
-		return errors.New("抓取作业已存在")
-	}
-
-	// 检查采集池是否存在
+	// 检查采集池是否存在（同一采集池内的名称唯一性由 DAO 保证）
 	poolExists, err := s.dao.CheckMonitorInstanceExists(ctx, monitorScrapeJob.PoolID)
 	if err != nil {
 		s.l.Error("创建抓取作业失败：检查采集池是否存在时出错", zap.Error(err))
@@ -145,26 +134,13 @@ func (s *scrapeJobService) UpdateMonitorScrapeJob(ctx context.Context, monitorSc
 		return errors.New("无效的抓取作业ID")
 	}
 
-	// 先获取原有的抓取作业信息
-	oldJob, err := s.dao.GetMonitorScrapeJobById(ctx, monitorScrapeJob.ID)
-	if err != nil {
+	// 检查抓取作业是否存在
+	if _, err := s.dao.GetMonitorScrapeJobById(ctx, monitorScrapeJob.ID); err != nil {
 		s.l.Error("更新抓取作业失败：获取原有抓取作业信息出错", zap.Error(err))
 		return err
 	}
 
-	// 如果名称发生变化,需要检查新名称是否已存在
-	if oldJob.Name != monitorScrapeJob.Name {
-		exists, err := s.dao.CheckMonitorScrapeJobExists(ctx, monitorScrapeJob.Name)
-		if err != nil {
-			s.l.Error("更新抓取作业失败：检查抓取作业名称是否存在时出错", zap.Error(err))
-			return err
-		}
-		if exists {
-			return errors.New("抓取作业名称已存在")
-		}
-	}
-
-	// 更新抓取作业
+	// 更新抓取作业（同一采集池内的名称唯一性由 DAO 保证）
 	if err := s.dao.UpdateMonitorScrapeJob(ctx, monitorScrapeJob); err != nil {
 		s.l.Error("更新抓取作业失败", zap.Error(err))
 		return err
@@ -28,6 +28,7 @@ package yaml
 import (
 	"context"
 
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
 	alertCache "github.com/GoSimplicity/AI-CloudOps/internal/prometheus/cache"
 )
 
@@ -36,6 +37,8 @@ type ConfigYamlService interface {
 	GetMonitorAlertManagerYaml(ctx context.Context, ip string) string
 	GetMonitorPrometheusAlertRuleYaml(ctx context.Context, ip string) string
 	GetMonitorPrometheusRecordYaml(ctx context.Context, ip string) string
+	PreviewMonitorPrometheusYaml(ctx context.Context) ([]*model.ConfigPreview, error)
+	PreviewMonitorAlertManagerYaml(ctx context.Context) ([]*model.ConfigPreview, error)
 }
 
 type configYamlService struct {
@@ -69,3 +72,13 @@ func (c *configYamlService) GetMonitorPrometheusAlertRuleYaml(ctx context.Contex
 func (c *configYamlService) GetMonitorPrometheusRecordYaml(ctx context.Context, ip string) string {
 	return c.recordCache.GetPrometheusRecordRuleConfigYamlByIp(ip)
 }
+
+// PreviewMonitorPrometheusYaml 预览 Prometheus 配置与当前下发配置的差异，不触发下发
+func (c *configYamlService) PreviewMonitorPrometheusYaml(ctx context.Context) ([]*model.ConfigPreview, error) {
+	return c.promCache.PreviewPrometheusMainConfig(ctx)
+}
+
+// PreviewMonitorAlertManagerYaml 预览 AlertManager 配置与当前下发配置的差异，不触发下发
+func (c *configYamlService) PreviewMonitorAlertManagerYaml(ctx context.Context) ([]*model.ConfigPreview, error) {
+	return c.alertCache.PreviewAlertManagerMainConfig(ctx)
+}
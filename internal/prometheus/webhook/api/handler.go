@@ -27,18 +27,18 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
-	"strings"
 	"sync"
 	"time"
 
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
 	"github.com/GoSimplicity/AI-CloudOps/internal/prometheus/webhook/dao"
+	"github.com/GoSimplicity/AI-CloudOps/internal/prometheus/webhook/request"
 	"github.com/GoSimplicity/AI-CloudOps/pkg/utils"
 	"github.com/gin-gonic/gin"
-	"github.com/prometheus/alertmanager/notify/webhook"
 	"github.com/prometheus/alertmanager/pkg/labels"
-	"github.com/prometheus/alertmanager/template"
 	"github.com/prometheus/alertmanager/types"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -48,13 +48,13 @@ import (
 type WebHookHandler struct {
 	l          *zap.Logger
 	dao        dao.WebhookDao
-	alertQueue chan template.Alert // 告警队列，用于异步处理
-	workerWG   sync.WaitGroup      // 工作组用于等待所有工作者完成
-	quitChan   chan struct{}       // 用于优雅地关闭工作者的通道
+	alertQueue chan *model.MonitorAlertEvent // 告警队列，用于异步处理
+	workerWG   sync.WaitGroup                // 工作组用于等待所有工作者完成
+	quitChan   chan struct{}                 // 用于优雅地关闭工作者的通道
 }
 
 // NewWebHookHandler 创建一个新的WebHookHandler实例，并启动告警处理工作者
-func NewWebHookHandler(l *zap.Logger, dao dao.WebhookDao, alertQueue chan template.Alert) *WebHookHandler {
+func NewWebHookHandler(l *zap.Logger, dao dao.WebhookDao, alertQueue chan *model.MonitorAlertEvent) *WebHookHandler {
 	handler := &WebHookHandler{
 		l:          l,
 		dao:        dao,
@@ -76,28 +76,37 @@ func (w *WebHookHandler) RegisterRouters(server *gin.Engine) {
 
 // MonitorAlertReceive 处理来自Alertmanager的告警接收请求
 func (w *WebHookHandler) MonitorAlertReceive(ctx *gin.Context) {
-	var msg webhook.Message
+	body, err := ctx.GetRawData()
+	if err != nil {
+		w.l.Error("读取告警请求体失败", zap.Error(err))
+		utils.ErrorWithMessage(ctx, "读取请求体失败")
+		return
+	}
 
-	if err := ctx.ShouldBindJSON(&msg); err != nil {
+	events, err := request.ParseAlertmanagerWebhook(body)
+	var invalid *request.InvalidAlertsError
+	if errors.As(err, &invalid) {
+		// 不合法的告警逐条跳过，其余告警继续处理
+		for _, alertErr := range invalid.Errs {
+			w.l.Warn("跳过不合法的告警", zap.Error(alertErr))
+		}
+	} else if err != nil {
 		w.l.Error("解析告警JSON失败", zap.Error(err))
-		utils.ErrorWithMessage(ctx, "无效的JSON数据")
+		utils.ErrorWithMessage(ctx, "无效的JSON数据: "+err.Error())
 		return
 	}
 
-	w.l.Info("收到告警消息",
-		zap.String("状态", msg.Status),
-		zap.Int("告警数量", len(msg.Alerts)),
-	)
+	w.l.Info("收到告警消息", zap.Int("告警数量", len(events)))
 
-	for _, alert := range msg.Alerts {
+	for _, event := range events {
 		select {
-		case w.alertQueue <- alert:
+		case w.alertQueue <- event:
 			w.l.Debug("告警已加入队列",
-				zap.String("告警名称", alert.Labels["alertname"]),
-				zap.String("告警级别", alert.Labels["severity"]))
+				zap.String("告警名称", event.AlertName),
+				zap.String("告警级别", event.Severity))
 		default:
 			w.l.Warn("告警队列已满",
-				zap.String("告警名称", alert.Labels["alertname"]))
+				zap.String("告警名称", event.AlertName))
 			utils.ErrorWithMessage(ctx, "告警队列已满,请稍后重试")
 			return
 		}
@@ -135,21 +144,9 @@ func (w *WebHookHandler) MonitorAlertSilence(ctx *gin.Context) {
 		return
 	}
 
-	// 解析标签
-	labelsM := make(map[string]string)
-	for _, label := range event.Labels {
-		parts := strings.Split(label, "=")
-		if len(parts) != 2 {
-			w.l.Warn("无效的标签格式", zap.String("label", label))
-			continue
-		}
-		labelsM[parts[0]] = parts[1]
-	}
-	event.LabelsMap = labelsM
-
 	// 构建匹配器
-	matchers := make(labels.Matchers, 0, len(labelsM))
-	for k, v := range labelsM {
+	matchers := make(labels.Matchers, 0, len(event.Labels))
+	for k, v := range event.Labels {
 		matchers = append(matchers, &labels.Matcher{
 			Type:  labels.MatchEqual,
 			Name:  k,
@@ -198,7 +195,7 @@ func (w *WebHookHandler) MonitorAlertSilence(ctx *gin.Context) {
 	}
 
 	// 更新告警事件的静默状态和silenceID
-	event.Status = "silenced"
+	event.Status = string(model.AlertStatusSilenced)
 	event.SilenceID = silenceResp.SilenceID
 	if err := w.dao.UpdateMonitorAlertEvent(ctx, event); err != nil {
 		w.l.Error("更新告警事件状态失败", zap.Error(err))
@@ -233,7 +230,7 @@ func (w *WebHookHandler) MonitorAlertUnSilence(ctx *gin.Context) {
 		return
 	}
 
-	if event.Status != "silenced" {
+	if event.Status != string(model.AlertStatusSilenced) {
 		utils.ErrorWithMessage(ctx, "该告警未处于静默状态")
 		return
 	}
@@ -257,7 +254,7 @@ func (w *WebHookHandler) MonitorAlertUnSilence(ctx *gin.Context) {
 	}
 
 	// 更新告警事件状态
-	event.Status = "firing"
+	event.Status = string(model.AlertStatusFiring)
 	event.SilenceID = ""
 	if err := w.dao.UpdateMonitorAlertEvent(ctx, event); err != nil {
 		w.l.Error("更新告警事件状态失败", zap.Error(err))
@@ -31,6 +31,7 @@ import (
 	"time"
 
 	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/GoSimplicity/AI-CloudOps/internal/prometheus/domain"
 	"github.com/GoSimplicity/AI-CloudOps/internal/prometheus/webhook/dao"
 	"github.com/GoSimplicity/AI-CloudOps/internal/prometheus/webhook/robot"
 	"github.com/spf13/viper"
@@ -39,11 +40,14 @@ import (
 )
 
 type WebhookCache interface {
-	RenewAllCaches(ctx context.Context) error            // 刷新所有缓存
-	GetOnDutyGroupById(id int) *model.MonitorOnDutyGroup // 根据 ID 获取 OnDutyGroup 数据
-	GetRuleById(id int) *model.MonitorAlertRule          // 根据 ID 获取 Rule 数据
-	GetSendGroupById(id int) *model.MonitorSendGroup     // 根据 ID 获取 SendGroup 数据
-	GetUserById(id int) *model.User                      // 根据 ID 获取 User 数据
+	RenewAllCaches(ctx context.Context) error                    // 刷新所有缓存
+	GetOnDutyGroupById(id int) *model.MonitorOnDutyGroup         // 根据 ID 获取 OnDutyGroup 数据
+	GetRuleById(id int) *model.MonitorAlertRule                  // 根据 ID 获取 Rule 数据
+	GetSendGroupById(id int) *model.MonitorSendGroup             // 根据 ID 获取 SendGroup 数据
+	GetUserById(id int) *model.User                              // 根据 ID 获取 User 数据
+	GetActiveSilences(now time.Time) []*domain.CompiledSilence   // 获取 now 时刻生效的静默
+	GetInhibitEvaluator() *domain.InhibitEvaluator               // 获取由已启用抑制规则构建的评估器
+	RouteSendGroup(ctx context.Context, labels model.Labels) int // 按标签路由规则匹配发送组，未匹配时返回默认发送组
 }
 
 type webhookCache struct {
@@ -56,6 +60,7 @@ type webhookCache struct {
 
 	// 各类缓存数据及其读写锁
 	SendGroupMap    map[int]*model.MonitorSendGroup
+	SendGroupRouter *domain.SendGroupRouter
 	SendGroupLock   sync.RWMutex
 	UserMap         map[int]*model.User
 	UserLock        sync.RWMutex
@@ -63,18 +68,24 @@ type webhookCache struct {
 	OnDutyGroupLock sync.RWMutex
 	RuleMap         map[int]*model.MonitorAlertRule
 	RuleLock        sync.RWMutex
+	Silences        []*domain.CompiledSilence
+	SilenceLock     sync.RWMutex
+	Inhibitor       *domain.InhibitEvaluator
+	InhibitorLock   sync.RWMutex
 }
 
 func NewWebhookCache(l *zap.Logger, dao dao.WebhookDao, robot robot.WebhookRobot) WebhookCache {
 	return &webhookCache{
-		l:              l,
-		dao:            dao,
-		robot:          robot,
-		cacheHasSynced: make(chan struct{}),
-		SendGroupMap:   make(map[int]*model.MonitorSendGroup),
-		UserMap:        make(map[int]*model.User),
-		OnDutyGroupMap: make(map[int]*model.MonitorOnDutyGroup),
-		RuleMap:        make(map[int]*model.MonitorAlertRule),
+		l:               l,
+		dao:             dao,
+		robot:           robot,
+		cacheHasSynced:  make(chan struct{}),
+		SendGroupMap:    make(map[int]*model.MonitorSendGroup),
+		SendGroupRouter: domain.NewSendGroupRouter(nil, viper.GetInt("webhook.routing.default_send_group_id")),
+		UserMap:         make(map[int]*model.User),
+		OnDutyGroupMap:  make(map[int]*model.MonitorOnDutyGroup),
+		RuleMap:         make(map[int]*model.MonitorAlertRule),
+		Inhibitor:       domain.NewInhibitEvaluator(nil),
 	}
 }
 
@@ -86,13 +97,15 @@ func (wc *webhookCache) RenewAllCaches(ctx context.Context) error {
 		renewInterval = 60 * time.Second
 	}
 
-	wc.initWG.Add(4) // 四个缓存需要初次同步
+	wc.initWG.Add(6) // 六个缓存需要初次同步
 
 	// 启动定时刷新各类缓存
 	wc.startCacheRefresh(ctx, wc.RenewMapSendGroup, renewInterval)
 	wc.startCacheRefresh(ctx, wc.RenewMapUser, renewInterval)
 	wc.startCacheRefresh(ctx, wc.RenewMapOnDutyGroup, renewInterval)
 	wc.startCacheRefresh(ctx, wc.RenewMapRule, renewInterval)
+	wc.startCacheRefresh(ctx, wc.RenewSilences, renewInterval)
+	wc.startCacheRefresh(ctx, wc.RenewInhibitRules, renewInterval)
 
 	// 启动私有机器人令牌的定时刷新
 	go wait.UntilWithContext(ctx, wc.robot.RefreshPrivateRobotToken, 5*time.Minute)
@@ -199,8 +212,11 @@ func (wc *webhookCache) RenewMapSendGroup(ctx context.Context) {
 		tmpMap[sendGroup.ID] = sendGroup
 	}
 
+	router := domain.NewSendGroupRouter(sendGroups, viper.GetInt("webhook.routing.default_send_group_id"))
+
 	wc.SendGroupLock.Lock()
 	wc.SendGroupMap = tmpMap
+	wc.SendGroupRouter = router
 	wc.SendGroupLock.Unlock()
 
 	wc.logCacheRefreshResult("SendGroup", len(tmpMap))
@@ -213,6 +229,13 @@ func (wc *webhookCache) GetSendGroupById(id int) *model.MonitorSendGroup {
 	return wc.SendGroupMap[id]
 }
 
+// RouteSendGroup 按缓存的发送组路由规则匹配告警标签，未匹配时返回默认发送组，未配置默认发送组时返回0
+func (wc *webhookCache) RouteSendGroup(ctx context.Context, labels model.Labels) int {
+	wc.SendGroupLock.RLock()
+	defer wc.SendGroupLock.RUnlock()
+	return wc.SendGroupRouter.ResolveSendGroup(ctx, labels)
+}
+
 // RenewMapUser 刷新 User 缓存
 func (wc *webhookCache) RenewMapUser(ctx context.Context) {
 	users, err := wc.dao.GetUserList(ctx)
@@ -240,6 +263,70 @@ func (wc *webhookCache) GetUserById(id int) *model.User {
 	return wc.UserMap[id]
 }
 
+// RenewSilences 刷新静默缓存，匹配器在此处编译一次，匹配器非法的静默跳过
+// 缓存包含尚未开始的静默，是否生效在读取时按当前时间判断，避免刷新间隔内静默开始或结束不及时
+func (wc *webhookCache) RenewSilences(ctx context.Context) {
+	silences, err := wc.dao.GetUnexpiredSilences(ctx, time.Now().Unix())
+	if err != nil {
+		wc.l.Error("[缓存刷新模块] 获取静默列表失败", zap.Error(err))
+		return
+	}
+
+	compiled := make([]*domain.CompiledSilence, 0, len(silences))
+	for _, silence := range silences {
+		cs, err := domain.CompileSilence(silence)
+		if err != nil {
+			wc.l.Warn("[缓存刷新模块] 跳过匹配器非法的静默", zap.Int("silenceID", silence.ID), zap.Error(err))
+			continue
+		}
+		compiled = append(compiled, cs)
+	}
+
+	wc.SilenceLock.Lock()
+	wc.Silences = compiled
+	wc.SilenceLock.Unlock()
+
+	wc.logCacheRefreshResult("Silence", len(compiled))
+}
+
+// GetActiveSilences 获取 now 时刻生效的静默
+func (wc *webhookCache) GetActiveSilences(now time.Time) []*domain.CompiledSilence {
+	wc.SilenceLock.RLock()
+	defer wc.SilenceLock.RUnlock()
+
+	active := make([]*domain.CompiledSilence, 0, len(wc.Silences))
+	for _, cs := range wc.Silences {
+		if cs.ActiveAt(now.Unix()) {
+			active = append(active, cs)
+		}
+	}
+	return active
+}
+
+// RenewInhibitRules 刷新抑制规则缓存
+func (wc *webhookCache) RenewInhibitRules(ctx context.Context) {
+	rules, err := wc.dao.GetEnabledInhibitRules(ctx)
+	if err != nil {
+		wc.l.Error("[缓存刷新模块] 获取抑制规则列表失败", zap.Error(err))
+		return
+	}
+
+	inhibitor := domain.NewInhibitEvaluator(rules)
+
+	wc.InhibitorLock.Lock()
+	wc.Inhibitor = inhibitor
+	wc.InhibitorLock.Unlock()
+
+	wc.logCacheRefreshResult("InhibitRule", len(rules))
+}
+
+// GetInhibitEvaluator 获取由已启用抑制规则构建的评估器
+func (wc *webhookCache) GetInhibitEvaluator() *domain.InhibitEvaluator {
+	wc.InhibitorLock.RLock()
+	defer wc.InhibitorLock.RUnlock()
+	return wc.Inhibitor
+}
+
 // logCacheRefreshResult 记录缓存刷新结果日志
 func (wc *webhookCache) logCacheRefreshResult(cacheName string, count int) {
 	wc.l.Info("缓存刷新完成",
@@ -25,6 +25,8 @@
 
 package constant
 
+import "github.com/GoSimplicity/AI-CloudOps/internal/model"
+
 // AlertSeverity 表示告警的严重性等级
 type AlertSeverity string
 
@@ -337,12 +339,12 @@ var (
 )
 
 const (
-	AlertSeverityCritical AlertSeverity = "critical" // 严重
-	AlertSeverityWarning  AlertSeverity = "warning"  // 警告
-	AlertSeverityInfo     AlertSeverity = "info"     // 信息
+	AlertSeverityCritical = AlertSeverity(model.AlertSeverityCritical) // 严重
+	AlertSeverityWarning  = AlertSeverity(model.AlertSeverityWarning)  // 警告
+	AlertSeverityInfo     = AlertSeverity(model.AlertSeverityInfo)     // 信息
 
-	AlertStatusFiring   AlertStatus = "firing"   // 触发中
-	AlertStatusResolved AlertStatus = "resolved" // 已恢复
+	AlertStatusFiring   = AlertStatus(model.AlertStatusFiring)   // 触发中
+	AlertStatusResolved = AlertStatus(model.AlertStatusResolved) // 已恢复
 )
 
 // SeverityTitleColorMap 将告警严重性映射到标题颜色
@@ -352,6 +354,11 @@ var SeverityTitleColorMap = map[AlertSeverity]string{
 	AlertSeverityInfo:     "blue",   // 信息 - 蓝色
 }
 
+// ShouldMentionOnDuty 判断该级别的告警是否需要在群消息中 @ 值班人，仅严重告警提醒
+func ShouldMentionOnDuty(severity AlertSeverity) bool {
+	return severity == AlertSeverityCritical
+}
+
 // StatusColorMap 将告警状态映射到颜色
 var StatusColorMap = map[AlertStatus]string{
 	AlertStatusFiring:   "red",   // 触发中 - 红色
@@ -27,15 +27,14 @@ package consumer
 
 import (
 	"context"
-	"fmt"
-	"strconv"
 	"sync"
+	"time"
 
 	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/GoSimplicity/AI-CloudOps/internal/prometheus/domain"
 	"github.com/GoSimplicity/AI-CloudOps/internal/prometheus/webhook/cache"
 	"github.com/GoSimplicity/AI-CloudOps/internal/prometheus/webhook/content"
 	"github.com/GoSimplicity/AI-CloudOps/internal/prometheus/webhook/dao"
-	"github.com/prometheus/alertmanager/template"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
@@ -44,13 +43,13 @@ import (
 type WebhookConsumer interface {
 	// AlertReceiveConsumerManager 管理告警接收的消费者
 	AlertReceiveConsumerManager(ctx context.Context) error
-	// HandleAlert 处理单个告警接收
-	HandleAlert(ctx context.Context, alert template.Alert)
+	// HandleAlert 处理单个已解析的告警事件
+	HandleAlert(ctx context.Context, event *model.MonitorAlertEvent)
 }
 
 // webhookConsumer 是 WebhookConsumer 接口的实现
 type webhookConsumer struct {
-	alertReceiveQueue chan template.Alert // 告警接收队列
+	alertReceiveQueue chan *model.MonitorAlertEvent // 告警接收队列
 	cache             cache.WebhookCache
 	dao               dao.WebhookDao
 	content           content.WebhookContent
@@ -64,7 +63,7 @@ type webhookConsumer struct {
 }
 
 // NewWebhookConsumer 创建一个新的WebhookConsumer实例
-func NewWebhookConsumer(logger *zap.Logger, cache cache.WebhookCache, dao dao.WebhookDao, content content.WebhookContent, alertReceiveQueue chan template.Alert) WebhookConsumer {
+func NewWebhookConsumer(logger *zap.Logger, cache cache.WebhookCache, dao dao.WebhookDao, content content.WebhookContent, alertReceiveQueue chan *model.MonitorAlertEvent) WebhookConsumer {
 	return &webhookConsumer{
 		logger:            logger,
 		cache:             cache,
@@ -83,47 +82,32 @@ func (wc *webhookConsumer) AlertReceiveConsumerManager(ctx context.Context) erro
 		case <-ctx.Done():
 			wc.logger.Info("AlertReceiveConsumerManager 收到其他任务退出信号 退出")
 			return nil
-		case alert := <-wc.alertReceiveQueue:
-			go wc.HandleAlert(ctx, alert)
+		case event := <-wc.alertReceiveQueue:
+			go wc.HandleAlert(ctx, event)
 		}
 
 	}
 }
 
-// HandleAlert 处理单个告警接收
-func (wc *webhookConsumer) HandleAlert(ctx context.Context, alert template.Alert) {
-	// 提取 send_group_id
-	sendGroupIDStr, exists := alert.Labels["alert_send_group"]
-	if !exists {
-		wc.logger.Info("告警信息缺少 send_group_id", zap.Any("alert", alert))
-		return
-	}
+// HandleAlert 处理单个已解析的告警事件，事件由 request.ParseAlertmanagerWebhook 生成，原始告警保存在 event.Alert 中
+func (wc *webhookConsumer) HandleAlert(ctx context.Context, event *model.MonitorAlertEvent) {
+	alert := event.Alert
 
-	// 提取 rule_id
-	ruleIDStr, exists := alert.Labels["alert_rule_id"]
-	if !exists {
-		wc.logger.Info("告警信息缺少 rule_id", zap.Any("alert", alert))
-		return
-	}
-
-	// 转换 send_group_id 和 rule_id 为整数
-	sendGroupID, err := strconv.Atoi(sendGroupIDStr)
-	if err != nil {
-		wc.logger.Error("转换 send_group_id 失败",
-			zap.String("sendGroupIDStr", sendGroupIDStr),
-			zap.Error(err),
-		)
-		return
+	// 缺少 alert_send_group 标签时按缓存的标签路由规则选择发送组，均未匹配时使用默认发送组
+	if event.SendGroupID <= 0 {
+		routedID := wc.cache.RouteSendGroup(ctx, event.Labels)
+		if routedID <= 0 {
+			wc.logger.Info("告警信息缺少 send_group_id 且未匹配任何发送组路由", zap.Any("alert", alert))
+			return
+		}
+		event.SendGroupID = routedID
 	}
 
-	ruleID, err := strconv.Atoi(ruleIDStr)
-	if err != nil {
-		wc.logger.Error("转换 rule_id 失败",
-			zap.String("ruleIDStr", ruleIDStr),
-			zap.Error(err),
-		)
+	if event.RuleID <= 0 {
+		wc.logger.Info("告警信息缺少 rule_id", zap.Any("alert", alert))
 		return
 	}
+	sendGroupID, ruleID := event.SendGroupID, event.RuleID
 
 	// 从缓存中获取 sendGroup
 	sendGroup := wc.cache.GetSendGroupById(sendGroupID)
@@ -163,35 +147,23 @@ func (wc *webhookConsumer) HandleAlert(ctx context.Context, alert template.Alert
 		zap.Int("ruleID", ruleID),
 	)
 
-	// 判断是否需要升级
-	upgradeNeed := false
-	if alert.Status == "firing" && sendGroup.FirstUpgradeUsers != nil && len(sendGroup.FirstUpgradeUsers) > 0 {
-		upgradeNeed = true
-	}
-
-	status := alert.Status
-	if upgradeNeed {
-		status = "upgraded"
-	}
-
-	// 构造标签
-	var labels []string
-	for key, val := range alert.Labels {
-		labels = append(labels, fmt.Sprintf("%s=%s", key, val))
-	}
-
-	// 构造 MonitorAlertEvent
-	event := &model.MonitorAlertEvent{
-		AlertName:   alert.Labels["alertname"],
-		Fingerprint: alert.Fingerprint,
-		Status:      status,
-		RuleID:      ruleID,
-		Labels:      labels,
-		SendGroupID: sendGroupID,
+	// 需要升级的发送组，告警中的事件标记为 upgraded
+	if alert.Status == string(model.AlertStatusFiring) && len(sendGroup.FirstUpgradeUsers) > 0 {
+		event.Status = string(model.AlertStatusUpgraded)
 	}
+	event.TeamID = sendGroup.TeamID
 
-	// 创建或更新事件
-	if err := wc.dao.CreateOrUpdateEvent(ctx, event); err != nil {
+	if alert.Status == string(model.AlertStatusResolved) {
+		// 恢复通知只更新已有事件，不创建新事件；未携带结束时间时以当前时间为恢复时间
+		if err := wc.dao.ResolveAlertEventByFingerprint(ctx, event.Fingerprint, event.ResolvedAt); err != nil {
+			wc.logger.Error("更新 MonitorAlertEvent 恢复状态失败",
+				zap.Error(err),
+				zap.String("fingerprint", alert.Fingerprint),
+			)
+			return
+		}
+	} else if err := wc.dao.CreateOrUpdateEvent(ctx, event); err != nil {
+		// 创建或更新事件
 		wc.logger.Error("创建或更新 MonitorAlertEvent 失败",
 			zap.Error(err),
 			zap.Any("event", event),
@@ -209,6 +181,40 @@ func (wc *webhookConsumer) HandleAlert(ctx context.Context, alert template.Alert
 		)
 		return
 	}
+	if updatedEvent == nil {
+		wc.logger.Info("MonitorAlertEvent 不存在，跳过通知",
+			zap.String("fingerprint", alert.Fingerprint),
+			zap.String("status", alert.Status),
+		)
+		return
+	}
+
+	// 持续告警按退避间隔重复通知，恢复通知不受限制
+	now := time.Now()
+	if alert.Status == string(model.AlertStatusFiring) && !domain.ShouldRenotify(updatedEvent, now) {
+		wc.logger.Debug("未到重复通知时间，跳过发送",
+			zap.String("fingerprint", alert.Fingerprint),
+			zap.Int("eventTimes", updatedEvent.EventTimes),
+			zap.Int64("lastNotifiedAt", updatedEvent.LastNotifiedAt),
+		)
+		return
+	}
+
+	// 被抑制的告警不发送通知
+	if alert.Status == string(model.AlertStatusFiring) && wc.isInhibited(ctx, updatedEvent) {
+		wc.logger.Info("告警被抑制规则抑制，跳过发送",
+			zap.String("fingerprint", alert.Fingerprint),
+		)
+		return
+	}
+
+	// 命中标签匹配静默的告警不发送通知
+	if alert.Status == string(model.AlertStatusFiring) && wc.isSilenced(updatedEvent, now) {
+		wc.logger.Info("告警命中标签匹配静默，跳过发送",
+			zap.String("fingerprint", alert.Fingerprint),
+		)
+		return
+	}
 
 	// 生成飞书卡片内容
 	if err := wc.content.GenerateFeishuCardContentOneAlert(ctx, alert, updatedEvent, rule, sendGroup); err != nil {
@@ -222,7 +228,46 @@ func (wc *webhookConsumer) HandleAlert(ctx context.Context, alert template.Alert
 		return
 	}
 
+	// 记录通知时间，用于计算下次重复通知
+	if err := wc.dao.UpdateMonitorAlertEvent(ctx, &model.MonitorAlertEvent{
+		ID:             updatedEvent.ID,
+		Fingerprint:    updatedEvent.Fingerprint,
+		LastNotifiedAt: now.Unix(),
+	}); err != nil {
+		wc.logger.Warn("更新告警通知时间失败",
+			zap.Error(err),
+			zap.String("fingerprint", alert.Fingerprint),
+		)
+	}
+
 	wc.logger.Info("成功处理告警",
 		zap.String("fingerprint", alert.Fingerprint),
 	)
 }
+
+// isInhibited 根据缓存的抑制规则判断事件是否被抑制，只查询可能抑制该事件的源告警，查询失败时不抑制，避免漏发告警
+func (wc *webhookConsumer) isInhibited(ctx context.Context, event *model.MonitorAlertEvent) bool {
+	inhibitor := wc.cache.GetInhibitEvaluator()
+
+	for _, required := range inhibitor.SourceCandidateLabels(event) {
+		sources, err := wc.dao.GetInhibitSourceCandidates(ctx, required)
+		if err != nil {
+			wc.logger.Warn("获取抑制源告警失败，忽略抑制", zap.Error(err))
+			return false
+		}
+		if inhibitor.IsInhibited(event, sources) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSilenced 判断事件是否命中任一生效的标签匹配静默，静默及其预编译的匹配器来自缓存
+func (wc *webhookConsumer) isSilenced(event *model.MonitorAlertEvent, now time.Time) bool {
+	for _, silence := range wc.cache.GetActiveSilences(now) {
+		if silence.Matches(event) {
+			return true
+		}
+	}
+	return false
+}
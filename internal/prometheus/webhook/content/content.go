@@ -28,6 +28,7 @@ package content
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	pkg "github.com/GoSimplicity/AI-CloudOps/pkg/utils"
 	"github.com/spf13/viper"
@@ -36,10 +37,13 @@ import (
 	"time"
 
 	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/GoSimplicity/AI-CloudOps/internal/prometheus/dao/alert"
+	"github.com/GoSimplicity/AI-CloudOps/internal/prometheus/metrics"
 	"github.com/GoSimplicity/AI-CloudOps/internal/prometheus/webhook/constant"
 	"github.com/GoSimplicity/AI-CloudOps/internal/prometheus/webhook/dao"
 	"github.com/GoSimplicity/AI-CloudOps/internal/prometheus/webhook/robot"
 	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
 
@@ -53,13 +57,15 @@ type WebhookContent interface {
 }
 
 type webhookContent struct {
-	l      *zap.Logger
-	dao    dao.WebhookDao
-	robot  robot.WebhookRobot
-	client *http.Client
+	l       *zap.Logger
+	dao     dao.WebhookDao
+	robot   robot.WebhookRobot
+	client  *http.Client
+	robots  *robotSelector
+	metrics *metrics.SendMetrics
 }
 
-func NewWebhookContent(l *zap.Logger, dao dao.WebhookDao, robot robot.WebhookRobot) WebhookContent {
+func NewWebhookContent(l *zap.Logger, dao dao.WebhookDao, robot robot.WebhookRobot, reg prometheus.Registerer) WebhookContent {
 	return &webhookContent{
 		l:     l,
 		dao:   dao,
@@ -67,6 +73,8 @@ func NewWebhookContent(l *zap.Logger, dao dao.WebhookDao, robot robot.WebhookRob
 		client: &http.Client{
 			Timeout: 10 * time.Second, // 设置默认超时时间
 		},
+		robots:  newRobotSelector(),
+		metrics: metrics.NewSendMetrics(reg),
 	}
 }
 
@@ -80,7 +88,11 @@ func (wc *webhookContent) GenerateFeishuCardContentOneAlert(ctx context.Context,
 	)
 
 	// 获取告警严重性和绑定的服务节点
-	severity := constant.AlertSeverity(alert.Labels["severity"])
+	severity := constant.AlertSeverity(event.Severity)
+	if severity == "" {
+		severity = constant.AlertSeverity(model.SeverityFromLabels(model.Labels(alert.Labels)))
+	}
+	mentionOnDuty := constant.ShouldMentionOnDuty(severity)
 	treeNode := alert.Labels["bind_tree_node"]
 
 	// 根据严重性获取标题颜色
@@ -137,12 +149,12 @@ func (wc *webhookContent) GenerateFeishuCardContentOneAlert(ctx context.Context,
 
 	if onDutyGroup.TodayDutyUser != nil {
 		yuanshiRen = onDutyGroup.TodayDutyUser.RealName
-		msgOnduty = fmt.Sprintf(`**👨‍💻 值班组 [%s](%s)：**\n当日值班人:%s\n user_id=%s<at id=%s></at>`,
+		msgOnduty = fmt.Sprintf(`**👨‍💻 值班组 [%s](%s)：**\n当日值班人:%s\n user_id=%s%s`,
 			onDutyGroup.Name,
 			onDutyGroupUrl,
 			onDutyGroup.TodayDutyUser.RealName,
 			onDutyGroup.TodayDutyUser.FeiShuUserId,
-			onDutyGroup.TodayDutyUser.FeiShuUserId,
+			feishuMention(onDutyGroup.TodayDutyUser.FeiShuUserId, mentionOnDuty),
 		)
 		privateUserIds[onDutyGroup.TodayDutyUser.FeiShuUserId] = ""
 	}
@@ -151,7 +163,7 @@ func (wc *webhookContent) GenerateFeishuCardContentOneAlert(ctx context.Context,
 	msgUpgrade := `**🎛️ 升级状态：**\n未升级`
 
 	// 判断是否需要升级告警
-	if event.Status != "renlinged" && alert.Status == string(constant.AlertStatusFiring) && sendGroup.FirstUpgradeUsers != nil && len(sendGroup.FirstUpgradeUsers) > 0 {
+	if event.Status != string(model.AlertStatusClaimed) && alert.Status == string(constant.AlertStatusFiring) && sendGroup.FirstUpgradeUsers != nil && len(sendGroup.FirstUpgradeUsers) > 0 {
 		upgradeMinutes := sendGroup.UpgradeMinutes
 		if upgradeMinutes == 0 {
 			upgradeMinutes = viper.GetInt("webhook.default_upgrade_minutes")
@@ -175,7 +187,7 @@ func (wc *webhookContent) GenerateFeishuCardContentOneAlert(ctx context.Context,
 				onDutyGroupUrl,
 				upgradeUserAtIds.String(),
 			)
-			event.Status = "upgraded"
+			event.Status = string(model.AlertStatusUpgraded)
 			if err := wc.dao.UpdateMonitorAlertEvent(ctx, event); err != nil {
 				return fmt.Errorf("更新告警事件状态失败: %w", err)
 			}
@@ -184,12 +196,12 @@ func (wc *webhookContent) GenerateFeishuCardContentOneAlert(ctx context.Context,
 
 	// 判断是否被认领
 	if event.RenLingUser != nil {
-		msgOnduty = fmt.Sprintf(`**👨‍💻 值班组 [%s](%s)：**\n认领人:%s\n user_id=%s<at id=%s></at>`,
+		msgOnduty = fmt.Sprintf(`**👨‍💻 值班组 [%s](%s)：**\n认领人:%s\n user_id=%s%s`,
 			onDutyGroup.Name,
 			onDutyGroupUrl,
 			event.RenLingUser.RealName,
 			event.RenLingUser.FeiShuUserId,
-			event.RenLingUser.FeiShuUserId,
+			feishuMention(event.RenLingUser.FeiShuUserId, mentionOnDuty),
 		)
 	}
 
@@ -203,9 +215,20 @@ func (wc *webhookContent) GenerateFeishuCardContentOneAlert(ctx context.Context,
 
 	anno := pkg.CloneMap(alert.Annotations)
 	delete(anno, "description_value")
+	summary := anno["summary"]
+	runbookURL := anno["runbook_url"]
+	delete(anno, "summary")
+	delete(anno, "runbook_url")
 
 	msgLabel := fmt.Sprintf(`**🛶标签信息：**\n%s`, pkg.FormatMap(labelMap))
 	msgAnno := fmt.Sprintf(`**🚂注释信息：**\n%s`, pkg.FormatMap(anno))
+	// 摘要和处理手册单独展示在注释信息之前，便于值班人员快速定位处理方式
+	if runbookURL != "" {
+		msgAnno = fmt.Sprintf(`**📖处理手册：**\n[%s](%s)\n%s`, escapeCardText(runbookURL), escapeCardText(runbookURL), msgAnno)
+	}
+	if summary != "" {
+		msgAnno = fmt.Sprintf(`**📌告警摘要：**\n%s\n%s`, escapeCardText(summary), msgAnno)
+	}
 
 	// 构建发送组信息
 	sendGroupUrl := fmt.Sprintf(constant.SendGroupURLTemplate,
@@ -230,6 +253,7 @@ func (wc *webhookContent) GenerateFeishuCardContentOneAlert(ctx context.Context,
 
 	// 使用 feiShuCardContent 模板构建 Feishu 卡片内容
 	cardContent, err := wc.buildFeishuCardContent(
+		wc.resolveCardTemplate(sendGroup),
 		alertHeaderColor, // header.template
 		alertHeader,      // header.title.content
 		msgLabel,         // 第一行标签信息
@@ -265,10 +289,10 @@ func (wc *webhookContent) GenerateFeishuCardContentOneAlert(ctx context.Context,
 	// 群聊发送
 	msgQun := fmt.Sprintf(constant.CartDataGroup, cardContent)
 
-	if err := wc.SentFeishuGroup(ctx, msgQun, sendGroup.FeiShuQunRobotToken); err != nil {
+	if err := wc.sentFeishuGroupWithFallback(ctx, msgQun, sendGroup, alert.Fingerprint, event.ID); err != nil {
 		wc.l.Error("发送 Feishu 群聊消息失败",
 			zap.Error(err),
-			zap.String("robotToken", sendGroup.FeiShuQunRobotToken),
+			zap.String("sendGroup", sendGroup.Name),
 		)
 		return fmt.Errorf("发送 Feishu 群聊消息失败: %w", err)
 	}
@@ -276,16 +300,60 @@ func (wc *webhookContent) GenerateFeishuCardContentOneAlert(ctx context.Context,
 	return nil
 }
 
-// buildFeishuCardContent 构建 Feishu 卡片内容的 JSON 字符串
+// sentFeishuGroupWithFallback 按发送组的机器人选择策略依次尝试负载机器人和备用机器人发送群聊消息，
+// 任一成功即记录通知投递并返回，全部失败时返回合并后的错误
+func (wc *webhookContent) sentFeishuGroupWithFallback(ctx context.Context, msg string, sendGroup *model.MonitorSendGroup, fingerprint string, eventID int) error {
+	tokens := wc.robots.tokens(sendGroup, fingerprint)
+	if len(tokens) == 0 {
+		return fmt.Errorf("发送组 %s 未配置飞书机器人", sendGroup.Name)
+	}
+
+	var errs []error
+	for i, token := range tokens {
+		body, err := wc.postFeishuGroup(ctx, msg, token)
+		if err == nil {
+			if i > 0 {
+				wc.l.Warn("首选飞书机器人发送失败，已通过其他机器人发送",
+					zap.String("sendGroup", sendGroup.Name),
+					zap.Int("channelIndex", i),
+				)
+			}
+			wc.recordFeishuNotification(ctx, eventID, body)
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("通道 %d: %w", i, err))
+
+		// 上下文已取消时不再尝试后续通道
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// resolveCardTemplate 获取发送组使用的卡片模板，依次使用发送组模板、全局配置模板和内置模板
+func (wc *webhookContent) resolveCardTemplate(sendGroup *model.MonitorSendGroup) string {
+	if sendGroup.MessageTemplate != "" {
+		return sendGroup.MessageTemplate
+	}
+	if tpl := viper.GetString("webhook.default_message_template"); tpl != "" {
+		return tpl
+	}
+	return constant.CardContent
+}
+
+// buildFeishuCardContent 使用指定模板构建 Feishu 卡片内容的 JSON 字符串，
+// 模板占位符顺序与 constant.CardContent 一致，渲染结果无效时回退到内置模板
 func (wc *webhookContent) buildFeishuCardContent(
+	cardTemplate string,
 	alertHeaderColor, alertHeader, msgLabel, msgAnno, msgSeverity, msgStatus,
 	msgStreeNode, msgTime, msgUpgrade, msgOnduty, msgGrafana, msgSendGroup, msgExpr string,
 	buttonURL1, buttonURL2, buttonURL3,
 	buttonURL4, buttonURL5, buttonURL6 string,
 ) (string, error) {
 
-	// 格式化 feiShuCardContent 模板
-	cardContent := fmt.Sprintf(constant.CardContent,
+	args := []interface{}{
 		alertHeaderColor, // header.template
 		alertHeader,      // header.title.content
 		msgLabel,         // 第一行标签信息
@@ -305,9 +373,39 @@ func (wc *webhookContent) buildFeishuCardContent(
 		buttonURL4,       // 取消屏蔽 URL
 		buttonURL5,       // 屏蔽6小时 URL
 		buttonURL6,       // 屏蔽7天 URL
-	)
+	}
+
+	cardContent, err := renderCardTemplate(cardTemplate, args)
+	if err == nil || cardTemplate == constant.CardContent {
+		return cardContent, err
+	}
+
+	wc.l.Warn("自定义卡片模板渲染结果无效，使用内置模板", zap.Error(err))
+
+	return renderCardTemplate(constant.CardContent, args)
+}
+
+// feishuMention 生成飞书 @ 用户的标记，不需要提醒时返回空字符串
+func feishuMention(feiShuUserID string, mention bool) string {
+	if !mention || feiShuUserID == "" {
+		return ""
+	}
+	return fmt.Sprintf("<at id=%s></at>", feiShuUserID)
+}
+
+// escapeCardText 转义嵌入卡片 JSON 字符串中的文本，避免注解中的引号或换行破坏卡片结构
+func escapeCardText(text string) string {
+	data, err := json.Marshal(text)
+	if err != nil {
+		return text
+	}
+	return string(data[1 : len(data)-1])
+}
+
+// renderCardTemplate 格式化卡片模板并验证生成的 JSON 是否有效
+func renderCardTemplate(cardTemplate string, args []interface{}) (string, error) {
+	cardContent := fmt.Sprintf(cardTemplate, args...)
 
-	// 验证生成的 JSON 是否有效
 	var temp interface{}
 	if err := json.Unmarshal([]byte(cardContent), &temp); err != nil {
 		return "", fmt.Errorf("生成的 Feishu 卡片内容 JSON 无效: %w", err)
@@ -316,22 +414,42 @@ func (wc *webhookContent) buildFeishuCardContent(
 	return cardContent, nil
 }
 
+// recordFeishuNotification 解析飞书响应中的消息ID并写入通知投递记录，失败只记录日志，不影响发送结果
+func (wc *webhookContent) recordFeishuNotification(ctx context.Context, eventID int, body []byte) {
+	messageID, err := alert.ParseFeishuMessageID(body)
+	if err != nil {
+		wc.l.Warn("解析飞书消息ID失败", zap.Error(err), zap.Int("eventID", eventID))
+	}
+
+	if err := wc.dao.RecordNotification(ctx, eventID, alert.WebhookProviderFeishu, messageID); err != nil {
+		wc.l.Warn("记录告警通知失败", zap.Error(err), zap.Int("eventID", eventID))
+	}
+}
+
 // SentFeishuGroup 发送消息到 Feishu 群聊
 func (wc *webhookContent) SentFeishuGroup(ctx context.Context, msg string, robotToken string) error {
+	_, err := wc.postFeishuGroup(ctx, msg, robotToken)
+	return err
+}
+
+// postFeishuGroup 发送消息到 Feishu 群聊并返回响应内容
+func (wc *webhookContent) postFeishuGroup(ctx context.Context, msg string, robotToken string) ([]byte, error) {
 	// 构建 Feishu 群聊机器人 API URL
 	url := fmt.Sprintf("%s/%s", viper.GetString("webhook.im_feishu.group_message_api"), robotToken)
 
 	// 发送 HTTP POST 请求
+	start := time.Now()
 	response, err := pkg.PostWithJson(ctx, wc.client, wc.l, url, msg, nil, nil)
+	wc.metrics.Observe(metrics.ProviderFeishu, start, err)
 	if err != nil {
 		wc.l.Error("发送飞书群聊卡片消息失败",
 			zap.Error(err),
 			zap.Any("结果", string(response)),
 		)
-		return fmt.Errorf("发送飞书群聊卡片消息失败: %w", err)
+		return nil, fmt.Errorf("发送飞书群聊卡片消息失败: %w", err)
 	}
 
-	return nil
+	return response, nil
 }
 
 // FeishuPrivateCardMsg 私聊消息的结构体
@@ -374,7 +492,9 @@ func (wc *webhookContent) SentFeishuPrivate(ctx context.Context, cardContent str
 		params := map[string]string{"receive_id_type": "user_id"}
 
 		// 发送 HTTP POST 请求
+		start := time.Now()
 		response, err := pkg.PostWithJson(ctx, wc.client, wc.l, url, string(data), params, headers)
+		wc.metrics.Observe(metrics.ProviderFeishuPrivate, start, err)
 		if err != nil {
 			wc.l.Error("发送飞书私聊卡片消息失败",
 				zap.Error(err),
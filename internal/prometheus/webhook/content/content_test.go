@@ -0,0 +1,395 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package content
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/GoSimplicity/AI-CloudOps/internal/prometheus/metrics"
+	"github.com/GoSimplicity/AI-CloudOps/internal/prometheus/webhook/constant"
+	"github.com/GoSimplicity/AI-CloudOps/internal/prometheus/webhook/dao"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// TestSentFeishuGroupRecordsMetrics webhook 进程的群聊发送需记录尝试、成功和失败次数
+func TestSentFeishuGroupRecordsMetrics(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/bad" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"code":0}`))
+	}))
+	defer srv.Close()
+
+	old := viper.Get("webhook.im_feishu.group_message_api")
+	viper.Set("webhook.im_feishu.group_message_api", srv.URL)
+	defer viper.Set("webhook.im_feishu.group_message_api", old)
+
+	reg := prometheus.NewRegistry()
+	wc := NewWebhookContent(zap.NewNop(), nil, nil, reg).(*webhookContent)
+
+	if err := wc.SentFeishuGroup(context.Background(), `{"msg_type":"text"}`, "ok"); err != nil {
+		t.Fatalf("发送群聊消息失败: %v", err)
+	}
+	if err := wc.SentFeishuGroup(context.Background(), `{"msg_type":"text"}`, "bad"); err == nil {
+		t.Fatal("服务端返回 500 时应返回错误")
+	}
+
+	for name, want := range map[string]float64{
+		"cloudops_notification_send_attempts_total":  2,
+		"cloudops_notification_send_successes_total": 1,
+		"cloudops_notification_send_failures_total":  1,
+	} {
+		if got := counterValue(t, reg, name, metrics.ProviderFeishu); got != want {
+			t.Errorf("指标 %s 期望 %v, 实际 %v", name, want, got)
+		}
+	}
+}
+
+// counterValue 从 reg 中读取指定 provider 标签的计数器取值
+func counterValue(t *testing.T, reg *prometheus.Registry, name string, provider string) float64 {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("采集指标失败: %v", err)
+	}
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "provider" && l.GetValue() == provider {
+					return m.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+type recordingWebhookDao struct {
+	dao.WebhookDao
+	eventID   int
+	channel   string
+	messageID string
+	calls     int
+}
+
+func (r *recordingWebhookDao) RecordNotification(_ context.Context, eventID int, channel string, messageID string) error {
+	r.calls++
+	r.eventID, r.channel, r.messageID = eventID, channel, messageID
+	return nil
+}
+
+// TestSentFeishuGroupWithFallbackRecordsNotification 群聊发送成功后需记录飞书返回的消息ID，失败的通道不记录
+func TestSentFeishuGroupWithFallbackRecordsNotification(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/bad" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"code":0,"data":{"message_id":"om_42"}}`))
+	}))
+	defer srv.Close()
+
+	old := viper.Get("webhook.im_feishu.group_message_api")
+	viper.Set("webhook.im_feishu.group_message_api", srv.URL)
+	defer viper.Set("webhook.im_feishu.group_message_api", old)
+
+	d := &recordingWebhookDao{}
+	wc := NewWebhookContent(zap.NewNop(), d, nil, prometheus.NewRegistry()).(*webhookContent)
+	sendGroup := &model.MonitorSendGroup{Name: "group", FeiShuQunRobotToken: "bad", FallbackRobotTokens: model.StringList{"ok"}}
+
+	if err := wc.sentFeishuGroupWithFallback(context.Background(), `{"msg_type":"text"}`, sendGroup, "fp", 42); err != nil {
+		t.Fatalf("备用机器人应发送成功: %v", err)
+	}
+	if d.calls != 1 || d.eventID != 42 || d.channel != "feishu" || d.messageID != "om_42" {
+		t.Fatalf("通知记录不符合预期: %+v", d)
+	}
+}
+
+// TestSentFeishuGroupWithFallbackOrder 按顺序尝试各机器人直到成功，全部失败时返回每个通道的错误
+func TestSentFeishuGroupWithFallbackOrder(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		if strings.HasPrefix(r.URL.Path, "/bad") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"code":0}`))
+	}))
+	defer srv.Close()
+
+	old := viper.Get("webhook.im_feishu.group_message_api")
+	viper.Set("webhook.im_feishu.group_message_api", srv.URL)
+	defer viper.Set("webhook.im_feishu.group_message_api", old)
+
+	wc := NewWebhookContent(zap.NewNop(), &recordingWebhookDao{}, nil, prometheus.NewRegistry()).(*webhookContent)
+
+	sendGroup := &model.MonitorSendGroup{Name: "group", FeiShuQunRobotToken: "bad1", FallbackRobotTokens: model.StringList{"bad2", "ok", "unused"}}
+	if err := wc.sentFeishuGroupWithFallback(context.Background(), `{"msg_type":"text"}`, sendGroup, "fp", 1); err != nil {
+		t.Fatalf("备用机器人应发送成功: %v", err)
+	}
+	if want := []string{"/bad1", "/bad2", "/ok"}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("应按顺序尝试并在成功后停止, 期望 %v, 实际 %v", want, paths)
+	}
+
+	paths = nil
+	sendGroup = &model.MonitorSendGroup{Name: "group", FeiShuQunRobotToken: "bad1", FallbackRobotTokens: model.StringList{"bad2"}}
+	err := wc.sentFeishuGroupWithFallback(context.Background(), `{"msg_type":"text"}`, sendGroup, "fp", 1)
+	if err == nil {
+		t.Fatal("所有机器人失败时应返回错误")
+	}
+	if !strings.Contains(err.Error(), "通道 0") || !strings.Contains(err.Error(), "通道 1") {
+		t.Fatalf("错误应包含每个通道的失败原因: %v", err)
+	}
+	if len(paths) != 2 {
+		t.Fatalf("所有机器人都应被尝试, 实际 %v", paths)
+	}
+}
+
+// TestSendGroupCardTemplate 发送组模板优先，未设置时使用全局模板，再回退到内置模板；渲染结果无效时使用内置模板
+func TestSendGroupCardTemplate(t *testing.T) {
+	wc := NewWebhookContent(zap.NewNop(), &recordingWebhookDao{}, nil, prometheus.NewRegistry()).(*webhookContent)
+
+	// 显式参数下标的模板可只引用部分占位符
+	groupTpl := `{"team":"ops","color":"%[1]s","title":"%[2]s"}`
+	globalTpl := `{"team":"default","title":"%[2]s"}`
+
+	old := viper.Get("webhook.default_message_template")
+	defer viper.Set("webhook.default_message_template", old)
+
+	viper.Set("webhook.default_message_template", "")
+	if got := wc.resolveCardTemplate(&model.MonitorSendGroup{}); got != constant.CardContent {
+		t.Fatalf("未设置任何模板时应使用内置模板, 实际 %q", got)
+	}
+
+	viper.Set("webhook.default_message_template", globalTpl)
+	if got := wc.resolveCardTemplate(&model.MonitorSendGroup{}); got != globalTpl {
+		t.Fatalf("发送组未设置模板时应使用全局模板, 实际 %q", got)
+	}
+	if got := wc.resolveCardTemplate(&model.MonitorSendGroup{MessageTemplate: groupTpl}); got != groupTpl {
+		t.Fatalf("应优先使用发送组模板, 实际 %q", got)
+	}
+
+	args := make([]string, 19)
+	args[0], args[1] = "red", "CPU 告警"
+	build := func(tpl string) string {
+		t.Helper()
+		content, err := wc.buildFeishuCardContent(tpl, args[0], args[1], args[2], args[3], args[4], args[5], args[6], args[7], args[8],
+			args[9], args[10], args[11], args[12], args[13], args[14], args[15], args[16], args[17], args[18])
+		if err != nil {
+			t.Fatalf("buildFeishuCardContent 返回错误: %v", err)
+		}
+		return content
+	}
+
+	if got := build(groupTpl); got != `{"team":"ops","color":"red","title":"CPU 告警"}` {
+		t.Fatalf("应按发送组模板渲染, 实际 %s", got)
+	}
+	if got := build(`{"broken":`); !strings.Contains(got, `"header"`) {
+		t.Fatalf("自定义模板渲染结果无效时应回退到内置模板, 实际 %s", got)
+	}
+}
+
+// onDutyWebhookDao 返回固定值班组的 webhook DAO，dutyUser 为当日值班人
+type onDutyWebhookDao struct {
+	recordingWebhookDao
+	dutyUser *model.User
+}
+
+func (d *onDutyWebhookDao) GetOnDutyGroupById(_ context.Context, id int) (*model.MonitorOnDutyGroup, error) {
+	return &model.MonitorOnDutyGroup{ID: id, Name: "值班组"}, nil
+}
+
+func (d *onDutyWebhookDao) FillTodayOnDutyUser(_ context.Context, group *model.MonitorOnDutyGroup) (*model.MonitorOnDutyGroup, error) {
+	group.TodayDutyUser = d.dutyUser
+	return group, nil
+}
+
+// captureFeishuCard 生成单条告警的飞书卡片，返回群聊收到的卡片中所有文本内容，以换行连接
+func captureFeishuCard(t *testing.T, wc *webhookContent, alert template.Alert, event *model.MonitorAlertEvent) string {
+	t.Helper()
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		_, _ = w.Write([]byte(`{"code":0}`))
+	}))
+	defer srv.Close()
+
+	old := viper.Get("webhook.im_feishu.group_message_api")
+	viper.Set("webhook.im_feishu.group_message_api", srv.URL)
+	defer viper.Set("webhook.im_feishu.group_message_api", old)
+
+	sendGroup := &model.MonitorSendGroup{ID: 1, Name: "group", FeiShuQunRobotToken: "token"}
+	if err := wc.GenerateFeishuCardContentOneAlert(context.Background(), alert, event, nil, sendGroup); err != nil {
+		t.Fatalf("GenerateFeishuCardContentOneAlert 返回错误: %v", err)
+	}
+
+	var msg struct {
+		Card interface{} `json:"card"`
+	}
+	if err := json.Unmarshal(body, &msg); err != nil {
+		t.Fatalf("群聊消息不是合法 JSON: %v, body=%s", err, body)
+	}
+	var texts []string
+	var collect func(v interface{})
+	collect = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if content, ok := v["content"].(string); ok {
+				texts = append(texts, content)
+			}
+			for _, child := range v {
+				collect(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				collect(child)
+			}
+		}
+	}
+	collect(msg.Card)
+	return strings.Join(texts, "\n")
+}
+
+// TestFeishuCardShowsAnnotations 卡片单独展示告警摘要和处理手册，注解中的引号和换行不破坏卡片 JSON
+func TestFeishuCardShowsAnnotations(t *testing.T) {
+	wc := NewWebhookContent(zap.NewNop(), &onDutyWebhookDao{}, nil, prometheus.NewRegistry()).(*webhookContent)
+	alert := template.Alert{
+		Status:   "firing",
+		Labels:   template.KV{"alertname": "cpu", "severity": "warning", "instance": "node-1"},
+		StartsAt: time.Now(),
+		Annotations: template.KV{
+			"summary":     `CPU 使用率 "95%"` + "\n持续 5 分钟",
+			"runbook_url": "https://wiki.example.com/runbook/cpu",
+			"description": "节点负载过高",
+		},
+		Fingerprint: "fp",
+	}
+
+	content := captureFeishuCard(t, wc, alert, &model.MonitorAlertEvent{ID: 1, EventTimes: 1})
+	for _, want := range []string{
+		"告警摘要",
+		`CPU 使用率 "95%"` + "\n持续 5 分钟",
+		"处理手册",
+		"[https://wiki.example.com/runbook/cpu](https://wiki.example.com/runbook/cpu)",
+		"description=节点负载过高",
+	} {
+		if !strings.Contains(content, want) {
+			t.Fatalf("卡片应包含 %q, 实际 %s", want, content)
+		}
+	}
+	if strings.Contains(content, "summary=") || strings.Contains(content, "runbook_url=") {
+		t.Fatalf("摘要和处理手册不应在注释信息中重复展示, 实际 %s", content)
+	}
+
+	// 没有摘要和处理手册时不展示对应区块
+	delete(alert.Annotations, "summary")
+	delete(alert.Annotations, "runbook_url")
+	content = captureFeishuCard(t, wc, alert, &model.MonitorAlertEvent{ID: 1, EventTimes: 1})
+	if strings.Contains(content, "告警摘要") || strings.Contains(content, "处理手册") {
+		t.Fatalf("没有摘要和处理手册时不应展示, 实际 %s", content)
+	}
+}
+
+// TestSeverityNotificationStyle 严重告警标红并 @ 认领人，警告和信息级别只改变颜色不提醒
+func TestSeverityNotificationStyle(t *testing.T) {
+	for _, c := range []struct {
+		severity constant.AlertSeverity
+		color    string
+		mention  bool
+	}{
+		{constant.AlertSeverityCritical, "red", true},
+		{constant.AlertSeverityWarning, "yellow", false},
+		{constant.AlertSeverityInfo, "blue", false},
+	} {
+		if got := constant.SeverityTitleColorMap[c.severity]; got != c.color {
+			t.Fatalf("%s 级别期望颜色 %s, 实际 %s", c.severity, c.color, got)
+		}
+		if got := constant.ShouldMentionOnDuty(c.severity); got != c.mention {
+			t.Fatalf("%s 级别期望提醒 %v, 实际 %v", c.severity, c.mention, got)
+		}
+	}
+	if feishuMention("ou_1", true) != "<at id=ou_1></at>" || feishuMention("ou_1", false) != "" || feishuMention("", true) != "" {
+		t.Fatal("feishuMention 生成的提醒标记不符合预期")
+	}
+
+	wc := NewWebhookContent(zap.NewNop(), &onDutyWebhookDao{}, nil, prometheus.NewRegistry()).(*webhookContent)
+	card := func(eventSeverity, labelSeverity string) string {
+		labels := template.KV{"alertname": "cpu"}
+		if labelSeverity != "" {
+			labels["severity"] = labelSeverity
+		}
+		alert := template.Alert{Status: "firing", Labels: labels, StartsAt: time.Now(), Fingerprint: "fp"}
+		event := &model.MonitorAlertEvent{
+			ID:          1,
+			EventTimes:  1,
+			Severity:    eventSeverity,
+			RenLingUser: &model.User{RealName: "张三", FeiShuUserId: "ou_1"},
+		}
+		return captureFeishuCard(t, wc, alert, event)
+	}
+
+	// 事件级别优先于标签，严重告警 @ 认领人
+	content := card("critical", "warning")
+	if !strings.Contains(content, "告警级别：**\ncritical") || !strings.Contains(content, "<at id=ou_1></at>") {
+		t.Fatalf("严重告警应展示 critical 并 @ 认领人, 实际 %s", content)
+	}
+
+	// 警告级别不提醒
+	content = card("warning", "critical")
+	if !strings.Contains(content, "告警级别：**\nwarning") || strings.Contains(content, "<at id=") {
+		t.Fatalf("警告告警不应 @ 认领人, 实际 %s", content)
+	}
+
+	// 事件未记录级别时从标签解析，标签也缺失时默认为 warning
+	if content = card("", "CRITICAL"); !strings.Contains(content, "告警级别：**\ncritical") || !strings.Contains(content, "<at id=ou_1></at>") {
+		t.Fatalf("应从标签解析出 critical, 实际 %s", content)
+	}
+	if content = card("", ""); !strings.Contains(content, "告警级别：**\nwarning") || strings.Contains(content, "<at id=") {
+		t.Fatalf("缺失级别时应默认为 warning, 实际 %s", content)
+	}
+}
@@ -0,0 +1,85 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+package content
+
+import (
+	"hash/fnv"
+	"sync"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+)
+
+// robotSelector 按发送组的选择策略决定飞书机器人的尝试顺序，轮换计数按发送组维护
+type robotSelector struct {
+	mu       sync.Mutex
+	counters map[int]uint64
+}
+
+func newRobotSelector() *robotSelector {
+	return &robotSelector{counters: make(map[int]uint64)}
+}
+
+// tokens 返回发送组本次发送依次尝试的机器人Token：负载机器人池按策略选出起点后轮转排列，备用机器人排在最后
+func (s *robotSelector) tokens(sendGroup *model.MonitorSendGroup, fingerprint string) []string {
+	pool := nonEmptyTokens(append([]string{sendGroup.FeiShuQunRobotToken}, sendGroup.RobotTokens...))
+
+	start := 0
+	if len(pool) > 1 {
+		switch sendGroup.RobotSelectStrategy {
+		case model.RobotSelectRoundRobin:
+			start = int(s.next(sendGroup.ID) % uint64(len(pool)))
+		case model.RobotSelectFingerprintHash:
+			h := fnv.New32a()
+			_, _ = h.Write([]byte(fingerprint))
+			start = int(h.Sum32() % uint32(len(pool)))
+		}
+	}
+
+	ordered := make([]string, 0, len(pool)+len(sendGroup.FallbackRobotTokens))
+	ordered = append(ordered, pool[start:]...)
+	ordered = append(ordered, pool[:start]...)
+	return append(ordered, nonEmptyTokens(sendGroup.FallbackRobotTokens)...)
+}
+
+// next 返回发送组当前的轮换序号并递增
+func (s *robotSelector) next(sendGroupID int) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := s.counters[sendGroupID]
+	s.counters[sendGroupID] = n + 1
+	return n
+}
+
+// nonEmptyTokens 过滤空Token
+func nonEmptyTokens(tokens []string) []string {
+	result := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if token != "" {
+			result = append(result, token)
+		}
+	}
+	return result
+}
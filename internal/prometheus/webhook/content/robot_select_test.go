@@ -0,0 +1,117 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package content
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+)
+
+func TestRobotSelectorRoundRobinRotates(t *testing.T) {
+	s := newRobotSelector()
+	group := &model.MonitorSendGroup{
+		ID:                  1,
+		FeiShuQunRobotToken: "a",
+		RobotTokens:         []string{"b", "", "c"},
+		FallbackRobotTokens: []string{"backup"},
+		RobotSelectStrategy: model.RobotSelectRoundRobin,
+	}
+
+	want := [][]string{
+		{"a", "b", "c", "backup"},
+		{"b", "c", "a", "backup"},
+		{"c", "a", "b", "backup"},
+		{"a", "b", "c", "backup"},
+	}
+	for i, w := range want {
+		if got := s.tokens(group, "fp"); !reflect.DeepEqual(got, w) {
+			t.Fatalf("第 %d 次发送期望 %v, 实际 %v", i+1, w, got)
+		}
+	}
+
+	// 轮换计数按发送组独立维护
+	other := *group
+	other.ID = 2
+	if got := s.tokens(&other, "fp"); got[0] != "a" {
+		t.Fatalf("新发送组应从第一个机器人开始, 实际 %v", got)
+	}
+	if got := s.tokens(group, "fp"); got[0] != "b" {
+		t.Fatalf("原发送组应继续轮换, 实际 %v", got)
+	}
+}
+
+func TestRobotSelectorFingerprintHashIsStable(t *testing.T) {
+	s := newRobotSelector()
+	group := &model.MonitorSendGroup{
+		ID:                  1,
+		FeiShuQunRobotToken: "a",
+		RobotTokens:         []string{"b", "c", "d"},
+		RobotSelectStrategy: model.RobotSelectFingerprintHash,
+	}
+
+	starts := make(map[string]bool)
+	for i := 0; i < 20; i++ {
+		fp := string(rune('a'+i)) + "-fingerprint"
+		first := s.tokens(group, fp)
+		for j := 0; j < 5; j++ {
+			if got := s.tokens(group, fp); !reflect.DeepEqual(got, first) {
+				t.Fatalf("指纹 %s 应固定路由, 期望 %v, 实际 %v", fp, first, got)
+			}
+		}
+		if len(first) != 4 {
+			t.Fatalf("应包含全部机器人, 实际 %v", first)
+		}
+		starts[first[0]] = true
+	}
+	if len(starts) < 2 {
+		t.Fatalf("不同指纹应分散到多个机器人, 实际只用到 %v", starts)
+	}
+}
+
+func TestRobotSelectorDefaultStrategyKeepsPrimaryFirst(t *testing.T) {
+	s := newRobotSelector()
+	group := &model.MonitorSendGroup{
+		ID:                  1,
+		FeiShuQunRobotToken: "a",
+		RobotTokens:         []string{"b"},
+		FallbackRobotTokens: []string{"", "backup"},
+	}
+	for i := 0; i < 3; i++ {
+		if got := s.tokens(group, "fp"); !reflect.DeepEqual(got, []string{"a", "b", "backup"}) {
+			t.Fatalf("未配置策略时应始终优先主机器人, 实际 %v", got)
+		}
+	}
+
+	// 只有一个机器人时轮换策略不改变顺序
+	single := &model.MonitorSendGroup{ID: 3, FeiShuQunRobotToken: "a", RobotSelectStrategy: model.RobotSelectRoundRobin}
+	for i := 0; i < 2; i++ {
+		if got := s.tokens(single, "fp"); !reflect.DeepEqual(got, []string{"a"}) {
+			t.Fatalf("单个机器人期望 [a], 实际 %v", got)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+package dao
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/GoSimplicity/AI-CloudOps/pkg/utils"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// labelBackfillBatchSize 回填标签索引时每批处理的事件数
+const labelBackfillBatchSize = 200
+
+// syncEventLabels 用事件当前的标签重建 alert_event_labels 中的索引行，需在写入事件的事务中调用；
+// 超长的标签键不建立索引，超长的标签值截断后写入，保证索引行不会导致事件写入失败
+func syncEventLabels(tx *gorm.DB, eventID int, labels model.Labels) error {
+	if err := tx.Where("event_id = ?", eventID).Delete(&model.AlertEventLabel{}).Error; err != nil {
+		return fmt.Errorf("清理告警事件标签索引失败: %w", err)
+	}
+
+	rows := eventLabelRows(eventID, labels)
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if err := tx.Create(&rows).Error; err != nil {
+		return fmt.Errorf("写入告警事件标签索引失败: %w", err)
+	}
+	return nil
+}
+
+// eventLabelRows 生成事件的标签索引行，按键排序保证写入顺序稳定
+func eventLabelRows(eventID int, labels model.Labels) []*model.AlertEventLabel {
+	rows := make([]*model.AlertEventLabel, 0, len(labels))
+	for key, value := range labels {
+		if !model.LabelIndexable(key) {
+			continue
+		}
+		rows = append(rows, &model.AlertEventLabel{EventID: eventID, Key: key, Value: model.LabelIndexValue(value)})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Key < rows[j].Key })
+	return rows
+}
+
+// BackfillEventLabels 为标签索引上线前写入、尚无索引行的未删除事件补建标签索引，返回处理的事件数；
+// 按ID分批处理，可重复执行
+func (wd *webhookDao) BackfillEventLabels(ctx context.Context) (int, error) {
+	total := 0
+	lastID := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		var events []*model.MonitorAlertEvent
+		if err := wd.db.WithContext(ctx).
+			Select("id, labels").
+			Scopes(utils.NotDeleted()).
+			Where("id > ?", lastID).
+			Where("NOT EXISTS (SELECT 1 FROM alert_event_labels l WHERE l.event_id = monitor_alert_events.id)").
+			Order("id ASC").
+			Limit(labelBackfillBatchSize).
+			Find(&events).Error; err != nil {
+			wd.l.Error("查询待回填标签索引的告警事件失败", zap.Error(err), zap.Int("lastID", lastID))
+			return total, err
+		}
+		if len(events) == 0 {
+			break
+		}
+
+		rows := make([]*model.AlertEventLabel, 0, len(events)*8)
+		for _, event := range events {
+			rows = append(rows, eventLabelRows(event.ID, event.Labels)...)
+		}
+		if len(rows) > 0 {
+			if err := wd.db.WithContext(ctx).CreateInBatches(rows, getAlertEventBatchSize()).Error; err != nil {
+				wd.l.Error("回填告警事件标签索引失败", zap.Error(err), zap.Int("lastID", lastID))
+				return total, err
+			}
+		}
+
+		total += len(events)
+		lastID = events[len(events)-1].ID
+		if len(events) < labelBackfillBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		wd.l.Info("回填告警事件标签索引完成", zap.Int("events", total))
+	}
+	return total, nil
+}
@@ -0,0 +1,99 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package dao
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+)
+
+func TestSyncEventLabelsSkipsLongKeysAndTruncatesValues(t *testing.T) {
+	wd, db := newTestWebhookDao(t)
+
+	longKey := strings.Repeat("k", model.AlertEventLabelKeyMaxLen+1)
+	longValue := strings.Repeat("值", model.AlertEventLabelValueMaxLen+10)
+	event := &model.MonitorAlertEvent{
+		AlertName:   "cpu",
+		Fingerprint: "fp-1",
+		Status:      "firing",
+		Labels:      model.Labels{"alertname": "cpu", longKey: "x", "description": longValue},
+	}
+	if err := wd.CreateOrUpdateEvent(context.Background(), event); err != nil {
+		t.Fatalf("超长标签不应导致事件写入失败: %v", err)
+	}
+
+	var rows []*model.AlertEventLabel
+	if err := db.Where("event_id = ?", event.ID).Order("label_key").Find(&rows).Error; err != nil {
+		t.Fatalf("查询标签索引失败: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("超长的标签键不应建立索引, 实际索引行 %d", len(rows))
+	}
+	for _, row := range rows {
+		if row.Key == "description" && row.Value != model.LabelIndexValue(longValue) {
+			t.Fatalf("超长的标签值应按字符截断后写入")
+		}
+	}
+}
+
+func TestBackfillEventLabels(t *testing.T) {
+	wd, db := newTestWebhookDao(t)
+	ctx := context.Background()
+
+	// 直接写入事件，模拟标签索引上线前的历史数据
+	events := []*model.MonitorAlertEvent{
+		{AlertName: "cpu", Fingerprint: "fp-1", Status: "firing", Labels: model.Labels{"alertname": "cpu", "env": "prod"}},
+		{AlertName: "mem", Fingerprint: "fp-2", Status: "firing", Labels: model.Labels{"alertname": "mem"}},
+		{AlertName: "old", Fingerprint: "fp-3", Status: "resolved", DeletedAt: 1, Labels: model.Labels{"alertname": "old"}},
+	}
+	if err := db.Create(&events).Error; err != nil {
+		t.Fatalf("写入历史告警事件失败: %v", err)
+	}
+
+	n, err := wd.BackfillEventLabels(ctx)
+	if err != nil {
+		t.Fatalf("BackfillEventLabels 返回错误: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("应为2条未删除事件回填索引, 实际 %d", n)
+	}
+
+	var count int64
+	if err := db.Model(&model.AlertEventLabel{}).Count(&count).Error; err != nil {
+		t.Fatalf("统计标签索引失败: %v", err)
+	}
+	if count != 3 {
+		t.Fatalf("应回填3条标签索引, 实际 %d", count)
+	}
+
+	// 重复执行不会重复写入
+	if n, err := wd.BackfillEventLabels(ctx); err != nil || n != 0 {
+		t.Fatalf("重复回填应不处理任何事件, 实际 %d, %v", n, err)
+	}
+}
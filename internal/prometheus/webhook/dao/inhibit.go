@@ -0,0 +1,91 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+package dao
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/GoSimplicity/AI-CloudOps/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// GetEnabledInhibitRules 获取所有启用的告警抑制规则
+func (wd *webhookDao) GetEnabledInhibitRules(ctx context.Context) ([]*model.MonitorInhibitRule, error) {
+	var rules []*model.MonitorInhibitRule
+
+	if err := wd.db.WithContext(ctx).
+		Scopes(utils.NotDeleted()).
+		Where("enable = ?", true).
+		Order("id ASC").
+		Find(&rules).Error; err != nil {
+		wd.l.Error("获取告警抑制规则失败", zap.Error(err))
+		return nil, fmt.Errorf("failed to get MonitorInhibitRules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// GetInhibitSourceCandidates 通过标签索引获取携带 required 全部标签的未恢复告警事件，作为抑制规则的源告警候选；
+// 无法建立索引的标签键不参与筛选，截断后的标签值可能多命中，调用方需在内存中按完整标签复核
+func (wd *webhookDao) GetInhibitSourceCandidates(ctx context.Context, required model.Labels) ([]*model.MonitorAlertEvent, error) {
+	var events []*model.MonitorAlertEvent
+
+	query := wd.db.WithContext(ctx).
+		Select("id", "fingerprint", "status", "labels").
+		Scopes(utils.NotDeleted()).
+		Where("status <> ?", model.AlertStatusResolved)
+	for key, val := range required {
+		if !model.LabelIndexable(key) {
+			continue
+		}
+		query = query.Where("id IN (?)", wd.db.Model(&model.AlertEventLabel{}).
+			Select("event_id").
+			Where("label_key = ? AND label_value = ?", key, model.LabelIndexValue(val)))
+	}
+
+	if err := query.Find(&events).Error; err != nil {
+		wd.l.Error("获取活跃告警事件失败", zap.Error(err))
+		return nil, fmt.Errorf("failed to get active MonitorAlertEvents: %w", err)
+	}
+
+	return events, nil
+}
+
+// GetUnexpiredSilences 获取在 now 时刻尚未结束的标签匹配静默，包含尚未开始的静默，供缓存按时间判断是否生效
+func (wd *webhookDao) GetUnexpiredSilences(ctx context.Context, now int64) ([]*model.MonitorSilence, error) {
+	var silences []*model.MonitorSilence
+
+	if err := wd.db.WithContext(ctx).
+		Scopes(utils.NotDeleted()).
+		Where("ends_at > ?", now).
+		Find(&silences).Error; err != nil {
+		wd.l.Error("获取未结束的静默失败", zap.Error(err))
+		return nil, fmt.Errorf("failed to get active MonitorSilences: %w", err)
+	}
+
+	return silences, nil
+}
@@ -0,0 +1,61 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package dao
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+)
+
+func TestGetInhibitSourceCandidatesFiltersByLabelIndex(t *testing.T) {
+	wd, _ := newTestWebhookDao(t)
+	ctx := context.Background()
+
+	events := []*model.MonitorAlertEvent{
+		{AlertName: "NodeDown", Fingerprint: "fp-n1", Status: string(model.AlertStatusFiring), Labels: model.Labels{"alertname": "NodeDown", "instance": "n1"}},
+		{AlertName: "NodeDown", Fingerprint: "fp-n2", Status: string(model.AlertStatusFiring), Labels: model.Labels{"alertname": "NodeDown", "instance": "n2"}},
+		{AlertName: "NodeDown", Fingerprint: "fp-n1-old", Status: string(model.AlertStatusResolved), Labels: model.Labels{"alertname": "NodeDown", "instance": "n1", "old": "1"}},
+		{AlertName: "HighCPU", Fingerprint: "fp-cpu", Status: string(model.AlertStatusFiring), Labels: model.Labels{"alertname": "HighCPU", "instance": "n1"}},
+	}
+	for _, e := range events {
+		if err := wd.CreateOrUpdateEvent(ctx, e); err != nil {
+			t.Fatalf("写入告警事件失败: %v", err)
+		}
+	}
+
+	sources, err := wd.GetInhibitSourceCandidates(ctx, model.Labels{"alertname": "NodeDown", "instance": "n1"})
+	if err != nil {
+		t.Fatalf("GetInhibitSourceCandidates 返回错误: %v", err)
+	}
+	if len(sources) != 1 || sources[0].Fingerprint != "fp-n1" {
+		t.Fatalf("只应返回携带全部标签且未恢复的事件, 实际 %+v", sources)
+	}
+	if sources[0].Labels["instance"] != "n1" {
+		t.Fatalf("候选事件需携带完整标签供内存复核, 实际 %+v", sources[0].Labels)
+	}
+}
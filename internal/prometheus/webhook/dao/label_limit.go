@@ -0,0 +1,168 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+package dao
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	// LabelLimitModeReject 标签超限时拒绝写入告警事件
+	LabelLimitModeReject = "reject"
+	// LabelLimitModeTruncate 标签超限时截断多余标签后写入
+	LabelLimitModeTruncate = "truncate"
+)
+
+// ErrLabelsExceedLimit 告警事件标签数量或大小超出限制
+var ErrLabelsExceedLimit = errors.New("告警事件标签超出限制")
+
+// reservedLabelKeys 截断时始终保留的标签，告警通知和路由依赖这些标签
+var reservedLabelKeys = []string{"alertname", "severity", "alert_rule_id", "alert_send_group", "bind_tree_node"}
+
+// LabelLimits 告警事件标签限制，MaxCount 或 MaxBytes 为 0 时不限制对应维度
+type LabelLimits struct {
+	MaxCount int    // 标签最大数量
+	MaxBytes int    // 标签序列化后的最大字节数
+	Mode     string // 超限处理方式，reject 或 truncate
+}
+
+// loadLabelLimits 从 webhook.label_limit 配置加载标签限制，未配置处理方式时默认截断
+func loadLabelLimits() LabelLimits {
+	limits := LabelLimits{
+		MaxCount: viper.GetInt("webhook.label_limit.max_count"),
+		MaxBytes: viper.GetInt("webhook.label_limit.max_bytes"),
+		Mode:     viper.GetString("webhook.label_limit.mode"),
+	}
+	if limits.Mode != LabelLimitModeReject {
+		limits.Mode = LabelLimitModeTruncate
+	}
+	return limits
+}
+
+// exceeded 判断标签是否超出限制
+func (ll LabelLimits) exceeded(labels model.Labels) (bool, error) {
+	if ll.MaxCount > 0 && len(labels) > ll.MaxCount {
+		return true, nil
+	}
+	if ll.MaxBytes > 0 {
+		size, err := labelsSize(labels)
+		if err != nil {
+			return false, err
+		}
+		return size > ll.MaxBytes, nil
+	}
+	return false, nil
+}
+
+// guardLabels 校验告警事件标签是否超出限制，reject 模式返回错误，truncate 模式截断多余标签
+func (wd *webhookDao) guardLabels(event *model.MonitorAlertEvent) error {
+	exceeded, err := wd.labelLimits.exceeded(event.Labels)
+	if err != nil {
+		return fmt.Errorf("计算标签大小失败: %w", err)
+	}
+	if !exceeded {
+		return nil
+	}
+
+	if wd.labelLimits.Mode == LabelLimitModeReject {
+		wd.l.Warn("告警事件标签超出限制，拒绝写入",
+			zap.String("fingerprint", event.Fingerprint),
+			zap.Int("labelCount", len(event.Labels)),
+		)
+		return fmt.Errorf("%w: fingerprint=%s, count=%d", ErrLabelsExceedLimit, event.Fingerprint, len(event.Labels))
+	}
+
+	original := len(event.Labels)
+	truncated, err := wd.labelLimits.truncate(event.Labels)
+	if err != nil {
+		return fmt.Errorf("截断标签失败: %w", err)
+	}
+	event.Labels = truncated
+
+	wd.l.Warn("告警事件标签超出限制，已截断",
+		zap.String("fingerprint", event.Fingerprint),
+		zap.Int("originalCount", original),
+		zap.Int("keptCount", len(truncated)),
+	)
+
+	return nil
+}
+
+// truncate 按保留标签优先、其余按键名排序的顺序保留标签，直到达到数量或大小限制
+func (ll LabelLimits) truncate(labels model.Labels) (model.Labels, error) {
+	keys := make([]string, 0, len(labels))
+	reserved := make(map[string]struct{}, len(reservedLabelKeys))
+	for _, key := range reservedLabelKeys {
+		reserved[key] = struct{}{}
+		if _, ok := labels[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+
+	others := make([]string, 0, len(labels))
+	for key := range labels {
+		if _, ok := reserved[key]; !ok {
+			others = append(others, key)
+		}
+	}
+	sort.Strings(others)
+	keys = append(keys, others...)
+
+	kept := make(model.Labels, len(keys))
+	for _, key := range keys {
+		if ll.MaxCount > 0 && len(kept) >= ll.MaxCount {
+			break
+		}
+
+		kept[key] = labels[key]
+		if ll.MaxBytes > 0 {
+			size, err := labelsSize(kept)
+			if err != nil {
+				return nil, err
+			}
+			if size > ll.MaxBytes {
+				delete(kept, key)
+			}
+		}
+	}
+
+	return kept, nil
+}
+
+// labelsSize 计算标签序列化后的字节数
+func labelsSize(labels model.Labels) (int, error) {
+	data, err := json.Marshal(labels)
+	if err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
@@ -0,0 +1,106 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package dao
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+)
+
+// manyLabels 生成 alertname 加 n 个普通标签
+func manyLabels(n int) model.Labels {
+	labels := model.Labels{"alertname": "cpu"}
+	for i := 0; i < n; i++ {
+		labels["label_"+strconv.Itoa(i)] = "value"
+	}
+	return labels
+}
+
+func TestLabelLimitRejectMode(t *testing.T) {
+	wd, db := newTestWebhookDao(t)
+	wd.labelLimits = LabelLimits{MaxCount: 5, Mode: LabelLimitModeReject}
+	ctx := context.Background()
+
+	err := wd.CreateOrUpdateEvent(ctx, &model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-1", Status: "firing", Labels: manyLabels(10)})
+	if !errors.Is(err, ErrLabelsExceedLimit) {
+		t.Fatalf("标签超限时应返回 ErrLabelsExceedLimit, 实际 %v", err)
+	}
+	var count int64
+	if err := db.Model(&model.MonitorAlertEvent{}).Count(&count).Error; err != nil {
+		t.Fatalf("统计告警事件失败: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("拒绝模式下不应写入事件, 实际 %d 条", count)
+	}
+
+	if err := wd.CreateOrUpdateEvent(ctx, &model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-2", Status: "firing", Labels: manyLabels(4)}); err != nil {
+		t.Fatalf("未超限的事件应正常写入: %v", err)
+	}
+}
+
+func TestLabelLimitTruncateMode(t *testing.T) {
+	wd, db := newTestWebhookDao(t)
+	wd.labelLimits = LabelLimits{MaxCount: 5, Mode: LabelLimitModeTruncate}
+	ctx := context.Background()
+
+	labels := manyLabels(10)
+	labels["severity"] = "critical"
+	if err := wd.CreateOrUpdateEvent(ctx, &model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-1", Status: "firing", Labels: labels}); err != nil {
+		t.Fatalf("截断模式下应写入事件: %v", err)
+	}
+
+	var stored model.MonitorAlertEvent
+	if err := db.Where("fingerprint = ?", "fp-1").First(&stored).Error; err != nil {
+		t.Fatalf("查询告警事件失败: %v", err)
+	}
+	if len(stored.Labels) != 5 {
+		t.Fatalf("应截断为 5 个标签, 实际 %d: %v", len(stored.Labels), stored.Labels)
+	}
+	// 保留标签优先，其余按键名顺序保留
+	for _, key := range []string{"alertname", "severity", "label_0", "label_1", "label_2"} {
+		if _, ok := stored.Labels[key]; !ok {
+			t.Fatalf("截断后应保留标签 %s: %v", key, stored.Labels)
+		}
+	}
+
+	// 按序列化大小截断
+	wd.labelLimits = LabelLimits{MaxBytes: 60, Mode: LabelLimitModeTruncate}
+	event := &model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-2", Status: "firing", Labels: manyLabels(10)}
+	if err := wd.guardLabels(event); err != nil {
+		t.Fatalf("guardLabels 返回错误: %v", err)
+	}
+	size, err := labelsSize(event.Labels)
+	if err != nil {
+		t.Fatalf("计算标签大小失败: %v", err)
+	}
+	if size > 60 || event.Labels["alertname"] != "cpu" {
+		t.Fatalf("截断后大小应不超过 60 字节且保留 alertname, 实际 %d 字节: %v", size, event.Labels)
+	}
+}
@@ -32,6 +32,9 @@ import (
 	"time"
 
 	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/GoSimplicity/AI-CloudOps/internal/prometheus/dao/alert"
+	"github.com/GoSimplicity/AI-CloudOps/pkg/utils"
+	"github.com/spf13/viper"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -46,22 +49,40 @@ type WebhookDao interface {
 	GetMonitorOnDutyGroupList(ctx context.Context) ([]*model.MonitorOnDutyGroup, error)
 	GetMonitorSendGroupList(ctx context.Context) ([]*model.MonitorSendGroup, error)
 	GetMonitorAlertEventByFingerprintId(ctx context.Context, fingerprintId string) (*model.MonitorAlertEvent, error)
+	GetEnabledInhibitRules(ctx context.Context) ([]*model.MonitorInhibitRule, error)
+	GetInhibitSourceCandidates(ctx context.Context, required model.Labels) ([]*model.MonitorAlertEvent, error)
+	GetUnexpiredSilences(ctx context.Context, now int64) ([]*model.MonitorSilence, error)
 
 	CreateOrUpdateEvent(ctx context.Context, event *model.MonitorAlertEvent) error
+	BatchCreateAlertEvents(ctx context.Context, events []*model.MonitorAlertEvent) error
 	UpdateMonitorAlertEvent(ctx context.Context, event *model.MonitorAlertEvent) error
+	ResolveAlertEventByFingerprint(ctx context.Context, fingerprint string, resolvedAt int64) error
+	BackfillEventLabels(ctx context.Context) (int, error)
+	RecordNotification(ctx context.Context, eventID int, channel string, messageID string) error
 
 	FillTodayOnDutyUser(ctx context.Context, onDutyGroup *model.MonitorOnDutyGroup) (*model.MonitorOnDutyGroup, error)
 }
 
+// defaultAlertEventBatchSize 批量写入告警事件时每批的默认行数
+const defaultAlertEventBatchSize = 100
+
 type webhookDao struct {
-	l  *zap.Logger
-	db *gorm.DB
+	l           *zap.Logger
+	db          *gorm.DB
+	eventCache  alert.AlertEventCache
+	labelLimits LabelLimits
 }
 
-func NewWebhookDao(l *zap.Logger, db *gorm.DB) WebhookDao {
+func NewWebhookDao(l *zap.Logger, db *gorm.DB, eventCache alert.AlertEventCache) WebhookDao {
+	if eventCache == nil {
+		eventCache = alert.NewNoopAlertEventCache()
+	}
+
 	return &webhookDao{
-		l:  l,
-		db: db,
+		l:           l,
+		db:          db,
+		eventCache:  eventCache,
+		labelLimits: loadLabelLimits(),
 	}
 }
 
@@ -133,18 +154,79 @@ func (wd *webhookDao) GetUserById(ctx context.Context, id int) (*model.User, err
 	return &user, nil
 }
 
-// CreateOrUpdateEvent 创建或更新 MonitorAlertEvent
+// BatchCreateAlertEvents 在同一事务中分批写入告警事件，任意一批失败则整体回滚
+func (wd *webhookDao) BatchCreateAlertEvents(ctx context.Context, events []*model.MonitorAlertEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	batchSize := getAlertEventBatchSize()
+
+	err := wd.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().Unix()
+		for _, event := range events {
+			if err := fillEventTeamID(tx, event); err != nil {
+				return err
+			}
+			if event.FiredAt <= 0 {
+				event.FiredAt = now
+			}
+		}
+		if err := tx.CreateInBatches(events, batchSize).Error; err != nil {
+			return err
+		}
+		for _, event := range events {
+			if err := syncEventLabels(tx, event.ID, event.Labels); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		wd.l.Error("批量创建 MonitorAlertEvent 失败",
+			zap.Error(err),
+			zap.Int("count", len(events)),
+			zap.Int("batch_size", batchSize),
+		)
+		return fmt.Errorf("failed to batch create %d MonitorAlertEvents: %w", len(events), err)
+	}
+
+	return nil
+}
+
+// getAlertEventBatchSize 获取批量写入告警事件的每批行数
+func getAlertEventBatchSize() int {
+	if size := viper.GetInt("prometheus.alert_event_batch_size"); size > 0 {
+		return size
+	}
+	return defaultAlertEventBatchSize
+}
+
+// CreateOrUpdateEvent 创建或更新 MonitorAlertEvent，写入前按 labelLimits 校验标签
 func (wd *webhookDao) CreateOrUpdateEvent(ctx context.Context, event *model.MonitorAlertEvent) error {
+	if err := wd.guardLabels(event); err != nil {
+		return err
+	}
+
+	defer wd.invalidateEventCache(ctx, event.Fingerprint)
+
 	// 使用事务确保操作的原子性
 	return wd.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := fillEventTeamID(tx, event); err != nil {
+			return err
+		}
+
 		var existingEvent model.MonitorAlertEvent
 
 		// 根据 fingerprint 查询是否存在该事件
-		err := tx.Where("fingerprint = ?", event.Fingerprint).First(&existingEvent).Error
+		err := tx.Scopes(utils.NotDeleted()).Where("fingerprint = ?", event.Fingerprint).First(&existingEvent).Error
 
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				// 记录不存在，创建新事件
+				if event.FiredAt <= 0 {
+					event.FiredAt = time.Now().Unix()
+				}
 				if err := tx.Create(event).Error; err != nil {
 					wd.l.Error("创建 MonitorAlertEvent 失败",
 						zap.Error(err),
@@ -152,6 +234,9 @@ func (wd *webhookDao) CreateOrUpdateEvent(ctx context.Context, event *model.Moni
 					)
 					return fmt.Errorf("failed to create MonitorAlertEvent: %w", err)
 				}
+				if err := syncEventLabels(tx, event.ID, event.Labels); err != nil {
+					return err
+				}
 				wd.l.Info("成功创建 MonitorAlertEvent",
 					zap.String("fingerprint", event.Fingerprint),
 				)
@@ -165,8 +250,8 @@ func (wd *webhookDao) CreateOrUpdateEvent(ctx context.Context, event *model.Moni
 			return fmt.Errorf("failed to query MonitorAlertEvent by fingerprint %s: %w", event.Fingerprint, err)
 		}
 
-		// 记录存在，执行更新
-		if err := tx.Model(&existingEvent).Updates(event).Error; err != nil {
+		// 记录存在，执行更新；fired_at 只在恢复后重新触发时更新，不随重复通知变化
+		if err := tx.Model(&existingEvent).Omit("fired_at").Updates(event).Error; err != nil {
 			wd.l.Error("更新 MonitorAlertEvent 失败",
 				zap.Error(err),
 				zap.Any("event", event),
@@ -174,6 +259,33 @@ func (wd *webhookDao) CreateOrUpdateEvent(ctx context.Context, event *model.Moni
 			return fmt.Errorf("failed to update MonitorAlertEvent: %w", err)
 		}
 
+		// Updates 忽略空标签，仅在收到新标签时重建索引
+		if len(event.Labels) > 0 {
+			if err := syncEventLabels(tx, existingEvent.ID, event.Labels); err != nil {
+				return err
+			}
+		}
+
+		// 重复收到同一告警时累加触发次数；已恢复的事件再次触发时开启新一轮告警，清零恢复时间
+		columns := map[string]interface{}{
+			"event_times": gorm.Expr("event_times + ?", 1),
+		}
+		if existingEvent.ResolvedAt > 0 {
+			firedAt := event.FiredAt
+			if firedAt <= 0 {
+				firedAt = time.Now().Unix()
+			}
+			columns["fired_at"] = firedAt
+			columns["resolved_at"] = 0
+		}
+		if err := tx.Model(&existingEvent).UpdateColumns(columns).Error; err != nil {
+			wd.l.Error("更新 MonitorAlertEvent 触发次数失败",
+				zap.Error(err),
+				zap.String("fingerprint", event.Fingerprint),
+			)
+			return fmt.Errorf("failed to increase MonitorAlertEvent event_times: %w", err)
+		}
+
 		wd.l.Info("成功更新 MonitorAlertEvent",
 			zap.String("fingerprint", event.Fingerprint),
 		)
@@ -181,12 +293,38 @@ func (wd *webhookDao) CreateOrUpdateEvent(ctx context.Context, event *model.Moni
 	})
 }
 
+// fillEventTeamID 事件未指定团队时继承所属发送组的团队，保证团队隔离查询能看到该事件
+func fillEventTeamID(tx *gorm.DB, event *model.MonitorAlertEvent) error {
+	if event.TeamID > 0 || event.SendGroupID <= 0 {
+		return nil
+	}
+
+	var teamIDs []int
+	if err := tx.Model(&model.MonitorSendGroup{}).Scopes(utils.NotDeleted()).
+		Where("id = ?", event.SendGroupID).
+		Pluck("team_id", &teamIDs).Error; err != nil {
+		return fmt.Errorf("failed to get team of MonitorSendGroup %d: %w", event.SendGroupID, err)
+	}
+	if len(teamIDs) > 0 {
+		event.TeamID = teamIDs[0]
+	}
+	return nil
+}
+
 // GetMonitorAlertEventByFingerprintId 根据fingerprintId获取MonitorAlertEvent
 func (wd *webhookDao) GetMonitorAlertEventByFingerprintId(ctx context.Context, fingerprintId string) (*model.MonitorAlertEvent, error) {
+	// 优先读取缓存，缓存异常时降级查询数据库
+	cached, err := wd.eventCache.Get(ctx, fingerprintId)
+	if err != nil {
+		wd.l.Warn("读取告警事件缓存失败", zap.Error(err), zap.String("fingerprintId", fingerprintId))
+	} else if cached != nil {
+		return cached, nil
+	}
+
 	var alertEvent model.MonitorAlertEvent
 
 	// 执行查询
-	if err := wd.db.WithContext(ctx).Where("fingerprint = ?", fingerprintId).First(&alertEvent).Error; err != nil {
+	if err := wd.db.WithContext(ctx).Scopes(utils.NotDeleted()).Where("fingerprint = ?", fingerprintId).First(&alertEvent).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			wd.l.Warn("MonitorAlertEvent 未找到", zap.String("fingerprintId", fingerprintId))
 			return nil, nil
@@ -195,9 +333,20 @@ func (wd *webhookDao) GetMonitorAlertEventByFingerprintId(ctx context.Context, f
 		return nil, fmt.Errorf("failed to get MonitorAlertEvent by fingerprint %s: %w", fingerprintId, err)
 	}
 
+	if err := wd.eventCache.Set(ctx, &alertEvent); err != nil {
+		wd.l.Warn("写入告警事件缓存失败", zap.Error(err), zap.String("fingerprintId", fingerprintId))
+	}
+
 	return &alertEvent, nil
 }
 
+// invalidateEventCache 事件变更后删除指纹缓存，下次查询时从数据库重新加载
+func (wd *webhookDao) invalidateEventCache(ctx context.Context, fingerprint string) {
+	if err := wd.eventCache.Delete(ctx, fingerprint); err != nil {
+		wd.l.Warn("删除告警事件缓存失败", zap.Error(err), zap.String("fingerprint", fingerprint))
+	}
+}
+
 // FillTodayOnDutyUser 为指定的值班组填充当天的值班用户
 func (wd *webhookDao) FillTodayOnDutyUser(ctx context.Context, onDutyGroup *model.MonitorOnDutyGroup) (*model.MonitorOnDutyGroup, error) {
 	// 获取当前日期的字符串表示，格式为 "YYYY-MM-DD"
@@ -296,10 +445,20 @@ func (wd *webhookDao) assignDefaultDutyUser(_ context.Context, onDutyGroup *mode
 
 // UpdateMonitorAlertEvent 更新 MonitorAlertEvent
 func (wd *webhookDao) UpdateMonitorAlertEvent(ctx context.Context, event *model.MonitorAlertEvent) error {
-	if err := wd.db.WithContext(ctx).
-		Model(&model.MonitorAlertEvent{}).
-		Where("id = ?", event.ID).
-		Updates(event).Error; err != nil {
+	err := wd.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.MonitorAlertEvent{}).
+			Scopes(utils.NotDeleted()).Where("id = ?", event.ID).
+			Updates(event)
+		if result.Error != nil {
+			return result.Error
+		}
+		// 事件已删除或未携带标签时不重建索引
+		if result.RowsAffected == 0 || len(event.Labels) == 0 {
+			return nil
+		}
+		return syncEventLabels(tx, event.ID, event.Labels)
+	})
+	if err != nil {
 		wd.l.Error("更新 MonitorAlertEvent 失败",
 			zap.Error(err),
 			zap.Any("event", event),
@@ -307,6 +466,49 @@ func (wd *webhookDao) UpdateMonitorAlertEvent(ctx context.Context, event *model.
 		return fmt.Errorf("failed to update MonitorAlertEvent: %w", err)
 	}
 
+	wd.invalidateEventCache(ctx, event.Fingerprint)
+
+	return nil
+}
+
+// ResolveAlertEventByFingerprint 收到 Alertmanager 恢复通知时将事件置为已恢复并清除认领，事件不存在时直接忽略
+func (wd *webhookDao) ResolveAlertEventByFingerprint(ctx context.Context, fingerprint string, resolvedAt int64) error {
+	if fingerprint == "" {
+		return fmt.Errorf("fingerprint 不能为空")
+	}
+
+	now := time.Now().Unix()
+	if resolvedAt <= 0 {
+		resolvedAt = now
+	}
+
+	// 重复的恢复通知保留首次恢复时间
+	result := wd.db.WithContext(ctx).
+		Model(&model.MonitorAlertEvent{}).
+		Scopes(utils.NotDeleted()).
+		Where("fingerprint = ?", fingerprint).
+		UpdateColumns(map[string]interface{}{
+			"status":           model.AlertStatusResolved,
+			"ren_ling_user_id": 0,
+			"resolved_at":      gorm.Expr("CASE WHEN resolved_at = 0 THEN ? ELSE resolved_at END", resolvedAt),
+			"updated_at":       now,
+		})
+	if result.Error != nil {
+		wd.l.Error("恢复 MonitorAlertEvent 失败",
+			zap.Error(result.Error),
+			zap.String("fingerprint", fingerprint),
+		)
+		return fmt.Errorf("failed to resolve MonitorAlertEvent by fingerprint %s: %w", fingerprint, result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		// 告警可能由其他实例创建，本地没有记录时无需处理
+		wd.l.Debug("恢复通知对应的 MonitorAlertEvent 不存在，跳过", zap.String("fingerprint", fingerprint))
+		return nil
+	}
+
+	wd.invalidateEventCache(ctx, fingerprint)
+
 	return nil
 }
 
@@ -365,3 +567,22 @@ func (wd *webhookDao) GetMonitorAlertRuleList(ctx context.Context) ([]*model.Mon
 
 	return rules, nil
 }
+
+// RecordNotification 记录告警事件的通知投递，messageID 为空表示渠道未返回消息ID
+func (wd *webhookDao) RecordNotification(ctx context.Context, eventID int, channel string, messageID string) error {
+	if eventID <= 0 {
+		return fmt.Errorf("无效的告警事件ID: %d", eventID)
+	}
+
+	record := &model.NotificationLog{
+		EventID:   eventID,
+		Channel:   channel,
+		MessageID: messageID,
+	}
+	if err := wd.db.WithContext(ctx).Create(record).Error; err != nil {
+		wd.l.Error("写入告警通知记录失败", zap.Error(err), zap.Int("eventID", eventID), zap.String("channel", channel))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,382 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/glebarez/sqlite"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestWebhookDao 创建基于内存 sqlite 的 webhookDao
+func newTestWebhookDao(t *testing.T) (*webhookDao, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("打开测试数据库失败: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取数据库连接失败: %v", err)
+	}
+	// 内存库每个连接独立，限制为单连接保证数据可见
+	sqlDB.SetMaxOpenConns(1)
+
+	// sqlite 的索引名在整个库内唯一，多张表同名的 idx_deleted_at 等索引会冲突，测试只依赖表结构，因此忽略索引重名错误
+	for _, m := range []interface{}{
+		&model.MonitorAlertEvent{},
+		&model.MonitorSendGroup{},
+		&model.AlertEventLabel{},
+		&model.NotificationLog{},
+	} {
+		if err := db.Migrator().CreateTable(m); err != nil && !strings.Contains(err.Error(), "already exists") {
+			t.Fatalf("迁移测试表失败: %v", err)
+		}
+	}
+
+	return NewWebhookDao(zap.NewNop(), db, nil).(*webhookDao), db
+}
+
+func TestCreateOrUpdateEventInheritsSendGroupTeam(t *testing.T) {
+	wd, db := newTestWebhookDao(t)
+	ctx := context.Background()
+
+	group := &model.MonitorSendGroup{Name: "ops", NameZh: "运维", TeamID: 7}
+	if err := db.Create(group).Error; err != nil {
+		t.Fatalf("创建发送组失败: %v", err)
+	}
+
+	event := &model.MonitorAlertEvent{
+		AlertName:   "cpu",
+		Fingerprint: "fp-1",
+		Status:      "firing",
+		SendGroupID: group.ID,
+		Labels:      model.Labels{"alertname": "cpu"},
+	}
+	if err := wd.CreateOrUpdateEvent(ctx, event); err != nil {
+		t.Fatalf("CreateOrUpdateEvent 返回错误: %v", err)
+	}
+
+	var stored model.MonitorAlertEvent
+	if err := db.Where("fingerprint = ?", "fp-1").First(&stored).Error; err != nil {
+		t.Fatalf("查询告警事件失败: %v", err)
+	}
+	if stored.TeamID != 7 {
+		t.Fatalf("告警事件应继承发送组的团队 7, 实际 %d", stored.TeamID)
+	}
+}
+
+func TestCreateOrUpdateEventRefireStartsNewIncident(t *testing.T) {
+	wd, db := newTestWebhookDao(t)
+	ctx := context.Background()
+
+	newEvent := func(firedAt int64) *model.MonitorAlertEvent {
+		return &model.MonitorAlertEvent{
+			AlertName:   "cpu",
+			Fingerprint: "fp-1",
+			Status:      "firing",
+			FiredAt:     firedAt,
+			Labels:      model.Labels{"alertname": "cpu"},
+		}
+	}
+
+	if err := wd.CreateOrUpdateEvent(ctx, newEvent(100)); err != nil {
+		t.Fatalf("CreateOrUpdateEvent 返回错误: %v", err)
+	}
+	// 同一轮告警重复通知不改变开始时间
+	if err := wd.CreateOrUpdateEvent(ctx, newEvent(150)); err != nil {
+		t.Fatalf("CreateOrUpdateEvent 返回错误: %v", err)
+	}
+	if err := wd.ResolveAlertEventByFingerprint(ctx, "fp-1", 200); err != nil {
+		t.Fatalf("ResolveAlertEventByFingerprint 返回错误: %v", err)
+	}
+	if err := wd.CreateOrUpdateEvent(ctx, newEvent(300)); err != nil {
+		t.Fatalf("CreateOrUpdateEvent 返回错误: %v", err)
+	}
+
+	var stored model.MonitorAlertEvent
+	if err := db.Where("fingerprint = ?", "fp-1").First(&stored).Error; err != nil {
+		t.Fatalf("查询告警事件失败: %v", err)
+	}
+	if stored.ResolvedAt != 0 {
+		t.Fatalf("重新触发后 resolved_at 应清零, 实际 %d", stored.ResolvedAt)
+	}
+	if stored.FiredAt != 300 {
+		t.Fatalf("重新触发后 fired_at 应为新一轮开始时间 300, 实际 %d", stored.FiredAt)
+	}
+	if stored.Status != "firing" || stored.EventTimes != 3 {
+		t.Fatalf("重新触发后状态应为 firing 且触发次数为 3, 实际 %s/%d", stored.Status, stored.EventTimes)
+	}
+}
+
+func TestResolveAlertEventByFingerprint(t *testing.T) {
+	wd, db := newTestWebhookDao(t)
+	ctx := context.Background()
+
+	claimed := &model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-1", Status: string(model.AlertStatusClaimed), RenLingUserID: 7, UpdatedAt: 1}
+	gone := &model.MonitorAlertEvent{AlertName: "disk", Fingerprint: "fp-2", Status: "firing", DeletedAt: 1}
+	for _, event := range []*model.MonitorAlertEvent{claimed, gone} {
+		if err := db.Create(event).Error; err != nil {
+			t.Fatalf("写入测试告警事件失败: %v", err)
+		}
+	}
+
+	if err := wd.ResolveAlertEventByFingerprint(ctx, "fp-1", 200); err != nil {
+		t.Fatalf("ResolveAlertEventByFingerprint 返回错误: %v", err)
+	}
+	// 重复的恢复通知保留首次恢复时间
+	if err := wd.ResolveAlertEventByFingerprint(ctx, "fp-1", 300); err != nil {
+		t.Fatalf("ResolveAlertEventByFingerprint 返回错误: %v", err)
+	}
+	var stored model.MonitorAlertEvent
+	if err := db.First(&stored, claimed.ID).Error; err != nil {
+		t.Fatalf("查询告警事件失败: %v", err)
+	}
+	if stored.Status != string(model.AlertStatusResolved) || stored.RenLingUserID != 0 || stored.ResolvedAt != 200 || stored.UpdatedAt <= 1 {
+		t.Fatalf("恢复后应为 resolved、清除认领、resolved_at=200 且更新 updated_at, 实际 %+v", stored)
+	}
+
+	// 指纹不存在或事件已删除时静默忽略，不创建也不修改记录
+	var before int64
+	db.Model(&model.MonitorAlertEvent{}).Count(&before)
+	for _, fingerprint := range []string{"fp-missing", "fp-2"} {
+		if err := wd.ResolveAlertEventByFingerprint(ctx, fingerprint, 200); err != nil {
+			t.Fatalf("%s: 事件不存在时应直接返回, 实际 %v", fingerprint, err)
+		}
+	}
+	var after int64
+	db.Model(&model.MonitorAlertEvent{}).Count(&after)
+	if after != before {
+		t.Fatalf("不应新增告警事件, 之前 %d 条, 之后 %d 条", before, after)
+	}
+	var deleted model.MonitorAlertEvent
+	if err := db.First(&deleted, gone.ID).Error; err != nil {
+		t.Fatalf("查询告警事件失败: %v", err)
+	}
+	if deleted.Status != "firing" || deleted.ResolvedAt != 0 {
+		t.Fatalf("已删除的事件不应被恢复, 实际 %+v", deleted)
+	}
+
+	if err := wd.ResolveAlertEventByFingerprint(ctx, "", 200); err == nil {
+		t.Fatal("fingerprint 为空时应返回错误")
+	}
+}
+
+func TestCreateOrUpdateEventStoresAnnotations(t *testing.T) {
+	wd, db := newTestWebhookDao(t)
+	ctx := context.Background()
+
+	newEvent := func(annotations model.Labels) *model.MonitorAlertEvent {
+		return &model.MonitorAlertEvent{
+			AlertName:   "cpu",
+			Fingerprint: "fp-1",
+			Status:      "firing",
+			Labels:      model.Labels{"alertname": "cpu"},
+			Annotations: annotations,
+		}
+	}
+	load := func() model.Labels {
+		t.Helper()
+		var stored model.MonitorAlertEvent
+		if err := db.Where("fingerprint = ?", "fp-1").First(&stored).Error; err != nil {
+			t.Fatalf("查询告警事件失败: %v", err)
+		}
+		return stored.Annotations
+	}
+
+	first := model.Labels{"summary": `CPU "95%"`, "runbook_url": "https://wiki.example.com/runbook/cpu"}
+	if err := wd.CreateOrUpdateEvent(ctx, newEvent(first)); err != nil {
+		t.Fatalf("CreateOrUpdateEvent 返回错误: %v", err)
+	}
+	if got := load(); !reflect.DeepEqual(got, first) {
+		t.Fatalf("注解应原样落库, 期望 %v, 实际 %v", first, got)
+	}
+
+	// 重复通知携带新注解时覆盖，未携带注解时保留原值
+	second := model.Labels{"summary": "CPU 恢复中"}
+	if err := wd.CreateOrUpdateEvent(ctx, newEvent(second)); err != nil {
+		t.Fatalf("CreateOrUpdateEvent 返回错误: %v", err)
+	}
+	if got := load(); !reflect.DeepEqual(got, second) {
+		t.Fatalf("注解应更新为 %v, 实际 %v", second, got)
+	}
+	if err := wd.CreateOrUpdateEvent(ctx, newEvent(nil)); err != nil {
+		t.Fatalf("CreateOrUpdateEvent 返回错误: %v", err)
+	}
+	if got := load(); !reflect.DeepEqual(got, second) {
+		t.Fatalf("未携带注解时应保留 %v, 实际 %v", second, got)
+	}
+
+	// JSON 输出中注解为对象
+	data, err := json.Marshal(&model.MonitorAlertEvent{Annotations: first})
+	if err != nil {
+		t.Fatalf("序列化告警事件失败: %v", err)
+	}
+	var out struct {
+		Annotations map[string]string `json:"annotations"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil || !reflect.DeepEqual(model.Labels(out.Annotations), first) {
+		t.Fatalf("JSON 中的注解应为对象, 实际 %s, %v", data, err)
+	}
+}
+
+func TestRecordNotification(t *testing.T) {
+	wd, db := newTestWebhookDao(t)
+	ctx := context.Background()
+
+	if err := wd.RecordNotification(ctx, 3, "feishu", "om_3"); err != nil {
+		t.Fatalf("记录通知失败: %v", err)
+	}
+	if err := wd.RecordNotification(ctx, 0, "feishu", ""); err == nil {
+		t.Fatal("无效事件ID应返回错误")
+	}
+
+	var logs []*model.NotificationLog
+	if err := db.Find(&logs).Error; err != nil {
+		t.Fatalf("查询通知记录失败: %v", err)
+	}
+	if len(logs) != 1 || logs[0].EventID != 3 || logs[0].Channel != "feishu" || logs[0].MessageID != "om_3" || logs[0].CreatedAt == 0 {
+		t.Fatalf("通知记录不符合预期: %+v", logs)
+	}
+}
+
+func TestBatchCreateAlertEvents(t *testing.T) {
+	wd, db := newTestWebhookDao(t)
+	ctx := context.Background()
+
+	viper.Set("prometheus.alert_event_batch_size", 100)
+	t.Cleanup(func() { viper.Set("prometheus.alert_event_batch_size", 0) })
+
+	events := make([]*model.MonitorAlertEvent, 0, 500)
+	for i := 0; i < 500; i++ {
+		events = append(events, &model.MonitorAlertEvent{
+			AlertName:   "cpu",
+			Fingerprint: "fp-" + strconv.Itoa(i),
+			Status:      "firing",
+			Labels:      model.Labels{"alertname": "cpu"},
+		})
+	}
+	if err := wd.BatchCreateAlertEvents(ctx, events); err != nil {
+		t.Fatalf("BatchCreateAlertEvents 返回错误: %v", err)
+	}
+
+	var count int64
+	if err := db.Model(&model.MonitorAlertEvent{}).Count(&count).Error; err != nil {
+		t.Fatalf("统计告警事件失败: %v", err)
+	}
+	if count != 500 {
+		t.Fatalf("应写入 500 条告警事件, 实际 %d", count)
+	}
+	if events[499].ID == 0 || events[499].FiredAt == 0 {
+		t.Fatalf("写入后应回填 ID 和 FiredAt: %+v", events[499])
+	}
+
+	// 任意一行失败时整体回滚，第二批中的重复指纹导致失败
+	failing := []*model.MonitorAlertEvent{
+		{AlertName: "disk", Fingerprint: "fp-new", Status: "firing", Labels: model.Labels{"alertname": "disk"}},
+		{AlertName: "disk", Fingerprint: "fp-0", Status: "firing", Labels: model.Labels{"alertname": "disk"}},
+	}
+	viper.Set("prometheus.alert_event_batch_size", 1)
+	if err := wd.BatchCreateAlertEvents(ctx, failing); err == nil {
+		t.Fatal("存在重复指纹时应返回错误")
+	}
+	if err := db.Model(&model.MonitorAlertEvent{}).Count(&count).Error; err != nil {
+		t.Fatalf("统计告警事件失败: %v", err)
+	}
+	if count != 500 {
+		t.Fatalf("失败后应整体回滚, 实际共 %d 条", count)
+	}
+}
+
+// fakeEventCache 基于内存 map 的告警事件缓存
+type fakeEventCache struct {
+	events map[string]*model.MonitorAlertEvent
+	sets   int
+}
+
+func (c *fakeEventCache) Get(_ context.Context, fingerprint string) (*model.MonitorAlertEvent, error) {
+	return c.events[fingerprint], nil
+}
+
+func (c *fakeEventCache) Set(_ context.Context, event *model.MonitorAlertEvent) error {
+	c.sets++
+	c.events[event.Fingerprint] = event
+	return nil
+}
+
+func (c *fakeEventCache) Delete(_ context.Context, fingerprint string) error {
+	delete(c.events, fingerprint)
+	return nil
+}
+
+func TestGetMonitorAlertEventByFingerprintIdUsesCache(t *testing.T) {
+	wd, db := newTestWebhookDao(t)
+	cache := &fakeEventCache{events: map[string]*model.MonitorAlertEvent{}}
+	wd.eventCache = cache
+	ctx := context.Background()
+
+	// 命中缓存时不查询数据库，数据库中不存在的指纹也能返回
+	cache.events["fp-cached"] = &model.MonitorAlertEvent{ID: 99, Fingerprint: "fp-cached"}
+	event, err := wd.GetMonitorAlertEventByFingerprintId(ctx, "fp-cached")
+	if err != nil || event == nil || event.ID != 99 {
+		t.Fatalf("应返回缓存中的事件, 实际 %+v, %v", event, err)
+	}
+
+	// 未命中时查询数据库并回写缓存
+	stored := &model.MonitorAlertEvent{AlertName: "cpu", Fingerprint: "fp-1", Status: "firing", Labels: model.Labels{"alertname": "cpu"}}
+	if err := db.Create(stored).Error; err != nil {
+		t.Fatalf("创建告警事件失败: %v", err)
+	}
+	event, err = wd.GetMonitorAlertEventByFingerprintId(ctx, "fp-1")
+	if err != nil || event == nil || event.ID != stored.ID {
+		t.Fatalf("未命中缓存时应查询数据库, 实际 %+v, %v", event, err)
+	}
+	if cache.sets != 1 || cache.events["fp-1"] == nil {
+		t.Fatalf("未命中缓存时应回写缓存, 实际写入 %d 次", cache.sets)
+	}
+
+	// 更新事件后删除缓存
+	if err := wd.UpdateMonitorAlertEvent(ctx, &model.MonitorAlertEvent{ID: stored.ID, Fingerprint: "fp-1", Status: "resolved"}); err != nil {
+		t.Fatalf("UpdateMonitorAlertEvent 返回错误: %v", err)
+	}
+	if _, ok := cache.events["fp-1"]; ok {
+		t.Fatal("更新事件后应删除指纹缓存")
+	}
+	event, err = wd.GetMonitorAlertEventByFingerprintId(ctx, "fp-1")
+	if err != nil || event == nil || event.Status != "resolved" {
+		t.Fatalf("缓存失效后应读取到最新状态, 实际 %+v, %v", event, err)
+	}
+}
@@ -25,9 +25,9 @@
 
 package di
 
-import "github.com/prometheus/alertmanager/template"
+import "github.com/GoSimplicity/AI-CloudOps/internal/model"
 
-func CreateAlertChan() chan template.Alert {
-	alerts := make(chan template.Alert, 1000)
+func CreateAlertChan() chan *model.MonitorAlertEvent {
+	alerts := make(chan *model.MonitorAlertEvent, 1000)
 	return alerts
 }
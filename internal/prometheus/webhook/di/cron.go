@@ -29,11 +29,19 @@ import (
 	"context"
 	"github.com/GoSimplicity/AI-CloudOps/internal/prometheus/webhook/cache"
 	"github.com/GoSimplicity/AI-CloudOps/internal/prometheus/webhook/consumer"
+	"github.com/GoSimplicity/AI-CloudOps/internal/prometheus/webhook/dao"
 	"go.uber.org/zap"
 )
 
-func InitWebHookCache(logger *zap.Logger, webHookCache cache.WebhookCache, webHookConsumer consumer.WebhookConsumer) func() {
+func InitWebHookCache(logger *zap.Logger, webHookCache cache.WebhookCache, webHookConsumer consumer.WebhookConsumer, webhookDao dao.WebhookDao) func() {
 	return func() {
+		// 为标签索引上线前的告警事件补建索引
+		go func() {
+			if _, err := webhookDao.BackfillEventLabels(context.Background()); err != nil {
+				logger.Error("回填告警事件标签索引失败", zap.Error(err))
+			}
+		}()
+
 		// 执行初始刷新 WebHookCache
 		go func() {
 			ctx := context.Background() // 使用持久上下文
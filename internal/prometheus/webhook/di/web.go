@@ -28,12 +28,15 @@ package di
 import (
 	webhookApi "github.com/GoSimplicity/AI-CloudOps/internal/prometheus/webhook/api"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // InitGinServer 初始化web服务
 func InitGinServer(m []gin.HandlerFunc, webHookHdl *webhookApi.WebHookHandler) *gin.Engine {
 	server := gin.Default()
 	server.Use(m...)
+	// 暴露进程自身的监控指标，供 Prometheus 抓取
+	server.GET("/metrics", gin.WrapH(promhttp.Handler()))
 	webHookHdl.RegisterRouters(server)
 	return server
 }
@@ -44,6 +44,8 @@ func InitWebServer() *Cmd {
 		InitMiddlewares,
 		CreateAlertChan,
 		InitDB,
+		InitAlertEventCache,
+		InitPrometheusRegisterer,
 		InitWebHookCache,
 		api.NewWebHookHandler,
 		cache.NewWebhookCache,
@@ -21,15 +21,17 @@ func InitWebServer() *Cmd {
 	logger := InitLogger()
 	v := InitMiddlewares(logger)
 	db := InitDB()
-	webhookDao := dao.NewWebhookDao(logger, db)
+	alertEventCache := InitAlertEventCache()
+	webhookDao := dao.NewWebhookDao(logger, db, alertEventCache)
 	v2 := CreateAlertChan()
 	webHookHandler := api.NewWebHookHandler(logger, webhookDao, v2)
 	engine := InitGinServer(v, webHookHandler)
 	webhookRobot := robot.NewWebhookRobot(logger)
 	webhookCache := cache.NewWebhookCache(logger, webhookDao, webhookRobot)
-	webhookContent := content.NewWebhookContent(logger, webhookDao, webhookRobot)
+	registerer := InitPrometheusRegisterer()
+	webhookContent := content.NewWebhookContent(logger, webhookDao, webhookRobot, registerer)
 	webhookConsumer := consumer.NewWebhookConsumer(logger, webhookCache, webhookDao, webhookContent, v2)
-	v3 := InitWebHookCache(logger, webhookCache, webhookConsumer)
+	v3 := InitWebHookCache(logger, webhookCache, webhookConsumer, webhookDao)
 	cmd := &Cmd{
 		Server: engine,
 		Start:  v3,
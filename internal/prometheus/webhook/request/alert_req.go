@@ -25,6 +25,17 @@
 
 package request
 
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/GoSimplicity/AI-CloudOps/internal/prometheus/webhook/constant"
+	"github.com/prometheus/alertmanager/template"
+)
+
 // AlertmanagerSilenceResponse 表示告警管理器静默响应结构体
 type AlertmanagerSilenceResponse struct {
 	Status string `json:"status"`
@@ -32,3 +43,109 @@ type AlertmanagerSilenceResponse struct {
 		SilenceId string `json:"silenceId"`
 	} `json:"data"`
 }
+
+// alertmanagerWebhookPayload Alertmanager webhook 请求体中解析所需的字段
+type alertmanagerWebhookPayload struct {
+	Alerts []template.Alert `json:"alerts"`
+}
+
+// InvalidAlertsError 请求体中部分告警不合法，这些告警被跳过，其余告警仍正常返回
+type InvalidAlertsError struct {
+	Errs []error // 每条不合法告警的原因
+}
+
+func (e *InvalidAlertsError) Error() string {
+	msgs := make([]string, 0, len(e.Errs))
+	for _, err := range e.Errs {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("跳过%d条不合法的告警: %s", len(e.Errs), strings.Join(msgs, "; "))
+}
+
+// ParseAlertmanagerWebhook 解析 Alertmanager webhook 请求体，将其中的 firing 和 resolved 告警转换为告警事件；
+// 告警规则ID和发送组ID分别取自 alert_rule_id 和 alert_send_group 标签，原始告警保存在事件的 Alert 字段中。
+// 单条告警不合法时跳过该告警，返回其余事件和 *InvalidAlertsError；请求体无法解析时返回 nil 和错误
+func ParseAlertmanagerWebhook(body []byte) ([]*model.MonitorAlertEvent, error) {
+	var payload alertmanagerWebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("解析 Alertmanager webhook 失败: %w", err)
+	}
+
+	events := make([]*model.MonitorAlertEvent, 0, len(payload.Alerts))
+	var invalid []error
+	for i, alert := range payload.Alerts {
+		event, err := alertToEvent(alert)
+		if err != nil {
+			invalid = append(invalid, fmt.Errorf("第%d条告警(fingerprint=%q)不合法: %w", i+1, alert.Fingerprint, err))
+			continue
+		}
+		events = append(events, event)
+	}
+
+	if len(invalid) > 0 {
+		return events, &InvalidAlertsError{Errs: invalid}
+	}
+	return events, nil
+}
+
+// alertToEvent 将单条 Alertmanager 告警转换为告警事件
+func alertToEvent(alert template.Alert) (*model.MonitorAlertEvent, error) {
+	if alert.Fingerprint == "" {
+		return nil, fmt.Errorf("fingerprint不能为空")
+	}
+	if alert.Status != string(constant.AlertStatusFiring) && alert.Status != string(constant.AlertStatusResolved) {
+		return nil, fmt.Errorf("不支持的告警状态: %q", alert.Status)
+	}
+
+	ruleID, err := intLabel(alert.Labels, "alert_rule_id")
+	if err != nil {
+		return nil, err
+	}
+	sendGroupID, err := intLabel(alert.Labels, "alert_send_group")
+	if err != nil {
+		return nil, err
+	}
+
+	labels := make(model.Labels, len(alert.Labels))
+	for key, val := range alert.Labels {
+		labels[key] = val
+	}
+	annotations := make(model.Labels, len(alert.Annotations))
+	for key, val := range alert.Annotations {
+		annotations[key] = val
+	}
+
+	event := &model.MonitorAlertEvent{
+		AlertName:   alert.Labels["alertname"],
+		Fingerprint: alert.Fingerprint,
+		Status:      alert.Status,
+		Severity:    model.SeverityFromLabels(labels),
+		RuleID:      ruleID,
+		SendGroupID: sendGroupID,
+		Labels:      labels,
+		Annotations: annotations,
+		Alert:       alert,
+	}
+	if !alert.StartsAt.IsZero() {
+		event.FiredAt = alert.StartsAt.Unix()
+	}
+	if alert.Status == string(constant.AlertStatusResolved) && !alert.EndsAt.IsZero() {
+		event.ResolvedAt = alert.EndsAt.Unix()
+	}
+
+	return event, nil
+}
+
+// intLabel 读取整数类型的标签，标签不存在时返回0
+func intLabel(labels template.KV, key string) (int, error) {
+	val, ok := labels[key]
+	if !ok {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, fmt.Errorf("标签 %s 不是有效的整数: %q", key, val)
+	}
+	return n, nil
+}
@@ -0,0 +1,121 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package request
+
+import (
+	"errors"
+	"testing"
+)
+
+const multiAlertPayload = `{
+  "receiver": "cloudops",
+  "status": "firing",
+  "alerts": [
+    {
+      "status": "firing",
+      "labels": {"alertname": "HighCPU", "severity": "Critical", "alert_rule_id": "3", "alert_send_group": "5"},
+      "annotations": {"summary": "CPU 使用率过高"},
+      "startsAt": "2024-01-01T00:00:00Z",
+      "endsAt": "0001-01-01T00:00:00Z",
+      "fingerprint": "fp-firing"
+    },
+    {
+      "status": "resolved",
+      "labels": {"alertname": "DiskFull", "alert_rule_id": "4"},
+      "annotations": {},
+      "startsAt": "2024-01-01T00:00:00Z",
+      "endsAt": "2024-01-01T01:00:00Z",
+      "fingerprint": "fp-resolved"
+    },
+    {
+      "status": "firing",
+      "labels": {"alertname": "Broken", "alert_rule_id": "abc"},
+      "fingerprint": "fp-invalid"
+    },
+    {
+      "status": "firing",
+      "labels": {"alertname": "NoFingerprint"}
+    }
+  ]
+}`
+
+func TestParseAlertmanagerWebhookMultiAlert(t *testing.T) {
+	events, err := ParseAlertmanagerWebhook([]byte(multiAlertPayload))
+
+	var invalid *InvalidAlertsError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("存在不合法告警时应返回 *InvalidAlertsError, 实际 %v", err)
+	}
+	if len(invalid.Errs) != 2 {
+		t.Fatalf("应跳过2条不合法告警, 实际 %d: %v", len(invalid.Errs), invalid.Errs)
+	}
+	if len(events) != 2 {
+		t.Fatalf("应返回2条合法告警, 实际 %d", len(events))
+	}
+
+	firing := events[0]
+	if firing.Fingerprint != "fp-firing" || firing.Status != "firing" || firing.AlertName != "HighCPU" {
+		t.Fatalf("firing 告警解析错误: %+v", firing)
+	}
+	if firing.RuleID != 3 || firing.SendGroupID != 5 {
+		t.Fatalf("规则ID和发送组ID应取自标签, 实际 %d/%d", firing.RuleID, firing.SendGroupID)
+	}
+	if firing.Severity != "critical" {
+		t.Fatalf("告警级别应统一为小写, 实际 %q", firing.Severity)
+	}
+	if firing.Annotations["summary"] != "CPU 使用率过高" {
+		t.Fatalf("注解解析错误: %v", firing.Annotations)
+	}
+	if firing.FiredAt != 1704067200 || firing.ResolvedAt != 0 {
+		t.Fatalf("firing 告警时间解析错误: fired_at=%d resolved_at=%d", firing.FiredAt, firing.ResolvedAt)
+	}
+	if firing.Alert.Fingerprint != "fp-firing" {
+		t.Fatalf("原始告警应保存在 Alert 字段中")
+	}
+
+	resolved := events[1]
+	if resolved.Status != "resolved" || resolved.SendGroupID != 0 {
+		t.Fatalf("resolved 告警解析错误: %+v", resolved)
+	}
+	if resolved.Severity != "warning" {
+		t.Fatalf("缺少 severity 标签时应为 warning, 实际 %q", resolved.Severity)
+	}
+	if resolved.ResolvedAt != 1704070800 {
+		t.Fatalf("恢复时间应取自 endsAt, 实际 %d", resolved.ResolvedAt)
+	}
+}
+
+func TestParseAlertmanagerWebhookInvalidJSON(t *testing.T) {
+	events, err := ParseAlertmanagerWebhook([]byte(`{"alerts": [`))
+	if err == nil || events != nil {
+		t.Fatalf("请求体无法解析时应返回错误且不返回事件")
+	}
+
+	var invalid *InvalidAlertsError
+	if errors.As(err, &invalid) {
+		t.Fatalf("请求体无法解析时不应返回 *InvalidAlertsError")
+	}
+}
@@ -48,8 +48,13 @@ type WebhookRobot interface {
 	GetPrivateRobotToken() string
 	// GetTenantAccessToken 获取租户访问令牌
 	GetTenantAccessToken(ctx context.Context) (string, error)
+	// PingNotificationTarget 检查通知地址是否可达
+	PingNotificationTarget(ctx context.Context, url string) error
 }
 
+// pingTimeout 通知地址连通性检查的超时时间
+const pingTimeout = 3 * time.Second
+
 // webhookRobot 是 WebhookRobot 接口的实现
 type webhookRobot struct {
 	privateRobotToken string
@@ -130,6 +135,46 @@ func (w *webhookRobot) GetPrivateRobotToken() string {
 	return w.privateRobotToken
 }
 
+// PingNotificationTarget 通过 HEAD 请求检查通知地址是否可达，不会向群组发送任何消息
+func (w *webhookRobot) PingNotificationTarget(ctx context.Context, url string) error {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	if err := PingURL(ctx, w.httpClient, url, nil); err != nil {
+		w.logger.Warn("通知地址检查失败",
+			zap.Error(err),
+			zap.String("url", url),
+		)
+		return err
+	}
+
+	return nil
+}
+
+// PingURL 使用给定的 client 向 url 发送 HEAD 请求检查其是否可达，headers 会附加到请求上。
+// 飞书 webhook 不支持 HEAD 时会返回 4xx，此时仍说明地址可达，只有连接失败或 5xx 视为不可达
+func PingURL(ctx context.Context, client *http.Client, url string, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return fmt.Errorf("创建检查请求失败: %w", err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("通知地址不可达: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("通知地址返回异常状态码: %s", resp.Status)
+	}
+
+	return nil
+}
+
 // postWithJson 发送带有JSON字节的POST请求
 func (w *webhookRobot) postWithJson(ctx context.Context, url string, jsonBytes []byte, headers map[string]string) ([]byte, error) {
 	// 创建HTTP请求
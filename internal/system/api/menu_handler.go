@@ -26,21 +26,25 @@
 package api
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/GoSimplicity/AI-CloudOps/internal/system/dao"
 	"github.com/GoSimplicity/AI-CloudOps/internal/system/service"
 	"github.com/GoSimplicity/AI-CloudOps/pkg/utils"
 	"github.com/gin-gonic/gin"
 )
 
 type MenuHandler struct {
-	svc service.MenuService
+	svc      service.MenuService
+	auditSvc service.AuditService
 }
 
-func NewMenuHandler(svc service.MenuService) *MenuHandler {
+func NewMenuHandler(svc service.MenuService, auditSvc service.AuditService) *MenuHandler {
 	return &MenuHandler{
-		svc: svc,
+		svc:      svc,
+		auditSvc: auditSvc,
 	}
 }
 
@@ -51,6 +55,11 @@ func (m *MenuHandler) RegisterRouters(server *gin.Engine) {
 	menuGroup.POST("/create", m.CreateMenu)
 	menuGroup.POST("/update", m.UpdateMenu)
 	menuGroup.DELETE("/:id", m.DeleteMenu)
+	menuGroup.GET("/:id/delete_preview", m.PreviewMenuDelete)
+	menuGroup.POST("/:id/restore", m.RestoreMenu)
+	menuGroup.POST("/:id/move", m.MoveMenu)
+	menuGroup.POST("/:id/permission", m.SetMenuPermission)
+	menuGroup.POST("/hidden", m.SetMenuHiddenBulk)
 	menuGroup.POST("/update_related", m.UpdateUserMenu)
 }
 
@@ -127,6 +136,8 @@ func (m *MenuHandler) UpdateMenu(c *gin.Context) {
 
 // DeleteMenu 删除菜单
 func (m *MenuHandler) DeleteMenu(c *gin.Context) {
+	uc := c.MustGet("user").(utils.UserClaims)
+
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		utils.ErrorWithMessage(c, "参数错误")
@@ -134,13 +145,139 @@ func (m *MenuHandler) DeleteMenu(c *gin.Context) {
 	}
 
 	if err := m.svc.DeleteMenu(c.Request.Context(), id); err != nil {
+		if errors.Is(err, dao.ErrMenuHasChildren) {
+			utils.ErrorWithMessage(c, "删除菜单失败: "+err.Error())
+			return
+		}
 		utils.ErrorWithMessage(c, "删除菜单失败")
 		return
 	}
 
+	m.auditSvc.RecordAction(c.Request.Context(), uc.Uid, service.AuditActionMenuDelete, "menu", c.Param("id"), "")
+
 	utils.SuccessWithMessage(c, "删除成功")
 }
 
+// PreviewMenuDelete 预览删除菜单的结果，存在子菜单时标记为拒绝删除，不执行删除
+func (m *MenuHandler) PreviewMenuDelete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMessage(c, "参数错误")
+		return
+	}
+
+	preview, err := m.svc.PreviewMenuDelete(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, dao.ErrMenuNotFound) {
+			utils.NotFoundError(c, err.Error())
+			return
+		}
+		utils.ErrorWithMessage(c, "预览删除菜单失败: "+err.Error())
+		return
+	}
+
+	utils.SuccessWithData(c, preview)
+}
+
+// RestoreMenu 恢复已删除的菜单
+func (m *MenuHandler) RestoreMenu(c *gin.Context) {
+	uc := c.MustGet("user").(utils.UserClaims)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMessage(c, "参数错误")
+		return
+	}
+
+	if err := m.svc.RestoreMenu(c.Request.Context(), id); err != nil {
+		if errors.Is(err, dao.ErrMenuRouteNameConflict) {
+			utils.ConflictError(c, err.Error())
+			return
+		}
+		utils.ErrorWithMessage(c, "恢复菜单失败: "+err.Error())
+		return
+	}
+
+	m.auditSvc.RecordAction(c.Request.Context(), uc.Uid, service.AuditActionMenuRestore, "menu", c.Param("id"), "")
+
+	utils.SuccessWithMessage(c, "恢复成功")
+}
+
+// MoveMenu 移动菜单及其子菜单
+func (m *MenuHandler) MoveMenu(c *gin.Context) {
+	var req model.MoveMenuRequest
+
+	uc := c.MustGet("user").(utils.UserClaims)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMessage(c, "参数错误")
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorWithDetails(c, err, "参数错误")
+		return
+	}
+
+	if err := m.svc.MoveMenu(c.Request.Context(), id, req.ParentId, req.SortOrder); err != nil {
+		if errors.Is(err, dao.ErrMenuMoveCycle) {
+			utils.BadRequestError(c, err.Error())
+			return
+		}
+		utils.ErrorWithMessage(c, "移动菜单失败: "+err.Error())
+		return
+	}
+
+	m.auditSvc.RecordAction(c.Request.Context(), uc.Uid, service.AuditActionMenuMove, "menu", c.Param("id"), "")
+
+	utils.SuccessWithMessage(c, "移动成功")
+}
+
+// SetMenuPermission 设置菜单关联的权限编码
+func (m *MenuHandler) SetMenuPermission(c *gin.Context) {
+	var req model.SetMenuPermissionRequest
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.ErrorWithMessage(c, "参数错误")
+		return
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorWithDetails(c, err, "参数错误")
+		return
+	}
+
+	if err := m.svc.SetMenuPermission(c.Request.Context(), id, req.PermissionCode); err != nil {
+		utils.ErrorWithMessage(c, "设置菜单权限失败: "+err.Error())
+		return
+	}
+
+	utils.SuccessWithMessage(c, "设置成功")
+}
+
+// SetMenuHiddenBulk 批量显示或隐藏菜单
+func (m *MenuHandler) SetMenuHiddenBulk(c *gin.Context) {
+	var req model.SetMenuHiddenBulkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorWithDetails(c, err, "参数错误")
+		return
+	}
+
+	affected, err := m.svc.SetMenuHiddenBulk(c.Request.Context(), req.Ids, req.Hidden)
+	if err != nil {
+		if errors.Is(err, dao.ErrInvalidMenu) {
+			utils.BadRequestError(c, err.Error())
+			return
+		}
+		utils.ErrorWithMessage(c, "批量设置菜单隐藏状态失败: "+err.Error())
+		return
+	}
+
+	utils.SuccessWithData(c, gin.H{"affected": affected})
+}
+
 // AddUserMenu 添加用户菜单关联
 func (m *MenuHandler) UpdateUserMenu(c *gin.Context) {
 	var req model.UpdateUserMenuRequest
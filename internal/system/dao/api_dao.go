@@ -32,6 +32,7 @@ import (
 	"time"
 
 	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/GoSimplicity/AI-CloudOps/pkg/utils"
 	"github.com/casbin/casbin/v2"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -87,7 +88,7 @@ func (a *apiDAO) GetApiById(ctx context.Context, id int) (*model.Api, error) {
 	}
 
 	var api model.Api
-	if err := a.db.WithContext(ctx).Where("id = ? AND deleted_at = ?", id, 0).First(&api).Error; err != nil {
+	if err := a.db.WithContext(ctx).Scopes(notDeleted).Where("id = ?", id).First(&api).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -144,7 +145,7 @@ func (a *apiDAO) UpdateApi(ctx context.Context, api *model.Api) error {
 
 	// 更新API记录
 	if err := tx.Model(&model.Api{}).
-		Where("id = ? AND deleted_at = ?", api.ID, 0).
+		Scopes(notDeleted).Where("id = ?", api.ID).
 		Updates(updates).Error; err != nil {
 		tx.Rollback()
 		return fmt.Errorf("更新API失败: %v", err)
@@ -216,12 +217,7 @@ func (a *apiDAO) DeleteApi(ctx context.Context, id int) error {
 		return errors.New("无效的API ID")
 	}
 
-	updates := map[string]interface{}{
-		"deleted_at":  1,
-		"update_time": time.Now().Unix(),
-	}
-
-	result := a.db.WithContext(ctx).Model(&model.Api{}).Where("id = ? AND deleted_at = ?", id, 0).Updates(updates)
+	result := a.db.WithContext(ctx).Model(&model.Api{}).Scopes(notDeleted).Where("id = ?", id).Updates(utils.SoftDeleteColumns())
 	if result.Error != nil {
 		return fmt.Errorf("删除API失败: %v", result.Error)
 	}
@@ -260,7 +256,7 @@ func (a *apiDAO) ListApis(ctx context.Context, page, pageSize int) ([]*model.Api
 	var total int64
 
 	// 构建基础查询
-	db := a.db.WithContext(ctx).Model(&model.Api{}).Where("deleted_at = ?", 0)
+	db := a.db.WithContext(ctx).Model(&model.Api{}).Scopes(notDeleted)
 
 	// 获取总数
 	if err := db.Count(&total).Error; err != nil {
@@ -74,7 +74,16 @@ func (d *auditDAO) ListAuditLogs(ctx context.Context, req *model.ListAuditLogsRe
 	if req.OperationType != "" {
 		query = query.Where("operation_type = ?", req.OperationType)
 	}
-	query = query.Where("created_at BETWEEN ? AND ?", req.StartTime, req.EndTime)
+	if req.Action != "" {
+		query = query.Where("action = ?", req.Action)
+	}
+	// 开始和结束时间分别生效，只指定其中一个时按单边范围过滤
+	if req.StartTime > 0 {
+		query = query.Where("created_at >= ?", req.StartTime)
+	}
+	if req.EndTime > 0 {
+		query = query.Where("created_at <= ?", req.EndTime)
+	}
 
 	if err = query.Count(&total).Error; err != nil {
 		d.l.Error("统计审计日志总数失败", zap.Error(err))
@@ -82,7 +91,7 @@ func (d *auditDAO) ListAuditLogs(ctx context.Context, req *model.ListAuditLogsRe
 	}
 
 	offset := (req.PageNumber - 1) * req.PageSize
-	if err = query.Order("created_at DESC").
+	if err = query.Order("created_at DESC, id DESC").
 		Offset(offset).
 		Limit(req.PageSize).
 		Find(&logs).Error; err != nil {
@@ -183,7 +192,7 @@ func (d *auditDAO) SearchAuditLogs(ctx context.Context, req *model.ListAuditLogs
 	}
 
 	offset := (req.PageNumber - 1) * req.PageSize
-	if err = query.Order("created_at DESC").
+	if err = query.Order("created_at DESC, id DESC").
 		Offset(offset).
 		Limit(req.PageSize).
 		Find(&logs).Error; err != nil {
@@ -200,7 +209,7 @@ func (d *auditDAO) ExportAuditLogs(ctx context.Context, req *model.ListAuditLogs
 	err := d.db.WithContext(ctx).
 		Model(&model.AuditLog{}).
 		Where("created_at BETWEEN ? AND ?", req.StartTime, req.EndTime).
-		Order("created_at DESC").
+		Order("created_at DESC, id DESC").
 		Find(&logs).Error
 	if err != nil {
 		d.l.Error("导出审计日志失败", zap.Error(err))
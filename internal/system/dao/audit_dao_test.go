@@ -0,0 +1,67 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package dao
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"go.uber.org/zap"
+)
+
+func TestListAuditLogsHonoursSingleTimeBound(t *testing.T) {
+	db := newTestDB(t, &model.AuditLog{})
+	d := NewAuditDAO(db, zap.NewNop())
+	ctx := context.Background()
+
+	for _, createdAt := range []int64{100, 200, 300} {
+		if err := db.Create(&model.AuditLog{UserID: 1, OperationType: "ACTION", Action: "menu.delete", CreatedAt: createdAt}).Error; err != nil {
+			t.Fatalf("写入测试审计日志失败: %v", err)
+		}
+	}
+
+	cases := []struct {
+		name       string
+		start, end int64
+		want       int64
+	}{
+		{name: "不限时间", want: 3},
+		{name: "仅开始时间", start: 200, want: 2},
+		{name: "仅结束时间", end: 200, want: 2},
+		{name: "时间范围", start: 150, end: 250, want: 1},
+	}
+	for _, c := range cases {
+		req := &model.ListAuditLogsRequest{PageNumber: 1, PageSize: 10, StartTime: c.start, EndTime: c.end}
+		logs, total, err := d.ListAuditLogs(ctx, req)
+		if err != nil {
+			t.Fatalf("%s: ListAuditLogs 返回错误: %v", c.name, err)
+		}
+		if total != c.want || int64(len(logs)) != c.want {
+			t.Fatalf("%s: 应返回 %d 条, 实际 total=%d len=%d", c.name, c.want, total, len(logs))
+		}
+	}
+}
@@ -29,30 +29,64 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/GoSimplicity/AI-CloudOps/pkg/utils"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var (
-	ErrMenuNotFound = errors.New("菜单不存在")
-	ErrInvalidMenu  = errors.New("无效的菜单参数")
+	ErrMenuNotFound          = errors.New("菜单不存在")
+	ErrInvalidMenu           = errors.New("无效的菜单参数")
+	ErrMenuRouteNameConflict = errors.New("路由名称已被其他菜单使用")
+	ErrMenuMoveCycle         = errors.New("不能将菜单移动到自身或其子菜单下")
+	ErrMenuHasChildren       = errors.New("存在子菜单,不能删除")
 )
 
+// maxMenuDepth 查询祖先菜单时的最大层级，防止父子关系成环导致死循环
+const maxMenuDepth = 16
+
+// menuTreeCacheTTL 菜单树缓存有效期，菜单写操作会主动使缓存失效
+const menuTreeCacheTTL = 5 * time.Minute
+
 type MenuDAO interface {
 	CreateMenu(ctx context.Context, menu *model.Menu) error
 	GetMenuById(ctx context.Context, id int) (*model.Menu, error)
 	UpdateMenu(ctx context.Context, menu *model.Menu) error
 	DeleteMenu(ctx context.Context, id int) error
+	PreviewMenuDelete(ctx context.Context, id int) (*model.MenuDeletePreview, error)
+	RestoreMenu(ctx context.Context, id int) error
+	MoveMenu(ctx context.Context, id, newParentID, newSortOrder int) error
+	SetMenuPermission(ctx context.Context, id int, permissionCode string) error
+	SetMenuHiddenBulk(ctx context.Context, ids []int, hidden int) (int64, error)
+	GetMenusByPermissionCodes(ctx context.Context, codes []string) ([]*model.Menu, error)
 	ListMenuTree(ctx context.Context) ([]*model.Menu, error)
+	GetMenuTree(ctx context.Context, maxDepth int) ([]*model.Menu, error)
 	UpdateUserMenu(ctx context.Context, userId int, menuIds []int) error
+	GetMenuAncestors(ctx context.Context, id int) ([]*model.Menu, error)
+	GetMenuTreeForUser(ctx context.Context, userID int, allowedMenuIDs []int) ([]*model.Menu, error)
+	GetMenuByPath(ctx context.Context, path string) (*model.Menu, error)
+	GetMenuStats(ctx context.Context) (*model.MenuStats, error)
+	FindDuplicateMenus(ctx context.Context) ([]*model.MenuDuplicateGroup, error)
+	InvalidateMenuCache()
+	GetMenuWithChildren(ctx context.Context, id int) (*model.Menu, error)
 }
 
 type menuDAO struct {
 	db *gorm.DB
 	l  *zap.Logger
+
+	// 菜单树缓存，读多写少，使用读写锁保护
+	treeMu       sync.RWMutex
+	treeCache    []*model.Menu
+	treeCachedAt time.Time
+	treeVersion  uint64 // 每次失效递增，防止加载期间发生写操作时回填旧数据
 }
 
 func NewMenuDAO(db *gorm.DB, l *zap.Logger) MenuDAO {
@@ -64,6 +98,8 @@ func NewMenuDAO(db *gorm.DB, l *zap.Logger) MenuDAO {
 
 // CreateMenu 创建菜单
 func (m *menuDAO) CreateMenu(ctx context.Context, menu *model.Menu) error {
+	defer m.InvalidateMenuCache()
+
 	if menu == nil {
 		return ErrInvalidMenu
 	}
@@ -81,7 +117,7 @@ func (m *menuDAO) CreateMenu(ctx context.Context, menu *model.Menu) error {
 		// 检查父菜单是否存在
 		if menu.ParentID != 0 {
 			var count int64
-			if err := tx.Model(&model.Menu{}).Where("id = ? AND deleted_at = ?", menu.ParentID, 0).Count(&count).Error; err != nil {
+			if err := tx.Model(&model.Menu{}).Scopes(notDeleted).Where("id = ?", menu.ParentID).Count(&count).Error; err != nil {
 				return fmt.Errorf("检查父菜单失败: %v", err)
 			}
 			if count == 0 {
@@ -91,7 +127,7 @@ func (m *menuDAO) CreateMenu(ctx context.Context, menu *model.Menu) error {
 
 		// 检查同级菜单名称是否重复
 		var count int64
-		if err := tx.Model(&model.Menu{}).Where("name = ? AND parent_id = ? AND deleted_at = ?", menu.Name, menu.ParentID, 0).Count(&count).Error; err != nil {
+		if err := tx.Model(&model.Menu{}).Scopes(notDeleted).Where("name = ? AND parent_id = ?", menu.Name, menu.ParentID).Count(&count).Error; err != nil {
 			return fmt.Errorf("检查菜单名称失败: %v", err)
 		}
 		if count > 0 {
@@ -130,7 +166,56 @@ func (m *menuDAO) GetMenuById(ctx context.Context, id int) (*model.Menu, error)
 	}
 
 	var menu model.Menu
-	if err := m.db.WithContext(ctx).Where("id = ? AND deleted_at = ?", id, 0).First(&menu).Error; err != nil {
+	if err := m.db.WithContext(ctx).Scopes(notDeleted).Where("id = ?", id).First(&menu).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMenuNotFound
+		}
+		return nil, fmt.Errorf("查询菜单失败: %v", err)
+	}
+
+	return &menu, nil
+}
+
+// GetMenuWithChildren 获取菜单及其未删除的直接子菜单，子菜单按 sort_order 升序排列
+func (m *menuDAO) GetMenuWithChildren(ctx context.Context, id int) (*model.Menu, error) {
+	menu, err := m.GetMenuById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var children []*model.Menu
+	if err := m.db.WithContext(ctx).
+		Scopes(notDeleted).Where("parent_id = ?", id).
+		Order("sort_order ASC, id ASC").
+		Find(&children).Error; err != nil {
+		return nil, fmt.Errorf("查询子菜单失败: %v", err)
+	}
+
+	menu.Children = children
+	return menu, nil
+}
+
+// GetMenuByPath 根据路由路径获取菜单,忽略末尾斜杠的差异
+func (m *menuDAO) GetMenuByPath(ctx context.Context, path string) (*model.Menu, error) {
+	if path == "" {
+		return nil, errors.New("菜单路径不能为空")
+	}
+
+	normalized := strings.TrimRight(path, "/")
+	if normalized == "" {
+		normalized = "/"
+	}
+	candidates := []string{normalized}
+	if normalized != "/" {
+		candidates = append(candidates, normalized+"/")
+	}
+
+	var menu model.Menu
+	// 优先返回与传入路径完全一致的菜单。First 会追加主键排序并覆盖表达式排序，这里用 Take
+	if err := m.db.WithContext(ctx).
+		Scopes(notDeleted).Where("path IN ?", candidates).
+		Order(clause.OrderBy{Expression: clause.Expr{SQL: "path = ? DESC, id", Vars: []interface{}{path}}}).
+		Take(&menu).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrMenuNotFound
 		}
@@ -142,6 +227,8 @@ func (m *menuDAO) GetMenuById(ctx context.Context, id int) (*model.Menu, error)
 
 // UpdateMenu 更新菜单
 func (m *menuDAO) UpdateMenu(ctx context.Context, menu *model.Menu) error {
+	defer m.InvalidateMenuCache()
+
 	if menu == nil {
 		return errors.New("菜单对象不能为空")
 	}
@@ -158,7 +245,7 @@ func (m *menuDAO) UpdateMenu(ctx context.Context, menu *model.Menu) error {
 	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// 检查菜单是否存在
 		var exists bool
-		if err := tx.Model(&model.Menu{}).Select("1").Where("id = ? AND deleted_at = ?", menu.ID, 0).Find(&exists).Error; err != nil {
+		if err := tx.Model(&model.Menu{}).Select("1").Scopes(notDeleted).Where("id = ?", menu.ID).Find(&exists).Error; err != nil {
 			return fmt.Errorf("检查菜单是否存在失败: %v", err)
 		}
 		if !exists {
@@ -167,13 +254,13 @@ func (m *menuDAO) UpdateMenu(ctx context.Context, menu *model.Menu) error {
 
 		// 检查是否有子菜单
 		var childCount int64
-		if err := tx.Model(&model.Menu{}).Where("parent_id = ? AND deleted_at = ?", menu.ID, 0).Count(&childCount).Error; err != nil {
+		if err := tx.Model(&model.Menu{}).Scopes(notDeleted).Where("parent_id = ?", menu.ID).Count(&childCount).Error; err != nil {
 			return fmt.Errorf("检查子菜单失败: %v", err)
 		}
 
 		// 获取原菜单信息
 		var oldMenu model.Menu
-		if err := tx.Where("id = ? AND deleted_at = ?", menu.ID, 0).First(&oldMenu).Error; err != nil {
+		if err := tx.Scopes(notDeleted).Where("id = ?", menu.ID).First(&oldMenu).Error; err != nil {
 			return fmt.Errorf("获取原菜单信息失败: %v", err)
 		}
 
@@ -188,7 +275,7 @@ func (m *menuDAO) UpdateMenu(ctx context.Context, menu *model.Menu) error {
 				return errors.New("不能将菜单设置为自己的子菜单")
 			}
 			var count int64
-			if err := tx.Model(&model.Menu{}).Where("id = ? AND deleted_at = ?", menu.ParentID, 0).Count(&count).Error; err != nil {
+			if err := tx.Model(&model.Menu{}).Scopes(notDeleted).Where("id = ?", menu.ParentID).Count(&count).Error; err != nil {
 				return fmt.Errorf("检查父菜单失败: %v", err)
 			}
 			if count == 0 {
@@ -198,8 +285,8 @@ func (m *menuDAO) UpdateMenu(ctx context.Context, menu *model.Menu) error {
 
 		// 检查同级菜单名称是否重复
 		var count int64
-		if err := tx.Model(&model.Menu{}).Where("name = ? AND parent_id = ? AND id != ? AND deleted_at = ?",
-			menu.Name, menu.ParentID, menu.ID, 0).Count(&count).Error; err != nil {
+		if err := tx.Model(&model.Menu{}).Scopes(notDeleted).Where("name = ? AND parent_id = ? AND id != ?",
+			menu.Name, menu.ParentID, menu.ID).Count(&count).Error; err != nil {
 			return fmt.Errorf("检查菜单名称失败: %v", err)
 		}
 		if count > 0 {
@@ -208,7 +295,7 @@ func (m *menuDAO) UpdateMenu(ctx context.Context, menu *model.Menu) error {
 
 		// 更新菜单信息
 		menu.UpdatedAt = time.Now().Unix()
-		result := tx.Model(&model.Menu{}).Where("id = ? AND deleted_at = ?", menu.ID, 0).Updates(menu)
+		result := tx.Model(&model.Menu{}).Scopes(notDeleted).Where("id = ?", menu.ID).Updates(menu)
 		if result.Error != nil {
 			return fmt.Errorf("更新菜单失败: %v", result.Error)
 		}
@@ -222,6 +309,8 @@ func (m *menuDAO) UpdateMenu(ctx context.Context, menu *model.Menu) error {
 
 // DeleteMenu 删除菜单
 func (m *menuDAO) DeleteMenu(ctx context.Context, id int) error {
+	defer m.InvalidateMenuCache()
+
 	if id <= 0 {
 		return errors.New("无效的菜单ID")
 	}
@@ -229,7 +318,7 @@ func (m *menuDAO) DeleteMenu(ctx context.Context, id int) error {
 	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// 检查菜单是否存在
 		var exists bool
-		if err := tx.Model(&model.Menu{}).Select("1").Where("id = ? AND deleted_at = ?", id, 0).Find(&exists).Error; err != nil {
+		if err := tx.Model(&model.Menu{}).Select("1").Scopes(notDeleted).Where("id = ?", id).Find(&exists).Error; err != nil {
 			return fmt.Errorf("检查菜单是否存在失败: %v", err)
 		}
 		if !exists {
@@ -238,19 +327,15 @@ func (m *menuDAO) DeleteMenu(ctx context.Context, id int) error {
 
 		// 检查是否有子菜单
 		var count int64
-		if err := tx.Model(&model.Menu{}).Where("parent_id = ? AND deleted_at = ?", id, 0).Count(&count).Error; err != nil {
+		if err := tx.Model(&model.Menu{}).Scopes(notDeleted).Where("parent_id = ?", id).Count(&count).Error; err != nil {
 			return fmt.Errorf("检查子菜单失败: %v", err)
 		}
 		if count > 0 {
-			return errors.New("存在子菜单,不能删除")
+			return ErrMenuHasChildren
 		}
 
 		// 软删除菜单
-		updates := map[string]interface{}{
-			"deleted_at":  1,
-			"update_time": time.Now().Unix(),
-		}
-		result := tx.Model(&model.Menu{}).Where("id = ? AND deleted_at = ?", id, 0).Updates(updates)
+		result := tx.Model(&model.Menu{}).Scopes(notDeleted).Where("id = ?", id).Updates(utils.SoftDeleteColumns())
 		if result.Error != nil {
 			return fmt.Errorf("删除菜单失败: %v", result.Error)
 		}
@@ -261,15 +346,363 @@ func (m *menuDAO) DeleteMenu(ctx context.Context, id int) error {
 	})
 }
 
-// GetMenuTree 获取菜单树形结构
+// PreviewMenuDelete 预览删除菜单的结果，不修改任何数据。DeleteMenu 不做级联删除，
+// 菜单存在未删除的子孙菜单时预览标记为 blocked，并列出阻止删除的子孙菜单
+func (m *menuDAO) PreviewMenuDelete(ctx context.Context, id int) (*model.MenuDeletePreview, error) {
+	if id <= 0 {
+		return nil, errors.New("无效的菜单ID")
+	}
+
+	var root model.Menu
+	if err := m.db.WithContext(ctx).Scopes(notDeleted).Where("id = ?", id).First(&root).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMenuNotFound
+		}
+		return nil, fmt.Errorf("获取菜单失败: %v", err)
+	}
+
+	descendants := []*model.Menu{}
+	visited := map[int]struct{}{root.ID: {}}
+	parentIDs := []int{root.ID}
+
+	// 逐层查询子菜单，层数受 maxMenuDepth 限制，防止父子关系成环导致死循环
+	for depth := 0; len(parentIDs) > 0; depth++ {
+		if depth >= maxMenuDepth {
+			return nil, fmt.Errorf("菜单层级超过最大深度 %d,可能存在循环引用", maxMenuDepth)
+		}
+
+		var children []*model.Menu
+		if err := m.db.WithContext(ctx).
+			Scopes(notDeleted).
+			Where("parent_id IN ?", parentIDs).
+			Order("id ASC").
+			Find(&children).Error; err != nil {
+			return nil, fmt.Errorf("查询子菜单失败: %v", err)
+		}
+
+		parentIDs = parentIDs[:0]
+		for _, child := range children {
+			if _, ok := visited[child.ID]; ok {
+				continue
+			}
+			visited[child.ID] = struct{}{}
+			descendants = append(descendants, child)
+			parentIDs = append(parentIDs, child.ID)
+		}
+	}
+
+	preview := &model.MenuDeletePreview{Menu: &root, Descendants: descendants}
+	if len(descendants) > 0 {
+		preview.Blocked = true
+		preview.Reason = ErrMenuHasChildren.Error()
+	}
+	return preview, nil
+}
+
+// RestoreMenu 恢复已软删除的菜单，路由名称与现有菜单冲突或父菜单不存在时拒绝恢复
+func (m *menuDAO) RestoreMenu(ctx context.Context, id int) error {
+	defer m.InvalidateMenuCache()
+
+	if id <= 0 {
+		return errors.New("无效的菜单ID")
+	}
+
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var menu model.Menu
+		if err := tx.Scopes(deleted).Where("id = ?", id).First(&menu).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrMenuNotFound
+			}
+			return fmt.Errorf("查询已删除菜单失败: %v", err)
+		}
+
+		// 删除期间可能已有新菜单使用了相同的路由名称
+		var count int64
+		if err := tx.Model(&model.Menu{}).Scopes(notDeleted).Where("route_name = ?", menu.RouteName).Count(&count).Error; err != nil {
+			return fmt.Errorf("检查路由名称失败: %v", err)
+		}
+		if count > 0 {
+			return fmt.Errorf("%w: %s，请先修改冲突菜单的路由名称", ErrMenuRouteNameConflict, menu.RouteName)
+		}
+
+		if menu.ParentID != 0 {
+			if err := tx.Model(&model.Menu{}).Scopes(notDeleted).Where("id = ?", menu.ParentID).Count(&count).Error; err != nil {
+				return fmt.Errorf("检查父菜单失败: %v", err)
+			}
+			if count == 0 {
+				return errors.New("父菜单不存在,请先恢复父菜单")
+			}
+		}
+
+		result := tx.Model(&model.Menu{}).Scopes(deleted).Where("id = ?", id).Updates(utils.RestoreColumns())
+		if result.Error != nil {
+			return fmt.Errorf("恢复菜单失败: %v", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrMenuNotFound
+		}
+		return nil
+	})
+}
+
+// MoveMenu 将菜单连同其子树移动到新的父菜单下,并在事务中重排新旧同级菜单的排序
+func (m *menuDAO) MoveMenu(ctx context.Context, id, newParentID, newSortOrder int) error {
+	defer m.InvalidateMenuCache()
+
+	if id <= 0 || newParentID < 0 || newSortOrder < 0 {
+		return ErrInvalidMenu
+	}
+	if newParentID == id {
+		return ErrMenuMoveCycle
+	}
+
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var menu model.Menu
+		if err := tx.Scopes(notDeleted).Where("id = ?", id).First(&menu).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrMenuNotFound
+			}
+			return fmt.Errorf("获取菜单失败: %v", err)
+		}
+
+		// 沿新父菜单向上查找,祖先链中出现当前菜单说明目标位于其子树内
+		for parentID, depth := newParentID, 0; parentID != 0; depth++ {
+			if depth >= maxMenuDepth {
+				return fmt.Errorf("菜单层级超过最大深度 %d,可能存在循环引用", maxMenuDepth)
+			}
+			if parentID == id {
+				return ErrMenuMoveCycle
+			}
+
+			var parent model.Menu
+			if err := tx.Select("id, parent_id").Scopes(notDeleted).Where("id = ?", parentID).First(&parent).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errors.New("父菜单不存在")
+				}
+				return fmt.Errorf("检查父菜单失败: %v", err)
+			}
+			parentID = parent.ParentID
+		}
+
+		// 检查新的同级菜单中名称是否重复
+		var count int64
+		if err := tx.Model(&model.Menu{}).Scopes(notDeleted).Where("name = ? AND parent_id = ? AND id != ?",
+			menu.Name, newParentID, id).Count(&count).Error; err != nil {
+			return fmt.Errorf("检查菜单名称失败: %v", err)
+		}
+		if count > 0 {
+			return errors.New("同级菜单名称已存在")
+		}
+
+		now := time.Now().Unix()
+		if err := tx.Model(&model.Menu{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"parent_id":  newParentID,
+			"updated_at": now,
+		}).Error; err != nil {
+			return fmt.Errorf("移动菜单失败: %v", err)
+		}
+
+		// 子菜单通过 parent_id 关联,移动节点即移动整个子树,只需重排同级排序
+		if err := reindexMenuSiblings(tx, newParentID, id, newSortOrder); err != nil {
+			return err
+		}
+		if menu.ParentID != newParentID {
+			if err := reindexMenuSiblings(tx, menu.ParentID, 0, 0); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// reindexMenuSiblings 按现有顺序将 parentID 下的菜单重新编号为连续的 sort_order,
+// movedID 不为0时将该菜单插入到 position 位置,超出范围时放在末尾
+func reindexMenuSiblings(tx *gorm.DB, parentID, movedID, position int) error {
+	var siblings []*model.Menu
+	if err := tx.Select("id, sort_order").Scopes(notDeleted).
+		Where("parent_id = ? AND id != ?", parentID, movedID).
+		Order("sort_order ASC, id ASC").
+		Find(&siblings).Error; err != nil {
+		return fmt.Errorf("获取同级菜单失败: %v", err)
+	}
+
+	if movedID != 0 {
+		if position > len(siblings) {
+			position = len(siblings)
+		}
+		moved := &model.Menu{ID: movedID, SortOrder: -1}
+		siblings = append(siblings[:position], append([]*model.Menu{moved}, siblings[position:]...)...)
+	}
+
+	for i, sibling := range siblings {
+		if sibling.SortOrder == i {
+			continue
+		}
+		if err := tx.Model(&model.Menu{}).Where("id = ?", sibling.ID).Update("sort_order", i).Error; err != nil {
+			return fmt.Errorf("更新菜单排序失败: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// SetMenuPermission 设置菜单关联的权限编码,传入空字符串表示取消关联
+func (m *menuDAO) SetMenuPermission(ctx context.Context, id int, permissionCode string) error {
+	defer m.InvalidateMenuCache()
+
+	if id <= 0 {
+		return errors.New("无效的菜单ID")
+	}
+
+	result := m.db.WithContext(ctx).Model(&model.Menu{}).Scopes(notDeleted).Where("id = ?", id).Updates(map[string]interface{}{
+		"permission_code": permissionCode,
+		"updated_at":      time.Now().Unix(),
+	})
+	if result.Error != nil {
+		return fmt.Errorf("设置菜单权限编码失败: %v", result.Error)
+	}
+	// 重复设置相同权限编码时 MySQL 不计入影响行数，需要再确认菜单是否存在
+	if result.RowsAffected == 0 {
+		var count int64
+		if err := m.db.WithContext(ctx).Model(&model.Menu{}).Scopes(notDeleted).Where("id = ?", id).Count(&count).Error; err != nil {
+			return fmt.Errorf("查询菜单失败: %v", err)
+		}
+		if count == 0 {
+			return ErrMenuNotFound
+		}
+	}
+
+	return nil
+}
+
+// SetMenuHiddenBulk 用一条语句批量设置菜单的隐藏状态，hidden 只能为0或1，返回实际更新的菜单数
+func (m *menuDAO) SetMenuHiddenBulk(ctx context.Context, ids []int, hidden int) (int64, error) {
+	if len(ids) == 0 {
+		return 0, fmt.Errorf("%w: 菜单ID列表不能为空", ErrInvalidMenu)
+	}
+	if hidden != 0 && hidden != 1 {
+		return 0, fmt.Errorf("%w: hidden 只能为0或1", ErrInvalidMenu)
+	}
+
+	defer m.InvalidateMenuCache()
+
+	result := m.db.WithContext(ctx).Model(&model.Menu{}).Scopes(notDeleted).Where("id IN ?", ids).Updates(map[string]interface{}{
+		"hidden":     hidden,
+		"updated_at": time.Now().Unix(),
+	})
+	if result.Error != nil {
+		return 0, fmt.Errorf("批量设置菜单隐藏状态失败: %v", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// GetMenusByPermissionCodes 获取关联了任一给定权限编码的菜单,按排序和ID升序返回
+func (m *menuDAO) GetMenusByPermissionCodes(ctx context.Context, codes []string) ([]*model.Menu, error) {
+	menus := make([]*model.Menu, 0)
+	if len(codes) == 0 {
+		return menus, nil
+	}
+
+	if err := m.db.WithContext(ctx).
+		Scopes(notDeleted).
+		Where("permission_code IN ?", codes).
+		Order("sort_order ASC, id ASC").
+		Find(&menus).Error; err != nil {
+		return nil, fmt.Errorf("按权限编码查询菜单失败: %v", err)
+	}
+
+	return menus, nil
+}
+
+// ListMenuTree 获取菜单树形结构，缓存未过期时直接返回缓存副本
 func (m *menuDAO) ListMenuTree(ctx context.Context) ([]*model.Menu, error) {
+	m.treeMu.RLock()
+	if m.treeCache != nil && time.Since(m.treeCachedAt) < menuTreeCacheTTL {
+		tree := cloneMenuTree(m.treeCache)
+		m.treeMu.RUnlock()
+		return tree, nil
+	}
+	version := m.treeVersion
+	m.treeMu.RUnlock()
+
+	tree, err := m.loadMenuTree(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	m.treeMu.Lock()
+	if m.treeVersion == version {
+		m.treeCache = tree
+		m.treeCachedAt = time.Now()
+	}
+	m.treeMu.Unlock()
+
+	return cloneMenuTree(tree), nil
+}
+
+// GetMenuTree 获取最多 maxDepth 层的菜单树，超出深度的子菜单被省略并通过 HasChildren 标记，maxDepth 为 0 时不限制深度
+func (m *menuDAO) GetMenuTree(ctx context.Context, maxDepth int) ([]*model.Menu, error) {
+	if maxDepth < 0 {
+		return nil, errors.New("maxDepth不能为负数")
+	}
+
+	tree, err := m.ListMenuTree(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pruneMenuTree(tree, maxDepth, 1)
+
+	return tree, nil
+}
+
+// pruneMenuTree 标记各节点是否存在子菜单，并裁剪超出 maxDepth 的子菜单
+func pruneMenuTree(menus []*model.Menu, maxDepth int, depth int) {
+	for _, menu := range menus {
+		menu.HasChildren = len(menu.Children) > 0
+		if maxDepth > 0 && depth >= maxDepth {
+			menu.Children = []*model.Menu{}
+			continue
+		}
+		pruneMenuTree(menu.Children, maxDepth, depth+1)
+	}
+}
+
+// InvalidateMenuCache 使菜单树缓存失效，下次查询时重新从数据库加载
+func (m *menuDAO) InvalidateMenuCache() {
+	m.treeMu.Lock()
+	m.treeCache = nil
+	m.treeCachedAt = time.Time{}
+	m.treeVersion++
+	m.treeMu.Unlock()
+}
+
+// cloneMenuTree 深拷贝菜单树，避免调用方修改影响缓存
+func cloneMenuTree(menus []*model.Menu) []*model.Menu {
+	cloned := make([]*model.Menu, 0, len(menus))
+	for _, menu := range menus {
+		if menu == nil {
+			continue
+		}
+		copied := *menu
+		copied.Children = cloneMenuTree(menu.Children)
+		cloned = append(cloned, &copied)
+	}
+	return cloned
+}
+
+// loadMenuTree 从数据库加载菜单并构建树形结构
+func (m *menuDAO) loadMenuTree(ctx context.Context) ([]*model.Menu, error) {
 	// 预分配合适的初始容量
 	menus := make([]*model.Menu, 0, 50)
 
 	// 使用索引字段优化查询,查询所有必要字段
 	if err := m.db.WithContext(ctx).
-		Select("id, name, parent_id, path, component, route_name, hidden, redirect, meta, create_time, update_time").
-		Where("deleted_at = ?", 0).
+		Select("id, name, parent_id, sort_order, path, component, route_name, hidden, redirect, meta, created_at, updated_at").
+		Scopes(notDeleted).
+		Order("sort_order ASC, id ASC").
 		Find(&menus).Error; err != nil {
 		return nil, fmt.Errorf("查询菜单列表失败: %v", err)
 	}
@@ -321,7 +754,7 @@ func (m *menuDAO) UpdateUserMenu(ctx context.Context, userId int, menuIds []int)
 
 	// 检查用户是否存在且未删除
 	var user model.User
-	if err := m.db.WithContext(ctx).Where("id = ? AND deleted_at = ?", userId, 0).First(&user).Error; err != nil {
+	if err := m.db.WithContext(ctx).Scopes(notDeleted).Where("id = ?", userId).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			m.l.Error("用户不存在或已删除", zap.Int("userId", userId))
 			return errors.New("用户不存在或已删除")
@@ -332,7 +765,7 @@ func (m *menuDAO) UpdateUserMenu(ctx context.Context, userId int, menuIds []int)
 
 	// 检查所有菜单是否存在且未删除
 	var count int64
-	if err := m.db.WithContext(ctx).Model(&model.Menu{}).Where("id IN ? AND deleted_at = 0", menuIds).Count(&count).Error; err != nil {
+	if err := m.db.WithContext(ctx).Model(&model.Menu{}).Scopes(notDeleted).Where("id IN ?", menuIds).Count(&count).Error; err != nil {
 		m.l.Error("查询菜单失败", zap.Error(err))
 		return fmt.Errorf("查询菜单失败: %v", err)
 	}
@@ -367,3 +800,192 @@ func (m *menuDAO) UpdateUserMenu(ctx context.Context, userId int, menuIds []int)
 		return nil
 	})
 }
+
+// GetMenuAncestors 获取菜单的祖先链,按从根菜单到直接父菜单的顺序返回,不包含菜单本身
+func (m *menuDAO) GetMenuAncestors(ctx context.Context, id int) ([]*model.Menu, error) {
+	menu, err := m.GetMenuById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ancestors := make([]*model.Menu, 0, 4)
+	parentID := menu.ParentID
+
+	for depth := 0; parentID != 0; depth++ {
+		if depth >= maxMenuDepth {
+			return nil, fmt.Errorf("菜单层级超过最大深度 %d,可能存在循环引用", maxMenuDepth)
+		}
+
+		parent, err := m.GetMenuById(ctx, parentID)
+		if err != nil {
+			if errors.Is(err, ErrMenuNotFound) {
+				return nil, fmt.Errorf("父菜单 %d 不存在: %w", parentID, err)
+			}
+			return nil, err
+		}
+
+		ancestors = append(ancestors, parent)
+		parentID = parent.ParentID
+	}
+
+	// 反转为从根菜单到直接父菜单的顺序
+	for i, j := 0, len(ancestors)-1; i < j; i, j = i+1, j-1 {
+		ancestors[i], ancestors[j] = ancestors[j], ancestors[i]
+	}
+
+	return ancestors, nil
+}
+
+// GetMenuTreeForUser 构建用户可见的菜单树,仅包含有权限的菜单及保持树连通所需的祖先菜单
+func (m *menuDAO) GetMenuTreeForUser(ctx context.Context, userID int, allowedMenuIDs []int) ([]*model.Menu, error) {
+	if len(allowedMenuIDs) == 0 {
+		return []*model.Menu{}, nil
+	}
+
+	var menus []*model.Menu
+	if err := m.db.WithContext(ctx).Scopes(notDeleted).Find(&menus).Error; err != nil {
+		m.l.Error("查询菜单列表失败", zap.Int("userID", userID), zap.Error(err))
+		return nil, fmt.Errorf("查询菜单列表失败: %v", err)
+	}
+
+	menuMap := make(map[int]*model.Menu, len(menus))
+	for _, menu := range menus {
+		menu.Children = make([]*model.Menu, 0, 4)
+		menuMap[menu.ID] = menu
+	}
+
+	// 可见菜单为有权限的菜单及其所有祖先菜单,没有可见子菜单的父菜单自然不会被包含
+	visible := make(map[int]struct{}, len(allowedMenuIDs))
+	for _, id := range allowedMenuIDs {
+		menu, ok := menuMap[id]
+		for depth := 0; ok && depth < maxMenuDepth; depth++ {
+			if _, seen := visible[menu.ID]; seen {
+				break
+			}
+			visible[menu.ID] = struct{}{}
+			menu, ok = menuMap[menu.ParentID]
+		}
+	}
+
+	rootMenus := make([]*model.Menu, 0)
+	for _, menu := range menus {
+		if _, ok := visible[menu.ID]; !ok {
+			continue
+		}
+		if parent, exists := menuMap[menu.ParentID]; exists && menu.ParentID != 0 {
+			parent.Children = append(parent.Children, menu)
+		} else {
+			// 顶级菜单或父菜单不存在时,作为根节点处理
+			rootMenus = append(rootMenus, menu)
+		}
+	}
+
+	return rootMenus, nil
+}
+
+// GetMenuStats 统计菜单总数、隐藏数、已删除数及菜单树最大深度，只查询一次菜单表
+func (m *menuDAO) GetMenuStats(ctx context.Context) (*model.MenuStats, error) {
+	var menus []*model.Menu
+	if err := m.db.WithContext(ctx).
+		Select("id, parent_id, hidden, deleted_at").
+		Find(&menus).Error; err != nil {
+		m.l.Error("获取菜单统计失败", zap.Error(err))
+		return nil, fmt.Errorf("获取菜单统计失败: %v", err)
+	}
+
+	stats := &model.MenuStats{}
+	parents := make(map[int]int, len(menus))
+	for _, menu := range menus {
+		if menu.DeletedAt != 0 {
+			stats.Deleted++
+			continue
+		}
+		stats.Total++
+		if menu.Hidden == 1 {
+			stats.Hidden++
+		}
+		parents[menu.ID] = menu.ParentID
+	}
+
+	// 自底向上计算每个菜单的深度并缓存，父菜单已删除时视为顶级菜单
+	depths := make(map[int]int, len(parents))
+	for id := range parents {
+		var chain []int
+		current, base := id, 0
+		for {
+			if d, ok := depths[current]; ok {
+				base = d
+				break
+			}
+			parentID, ok := parents[current]
+			if !ok {
+				break
+			}
+			chain = append(chain, current)
+			if len(chain) > maxMenuDepth {
+				return nil, fmt.Errorf("菜单层级超过最大深度 %d,可能存在循环引用", maxMenuDepth)
+			}
+			current = parentID
+		}
+
+		for i := len(chain) - 1; i >= 0; i-- {
+			base++
+			depths[chain[i]] = base
+		}
+	}
+
+	for _, d := range depths {
+		if d > stats.MaxDepth {
+			stats.MaxDepth = d
+		}
+	}
+
+	return stats, nil
+}
+
+// FindDuplicateMenus 查找路由名称或路径相同的未删除菜单，用于在添加唯一索引前清理重复数据。
+// 分组按组内最小的路由名称升序排列，路由名称相同时按字段和字段值排序
+func (m *menuDAO) FindDuplicateMenus(ctx context.Context) ([]*model.MenuDuplicateGroup, error) {
+	var menus []*model.Menu
+	if err := m.db.WithContext(ctx).
+		Scopes(notDeleted).
+		Select("id, name, parent_id, path, route_name, created_at, updated_at").
+		Order("route_name ASC, id ASC").
+		Find(&menus).Error; err != nil {
+		m.l.Error("查询重复菜单失败", zap.Error(err))
+		return nil, fmt.Errorf("查询重复菜单失败: %v", err)
+	}
+
+	byRouteName := make(map[string][]*model.Menu)
+	byPath := make(map[string][]*model.Menu)
+	for _, menu := range menus {
+		byRouteName[menu.RouteName] = append(byRouteName[menu.RouteName], menu)
+		byPath[menu.Path] = append(byPath[menu.Path], menu)
+	}
+
+	// 菜单已按路由名称和ID排序，分组内顺序随之保持
+	groups := make([]*model.MenuDuplicateGroup, 0)
+	for routeName, items := range byRouteName {
+		if len(items) > 1 {
+			groups = append(groups, &model.MenuDuplicateGroup{Field: "route_name", Value: routeName, Menus: items})
+		}
+	}
+	for path, items := range byPath {
+		if len(items) > 1 {
+			groups = append(groups, &model.MenuDuplicateGroup{Field: "path", Value: path, Menus: items})
+		}
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		ri, rj := groups[i].Menus[0].RouteName, groups[j].Menus[0].RouteName
+		if ri != rj {
+			return ri < rj
+		}
+		if groups[i].Field != groups[j].Field {
+			return groups[i].Field > groups[j].Field
+		}
+		return groups[i].Value < groups[j].Value
+	})
+
+	return groups, nil
+}
@@ -0,0 +1,832 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package dao
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/glebarez/sqlite"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB 创建内存 sqlite 数据库并迁移给定模型
+func newTestDB(t *testing.T, models ...interface{}) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("打开测试数据库失败: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取数据库连接失败: %v", err)
+	}
+	// 内存库每个连接独立，限制为单连接保证数据可见
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatalf("迁移测试表失败: %v", err)
+	}
+	return db
+}
+
+// countMenuQueries 统计针对菜单表的查询次数
+func countMenuQueries(t *testing.T, db *gorm.DB) *int64 {
+	t.Helper()
+	var n int64
+	err := db.Callback().Query().Before("gorm:query").Register("test:count_menu_query", func(tx *gorm.DB) {
+		if tx.Statement.Table == "menus" {
+			atomic.AddInt64(&n, 1)
+		}
+	})
+	if err != nil {
+		t.Fatalf("注册查询回调失败: %v", err)
+	}
+	return &n
+}
+
+func newTestMenuDAO(t *testing.T) (*menuDAO, *int64) {
+	t.Helper()
+	db := newTestDB(t, &model.Menu{})
+	queries := countMenuQueries(t, db)
+	return NewMenuDAO(db, zap.NewNop()).(*menuDAO), queries
+}
+
+func TestListMenuTreeCacheHit(t *testing.T) {
+	m, queries := newTestMenuDAO(t)
+	m.treeCache = []*model.Menu{{ID: 1, Name: "系统管理"}}
+	m.treeCachedAt = time.Now()
+
+	tree, err := m.ListMenuTree(context.Background())
+	if err != nil {
+		t.Fatalf("ListMenuTree 返回错误: %v", err)
+	}
+	if got := atomic.LoadInt64(queries); got != 0 {
+		t.Fatalf("缓存命中时不应查询数据库, 实际查询 %d 次", got)
+	}
+	if len(tree) != 1 || tree[0].Name != "系统管理" {
+		t.Fatalf("返回的菜单树与缓存不一致: %+v", tree)
+	}
+
+	// 调用方修改返回值不应影响缓存
+	tree[0].Name = "被修改"
+	if m.treeCache[0].Name != "系统管理" {
+		t.Fatalf("修改返回值污染了缓存")
+	}
+}
+
+func TestListMenuTreeCacheExpired(t *testing.T) {
+	m, queries := newTestMenuDAO(t)
+	m.treeCache = []*model.Menu{{ID: 1, Name: "系统管理"}}
+	m.treeCachedAt = time.Now().Add(-menuTreeCacheTTL - time.Second)
+
+	_, _ = m.ListMenuTree(context.Background())
+	if got := atomic.LoadInt64(queries); got != 1 {
+		t.Fatalf("缓存过期后应重新查询数据库 1 次, 实际 %d 次", got)
+	}
+}
+
+func TestMenuCacheInvalidatedByMutation(t *testing.T) {
+	m, queries := newTestMenuDAO(t)
+	m.treeCache = []*model.Menu{{ID: 1, Name: "系统管理"}}
+	m.treeCachedAt = time.Now()
+
+	if err := m.CreateMenu(context.Background(), &model.Menu{Name: "用户管理", Path: "/user", Component: "User", RouteName: "User"}); err != nil {
+		t.Fatalf("CreateMenu 返回错误: %v", err)
+	}
+	if m.treeCache != nil {
+		t.Fatalf("写操作后缓存应被清空")
+	}
+
+	before := atomic.LoadInt64(queries)
+	_, _ = m.ListMenuTree(context.Background())
+	if got := atomic.LoadInt64(queries) - before; got != 1 {
+		t.Fatalf("缓存失效后应重新查询数据库 1 次, 实际 %d 次", got)
+	}
+}
+
+func TestRestoreMenu(t *testing.T) {
+	m, _ := newTestMenuDAO(t)
+	ctx := context.Background()
+
+	deleted := &model.Menu{Name: "用户管理", Path: "/user", Component: "User", RouteName: "User", DeletedAt: time.Now().Unix()}
+	if err := m.db.Create(deleted).Error; err != nil {
+		t.Fatalf("创建已删除菜单失败: %v", err)
+	}
+
+	if err := m.RestoreMenu(ctx, deleted.ID); err != nil {
+		t.Fatalf("RestoreMenu 返回错误: %v", err)
+	}
+	var restored model.Menu
+	if err := m.db.First(&restored, deleted.ID).Error; err != nil {
+		t.Fatalf("查询恢复后的菜单失败: %v", err)
+	}
+	if restored.DeletedAt != 0 || restored.UpdatedAt == 0 {
+		t.Fatalf("恢复后 deleted_at 应为 0 且 updated_at 已更新, 实际 %+v", restored)
+	}
+
+	if err := m.RestoreMenu(ctx, deleted.ID); !errors.Is(err, ErrMenuNotFound) {
+		t.Fatalf("恢复未删除的菜单应返回 ErrMenuNotFound, 实际 %v", err)
+	}
+}
+
+func TestMenuSoftDeleteUsesDeletedAtTimestamp(t *testing.T) {
+	m, _ := newTestMenuDAO(t)
+	ctx := context.Background()
+
+	live := &model.Menu{Name: "角色管理", RouteName: "Role"}
+	menu := &model.Menu{Name: "用户管理", RouteName: "User"}
+	seedMenus(t, m.db, live, menu)
+	if err := m.db.Model(&model.Menu{}).Where("id = ?", menu.ID).UpdateColumn("updated_at", 1).Error; err != nil {
+		t.Fatalf("重置更新时间失败: %v", err)
+	}
+
+	before := time.Now().Unix()
+	if err := m.DeleteMenu(ctx, menu.ID); err != nil {
+		t.Fatalf("删除菜单失败: %v", err)
+	}
+	var stored model.Menu
+	if err := m.db.First(&stored, menu.ID).Error; err != nil {
+		t.Fatalf("查询菜单失败: %v", err)
+	}
+	// 与告警事件一致，deleted_at 记录删除时的 Unix 时间戳并同步刷新 updated_at
+	if stored.DeletedAt < before || stored.UpdatedAt < before {
+		t.Fatalf("软删除应写入删除时间和更新时间, 实际 deleted_at=%d updated_at=%d", stored.DeletedAt, stored.UpdatedAt)
+	}
+
+	if _, err := m.GetMenuById(ctx, menu.ID); !errors.Is(err, ErrMenuNotFound) {
+		t.Fatalf("已删除菜单应返回 ErrMenuNotFound, 实际 %v", err)
+	}
+	tree, err := m.ListMenuTree(ctx)
+	if err != nil {
+		t.Fatalf("ListMenuTree 返回错误: %v", err)
+	}
+	if len(tree) != 1 || tree[0].ID != live.ID {
+		t.Fatalf("菜单树不应包含已删除菜单, 实际 %d 个顶级菜单", len(tree))
+	}
+	var deletedMenus []*model.Menu
+	if err := m.db.Scopes(deleted).Find(&deletedMenus).Error; err != nil {
+		t.Fatalf("查询已删除菜单失败: %v", err)
+	}
+	if len(deletedMenus) != 1 || deletedMenus[0].ID != menu.ID {
+		t.Fatalf("deleted 应只返回已删除菜单, 实际 %d 条", len(deletedMenus))
+	}
+}
+
+func TestRestoreMenuRouteNameConflict(t *testing.T) {
+	m, _ := newTestMenuDAO(t)
+	ctx := context.Background()
+
+	a := &model.Menu{Name: "用户管理", RouteName: "User"}
+	seedMenus(t, m.db, a)
+	if err := m.DeleteMenu(ctx, a.ID); err != nil {
+		t.Fatalf("删除菜单失败: %v", err)
+	}
+	// 路由名称与 deleted_at 组成唯一索引，将删除时间提前以免与稍后删除的同名菜单冲突
+	if err := m.db.Model(&model.Menu{}).Where("id = ?", a.ID).Update("deleted_at", time.Now().Add(-time.Hour).Unix()).Error; err != nil {
+		t.Fatalf("更新删除时间失败: %v", err)
+	}
+	b := &model.Menu{Name: "新用户管理", RouteName: "User"}
+	seedMenus(t, m.db, b)
+
+	if err := m.RestoreMenu(ctx, a.ID); !errors.Is(err, ErrMenuRouteNameConflict) {
+		t.Fatalf("路由名称冲突时应返回 ErrMenuRouteNameConflict, 实际 %v", err)
+	}
+	var stillDeleted model.Menu
+	if err := m.db.First(&stillDeleted, a.ID).Error; err != nil {
+		t.Fatalf("查询菜单失败: %v", err)
+	}
+	if stillDeleted.DeletedAt == 0 {
+		t.Fatal("冲突时不应恢复菜单")
+	}
+
+	// 冲突菜单删除后可以恢复
+	if err := m.DeleteMenu(ctx, b.ID); err != nil {
+		t.Fatalf("删除菜单失败: %v", err)
+	}
+	if err := m.RestoreMenu(ctx, a.ID); err != nil {
+		t.Fatalf("无冲突时应恢复成功: %v", err)
+	}
+}
+
+// TestPreviewMenuDeleteMatchesDelete 预览结果必须与 DeleteMenu 的实际行为一致
+func TestPreviewMenuDeleteMatchesDelete(t *testing.T) {
+	m, _ := newTestMenuDAO(t)
+	ctx := context.Background()
+
+	parent := &model.Menu{Name: "系统管理", Path: "/system", Component: "System", RouteName: "System"}
+	if err := m.db.Create(parent).Error; err != nil {
+		t.Fatalf("创建父菜单失败: %v", err)
+	}
+	child := &model.Menu{Name: "用户管理", Path: "/system/user", Component: "User", RouteName: "User", ParentID: parent.ID}
+	if err := m.db.Create(child).Error; err != nil {
+		t.Fatalf("创建子菜单失败: %v", err)
+	}
+
+	preview, err := m.PreviewMenuDelete(ctx, parent.ID)
+	if err != nil {
+		t.Fatalf("PreviewMenuDelete 返回错误: %v", err)
+	}
+	if !preview.Blocked || len(preview.Descendants) != 1 || preview.Descendants[0].ID != child.ID {
+		t.Fatalf("存在子菜单时预览应标记为拒绝删除并列出子菜单, 实际 %+v", preview)
+	}
+	if err := m.DeleteMenu(ctx, parent.ID); !errors.Is(err, ErrMenuHasChildren) {
+		t.Fatalf("存在子菜单时 DeleteMenu 应返回 ErrMenuHasChildren, 实际 %v", err)
+	}
+
+	preview, err = m.PreviewMenuDelete(ctx, child.ID)
+	if err != nil {
+		t.Fatalf("PreviewMenuDelete 返回错误: %v", err)
+	}
+	if preview.Blocked || len(preview.Descendants) != 0 || preview.Menu.ID != child.ID {
+		t.Fatalf("叶子菜单预览不应被拒绝, 实际 %+v", preview)
+	}
+	if err := m.DeleteMenu(ctx, child.ID); err != nil {
+		t.Fatalf("删除叶子菜单失败: %v", err)
+	}
+}
+
+// zeroUpdateRowsAffected 模拟 MySQL 对数据未变化的行不计入影响行数的行为
+func zeroUpdateRowsAffected(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	err := db.Callback().Update().After("gorm:update").Register("test:zero_rows_affected", func(tx *gorm.DB) {
+		tx.RowsAffected = 0
+	})
+	if err != nil {
+		t.Fatalf("注册更新回调失败: %v", err)
+	}
+}
+
+func TestSetMenuPermissionUnchanged(t *testing.T) {
+	m, _ := newTestMenuDAO(t)
+	ctx := context.Background()
+
+	menu := &model.Menu{Name: "用户管理", Path: "/user", Component: "User", RouteName: "User"}
+	if err := m.CreateMenu(ctx, menu); err != nil {
+		t.Fatalf("CreateMenu 返回错误: %v", err)
+	}
+	zeroUpdateRowsAffected(t, m.db)
+
+	if err := m.SetMenuPermission(ctx, menu.ID, "user:view"); err != nil {
+		t.Fatalf("数据未变化时不应返回错误, 实际 %v", err)
+	}
+	if err := m.SetMenuPermission(ctx, menu.ID+100, "user:view"); !errors.Is(err, ErrMenuNotFound) {
+		t.Fatalf("菜单不存在时应返回 ErrMenuNotFound, 实际 %v", err)
+	}
+}
+
+func TestMenuPermissionAssociationAndLookup(t *testing.T) {
+	m, _ := newTestMenuDAO(t)
+	ctx := context.Background()
+
+	user := &model.Menu{Name: "用户管理", RouteName: "User", SortOrder: 2}
+	role := &model.Menu{Name: "角色管理", RouteName: "Role", SortOrder: 1}
+	audit := &model.Menu{Name: "审计日志", RouteName: "Audit"}
+	legacy := &model.Menu{Name: "旧菜单", RouteName: "Legacy"}
+	seedMenus(t, m.db, user, role, audit, legacy)
+
+	for id, code := range map[int]string{user.ID: "user:view", role.ID: "role:view", audit.ID: "audit:view", legacy.ID: "user:view"} {
+		if err := m.SetMenuPermission(ctx, id, code); err != nil {
+			t.Fatalf("SetMenuPermission 返回错误: %v", err)
+		}
+	}
+	if err := m.DeleteMenu(ctx, legacy.ID); err != nil {
+		t.Fatalf("删除菜单失败: %v", err)
+	}
+	// 取消关联后不再出现在查询结果中
+	if err := m.SetMenuPermission(ctx, audit.ID, ""); err != nil {
+		t.Fatalf("SetMenuPermission 返回错误: %v", err)
+	}
+
+	var stored model.Menu
+	if err := m.db.First(&stored, user.ID).Error; err != nil {
+		t.Fatalf("查询菜单失败: %v", err)
+	}
+	if stored.PermissionCode != "user:view" {
+		t.Fatalf("菜单应关联权限编码 user:view, 实际 %q", stored.PermissionCode)
+	}
+
+	menus, err := m.GetMenusByPermissionCodes(ctx, []string{"user:view", "role:view", "audit:view", "unknown"})
+	if err != nil {
+		t.Fatalf("GetMenusByPermissionCodes 返回错误: %v", err)
+	}
+	names := make([]string, 0, len(menus))
+	for _, menu := range menus {
+		names = append(names, menu.RouteName)
+	}
+	// 按 sort_order 升序，不包含已删除和已取消关联的菜单
+	if got := strings.Join(names, ","); got != "Role,User" {
+		t.Fatalf("可访问菜单期望 Role,User, 实际 %s", got)
+	}
+
+	for _, codes := range [][]string{nil, {}, {"unknown"}} {
+		menus, err := m.GetMenusByPermissionCodes(ctx, codes)
+		if err != nil || menus == nil || len(menus) != 0 {
+			t.Fatalf("权限编码 %v 应返回空列表, 实际 %v, %v", codes, menus, err)
+		}
+	}
+}
+
+// seedMenus 按顺序写入测试菜单，ParentID 需引用已写入菜单的ID
+func seedMenus(t *testing.T, db *gorm.DB, menus ...*model.Menu) {
+	t.Helper()
+	for _, menu := range menus {
+		if menu.Path == "" {
+			menu.Path = "/" + menu.RouteName
+		}
+		if menu.Component == "" {
+			menu.Component = menu.RouteName
+		}
+		if err := db.Create(menu).Error; err != nil {
+			t.Fatalf("写入测试菜单失败: %v", err)
+		}
+	}
+}
+
+// seedMenuChain 写入 depth 层的菜单链，返回从根到叶子的菜单
+func seedMenuChain(t *testing.T, db *gorm.DB, depth int) []*model.Menu {
+	t.Helper()
+	chain := make([]*model.Menu, 0, depth)
+	parentID := 0
+	for i := 0; i < depth; i++ {
+		menu := &model.Menu{Name: "菜单" + strconv.Itoa(i+1), RouteName: "Level" + strconv.Itoa(i+1), ParentID: parentID}
+		seedMenus(t, db, menu)
+		chain = append(chain, menu)
+		parentID = menu.ID
+	}
+	return chain
+}
+
+func TestGetMenuAncestors(t *testing.T) {
+	m, _ := newTestMenuDAO(t)
+	ctx := context.Background()
+
+	chain := seedMenuChain(t, m.db, 4)
+
+	ancestors, err := m.GetMenuAncestors(ctx, chain[3].ID)
+	if err != nil {
+		t.Fatalf("GetMenuAncestors 返回错误: %v", err)
+	}
+	if len(ancestors) != 3 {
+		t.Fatalf("四级菜单应有 3 个祖先, 实际 %d", len(ancestors))
+	}
+	for i, ancestor := range ancestors {
+		if ancestor.ID != chain[i].ID {
+			t.Fatalf("祖先应按从根到父菜单的顺序返回, 第 %d 个期望 %d, 实际 %d", i, chain[i].ID, ancestor.ID)
+		}
+	}
+
+	ancestors, err = m.GetMenuAncestors(ctx, chain[0].ID)
+	if err != nil || len(ancestors) != 0 {
+		t.Fatalf("顶级菜单不应有祖先, 实际 %+v, %v", ancestors, err)
+	}
+
+	// 父菜单指向不存在的菜单时返回错误
+	broken := &model.Menu{Name: "断链菜单", RouteName: "Broken", ParentID: chain[3].ID + 100}
+	seedMenus(t, m.db, broken)
+	if _, err := m.GetMenuAncestors(ctx, broken.ID); !errors.Is(err, ErrMenuNotFound) {
+		t.Fatalf("父菜单不存在时应返回 ErrMenuNotFound, 实际 %v", err)
+	}
+}
+
+func TestGetMenuTreeForUserPartialPermissions(t *testing.T) {
+	m, _ := newTestMenuDAO(t)
+	ctx := context.Background()
+
+	system := &model.Menu{Name: "系统管理", RouteName: "System"}
+	monitor := &model.Menu{Name: "监控管理", RouteName: "Monitor"}
+	seedMenus(t, m.db, system, monitor)
+	user := &model.Menu{Name: "用户管理", RouteName: "User", ParentID: system.ID}
+	role := &model.Menu{Name: "角色管理", RouteName: "Role", ParentID: system.ID}
+	alert := &model.Menu{Name: "告警管理", RouteName: "Alert", ParentID: monitor.ID}
+	seedMenus(t, m.db, user, role, alert)
+
+	// 只允许子菜单时带上父菜单，未授权的兄弟菜单不可见
+	tree, err := m.GetMenuTreeForUser(ctx, 1, []int{role.ID})
+	if err != nil {
+		t.Fatalf("GetMenuTreeForUser 返回错误: %v", err)
+	}
+	if len(tree) != 1 || tree[0].ID != system.ID || len(tree[0].Children) != 1 || tree[0].Children[0].ID != role.ID {
+		t.Fatalf("应只包含角色管理及其父菜单: %+v", tree)
+	}
+
+	// 父菜单自身有权限时，即使没有可见子菜单也保留
+	tree, err = m.GetMenuTreeForUser(ctx, 1, []int{monitor.ID})
+	if err != nil {
+		t.Fatalf("GetMenuTreeForUser 返回错误: %v", err)
+	}
+	if len(tree) != 1 || tree[0].ID != monitor.ID || len(tree[0].Children) != 0 {
+		t.Fatalf("有权限的父菜单应保留且不带未授权子菜单: %+v", tree)
+	}
+
+	// 跨多个顶级菜单的部分权限
+	tree, err = m.GetMenuTreeForUser(ctx, 1, []int{user.ID, alert.ID})
+	if err != nil {
+		t.Fatalf("GetMenuTreeForUser 返回错误: %v", err)
+	}
+	if len(tree) != 2 {
+		t.Fatalf("应包含两个顶级菜单, 实际 %+v", tree)
+	}
+	for _, root := range tree {
+		if len(root.Children) != 1 {
+			t.Fatalf("%s 应只有一个可见子菜单, 实际 %d", root.Name, len(root.Children))
+		}
+	}
+
+	tree, err = m.GetMenuTreeForUser(ctx, 1, nil)
+	if err != nil || len(tree) != 0 {
+		t.Fatalf("没有任何权限时应返回空菜单树, 实际 %+v, %v", tree, err)
+	}
+}
+
+func TestGetMenuByPath(t *testing.T) {
+	m, _ := newTestMenuDAO(t)
+	ctx := context.Background()
+
+	user := &model.Menu{Name: "用户管理", Path: "/system/user", RouteName: "User"}
+	role := &model.Menu{Name: "角色管理", Path: "/system/role/", RouteName: "Role"}
+	deleted := &model.Menu{Name: "旧菜单", Path: "/legacy", RouteName: "Legacy", DeletedAt: time.Now().Unix()}
+	seedMenus(t, m.db, user, role, deleted)
+
+	for _, c := range []struct {
+		path string
+		want int
+	}{
+		{"/system/user", user.ID},  // 精确匹配
+		{"/system/user/", user.ID}, // 去掉末尾斜杠后匹配
+		{"/system/role/", role.ID}, // 菜单路径带末尾斜杠时精确匹配
+		{"/system/role", role.ID},  // 补上末尾斜杠后匹配
+	} {
+		menu, err := m.GetMenuByPath(ctx, c.path)
+		if err != nil {
+			t.Fatalf("GetMenuByPath(%s) 返回错误: %v", c.path, err)
+		}
+		if menu.ID != c.want {
+			t.Fatalf("GetMenuByPath(%s) 期望菜单 %d, 实际 %d", c.path, c.want, menu.ID)
+		}
+	}
+
+	// 同时存在带与不带末尾斜杠的菜单时优先返回完全一致的路径
+	userSlash := &model.Menu{Name: "用户管理2", Path: "/system/user/", RouteName: "User2"}
+	seedMenus(t, m.db, userSlash)
+	if menu, err := m.GetMenuByPath(ctx, "/system/user/"); err != nil || menu.ID != userSlash.ID {
+		t.Fatalf("应优先返回完全一致的路径, 实际 %+v, %v", menu, err)
+	}
+
+	for _, path := range []string{"/legacy", "/missing"} {
+		if _, err := m.GetMenuByPath(ctx, path); !errors.Is(err, ErrMenuNotFound) {
+			t.Fatalf("GetMenuByPath(%s) 应返回 ErrMenuNotFound, 实际 %v", path, err)
+		}
+	}
+}
+
+func TestGetMenuStats(t *testing.T) {
+	m, queries := newTestMenuDAO(t)
+	ctx := context.Background()
+
+	chain := seedMenuChain(t, m.db, 3)
+	seedMenus(t, m.db,
+		&model.Menu{Name: "隐藏菜单", RouteName: "Hidden", Hidden: 1},
+		&model.Menu{Name: "隐藏子菜单", RouteName: "HiddenChild", ParentID: chain[0].ID, Hidden: 1},
+		// 已删除的菜单不计入深度
+		&model.Menu{Name: "已删除菜单", RouteName: "Deleted", ParentID: chain[2].ID, DeletedAt: time.Now().Unix()},
+	)
+
+	before := atomic.LoadInt64(queries)
+	stats, err := m.GetMenuStats(ctx)
+	if err != nil {
+		t.Fatalf("GetMenuStats 返回错误: %v", err)
+	}
+	want := model.MenuStats{Total: 5, Hidden: 2, Deleted: 1, MaxDepth: 3}
+	if *stats != want {
+		t.Fatalf("期望 %+v, 实际 %+v", want, *stats)
+	}
+	if got := atomic.LoadInt64(queries) - before; got != 1 {
+		t.Fatalf("应只查询一次菜单表, 实际 %d 次", got)
+	}
+}
+
+func TestGetMenuWithChildren(t *testing.T) {
+	m, _ := newTestMenuDAO(t)
+	ctx := context.Background()
+
+	parent := &model.Menu{Name: "系统管理", RouteName: "System"}
+	seedMenus(t, m.db, parent)
+	seedMenus(t, m.db,
+		&model.Menu{Name: "角色管理", RouteName: "Role", ParentID: parent.ID, SortOrder: 2},
+		&model.Menu{Name: "用户管理", RouteName: "User", ParentID: parent.ID, SortOrder: 1},
+		&model.Menu{Name: "菜单管理", RouteName: "Menu", ParentID: parent.ID, SortOrder: 1},
+		&model.Menu{Name: "旧菜单", RouteName: "Legacy", ParentID: parent.ID, DeletedAt: time.Now().Unix()},
+	)
+	seedMenus(t, m.db, &model.Menu{Name: "孙菜单", RouteName: "Grandchild", ParentID: parent.ID + 2})
+
+	menu, err := m.GetMenuWithChildren(ctx, parent.ID)
+	if err != nil {
+		t.Fatalf("GetMenuWithChildren 返回错误: %v", err)
+	}
+	names := make([]string, 0, len(menu.Children))
+	for _, child := range menu.Children {
+		names = append(names, child.RouteName)
+	}
+	// 按 sort_order 升序，相同时按 ID 升序；不包含已删除菜单和孙菜单
+	if want := []string{"User", "Menu", "Role"}; strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Fatalf("子菜单顺序期望 %v, 实际 %v", want, names)
+	}
+
+	if _, err := m.GetMenuWithChildren(ctx, parent.ID+100); !errors.Is(err, ErrMenuNotFound) {
+		t.Fatalf("菜单不存在时应返回 ErrMenuNotFound, 实际 %v", err)
+	}
+}
+
+func TestGetMenuTreeMaxDepth(t *testing.T) {
+	m, _ := newTestMenuDAO(t)
+	ctx := context.Background()
+
+	seedMenuChain(t, m.db, 4)
+
+	// depthOf 返回树的层数
+	var depthOf func(menus []*model.Menu) int
+	depthOf = func(menus []*model.Menu) int {
+		depth := 0
+		for _, menu := range menus {
+			if d := depthOf(menu.Children) + 1; d > depth {
+				depth = d
+			}
+		}
+		return depth
+	}
+	// leafOf 返回树中最深一层的节点
+	leafOf := func(menus []*model.Menu) *model.Menu {
+		menu := menus[0]
+		for len(menu.Children) > 0 {
+			menu = menu.Children[0]
+		}
+		return menu
+	}
+
+	cases := []struct {
+		maxDepth        int
+		wantDepth       int
+		wantLeafRoute   string
+		wantHasChildren bool
+	}{
+		{maxDepth: 1, wantDepth: 1, wantLeafRoute: "Level1", wantHasChildren: true},
+		{maxDepth: 2, wantDepth: 2, wantLeafRoute: "Level2", wantHasChildren: true},
+		{maxDepth: 0, wantDepth: 4, wantLeafRoute: "Level4", wantHasChildren: false},
+		{maxDepth: 10, wantDepth: 4, wantLeafRoute: "Level4", wantHasChildren: false},
+	}
+	for _, c := range cases {
+		tree, err := m.GetMenuTree(ctx, c.maxDepth)
+		if err != nil {
+			t.Fatalf("maxDepth=%d 时 GetMenuTree 返回错误: %v", c.maxDepth, err)
+		}
+		if len(tree) != 1 {
+			t.Fatalf("maxDepth=%d 时应只有 1 个顶级菜单, 实际 %d", c.maxDepth, len(tree))
+		}
+		if got := depthOf(tree); got != c.wantDepth {
+			t.Fatalf("maxDepth=%d 时树深度期望 %d, 实际 %d", c.maxDepth, c.wantDepth, got)
+		}
+		leaf := leafOf(tree)
+		if leaf.RouteName != c.wantLeafRoute || leaf.HasChildren != c.wantHasChildren {
+			t.Fatalf("maxDepth=%d 时最深节点期望 %s(HasChildren=%v), 实际 %s(HasChildren=%v)",
+				c.maxDepth, c.wantLeafRoute, c.wantHasChildren, leaf.RouteName, leaf.HasChildren)
+		}
+		if !tree[0].HasChildren {
+			t.Fatalf("maxDepth=%d 时顶级菜单应标记 HasChildren", c.maxDepth)
+		}
+	}
+
+	if _, err := m.GetMenuTree(ctx, -1); err == nil {
+		t.Fatal("maxDepth 为负数时应返回错误")
+	}
+}
+
+// childRouteNames 返回 parentID 下未删除的子菜单路由名称，按 sort_order 排序并校验排序连续
+func childRouteNames(t *testing.T, db *gorm.DB, parentID int) []string {
+	t.Helper()
+	var children []*model.Menu
+	if err := db.Where("parent_id = ? AND deleted_at = 0", parentID).Order("sort_order, id").Find(&children).Error; err != nil {
+		t.Fatalf("查询子菜单失败: %v", err)
+	}
+	names := make([]string, 0, len(children))
+	for i, child := range children {
+		if child.SortOrder != i {
+			t.Fatalf("菜单 %s 的 sort_order 应为 %d, 实际 %d", child.RouteName, i, child.SortOrder)
+		}
+		names = append(names, child.RouteName)
+	}
+	return names
+}
+
+func TestMoveMenu(t *testing.T) {
+	m, _ := newTestMenuDAO(t)
+	ctx := context.Background()
+
+	system := &model.Menu{Name: "系统管理", RouteName: "System"}
+	monitor := &model.Menu{Name: "监控管理", RouteName: "Monitor", SortOrder: 1}
+	seedMenus(t, m.db, system, monitor)
+	user := &model.Menu{Name: "用户管理", RouteName: "User", ParentID: system.ID}
+	role := &model.Menu{Name: "角色管理", RouteName: "Role", ParentID: system.ID, SortOrder: 1}
+	alert := &model.Menu{Name: "告警管理", RouteName: "Alert", ParentID: monitor.ID}
+	rule := &model.Menu{Name: "规则管理", RouteName: "Rule", ParentID: monitor.ID, SortOrder: 1}
+	seedMenus(t, m.db, user, role, alert, rule)
+	detail := &model.Menu{Name: "用户详情", RouteName: "UserDetail", ParentID: user.ID}
+	seedMenus(t, m.db, detail)
+
+	// 移动到新父菜单的指定位置，子树随之移动，新旧同级菜单重新编号
+	if err := m.MoveMenu(ctx, user.ID, monitor.ID, 1); err != nil {
+		t.Fatalf("MoveMenu 返回错误: %v", err)
+	}
+	if got := strings.Join(childRouteNames(t, m.db, monitor.ID), ","); got != "Alert,User,Rule" {
+		t.Fatalf("新同级菜单顺序期望 Alert,User,Rule, 实际 %s", got)
+	}
+	if got := strings.Join(childRouteNames(t, m.db, system.ID), ","); got != "Role" {
+		t.Fatalf("原同级菜单期望 Role, 实际 %s", got)
+	}
+	if got := strings.Join(childRouteNames(t, m.db, user.ID), ","); got != "UserDetail" {
+		t.Fatalf("子菜单应随父菜单移动, 实际 %s", got)
+	}
+
+	// 同级内调整顺序，超出范围时放在末尾
+	if err := m.MoveMenu(ctx, alert.ID, monitor.ID, 10); err != nil {
+		t.Fatalf("MoveMenu 返回错误: %v", err)
+	}
+	if got := strings.Join(childRouteNames(t, m.db, monitor.ID), ","); got != "User,Rule,Alert" {
+		t.Fatalf("同级菜单顺序期望 User,Rule,Alert, 实际 %s", got)
+	}
+
+	// 移动到顶级
+	if err := m.MoveMenu(ctx, user.ID, 0, 0); err != nil {
+		t.Fatalf("MoveMenu 返回错误: %v", err)
+	}
+	if got := strings.Join(childRouteNames(t, m.db, 0), ","); got != "User,System,Monitor" {
+		t.Fatalf("顶级菜单顺序期望 User,System,Monitor, 实际 %s", got)
+	}
+
+	// 移动到自身或自身子树下形成循环，拒绝且不修改任何菜单
+	for _, parentID := range []int{user.ID, detail.ID} {
+		if err := m.MoveMenu(ctx, user.ID, parentID, 0); !errors.Is(err, ErrMenuMoveCycle) {
+			t.Fatalf("移动到 %d 下应返回 ErrMenuMoveCycle, 实际 %v", parentID, err)
+		}
+	}
+	var stored model.Menu
+	if err := m.db.First(&stored, user.ID).Error; err != nil {
+		t.Fatalf("查询菜单失败: %v", err)
+	}
+	if stored.ParentID != 0 {
+		t.Fatalf("循环移动被拒绝后父菜单不应变化, 实际 %d", stored.ParentID)
+	}
+
+	if err := m.MoveMenu(ctx, user.ID, detail.ID+100, 0); err == nil {
+		t.Fatal("父菜单不存在时应返回错误")
+	}
+	if err := m.MoveMenu(ctx, detail.ID+100, 0, 0); !errors.Is(err, ErrMenuNotFound) {
+		t.Fatalf("菜单不存在时应返回 ErrMenuNotFound, 实际 %v", err)
+	}
+}
+
+func TestFindDuplicateMenus(t *testing.T) {
+	m, _ := newTestMenuDAO(t)
+	ctx := context.Background()
+
+	// 重复数据产生于唯一索引建立之前，测试中去掉索引以写入历史数据
+	if err := m.db.Migrator().DropIndex(&model.Menu{}, "idx_route_del"); err != nil {
+		t.Fatalf("删除唯一索引失败: %v", err)
+	}
+	a := &model.Menu{Name: "A", RouteName: "Alpha", Path: "/a"}
+	b := &model.Menu{Name: "B", RouteName: "Alpha", Path: "/b"}
+	c := &model.Menu{Name: "C", RouteName: "Beta", Path: "/a"}
+	seedMenus(t, m.db, a, b, c,
+		&model.Menu{Name: "D", RouteName: "Beta", Path: "/d", DeletedAt: 1},
+		&model.Menu{Name: "E", RouteName: "Gamma", Path: "/g"},
+		&model.Menu{Name: "F", RouteName: "Gamma", Path: "/g", DeletedAt: 1},
+	)
+
+	groups, err := m.FindDuplicateMenus(ctx)
+	if err != nil {
+		t.Fatalf("FindDuplicateMenus 返回错误: %v", err)
+	}
+	got := make([]string, 0, len(groups))
+	for _, group := range groups {
+		ids := make([]string, 0, len(group.Menus))
+		for _, menu := range group.Menus {
+			ids = append(ids, strconv.Itoa(menu.ID))
+		}
+		got = append(got, group.Field+"="+group.Value+":"+strings.Join(ids, ","))
+	}
+	// 已删除的菜单不参与比较，分组按路由名称排序
+	want := []string{
+		"route_name=Alpha:" + strconv.Itoa(a.ID) + "," + strconv.Itoa(b.ID),
+		"path=/a:" + strconv.Itoa(a.ID) + "," + strconv.Itoa(c.ID),
+	}
+	if strings.Join(got, ";") != strings.Join(want, ";") {
+		t.Fatalf("重复分组期望 %v, 实际 %v", want, got)
+	}
+}
+
+func TestSetMenuHiddenBulk(t *testing.T) {
+	m, _ := newTestMenuDAO(t)
+	ctx := context.Background()
+
+	system := &model.Menu{Name: "系统管理", RouteName: "System"}
+	user := &model.Menu{Name: "用户管理", RouteName: "User", SortOrder: 1}
+	role := &model.Menu{Name: "角色管理", RouteName: "Role", SortOrder: 2}
+	removed := &model.Menu{Name: "已删除", RouteName: "Removed", SortOrder: 3}
+	seedMenus(t, m.db, system, user, role, removed)
+	if err := m.db.Model(&model.Menu{}).Where("id IN ?", []int{system.ID, user.ID, role.ID, removed.ID}).
+		UpdateColumn("updated_at", 1).Error; err != nil {
+		t.Fatalf("重置 updated_at 失败: %v", err)
+	}
+	if err := m.db.Model(&model.Menu{}).Where("id = ?", removed.ID).UpdateColumn("deleted_at", time.Now().Unix()).Error; err != nil {
+		t.Fatalf("软删除菜单失败: %v", err)
+	}
+
+	hiddenOf := func(id int) (int8, int64) {
+		var menu model.Menu
+		if err := m.db.Where("id = ?", id).First(&menu).Error; err != nil {
+			t.Fatalf("查询菜单 %d 失败: %v", id, err)
+		}
+		return menu.Hidden, menu.UpdatedAt
+	}
+
+	// 参数校验失败时不修改任何数据
+	for _, c := range []struct {
+		name   string
+		ids    []int
+		hidden int
+	}{
+		{"空ID列表", nil, 1},
+		{"hidden 非法", []int{system.ID}, 2},
+		{"hidden 为负数", []int{system.ID}, -1},
+	} {
+		affected, err := m.SetMenuHiddenBulk(ctx, c.ids, c.hidden)
+		if !errors.Is(err, ErrInvalidMenu) {
+			t.Fatalf("%s: 期望 ErrInvalidMenu, 实际 %v", c.name, err)
+		}
+		if affected != 0 {
+			t.Fatalf("%s: 期望影响 0 行, 实际 %d", c.name, affected)
+		}
+	}
+	if hidden, updatedAt := hiddenOf(system.ID); hidden != 0 || updatedAt != 1 {
+		t.Fatalf("校验失败时不应修改菜单, 实际 hidden=%d updated_at=%d", hidden, updatedAt)
+	}
+
+	// 批量隐藏只影响未删除的菜单，并刷新更新时间
+	affected, err := m.SetMenuHiddenBulk(ctx, []int{system.ID, user.ID, removed.ID}, 1)
+	if err != nil {
+		t.Fatalf("SetMenuHiddenBulk 返回错误: %v", err)
+	}
+	if affected != 2 {
+		t.Fatalf("期望影响 2 行, 实际 %d", affected)
+	}
+	for _, id := range []int{system.ID, user.ID} {
+		if hidden, updatedAt := hiddenOf(id); hidden != 1 || updatedAt <= 1 {
+			t.Fatalf("菜单 %d 应被隐藏并刷新更新时间, 实际 hidden=%d updated_at=%d", id, hidden, updatedAt)
+		}
+	}
+	if hidden, updatedAt := hiddenOf(role.ID); hidden != 0 || updatedAt != 1 {
+		t.Fatalf("未指定的菜单不应被修改, 实际 hidden=%d updated_at=%d", hidden, updatedAt)
+	}
+	if hidden, _ := hiddenOf(removed.ID); hidden != 0 {
+		t.Fatal("已删除的菜单不应被修改")
+	}
+
+	// 批量显示
+	affected, err = m.SetMenuHiddenBulk(ctx, []int{system.ID}, 0)
+	if err != nil || affected != 1 {
+		t.Fatalf("期望显示 1 个菜单, 实际 affected=%d err=%v", affected, err)
+	}
+	if hidden, _ := hiddenOf(system.ID); hidden != 0 {
+		t.Fatal("菜单应恢复显示")
+	}
+}
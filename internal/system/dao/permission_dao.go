@@ -71,7 +71,7 @@ func (p *permissionDAO) AssignRole(ctx context.Context, roleId int, apiIds []int
 	}
 
 	var role model.Role
-	if err := p.db.WithContext(ctx).Where("id = ? AND deleted_at = ?", roleId, 0).First(&role).Error; err != nil {
+	if err := p.db.WithContext(ctx).Scopes(notDeleted).Where("id = ?", roleId).First(&role).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return errors.New("角色不存在")
 		}
@@ -118,7 +118,7 @@ func (p *permissionDAO) AssignRoleToUser(ctx context.Context, userId int, roleId
 		// 获取角色信息
 		var roles []*model.Role
 		if len(roleIds) > 0 {
-			if err := tx.Where("id IN ? AND deleted_at = ?", roleIds, 0).Find(&roles).Error; err != nil {
+			if err := tx.Scopes(notDeleted).Where("id IN ?", roleIds).Find(&roles).Error; err != nil {
 				return fmt.Errorf("获取角色信息失败: %v", err)
 			}
 			if len(roles) != len(roleIds) {
@@ -143,7 +143,7 @@ func (p *permissionDAO) AssignRoleToUser(ctx context.Context, userId int, roleId
 		// 更新用户的API关联
 		if len(apiIds) > 0 {
 			var apis []*model.Api
-			if err := tx.Where("id IN ? AND deleted_at = ?", apiIds, 0).Find(&apis).Error; err != nil {
+			if err := tx.Scopes(notDeleted).Where("id IN ?", apiIds).Find(&apis).Error; err != nil {
 				return fmt.Errorf("获取API信息失败: %v", err)
 			}
 			if len(apis) != len(apiIds) {
@@ -220,7 +220,7 @@ func (p *permissionDAO) RemoveRolePermissions(ctx context.Context, roleId int) e
 
 	// 查询角色名称
 	var role model.Role
-	if err := p.db.WithContext(ctx).Where("id = ? AND deleted_at = ?", roleId, 0).First(&role).Error; err != nil {
+	if err := p.db.WithContext(ctx).Scopes(notDeleted).Where("id = ?", roleId).First(&role).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return errors.New("角色不存在")
 		}
@@ -330,7 +330,7 @@ func (p *permissionDAO) AssignRoleToUsers(ctx context.Context, userIds []int, ro
 		// 获取角色信息
 		var roles []*model.Role
 		if len(roleIds) > 0 {
-			if err := tx.Where("id IN ? AND deleted_at = ?", roleIds, 0).Find(&roles).Error; err != nil {
+			if err := tx.Scopes(notDeleted).Where("id IN ?", roleIds).Find(&roles).Error; err != nil {
 				return fmt.Errorf("获取角色信息失败: %v", err)
 			}
 			if len(roles) != len(roleIds) {
@@ -33,6 +33,7 @@ import (
 	"time"
 
 	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/GoSimplicity/AI-CloudOps/pkg/utils"
 	"github.com/casbin/casbin/v2"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -80,7 +81,7 @@ func (r *roleDAO) CreateRole(ctx context.Context, role *model.Role, apiIds []int
 		var count int64
 
 		// 检查角色名是否已存在
-		if err := tx.Model(&model.Role{}).Where("name = ? AND deleted_at = ?", role.Name, 0).Count(&count).Error; err != nil {
+		if err := tx.Model(&model.Role{}).Scopes(notDeleted).Where("name = ?", role.Name).Count(&count).Error; err != nil {
 			return fmt.Errorf("检查角色名称失败: %v", err)
 		}
 		if count > 0 {
@@ -125,7 +126,7 @@ func (r *roleDAO) GetRoleById(ctx context.Context, id int) (*model.Role, error)
 	}
 
 	var role model.Role
-	if err := r.db.WithContext(ctx).Where("id = ? AND deleted_at = ?", id, 0).First(&role).Error; err != nil {
+	if err := r.db.WithContext(ctx).Scopes(notDeleted).Where("id = ?", id).First(&role).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -149,14 +150,14 @@ func (r *roleDAO) UpdateRole(ctx context.Context, role *model.Role) error {
 
 	// 获取原角色信息
 	var oldRole model.Role
-	if err := r.db.WithContext(ctx).Where("id = ? AND deleted_at = ?", role.ID, 0).First(&oldRole).Error; err != nil {
+	if err := r.db.WithContext(ctx).Scopes(notDeleted).Where("id = ?", role.ID).First(&oldRole).Error; err != nil {
 		return fmt.Errorf("获取原角色信息失败: %v", err)
 	}
 
 	// 检查角色名是否已被其他角色使用
 	var count int64
 	if err := r.db.WithContext(ctx).Model(&model.Role{}).
-		Where("name = ? AND id != ? AND deleted_at = ?", role.Name, role.ID, 0).
+		Scopes(notDeleted).Where("name = ? AND id != ?", role.Name, role.ID).
 		Count(&count).Error; err != nil {
 		return fmt.Errorf("检查角色名称失败: %v", err)
 	}
@@ -174,7 +175,7 @@ func (r *roleDAO) UpdateRole(ctx context.Context, role *model.Role) error {
 
 	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		result := tx.Model(&model.Role{}).
-			Where("id = ? AND deleted_at = ?", role.ID, 0).
+			Scopes(notDeleted).Where("id = ?", role.ID).
 			Updates(updates)
 		if result.Error != nil {
 			return fmt.Errorf("更新角色失败: %v", result.Error)
@@ -224,7 +225,7 @@ func (r *roleDAO) DeleteRole(ctx context.Context, id int) error {
 
 	// 检查是否为默认角色
 	var role model.Role
-	if err := r.db.WithContext(ctx).Where("id = ? AND deleted_at = ?", id, 0).First(&role).Error; err != nil {
+	if err := r.db.WithContext(ctx).Scopes(notDeleted).Where("id = ?", id).First(&role).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return errors.New("角色不存在")
 		}
@@ -235,12 +236,7 @@ func (r *roleDAO) DeleteRole(ctx context.Context, id int) error {
 		return errors.New("默认角色不能删除")
 	}
 
-	updates := map[string]interface{}{
-		"deleted_at":  1,
-		"update_time": time.Now().Unix(),
-	}
-
-	result := r.db.WithContext(ctx).Model(&model.Role{}).Where("id = ? AND deleted_at = ?", id, 0).Updates(updates)
+	result := r.db.WithContext(ctx).Model(&model.Role{}).Scopes(notDeleted).Where("id = ?", id).Updates(utils.SoftDeleteColumns())
 	if result.Error != nil {
 		return fmt.Errorf("删除角色失败: %v", result.Error)
 	}
@@ -265,7 +261,7 @@ func (r *roleDAO) ListRoles(ctx context.Context, page, pageSize int) ([]*model.R
 	var roles []*model.Role
 	var total int64
 
-	db := r.db.WithContext(ctx).Model(&model.Role{}).Where("deleted_at = ?", 0)
+	db := r.db.WithContext(ctx).Model(&model.Role{}).Scopes(notDeleted)
 
 	// 获取总数
 	if err := db.Count(&total).Error; err != nil {
@@ -288,7 +284,7 @@ func (r *roleDAO) GetRole(ctx context.Context, roleId int) (*model.Role, error)
 	}
 
 	var role model.Role
-	if err := r.db.WithContext(ctx).Where("id = ? AND deleted_at = ?", roleId, 0).First(&role).Error; err != nil {
+	if err := r.db.WithContext(ctx).Scopes(notDeleted).Where("id = ?", roleId).First(&role).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -314,7 +310,7 @@ func (r *roleDAO) GetRole(ctx context.Context, roleId int) (*model.Role, error)
 
 	// 查询API详细信息
 	if len(apiIds) > 0 {
-		if err := r.db.WithContext(ctx).Where("id IN ? AND deleted_at = ?", apiIds, 0).Find(&role.Apis).Error; err != nil {
+		if err := r.db.WithContext(ctx).Scopes(notDeleted).Where("id IN ?", apiIds).Find(&role.Apis).Error; err != nil {
 			return nil, fmt.Errorf("查询API失败: %v", err)
 		}
 	}
@@ -330,7 +326,7 @@ func (r *roleDAO) GetUserRole(ctx context.Context, userId int) (*model.Role, err
 
 	// 先从数据库中获取用户的角色
 	var user model.User
-	if err := r.db.WithContext(ctx).Preload("Roles", "deleted_at = ?", 0).Where("id = ? AND deleted_at = ?", userId, 0).First(&user).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Roles", notDeleted).Scopes(notDeleted).Where("id = ?", userId).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -385,7 +381,7 @@ func (r *roleDAO) GetUserRole(ctx context.Context, userId int) (*model.Role, err
 	var apis []*model.Api
 
 	if len(apiIds) > 0 {
-		if err := r.db.WithContext(ctx).Where("id IN ? AND deleted_at = ?", apiIds, 0).Find(&apis).Error; err != nil {
+		if err := r.db.WithContext(ctx).Scopes(notDeleted).Where("id IN ?", apiIds).Find(&apis).Error; err != nil {
 			return nil, fmt.Errorf("查询API失败: %v", err)
 		}
 		role.Apis = apis
@@ -401,7 +397,7 @@ func (r *roleDAO) GetRoleByName(ctx context.Context, name string) (*model.Role,
 	}
 
 	var role model.Role
-	if err := r.db.WithContext(ctx).Where("name = ? AND deleted_at = ?", name, 0).First(&role).Error; err != nil {
+	if err := r.db.WithContext(ctx).Scopes(notDeleted).Where("name = ?", name).First(&role).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
 		}
@@ -27,9 +27,28 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/GoSimplicity/AI-CloudOps/internal/model"
 	"github.com/GoSimplicity/AI-CloudOps/internal/system/dao"
+	"go.uber.org/zap"
+)
+
+// 业务审计操作标识
+const (
+	AuditActionAlertEventSilence = "alert_event.silence"
+	AuditActionMenuDelete        = "menu.delete"
+	AuditActionMenuRestore       = "menu.restore"
+	AuditActionMenuMove          = "menu.move"
+)
+
+const (
+	// auditOperationAction 业务审计记录的操作类型，用于与中间件自动记录的请求日志区分
+	auditOperationAction = "ACTION"
+	// auditWriteTimeout 异步写入审计日志的超时时间
+	auditWriteTimeout = 3 * time.Second
+	// defaultAuditPageSize 未指定分页大小时的默认值
+	defaultAuditPageSize = 20
 )
 
 type AuditService interface {
@@ -43,21 +62,31 @@ type AuditService interface {
 	BatchDeleteLogs(ctx context.Context, ids []uint) error
 	ArchiveAuditLogs(ctx context.Context, req *model.ListAuditLogsRequest) error
 	RecordOperationLog(ctx context.Context, req *model.AuditLog) error
+	RecordAction(ctx context.Context, userID int, action, targetType, targetID, detail string)
 }
 
 type auditService struct {
 	dao dao.AuditDAO
+	l   *zap.Logger
 }
 
-func NewAuditService(dao dao.AuditDAO) AuditService {
+func NewAuditService(dao dao.AuditDAO, l *zap.Logger) AuditService {
 	return &auditService{
 		dao: dao,
+		l:   l,
 	}
 }
 
-// ListAuditLogs 获取审计日志列表
+// ListAuditLogs 获取审计日志列表，支持按操作人和业务操作过滤
 func (s *auditService) ListAuditLogs(ctx context.Context, req *model.ListAuditLogsRequest) ([]*model.AuditLog, int64, error) {
-	return nil, 0, nil
+	if req.PageNumber <= 0 {
+		req.PageNumber = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = defaultAuditPageSize
+	}
+
+	return s.dao.ListAuditLogs(ctx, req)
 }
 
 // GetAuditLogDetail 获取审计日志详情
@@ -117,3 +146,32 @@ func (s *auditService) RecordOperationLog(ctx context.Context, req *model.AuditL
 
 	return nil
 }
+
+// RecordAction 异步记录一条业务审计日志，写入失败只记录错误日志，不影响主流程
+func (s *auditService) RecordAction(ctx context.Context, userID int, action, targetType, targetID, detail string) {
+	auditLog := &model.AuditLog{
+		UserID:        uint(userID),
+		OperationType: auditOperationAction,
+		TargetType:    targetType,
+		TargetID:      targetID,
+		Action:        action,
+		Detail:        detail,
+		CreatedAt:     time.Now().Unix(),
+	}
+
+	// 脱离请求的取消信号，避免请求结束后写入被中断
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), auditWriteTimeout)
+
+	go func() {
+		defer cancel()
+
+		if err := s.dao.CreateAuditLog(writeCtx, auditLog); err != nil {
+			s.l.Error("记录业务审计日志失败",
+				zap.Int("user_id", userID),
+				zap.String("action", action),
+				zap.String("target_type", targetType),
+				zap.String("target_id", targetID),
+				zap.Error(err))
+		}
+	}()
+}
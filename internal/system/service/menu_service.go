@@ -28,19 +28,31 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/GoSimplicity/AI-CloudOps/internal/system/dao"
 
 	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
 
+// ErrMenuIconNotAllowed 菜单图标不在允许列表中
+var ErrMenuIconNotAllowed = errors.New("菜单图标不在允许列表中")
+
 type MenuService interface {
 	GetMenus(ctx context.Context, pageNum, pageSize int) ([]*model.Menu, int, error)
 	CreateMenu(ctx context.Context, menu *model.Menu) error
 	GetMenuById(ctx context.Context, id int) (*model.Menu, error)
 	UpdateMenu(ctx context.Context, menu *model.Menu) error
 	DeleteMenu(ctx context.Context, id int) error
+	PreviewMenuDelete(ctx context.Context, id int) (*model.MenuDeletePreview, error)
+	RestoreMenu(ctx context.Context, id int) error
+	MoveMenu(ctx context.Context, id, newParentID, newSortOrder int) error
+	SetMenuPermission(ctx context.Context, id int, permissionCode string) error
+	SetMenuHiddenBulk(ctx context.Context, ids []int, hidden int) (int64, error)
+	GetMenusByPermissionCodes(ctx context.Context, codes []string) ([]*model.Menu, error)
 	UpdateUserMenu(ctx context.Context, userId int, menuId []int) error
 }
 
@@ -79,6 +91,11 @@ func (m *menuService) CreateMenu(ctx context.Context, menu *model.Menu) error {
 		return errors.New("菜单不能为空")
 	}
 
+	if err := validateMenuIcon(menu.Meta.Icon); err != nil {
+		m.l.Warn("菜单图标校验失败", zap.String("图标", menu.Meta.Icon))
+		return err
+	}
+
 	return m.menuDao.CreateMenu(ctx, menu)
 }
 
@@ -99,6 +116,11 @@ func (m *menuService) UpdateMenu(ctx context.Context, menu *model.Menu) error {
 		return errors.New("菜单不能为空")
 	}
 
+	if err := validateMenuIcon(menu.Meta.Icon); err != nil {
+		m.l.Warn("菜单图标校验失败", zap.String("图标", menu.Meta.Icon))
+		return err
+	}
+
 	return m.menuDao.UpdateMenu(ctx, menu)
 }
 
@@ -112,6 +134,56 @@ func (m *menuService) DeleteMenu(ctx context.Context, id int) error {
 	return m.menuDao.DeleteMenu(ctx, id)
 }
 
+// PreviewMenuDelete 预览删除菜单的结果，存在子菜单时标记为拒绝删除
+func (m *menuService) PreviewMenuDelete(ctx context.Context, id int) (*model.MenuDeletePreview, error) {
+	if id <= 0 {
+		m.l.Warn("菜单ID无效", zap.Int("ID", id))
+		return nil, errors.New("菜单ID无效")
+	}
+
+	return m.menuDao.PreviewMenuDelete(ctx, id)
+}
+
+// RestoreMenu 恢复已删除的菜单
+func (m *menuService) RestoreMenu(ctx context.Context, id int) error {
+	if id <= 0 {
+		m.l.Warn("菜单ID无效", zap.Int("ID", id))
+		return errors.New("菜单ID无效")
+	}
+
+	return m.menuDao.RestoreMenu(ctx, id)
+}
+
+// MoveMenu 移动菜单及其子菜单到新的父菜单下
+func (m *menuService) MoveMenu(ctx context.Context, id, newParentID, newSortOrder int) error {
+	if id <= 0 {
+		m.l.Warn("菜单ID无效", zap.Int("ID", id))
+		return errors.New("菜单ID无效")
+	}
+
+	return m.menuDao.MoveMenu(ctx, id, newParentID, newSortOrder)
+}
+
+// SetMenuPermission 设置菜单关联的权限编码
+func (m *menuService) SetMenuPermission(ctx context.Context, id int, permissionCode string) error {
+	if id <= 0 {
+		m.l.Warn("菜单ID无效", zap.Int("ID", id))
+		return errors.New("菜单ID无效")
+	}
+
+	return m.menuDao.SetMenuPermission(ctx, id, strings.TrimSpace(permissionCode))
+}
+
+// SetMenuHiddenBulk 批量设置菜单的隐藏状态
+func (m *menuService) SetMenuHiddenBulk(ctx context.Context, ids []int, hidden int) (int64, error) {
+	return m.menuDao.SetMenuHiddenBulk(ctx, ids, hidden)
+}
+
+// GetMenusByPermissionCodes 根据权限编码获取可访问的菜单
+func (m *menuService) GetMenusByPermissionCodes(ctx context.Context, codes []string) ([]*model.Menu, error) {
+	return m.menuDao.GetMenusByPermissionCodes(ctx, codes)
+}
+
 // UpdateUserMenu 更新用户菜单关联
 func (m *menuService) UpdateUserMenu(ctx context.Context, userId int, menuId []int) error {
 	if userId <= 0 || len(menuId) == 0 {
@@ -121,3 +193,23 @@ func (m *menuService) UpdateUserMenu(ctx context.Context, userId int, menuId []i
 
 	return m.menuDao.UpdateUserMenu(ctx, userId, menuId)
 }
+
+// validateMenuIcon 校验菜单图标是否在 menu.allowed_icons 配置的列表中，列表为空或图标为空时跳过校验
+func validateMenuIcon(icon string) error {
+	if icon == "" {
+		return nil
+	}
+
+	allowed := viper.GetStringSlice("menu.allowed_icons")
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	for _, a := range allowed {
+		if a == icon {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s", ErrMenuIconNotAllowed, icon)
+}
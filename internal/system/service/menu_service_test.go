@@ -0,0 +1,91 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/GoSimplicity/AI-CloudOps/internal/system/dao"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// stubMenuDAO 记录写入的菜单，未覆盖的方法调用时会 panic
+type stubMenuDAO struct {
+	dao.MenuDAO
+	created []*model.Menu
+	updated []*model.Menu
+}
+
+func (s *stubMenuDAO) CreateMenu(_ context.Context, menu *model.Menu) error {
+	s.created = append(s.created, menu)
+	return nil
+}
+
+func (s *stubMenuDAO) UpdateMenu(_ context.Context, menu *model.Menu) error {
+	s.updated = append(s.updated, menu)
+	return nil
+}
+
+func TestMenuIconValidation(t *testing.T) {
+	ctx := context.Background()
+	menuWithIcon := func(icon string) *model.Menu {
+		return &model.Menu{Name: "用户管理", Meta: model.MetaField{Icon: icon}}
+	}
+
+	// 未配置允许列表时不校验
+	viper.Set("menu.allowed_icons", nil)
+	d := &stubMenuDAO{}
+	svc := NewMenuService(d, zap.NewNop())
+	if err := svc.CreateMenu(ctx, menuWithIcon("typo-icon")); err != nil {
+		t.Fatalf("未配置允许列表时不应校验图标: %v", err)
+	}
+
+	viper.Set("menu.allowed_icons", []string{"user", "setting"})
+	defer viper.Set("menu.allowed_icons", nil)
+
+	for _, icon := range []string{"user", ""} {
+		if err := svc.CreateMenu(ctx, menuWithIcon(icon)); err != nil {
+			t.Fatalf("图标 %q 应允许创建: %v", icon, err)
+		}
+		if err := svc.UpdateMenu(ctx, menuWithIcon(icon)); err != nil {
+			t.Fatalf("图标 %q 应允许更新: %v", icon, err)
+		}
+	}
+
+	if err := svc.CreateMenu(ctx, menuWithIcon("usr")); !errors.Is(err, ErrMenuIconNotAllowed) {
+		t.Fatalf("创建时未知图标应返回 ErrMenuIconNotAllowed, 实际 %v", err)
+	}
+	if err := svc.UpdateMenu(ctx, menuWithIcon("usr")); !errors.Is(err, ErrMenuIconNotAllowed) {
+		t.Fatalf("更新时未知图标应返回 ErrMenuIconNotAllowed, 实际 %v", err)
+	}
+	if len(d.created) != 3 || len(d.updated) != 2 {
+		t.Fatalf("校验失败的菜单不应写入, 实际创建 %d 次, 更新 %d 次", len(d.created), len(d.updated))
+	}
+}
@@ -108,6 +108,8 @@ func (u *UserHandler) Login(ctx *gin.Context) {
 			utils.ErrorWithMessage(ctx, "用户不存在")
 		case errors.Is(err, constants.ErrorPasswordIncorrect):
 			utils.ErrorWithMessage(ctx, "密码错误")
+		case errors.Is(err, constants.ErrorAccountLocked):
+			utils.ErrorWithMessage(ctx, "账号已锁定: "+err.Error())
 		default:
 			u.l.Error("登录失败", zap.Error(err))
 			utils.InternalServerError(ctx, http.StatusInternalServerError, err.Error(), "登录失败")
@@ -115,7 +117,7 @@ func (u *UserHandler) Login(ctx *gin.Context) {
 		return
 	}
 
-	accessToken, refreshToken, err := u.ijwt.SetLoginToken(ctx, user.ID)
+	accessToken, refreshToken, err := u.ijwt.SetLoginToken(ctx, user.ID, user.TeamID)
 	if err != nil {
 		u.l.Error("生成令牌失败", zap.Error(err))
 		utils.InternalServerError(ctx, http.StatusInternalServerError, err.Error(), "登录失败")
@@ -185,7 +187,7 @@ func (u *UserHandler) RefreshToken(ctx *gin.Context) {
 		return
 	}
 
-	newToken, err := u.ijwt.SetJWTToken(ctx, rc.Uid, rc.Ssid)
+	newToken, err := u.ijwt.SetJWTToken(ctx, rc.Uid, rc.TeamID, rc.Ssid)
 	if err != nil {
 		u.l.Error("生成新令牌失败", zap.Error(err))
 		utils.InternalServerError(ctx, http.StatusInternalServerError, err.Error(), "刷新令牌失败")
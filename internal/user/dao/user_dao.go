@@ -28,10 +28,13 @@ package dao
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/GoSimplicity/AI-CloudOps/internal/model"
 	"github.com/GoSimplicity/AI-CloudOps/internal/system/dao"
+	"github.com/spf13/viper"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -41,13 +44,44 @@ type UserDAO interface {
 	GetUserByUsername(ctx context.Context, username string) (*model.User, error)
 	GetUserByUsernames(ctx context.Context, usernames []string) ([]*model.User, error)
 	GetAllUsers(ctx context.Context) ([]*model.User, error)
+	SearchUsers(ctx context.Context, keyword string, status *int, offset, limit int) ([]*model.User, int64, error)
 	GetUserByID(ctx context.Context, id int) (*model.User, error)
 	GetUserByIDs(ctx context.Context, ids []int) ([]*model.User, error)
+	GetUsernamesByIDs(ctx context.Context, ids []int) map[int]string
+	GetUserBriefByID(ctx context.Context, id int) (*model.UserBrief, error)
 	GetPermCode(ctx context.Context, uid int) ([]string, error)
 	ChangePassword(ctx context.Context, uid int, password string) error
 	WriteOff(ctx context.Context, username, password string) error
 	UpdateProfile(ctx context.Context, user *model.User) error
 	DeleteUser(ctx context.Context, uid int) error
+	AssignRolesToUser(ctx context.Context, userID int, roleIDs []int) error
+	GetUserRoles(ctx context.Context, userID int) ([]*model.Role, error)
+	UserHasPermission(ctx context.Context, userID int, permission string) (bool, error)
+	IsAdmin(ctx context.Context, userID int) (bool, error)
+	RecordLoginFailure(ctx context.Context, username string) error
+	ClearLoginFailures(ctx context.Context, userID int) error
+}
+
+const (
+	// defaultLoginMaxFailures 默认连续登录失败锁定阈值
+	defaultLoginMaxFailures = 5
+	// defaultLoginLockMinutes 默认账号锁定时长(分钟)
+	defaultLoginLockMinutes = 15
+)
+
+type permissionCacheKey struct{}
+
+// permissionCache 请求级别的用户权限集合缓存
+type permissionCache struct {
+	mu    sync.Mutex
+	perms map[int]map[string]struct{}
+}
+
+// WithPermissionCache 为请求上下文挂载权限缓存，同一请求内重复校验权限时避免重复查询
+func WithPermissionCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, permissionCacheKey{}, &permissionCache{
+		perms: make(map[int]map[string]struct{}),
+	})
 }
 
 type userDAO struct {
@@ -76,7 +110,7 @@ func (u *userDAO) CreateUser(ctx context.Context, user *model.User) error {
 	// 使用事务和一次性查询检查唯一性约束
 	err := u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		var count int64
-		query := tx.Model(&model.User{}).Where("deleted_at = ?", 0).
+		query := tx.Model(&model.User{}).Scopes(notDeleted).
 			Where("username = ? OR (mobile = ? AND mobile != '') OR (fei_shu_user_id = ? AND fei_shu_user_id != '')",
 				user.Username, user.Mobile, user.FeiShuUserId)
 
@@ -88,16 +122,16 @@ func (u *userDAO) CreateUser(ctx context.Context, user *model.User) error {
 		if count > 0 {
 			// 进一步确定具体是哪个字段重复
 			var existingUser model.User
-			if err := tx.Where("username = ? AND deleted_at = ?", user.Username, 0).First(&existingUser).Error; err == nil {
+			if err := tx.Scopes(notDeleted).Where("username = ?", user.Username).First(&existingUser).Error; err == nil {
 				return errors.New("用户名已存在")
 			}
 			if user.Mobile != "" {
-				if err := tx.Where("mobile = ? AND deleted_at = ?", user.Mobile, 0).First(&existingUser).Error; err == nil {
+				if err := tx.Scopes(notDeleted).Where("mobile = ?", user.Mobile).First(&existingUser).Error; err == nil {
 					return errors.New("手机号已存在")
 				}
 			}
 			if user.FeiShuUserId != "" {
-				if err := tx.Where("fei_shu_user_id = ? AND deleted_at = ?", user.FeiShuUserId, 0).First(&existingUser).Error; err == nil {
+				if err := tx.Scopes(notDeleted).Where("fei_shu_user_id = ?", user.FeiShuUserId).First(&existingUser).Error; err == nil {
 					return errors.New("飞书用户ID已存在")
 				}
 			}
@@ -122,7 +156,7 @@ func (u *userDAO) GetUserByUsername(ctx context.Context, username string) (*mode
 	}
 
 	var user model.User
-	if err := u.db.WithContext(ctx).Where("username = ? AND deleted_at = ?", username, 0).First(&user).Error; err != nil {
+	if err := u.db.WithContext(ctx).Scopes(notDeleted).Where("username = ?", username).First(&user).Error; err != nil {
 		u.l.Error("根据用户名获取用户失败", zap.String("username", username), zap.Error(err))
 		return nil, err
 	}
@@ -134,7 +168,7 @@ func (u *userDAO) GetUserByUsername(ctx context.Context, username string) (*mode
 func (u *userDAO) GetAllUsers(ctx context.Context) ([]*model.User, error) {
 	var users []*model.User
 	if err := u.db.WithContext(ctx).
-		Where("deleted_at = ?", 0).
+		Scopes(notDeleted).
 		Preload("Roles").
 		Preload("Menus").
 		Preload("Apis").
@@ -146,6 +180,43 @@ func (u *userDAO) GetAllUsers(ctx context.Context) ([]*model.User, error) {
 	return users, nil
 }
 
+// SearchUsers 按用户名或真实姓名模糊搜索用户，可按状态过滤，返回分页结果和总数
+func (u *userDAO) SearchUsers(ctx context.Context, keyword string, status *int, offset, limit int) ([]*model.User, int64, error) {
+	if offset < 0 {
+		return nil, 0, fmt.Errorf("offset不能为负数")
+	}
+	if limit <= 0 {
+		return nil, 0, fmt.Errorf("limit必须大于0")
+	}
+
+	query := u.db.WithContext(ctx).Model(&model.User{}).Scopes(notDeleted)
+	if keyword != "" {
+		like := "%" + keyword + "%"
+		query = query.Where("username LIKE ? OR real_name LIKE ?", like, like)
+	}
+	if status != nil {
+		query = query.Where("enable = ?", *status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		u.l.Error("统计用户数量失败", zap.String("keyword", keyword), zap.Error(err))
+		return nil, 0, err
+	}
+
+	var users []*model.User
+	if err := query.
+		Order("created_at DESC, id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&users).Error; err != nil {
+		u.l.Error("搜索用户失败", zap.String("keyword", keyword), zap.Error(err))
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
 // GetUserByID 根据用户ID获取用户信息
 func (u *userDAO) GetUserByID(ctx context.Context, id int) (*model.User, error) {
 	if id <= 0 {
@@ -154,7 +225,7 @@ func (u *userDAO) GetUserByID(ctx context.Context, id int) (*model.User, error)
 
 	var user model.User
 	if err := u.db.WithContext(ctx).
-		Where("id = ? AND deleted_at = ?", id, 0).
+		Scopes(notDeleted).Where("id = ?", id).
 		Preload("Roles").
 		Preload("Apis").
 		First(&user).Error; err != nil {
@@ -223,7 +294,7 @@ func (u *userDAO) GetUserByUsernames(ctx context.Context, usernames []string) ([
 
 	var users []*model.User
 	if err := u.db.WithContext(ctx).
-		Where("username in (?) AND deleted_at = ?", usernames, 0).
+		Scopes(notDeleted).Where("username in (?)", usernames).
 		Find(&users).Error; err != nil {
 		u.l.Error("批量获取用户失败", zap.Strings("usernames", usernames), zap.Error(err))
 		return nil, err
@@ -243,7 +314,7 @@ func (u *userDAO) ChangePassword(ctx context.Context, uid int, password string)
 
 	if err := u.db.WithContext(ctx).
 		Model(&model.User{}).
-		Where("id = ? AND deleted_at = ?", uid, 0).
+		Scopes(notDeleted).Where("id = ?", uid).
 		Update("password", password).Error; err != nil {
 		u.l.Error("修改密码失败", zap.Int("uid", uid), zap.Error(err))
 		return err
@@ -261,7 +332,7 @@ func (u *userDAO) UpdateProfile(ctx context.Context, user *model.User) error {
 	// 使用事务和一次性查询检查唯一性约束
 	err := u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		var count int64
-		query := tx.Model(&model.User{}).Where("deleted_at = ?", 0).
+		query := tx.Model(&model.User{}).Scopes(notDeleted).
 			Where("id != ? AND ((mobile = ? AND mobile != '') OR (fei_shu_user_id = ? AND fei_shu_user_id != ''))",
 				user.ID, user.Mobile, user.FeiShuUserId)
 
@@ -274,12 +345,12 @@ func (u *userDAO) UpdateProfile(ctx context.Context, user *model.User) error {
 			// 进一步确定具体是哪个字段重复
 			var existingUser model.User
 			if user.Mobile != "" {
-				if err := tx.Where("id != ? AND mobile = ? AND deleted_at = ?", user.ID, user.Mobile, 0).First(&existingUser).Error; err == nil {
+				if err := tx.Scopes(notDeleted).Where("id != ? AND mobile = ?", user.ID, user.Mobile).First(&existingUser).Error; err == nil {
 					return errors.New("手机号已存在")
 				}
 			}
 			if user.FeiShuUserId != "" {
-				if err := tx.Where("id != ? AND fei_shu_user_id = ? AND deleted_at = ?", user.ID, user.FeiShuUserId, 0).First(&existingUser).Error; err == nil {
+				if err := tx.Scopes(notDeleted).Where("id != ? AND fei_shu_user_id = ?", user.ID, user.FeiShuUserId).First(&existingUser).Error; err == nil {
 					return errors.New("飞书用户ID已存在")
 				}
 			}
@@ -297,7 +368,7 @@ func (u *userDAO) UpdateProfile(ctx context.Context, user *model.User) error {
 		}
 
 		if err := tx.Model(&model.User{}).
-			Where("id = ? AND deleted_at = ?", user.ID, 0).
+			Scopes(notDeleted).Where("id = ?", user.ID).
 			Updates(updates).Error; err != nil {
 			u.l.Error("更新用户信息失败", zap.Int("uid", user.ID), zap.Error(err))
 			return err
@@ -323,7 +394,7 @@ func (u *userDAO) WriteOff(ctx context.Context, username string, password string
 
 	if err := u.db.WithContext(ctx).
 		Model(&model.User{}).
-		Where("username = ? AND deleted_at = ?", username, 0).
+		Scopes(notDeleted).Where("username = ?", username).
 		Updates(updates).Error; err != nil {
 		u.l.Error("注销用户失败", zap.String("username", username), zap.Error(err))
 		return err
@@ -357,7 +428,7 @@ func (u *userDAO) DeleteUser(ctx context.Context, uid int) error {
 		}
 
 		// 删除用户
-		if err := tx.Where("id = ? AND deleted_at = ?", uid, 0).Delete(&model.User{}).Error; err != nil {
+		if err := tx.Scopes(notDeleted).Where("id = ?", uid).Delete(&model.User{}).Error; err != nil {
 			u.l.Error("删除用户失败", zap.Int("uid", uid), zap.Error(err))
 			return err
 		}
@@ -374,7 +445,7 @@ func (u *userDAO) GetUserByIDs(ctx context.Context, ids []int) ([]*model.User, e
 
 	var users []*model.User
 	if err := u.db.WithContext(ctx).
-		Where("id in (?) AND deleted_at = ?", ids, 0).
+		Scopes(notDeleted).Where("id in (?)", ids).
 		Find(&users).Error; err != nil {
 		u.l.Error("批量获取用户失败", zap.Ints("ids", ids), zap.Error(err))
 		return nil, err
@@ -382,3 +453,259 @@ func (u *userDAO) GetUserByIDs(ctx context.Context, ids []int) ([]*model.User, e
 
 	return users, nil
 }
+
+// GetUserBriefByID 仅查询用户的公开字段，不加载角色、接口和菜单，用户不存在时返回 gorm.ErrRecordNotFound
+func (u *userDAO) GetUserBriefByID(ctx context.Context, id int) (*model.UserBrief, error) {
+	if id <= 0 {
+		return nil, errors.New("invalid user id")
+	}
+
+	var brief model.UserBrief
+	if err := u.db.WithContext(ctx).
+		Model(&model.User{}).
+		Select("id, username, real_name, fei_shu_user_id").
+		Scopes(notDeleted).Where("id = ?", id).
+		Take(&brief).Error; err != nil {
+		return nil, err
+	}
+
+	return &brief, nil
+}
+
+// GetUsernamesByIDs 批量获取用户名，跳过零值和重复ID，未找到的用户不会出现在结果中
+func (u *userDAO) GetUsernamesByIDs(ctx context.Context, ids []int) map[int]string {
+	usernames := make(map[int]string, len(ids))
+
+	seen := make(map[int]struct{}, len(ids))
+	uniqueIDs := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if id <= 0 {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		uniqueIDs = append(uniqueIDs, id)
+	}
+
+	if len(uniqueIDs) == 0 {
+		return usernames
+	}
+
+	var users []*model.User
+	if err := u.db.WithContext(ctx).
+		Select("id, username").
+		Scopes(notDeleted).Where("id in (?)", uniqueIDs).
+		Find(&users).Error; err != nil {
+		u.l.Error("批量获取用户名失败", zap.Ints("ids", uniqueIDs), zap.Error(err))
+		return usernames
+	}
+
+	for _, user := range users {
+		usernames[user.ID] = user.Username
+	}
+
+	return usernames
+}
+
+// AssignRolesToUser 替换用户的角色集合，传入空切片时清空所有角色
+func (u *userDAO) AssignRolesToUser(ctx context.Context, userID int, roleIDs []int) error {
+	return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user model.User
+		if err := tx.Scopes(notDeleted).Where("id = ?", userID).First(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("用户不存在")
+			}
+			u.l.Error("获取用户失败", zap.Int("userID", userID), zap.Error(err))
+			return err
+		}
+
+		roles := make([]*model.Role, 0, len(roleIDs))
+		if len(roleIDs) > 0 {
+			if err := tx.Scopes(notDeleted).Where("id IN ?", roleIDs).Find(&roles).Error; err != nil {
+				u.l.Error("获取角色失败", zap.Ints("roleIDs", roleIDs), zap.Error(err))
+				return err
+			}
+			if len(roles) != len(roleIDs) {
+				return errors.New("部分角色不存在或已被删除")
+			}
+		}
+
+		// 空集合时清空角色关联
+		if len(roles) == 0 {
+			if err := tx.Model(&user).Association("Roles").Clear(); err != nil {
+				u.l.Error("清空用户角色关联失败", zap.Int("userID", userID), zap.Error(err))
+				return fmt.Errorf("清空用户角色关联失败: %v", err)
+			}
+			return nil
+		}
+
+		// 整体替换用户的角色关联
+		if err := tx.Model(&user).Association("Roles").Replace(roles); err != nil {
+			u.l.Error("更新用户角色关联失败", zap.Int("userID", userID), zap.Error(err))
+			return fmt.Errorf("更新用户角色关联失败: %v", err)
+		}
+
+		return nil
+	})
+}
+
+// GetUserRoles 获取用户关联的角色
+func (u *userDAO) GetUserRoles(ctx context.Context, userID int) ([]*model.Role, error) {
+	var roles []*model.Role
+
+	if err := u.db.WithContext(ctx).
+		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ? AND roles.deleted_at = ?", userID, 0).
+		Find(&roles).Error; err != nil {
+		u.l.Error("获取用户角色失败", zap.Int("userID", userID), zap.Error(err))
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// UserHasPermission 校验用户是否拥有指定权限，权限为用户角色关联的接口名称，未知权限返回 false
+func (u *userDAO) UserHasPermission(ctx context.Context, userID int, permission string) (bool, error) {
+	perms, err := u.getUserPermissions(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	_, ok := perms[permission]
+	return ok, nil
+}
+
+// IsAdmin 判断用户是否为管理员：内置管理员账号，或拥有未删除的系统角色，用户不存在时返回 false
+func (u *userDAO) IsAdmin(ctx context.Context, userID int) (bool, error) {
+	if userID <= 0 {
+		return false, nil
+	}
+
+	var usernames []string
+	if err := u.db.WithContext(ctx).
+		Model(&model.User{}).
+		Scopes(notDeleted).Where("id = ?", userID).
+		Pluck("username", &usernames).Error; err != nil {
+		u.l.Error("查询用户失败", zap.Int("userID", userID), zap.Error(err))
+		return false, err
+	}
+	if len(usernames) == 0 {
+		return false, nil
+	}
+	if usernames[0] == model.AdminUsername {
+		return true, nil
+	}
+
+	var count int64
+	if err := u.db.WithContext(ctx).
+		Model(&model.Role{}).
+		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ? AND roles.role_type = ? AND roles.deleted_at = ?", userID, model.RoleTypeSystem, 0).
+		Count(&count).Error; err != nil {
+		u.l.Error("查询用户系统角色失败", zap.Int("userID", userID), zap.Error(err))
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// getUserPermissions 获取用户的权限集合，上下文中存在权限缓存时优先使用缓存
+func (u *userDAO) getUserPermissions(ctx context.Context, userID int) (map[string]struct{}, error) {
+	cache, _ := ctx.Value(permissionCacheKey{}).(*permissionCache)
+	if cache != nil {
+		cache.mu.Lock()
+		defer cache.mu.Unlock()
+		if perms, ok := cache.perms[userID]; ok {
+			return perms, nil
+		}
+	}
+
+	var names []string
+	if err := u.db.WithContext(ctx).
+		Model(&model.Api{}).
+		Distinct("apis.name").
+		Joins("JOIN role_apis ON role_apis.api_id = apis.id").
+		Joins("JOIN user_roles ON user_roles.role_id = role_apis.role_id").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("user_roles.user_id = ? AND apis.deleted_at = ? AND roles.deleted_at = ?", userID, 0, 0).
+		Pluck("apis.name", &names).Error; err != nil {
+		u.l.Error("获取用户权限失败", zap.Int("userID", userID), zap.Error(err))
+		return nil, err
+	}
+
+	perms := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		perms[name] = struct{}{}
+	}
+
+	if cache != nil {
+		cache.perms[userID] = perms
+	}
+
+	return perms, nil
+}
+
+// RecordLoginFailure 记录一次登录失败，连续失败达到阈值后锁定账号
+func (u *userDAO) RecordLoginFailure(ctx context.Context, username string) error {
+	maxFailures := viper.GetInt("user.login_max_failures")
+	if maxFailures <= 0 {
+		maxFailures = defaultLoginMaxFailures
+	}
+	lockMinutes := viper.GetInt("user.login_lock_minutes")
+	if lockMinutes <= 0 {
+		lockMinutes = defaultLoginLockMinutes
+	}
+
+	return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.User{}).
+			Scopes(notDeleted).Where("username = ?", username).
+			Update("login_failures", gorm.Expr("login_failures + ?", 1)).Error; err != nil {
+			u.l.Error("记录登录失败次数失败", zap.String("username", username), zap.Error(err))
+			return err
+		}
+
+		var user model.User
+		if err := tx.Select("id, login_failures").
+			Scopes(notDeleted).Where("username = ?", username).
+			First(&user).Error; err != nil {
+			return err
+		}
+
+		if user.LoginFailures < maxFailures {
+			return nil
+		}
+
+		// 达到阈值后锁定账号并重新计数
+		lockedUntil := time.Now().Add(time.Duration(lockMinutes) * time.Minute).Unix()
+		if err := tx.Model(&model.User{}).
+			Where("id = ?", user.ID).
+			Updates(map[string]interface{}{
+				"login_failures": 0,
+				"locked_until":   lockedUntil,
+			}).Error; err != nil {
+			u.l.Error("锁定账号失败", zap.String("username", username), zap.Error(err))
+			return err
+		}
+
+		u.l.Warn("连续登录失败次数过多，账号已锁定", zap.String("username", username), zap.Int64("lockedUntil", lockedUntil))
+		return nil
+	})
+}
+
+// ClearLoginFailures 清除用户的登录失败次数和锁定状态
+func (u *userDAO) ClearLoginFailures(ctx context.Context, userID int) error {
+	if err := u.db.WithContext(ctx).
+		Model(&model.User{}).
+		Scopes(notDeleted).Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"login_failures": 0,
+			"locked_until":   0,
+		}).Error; err != nil {
+		u.l.Error("清除登录失败次数失败", zap.Int("userID", userID), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
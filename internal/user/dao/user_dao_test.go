@@ -0,0 +1,306 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package dao
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
+	"github.com/glebarez/sqlite"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestUserDAO(t *testing.T) *userDAO {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("打开测试数据库失败: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取数据库连接失败: %v", err)
+	}
+	// 内存库每个连接独立，限制为单连接保证数据可见
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.Migrator().CreateTable(&model.User{}); err != nil {
+		t.Fatalf("迁移测试表失败: %v", err)
+	}
+	return NewUserDAO(db, zap.NewNop(), nil).(*userDAO)
+}
+
+func TestGetUserBriefByID(t *testing.T) {
+	u := newTestUserDAO(t)
+	ctx := context.Background()
+
+	user := &model.User{Username: "alice", Password: "hash", RealName: "爱丽丝", FeiShuUserId: "ou_alice", Mobile: "13800000000"}
+	if err := u.db.Create(user).Error; err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+
+	brief, err := u.GetUserBriefByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserBriefByID 返回错误: %v", err)
+	}
+	want := model.UserBrief{ID: user.ID, Username: "alice", RealName: "爱丽丝", FeiShuUserId: "ou_alice"}
+	if *brief != want {
+		t.Fatalf("返回的用户信息不一致, 期望 %+v, 实际 %+v", want, *brief)
+	}
+
+	if err := u.db.Model(&model.User{}).Where("id = ?", user.ID).Update("deleted_at", time.Now().Unix()).Error; err != nil {
+		t.Fatalf("软删除用户失败: %v", err)
+	}
+	if _, err := u.GetUserBriefByID(ctx, user.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("已删除用户应返回 gorm.ErrRecordNotFound, 实际 %v", err)
+	}
+}
+
+// seedPermissionTables 建立权限查询所需的最小表结构，并为用户 1 授予 api:read 权限
+func seedPermissionTables(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	stmts := []string{
+		"CREATE TABLE roles (id INTEGER PRIMARY KEY, name TEXT, deleted_at INTEGER DEFAULT 0)",
+		"CREATE TABLE apis (id INTEGER PRIMARY KEY, name TEXT, deleted_at INTEGER DEFAULT 0)",
+		"CREATE TABLE user_roles (user_id INTEGER, role_id INTEGER)",
+		"CREATE TABLE role_apis (role_id INTEGER, api_id INTEGER)",
+		"INSERT INTO roles (id, name) VALUES (1, 'viewer')",
+		"INSERT INTO apis (id, name) VALUES (1, 'api:read'), (2, 'api:write')",
+		"INSERT INTO user_roles (user_id, role_id) VALUES (1, 1)",
+		"INSERT INTO role_apis (role_id, api_id) VALUES (1, 1)",
+	}
+	for _, stmt := range stmts {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("初始化权限表失败: %v", err)
+		}
+	}
+}
+
+func TestUserHasPermissionCachedPerRequest(t *testing.T) {
+	u := newTestUserDAO(t)
+	seedPermissionTables(t, u.db)
+
+	var queries int64
+	if err := u.db.Callback().Query().Before("gorm:query").Register("test:count_api_query", func(tx *gorm.DB) {
+		if strings.Contains(tx.Statement.Table, "apis") {
+			atomic.AddInt64(&queries, 1)
+		}
+	}); err != nil {
+		t.Fatalf("注册查询回调失败: %v", err)
+	}
+
+	ctx := WithPermissionCache(context.Background())
+	for _, c := range []struct {
+		perm string
+		want bool
+	}{
+		{"api:read", true},
+		{"api:write", false},
+		{"unknown", false},
+	} {
+		ok, err := u.UserHasPermission(ctx, 1, c.perm)
+		if err != nil {
+			t.Fatalf("UserHasPermission(%s) 返回错误: %v", c.perm, err)
+		}
+		if ok != c.want {
+			t.Fatalf("UserHasPermission(%s) 期望 %v, 实际 %v", c.perm, c.want, ok)
+		}
+	}
+	if got := atomic.LoadInt64(&queries); got != 1 {
+		t.Fatalf("同一请求内应只查询一次权限, 实际 %d 次", got)
+	}
+
+	// 未挂载缓存的上下文每次都查询
+	if _, err := u.UserHasPermission(context.Background(), 1, "api:read"); err != nil {
+		t.Fatalf("UserHasPermission 返回错误: %v", err)
+	}
+	if got := atomic.LoadInt64(&queries); got != 2 {
+		t.Fatalf("未挂载缓存时应重新查询, 实际累计 %d 次", got)
+	}
+}
+
+func TestIsAdmin(t *testing.T) {
+	u := newTestUserDAO(t)
+	ctx := context.Background()
+
+	for _, user := range []*model.User{
+		{ID: 1, Username: model.AdminUsername, Password: "hash", Mobile: "1", FeiShuUserId: "1"},
+		{ID: 2, Username: "ops", Password: "hash", Mobile: "2", FeiShuUserId: "2"},
+		{ID: 3, Username: "dev", Password: "hash", Mobile: "3", FeiShuUserId: "3"},
+		{ID: 4, Username: "former", Password: "hash", Mobile: "4", FeiShuUserId: "4"},
+	} {
+		if err := u.db.Create(user).Error; err != nil {
+			t.Fatalf("创建用户失败: %v", err)
+		}
+	}
+	// roles 与 apis 的唯一索引同名，sqlite 中索引名全局唯一，这里直接建表
+	stmts := []string{
+		"CREATE TABLE roles (id INTEGER PRIMARY KEY, name TEXT, role_type INTEGER, deleted_at INTEGER DEFAULT 0)",
+		"CREATE TABLE user_roles (user_id INTEGER, role_id INTEGER)",
+		"INSERT INTO roles (id, name, role_type, deleted_at) VALUES (1, 'sre', 1, 0), (2, 'dev', 2, 0), (3, 'legacy', 1, 100)",
+		"INSERT INTO user_roles (user_id, role_id) VALUES (2, 1), (3, 2), (4, 3)",
+	}
+	for _, stmt := range stmts {
+		if err := u.db.Exec(stmt).Error; err != nil {
+			t.Fatalf("初始化角色表失败: %v", err)
+		}
+	}
+
+	for _, c := range []struct {
+		userID int
+		want   bool
+	}{
+		{1, true},   // 内置管理员账号
+		{2, true},   // 拥有系统角色
+		{3, false},  // 仅有自定义角色
+		{4, false},  // 系统角色已删除
+		{99, false}, // 用户不存在
+	} {
+		got, err := u.IsAdmin(ctx, c.userID)
+		if err != nil {
+			t.Fatalf("IsAdmin(%d) 返回错误: %v", c.userID, err)
+		}
+		if got != c.want {
+			t.Fatalf("IsAdmin(%d) = %v, 期望 %v", c.userID, got, c.want)
+		}
+	}
+}
+
+func TestAssignRolesToUserReplacesSet(t *testing.T) {
+	u := newTestUserDAO(t)
+	ctx := context.Background()
+
+	user := &model.User{Username: "ops", Password: "hash", Mobile: "1", FeiShuUserId: "1"}
+	if err := u.db.Create(user).Error; err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+	// Replace 会回写角色记录，需要完整的角色表结构
+	if err := u.db.Migrator().CreateTable(&model.Role{}); err != nil {
+		t.Fatalf("迁移角色表失败: %v", err)
+	}
+	if err := u.db.Exec("CREATE TABLE user_roles (user_id INTEGER, role_id INTEGER, PRIMARY KEY (user_id, role_id))").Error; err != nil {
+		t.Fatalf("创建用户角色关联表失败: %v", err)
+	}
+	for _, role := range []*model.Role{
+		{ID: 1, Name: "viewer"},
+		{ID: 2, Name: "editor"},
+		{ID: 3, Name: "owner"},
+		{ID: 4, Name: "legacy", DeletedAt: 100},
+	} {
+		if err := u.db.Create(role).Error; err != nil {
+			t.Fatalf("创建角色失败: %v", err)
+		}
+	}
+
+	roleIDs := func() []int {
+		t.Helper()
+		roles, err := u.GetUserRoles(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("GetUserRoles 返回错误: %v", err)
+		}
+		ids := make([]int, 0, len(roles))
+		for _, role := range roles {
+			ids = append(ids, role.ID)
+		}
+		sort.Ints(ids)
+		return ids
+	}
+
+	if err := u.AssignRolesToUser(ctx, user.ID, []int{1, 2}); err != nil {
+		t.Fatalf("AssignRolesToUser 返回错误: %v", err)
+	}
+	if got := roleIDs(); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Fatalf("分配后角色应为 [1 2], 实际 %v", got)
+	}
+
+	// 再次分配整体替换原有角色
+	if err := u.AssignRolesToUser(ctx, user.ID, []int{2, 3}); err != nil {
+		t.Fatalf("AssignRolesToUser 返回错误: %v", err)
+	}
+	if got := roleIDs(); !reflect.DeepEqual(got, []int{2, 3}) {
+		t.Fatalf("替换后角色应为 [2 3], 实际 %v", got)
+	}
+
+	// 包含已删除角色时整体失败，原有角色保持不变
+	if err := u.AssignRolesToUser(ctx, user.ID, []int{1, 4}); err == nil {
+		t.Fatal("包含已删除角色时应返回错误")
+	}
+	if got := roleIDs(); !reflect.DeepEqual(got, []int{2, 3}) {
+		t.Fatalf("分配失败后角色不应变化, 实际 %v", got)
+	}
+
+	if err := u.AssignRolesToUser(ctx, user.ID, []int{}); err != nil {
+		t.Fatalf("AssignRolesToUser 返回错误: %v", err)
+	}
+	if got := roleIDs(); len(got) != 0 {
+		t.Fatalf("分配空集合应清空角色, 实际 %v", got)
+	}
+}
+
+func TestGetUsernamesByIDsDuplicateAndMissing(t *testing.T) {
+	u := newTestUserDAO(t)
+	ctx := context.Background()
+
+	for _, user := range []*model.User{
+		{ID: 1, Username: "alice", Password: "hash", Mobile: "1", FeiShuUserId: "1"},
+		{ID: 2, Username: "bob", Password: "hash", Mobile: "2", FeiShuUserId: "2"},
+		{ID: 3, Username: "former", Password: "hash", Mobile: "3", FeiShuUserId: "3", DeletedAt: 100},
+	} {
+		if err := u.db.Create(user).Error; err != nil {
+			t.Fatalf("创建用户失败: %v", err)
+		}
+	}
+
+	var queries int64
+	if err := u.db.Callback().Query().Before("gorm:query").Register("test:count_user_query", func(tx *gorm.DB) {
+		atomic.AddInt64(&queries, 1)
+	}); err != nil {
+		t.Fatalf("注册查询回调失败: %v", err)
+	}
+
+	got := u.GetUsernamesByIDs(ctx, []int{1, 2, 1, 0, 3, 99, 2})
+	want := map[int]string{1: "alice", 2: "bob"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("期望 %v, 实际 %v", want, got)
+	}
+	if n := atomic.LoadInt64(&queries); n != 1 {
+		t.Fatalf("应只查询一次数据库, 实际 %d 次", n)
+	}
+
+	if got := u.GetUsernamesByIDs(ctx, []int{0, -1}); len(got) != 0 {
+		t.Fatalf("无有效ID时应返回空结果, 实际 %v", got)
+	}
+	if n := atomic.LoadInt64(&queries); n != 1 {
+		t.Fatalf("无有效ID时不应查询数据库, 实际累计 %d 次", n)
+	}
+}
@@ -28,6 +28,8 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/GoSimplicity/AI-CloudOps/internal/constants"
 	"github.com/GoSimplicity/AI-CloudOps/internal/system/service"
@@ -99,11 +101,25 @@ func (us *userService) Login(ctx context.Context, user *model.User) (*model.User
 		return &model.User{}, err
 	}
 
+	// 账号锁定期间不校验密码
+	if u.LockedUntil > time.Now().Unix() {
+		return &model.User{}, fmt.Errorf("%w, 解锁时间: %s", constants.ErrorAccountLocked, time.Unix(u.LockedUntil, 0).Format(time.DateTime))
+	}
+
 	err = bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(user.Password))
 	if err != nil {
+		if err := us.dao.RecordLoginFailure(ctx, u.Username); err != nil {
+			return &model.User{}, err
+		}
 		return &model.User{}, constants.ErrorPasswordIncorrect
 	}
 
+	if u.LoginFailures > 0 || u.LockedUntil > 0 {
+		if err := us.dao.ClearLoginFailures(ctx, u.ID); err != nil {
+			return &model.User{}, err
+		}
+	}
+
 	return u, nil
 }
 
@@ -0,0 +1,73 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package cursor
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	c := NewCodec([]byte("secret"))
+	want := Cursor{LastID: 42, SortKey: 1700000000}
+
+	got, err := c.Decode(c.Encode(want))
+	if err != nil {
+		t.Fatalf("解码游标失败: %v", err)
+	}
+	if got == nil || *got != want {
+		t.Fatalf("期望 %+v, 实际 %+v", want, got)
+	}
+
+	if got, err := c.Decode(""); err != nil || got != nil {
+		t.Fatalf("空游标应表示第一页, 实际 %+v, %v", got, err)
+	}
+}
+
+func TestCodecRejectsTamperedCursor(t *testing.T) {
+	c := NewCodec([]byte("secret"))
+	encoded := c.Encode(Cursor{LastID: 42, SortKey: 1700000000})
+	_, sig, _ := strings.Cut(encoded, ".")
+
+	forgedPayload := base64.RawURLEncoding.EncodeToString([]byte(`{"i":1,"k":0}`))
+
+	cases := map[string]string{
+		"篡改内容":   forgedPayload + "." + sig,
+		"篡改签名":   strings.TrimSuffix(encoded, sig) + base64.RawURLEncoding.EncodeToString(make([]byte, signatureSize)),
+		"其他密钥签名": NewCodec([]byte("other")).Encode(Cursor{LastID: 42, SortKey: 1700000000}),
+		"缺少签名":   forgedPayload,
+		"非法编码":   "!!!.!!!",
+	}
+	for name, s := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := c.Decode(s); !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("期望 ErrInvalidCursor, 实际 %v", err)
+			}
+		})
+	}
+}
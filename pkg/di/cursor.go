@@ -0,0 +1,53 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package di
+
+import (
+	"crypto/rand"
+
+	"github.com/GoSimplicity/AI-CloudOps/pkg/cursor"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// cursorSecretSize 未配置游标密钥时随机生成的密钥字节数
+const cursorSecretSize = 32
+
+// InitCursorCodec 使用 server.cursor_secret 创建分页游标编解码器，
+// 未配置时在启动时随机生成密钥，重启后之前下发的游标将失效
+func InitCursorCodec(l *zap.Logger) *cursor.Codec {
+	if secret := viper.GetString("server.cursor_secret"); secret != "" {
+		return cursor.NewCodec([]byte(secret))
+	}
+
+	secret := make([]byte, cursorSecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		l.Fatal("生成分页游标密钥失败", zap.Error(err))
+	}
+	l.Warn("未配置 server.cursor_secret，使用随机生成的分页游标密钥，多实例部署或重启后游标将失效")
+
+	return cursor.NewCodec(secret)
+}
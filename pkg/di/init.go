@@ -67,5 +67,10 @@ func InitTables(db *gorm.DB) error {
 		&model.MonitorSendGroup{},
 		&model.MonitorOnDutyChange{},
 		&model.MonitorAlertEvent{},
+		&model.AlertEventAudit{},
+		&model.MonitorInhibitRule{},
+		&model.MonitorSilence{},
+		&model.NotificationLog{},
+		&model.AlertEventLabel{},
 	)
 }
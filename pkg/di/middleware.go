@@ -42,11 +42,12 @@ import (
 // InitMiddlewares 初始化中间件
 func InitMiddlewares(ih ijwt.Handler, l *zap.Logger, enforcer *casbin.Enforcer, auditSvc service.AuditService) []gin.HandlerFunc {
 	return []gin.HandlerFunc{
+		middleware.RequestID(),
 		cors.New(cors.Config{
 			AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
 			AllowCredentials: true,
-			AllowHeaders:     []string{"Content-Type", "Authorization", "X-Refresh-Token"},
-			ExposeHeaders:    []string{"x-jwt-token", "x-refresh-token"},
+			AllowHeaders:     []string{"Content-Type", "Authorization", "X-Refresh-Token", "X-Request-Id"},
+			ExposeHeaders:    []string{"x-jwt-token", "x-refresh-token", "x-request-id"},
 			AllowOriginFunc: func(origin string) bool {
 				if strings.HasPrefix(origin, "http://localhost") {
 					return true
@@ -56,6 +57,7 @@ func InitMiddlewares(ih ijwt.Handler, l *zap.Logger, enforcer *casbin.Enforcer,
 			MaxAge: 12 * time.Hour,
 		}),
 		middleware.NewJWTMiddleware(ih).CheckLogin(),
+		middleware.PermissionCache(),
 		middleware.NewCasbinMiddleware(enforcer).CheckCasbin(),
 		middleware.NewLogMiddleware(l).Log(),
 		middleware.NewAuditLogMiddleware(auditSvc, l).AuditLog(),
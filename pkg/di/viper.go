@@ -26,6 +26,7 @@
 package di
 
 import (
+	"github.com/GoSimplicity/AI-CloudOps/internal/model"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
@@ -40,6 +41,8 @@ func InitViper() error {
 		return err
 	}
 
+	model.SetRFC3339TimeEnabled(viper.GetBool("server.rfc3339_time"))
+
 	return nil
 }
 
@@ -33,6 +33,7 @@ import (
 	treeApi "github.com/GoSimplicity/AI-CloudOps/internal/tree/api"
 	userApi "github.com/GoSimplicity/AI-CloudOps/internal/user/api"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // InitGinServer 初始化web服务
@@ -74,6 +75,8 @@ func InitGinServer(
 ) *gin.Engine {
 	server := gin.Default()
 	server.Use(m...)
+	// 暴露进程自身的监控指标，供 Prometheus 抓取
+	server.GET("/metrics", gin.WrapH(promhttp.Handler()))
 	userHdl.RegisterRoutes(server)
 	authMenuHdl.RegisterRouters(server)
 	authApiHdl.RegisterRouters(server)
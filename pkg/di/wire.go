@@ -64,6 +64,10 @@ func InitWebServer() *Cmd {
 		ijwt.NewJWTHandler,
 		InitGinServer,
 		InitLogger,
+		InitPrometheusRegisterer,
+		InitNotifyHeaders,
+		InitHTTPClient,
+		InitCursorCodec,
 		InitRedis,
 		InitDB,
 		InitCasbin,
@@ -143,6 +147,7 @@ func InitWebServer() *Cmd {
 		scrapeJobService.NewPrometheusScrapeService,
 		scrapeJobService.NewPrometheusPoolService,
 		alertDao.NewAlertManagerEventDAO,
+		alertDao.NewRedisAlertEventCache,
 		alertDao.NewAlertManagerOnDutyDAO,
 		alertDao.NewAlertManagerPoolDAO,
 		alertDao.NewAlertManagerRecordDAO,
@@ -45,10 +45,11 @@ func InitWebServer() *Cmd {
 	cmdable := InitRedis()
 	handler := utils.NewJWTHandler(cmdable)
 	logger := InitLogger()
+	registerer := InitPrometheusRegisterer()
 	db := InitDB()
 	enforcer := InitCasbin(db)
 	auditDAO := dao.NewAuditDAO(db, logger)
-	auditService := service.NewAuditService(auditDAO)
+	auditService := service.NewAuditService(auditDAO, logger)
 	v := InitMiddlewares(handler, logger, enforcer, auditService)
 	apiDAO := dao.NewApiDAO(db, enforcer, logger)
 	permissionDAO := dao.NewPermissionDAO(db, logger, enforcer, apiDAO)
@@ -62,7 +63,7 @@ func InitWebServer() *Cmd {
 	apiHandler := api2.NewApiHandler(apiService)
 	menuDAO := dao.NewMenuDAO(db, logger)
 	menuService := service.NewMenuService(menuDAO, logger)
-	menuHandler := api2.NewMenuHandler(menuService)
+	menuHandler := api2.NewMenuHandler(menuService, auditService)
 	roleHandler := api2.NewRoleHandler(roleService, apiService, permissionService, logger)
 	permissionHandler := api2.NewPermissionHandler(permissionService)
 	treeNodeDAO := dao3.NewTreeNodeDAO(db, logger)
@@ -112,20 +113,24 @@ func InitWebServer() *Cmd {
 	yamlTemplateService := admin2.NewYamlTemplateService(yamlTemplateDAO, yamlTaskDAO, k8sClient, logger)
 	k8sYamlTemplateHandler := api5.NewK8sYamlTemplateHandler(logger, yamlTemplateService)
 	k8sAppHandler := api5.NewK8sAppHandler(logger)
-	alertManagerEventDAO := alert.NewAlertManagerEventDAO(db, logger, userDAO)
+	alertEventCache := alert.NewRedisAlertEventCache(cmdable)
+	notifyHeaders := InitNotifyHeaders()
+	httpClient := InitHTTPClient()
+	alertManagerEventDAO := alert.NewAlertManagerEventDAO(db, logger, userDAO, registerer, alertEventCache, notifyHeaders, httpClient)
 	scrapePoolDAO := scrape.NewScrapePoolDAO(db, logger, userDAO)
 	scrapeJobDAO := scrape.NewScrapeJobDAO(db, logger, userDAO)
-	promConfigCache := cache.NewPromConfigCache(logger, scrapePoolDAO, scrapeJobDAO)
+	promConfigCache := cache.NewPromConfigCache(logger, scrapePoolDAO, scrapeJobDAO, treeNodeDAO, httpClient)
 	alertManagerPoolDAO := alert.NewAlertManagerPoolDAO(db, logger, userDAO)
 	alertManagerSendDAO := alert.NewAlertManagerSendDAO(db, logger, userDAO)
 	alertConfigCache := cache.NewAlertConfigCache(logger, alertManagerPoolDAO, alertManagerSendDAO)
 	alertManagerRuleDAO := alert.NewAlertManagerRuleDAO(db, logger, userDAO)
-	ruleConfigCache := cache.NewRuleConfigCache(logger, scrapePoolDAO, alertManagerRuleDAO)
+	ruleConfigCache := cache.NewRuleConfigCache(logger, scrapePoolDAO, alertManagerRuleDAO, promConfigCache)
 	alertManagerRecordDAO := alert.NewAlertManagerRecordDAO(db, logger, userDAO)
-	recordConfigCache := cache.NewRecordConfig(logger, scrapePoolDAO, alertManagerRecordDAO)
+	recordConfigCache := cache.NewRecordConfig(logger, scrapePoolDAO, alertManagerRecordDAO, promConfigCache)
 	monitorCache := cache.NewMonitorCache(promConfigCache, alertConfigCache, ruleConfigCache, recordConfigCache, logger)
-	alertManagerEventService := alert2.NewAlertManagerEventService(alertManagerEventDAO, monitorCache, logger, userDAO, alertManagerSendDAO)
-	alertEventHandler := api6.NewAlertEventHandler(logger, alertManagerEventService)
+	codec := InitCursorCodec(logger)
+	alertManagerEventService := alert2.NewAlertManagerEventService(alertManagerEventDAO, monitorCache, logger, userDAO, alertManagerSendDAO, codec)
+	alertEventHandler := api6.NewAlertEventHandler(logger, alertManagerEventService, auditService)
 	alertManagerPoolService := alert2.NewAlertManagerPoolService(alertManagerPoolDAO, alertManagerSendDAO, monitorCache, logger, userDAO)
 	alertPoolHandler := api6.NewAlertPoolHandler(logger, alertManagerPoolService)
 	alertManagerRuleService := alert2.NewAlertManagerRuleService(alertManagerRuleDAO, monitorCache, logger, userDAO)
@@ -181,6 +181,26 @@ func Unauthorized(c *gin.Context, code int, data interface{}, message string) {
 	})
 }
 
+// NotFound 资源不存在的返回，使用HTTP 404状态码
+func NotFound(c *gin.Context, code int, data interface{}, message string) {
+	c.JSON(http.StatusNotFound, ApiResponse{
+		Code:    code,
+		Data:    data,
+		Message: message,
+		Type:    "",
+	})
+}
+
+// Conflict 资源状态冲突的返回，使用HTTP 409状态码
+func Conflict(c *gin.Context, code int, data interface{}, message string) {
+	c.JSON(http.StatusConflict, ApiResponse{
+		Code:    code,
+		Data:    data,
+		Message: message,
+		Type:    "",
+	})
+}
+
 // InternalServerError 服务器内部错误的返回，使用HTTP 500状态码
 func InternalServerError(c *gin.Context, code int, data interface{}, message string) {
 	c.JSON(http.StatusInternalServerError, ApiResponse{
@@ -211,6 +231,16 @@ func ForbiddenError(c *gin.Context, message string) {
 	Forbidden(c, StatusError, map[string]interface{}{}, message)
 }
 
+// NotFoundError 资源不存在的失败返回
+func NotFoundError(c *gin.Context, message string) {
+	NotFound(c, StatusError, map[string]interface{}{}, message)
+}
+
+// ConflictError 资源状态冲突的失败返回
+func ConflictError(c *gin.Context, message string) {
+	Conflict(c, StatusError, map[string]interface{}{}, message)
+}
+
 // InternalServerErrorWithDetails 带详细数据和消息的服务器内部错误返回
 func InternalServerErrorWithDetails(c *gin.Context, data interface{}, message string) {
 	InternalServerError(c, StatusError, data, message)
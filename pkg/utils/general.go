@@ -37,6 +37,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/go-ping/ping"
 )
@@ -131,6 +132,33 @@ func TrimSpaces(str string) string {
 	return strings.TrimSpace(str)
 }
 
+// truncatedSuffix 消息被截断时追加的省略号
+const truncatedSuffix = "…"
+
+// TruncateMessage 将消息截断到不超过 maxBytes 字节并追加省略号，只在字符边界截断，不会拆分多字节字符；
+// maxBytes 不足以容纳省略号时返回空字符串
+func TruncateMessage(msg string, maxBytes int) string {
+	return TruncateMessageWithSuffix(msg, maxBytes, truncatedSuffix)
+}
+
+// TruncateMessageWithSuffix 与 TruncateMessage 相同，但截断时追加指定的后缀；
+// maxBytes 不足以容纳后缀时返回空字符串
+func TruncateMessageWithSuffix(msg string, maxBytes int, suffix string) string {
+	if len(msg) <= maxBytes {
+		return msg
+	}
+	if maxBytes < len(suffix) {
+		return ""
+	}
+
+	cut := maxBytes - len(suffix)
+	for cut > 0 && !utf8.RuneStart(msg[cut]) {
+		cut--
+	}
+
+	return msg[:cut] + suffix
+}
+
 // IsSameDay 判断两个日期是否为同一天
 func IsSameDay(t1, t2 time.Time) bool {
 	y1, m1, d1 := t1.Date()
@@ -0,0 +1,79 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package utils
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateMessageNeverSplitsRunes(t *testing.T) {
+	msg := strings.Repeat("告警", 10) // 每个汉字 3 字节
+	for maxBytes := len(truncatedSuffix); maxBytes < len(msg); maxBytes++ {
+		got := TruncateMessage(msg, maxBytes)
+		if len(got) > maxBytes {
+			t.Fatalf("maxBytes=%d 时结果超出限制: %d 字节", maxBytes, len(got))
+		}
+		if !utf8.ValidString(got) {
+			t.Fatalf("maxBytes=%d 时截断拆开了多字节字符: %q", maxBytes, got)
+		}
+		if !strings.HasSuffix(got, truncatedSuffix) {
+			t.Fatalf("截断后应追加省略号: %q", got)
+		}
+	}
+
+	if got := TruncateMessage(msg, len(msg)); got != msg {
+		t.Fatalf("未超出限制时应原样返回, 实际 %q", got)
+	}
+	if got := TruncateMessage(msg, len(truncatedSuffix)-1); got != "" {
+		t.Fatalf("容纳不下省略号时应返回空字符串, 实际 %q", got)
+	}
+}
+
+func TestTruncateMessageWithSuffix(t *testing.T) {
+	const marker = "…(truncated)"
+	msg := strings.Repeat("告警", 10)
+	for maxBytes := len(marker); maxBytes < len(msg); maxBytes++ {
+		got := TruncateMessageWithSuffix(msg, maxBytes, marker)
+		if len(got) > maxBytes || !utf8.ValidString(got) {
+			t.Fatalf("maxBytes=%d 时截断结果非法: %q", maxBytes, got)
+		}
+		if !strings.HasSuffix(got, marker) {
+			t.Fatalf("截断后应追加截断标记: %q", got)
+		}
+	}
+
+	if got := TruncateMessageWithSuffix(msg, len(msg), marker); got != msg {
+		t.Fatalf("未超出限制时应原样返回, 实际 %q", got)
+	}
+	if got := TruncateMessageWithSuffix(msg, len(marker)-1, marker); got != "" {
+		t.Fatalf("容纳不下截断标记时应返回空字符串, 实际 %q", got)
+	}
+	if got, want := TruncateMessage(msg, 10), TruncateMessageWithSuffix(msg, 10, truncatedSuffix); got != want {
+		t.Fatalf("TruncateMessage 应使用省略号后缀, 期望 %q, 实际 %q", want, got)
+	}
+}
@@ -225,7 +225,7 @@ func BuildMatchers(alertEvent *model.MonitorAlertEvent, l *zap.Logger, useName b
 	var matchers []*labels.Matcher
 	if useName {
 		// 如果 useName 为 true，仅使用 alertname 匹配器
-		alertName, exists := alertEvent.LabelsMap["alertname"]
+		alertName, exists := alertEvent.Labels["alertname"]
 		if !exists {
 			l.Error("EventAlertSilence failed: alertname missing in LabelsMatcher", zap.Int("id", alertEvent.ID))
 			return nil, fmt.Errorf("alertname missing in LabelsMatcher")
@@ -239,7 +239,7 @@ func BuildMatchers(alertEvent *model.MonitorAlertEvent, l *zap.Logger, useName b
 		}
 	} else {
 		// 否则，使用所有标签匹配器
-		for key, val := range alertEvent.LabelsMap {
+		for key, val := range alertEvent.Labels {
 			matcher := &labels.Matcher{
 				Type:  labels.MatchEqual,
 				Name:  key,
@@ -369,6 +369,50 @@ func PostWithJson(ctx context.Context, client *http.Client, l *zap.Logger, url s
 	return bodyBytes, nil
 }
 
+const maxReloadRetries = 3 // 重新加载 Prometheus 配置的最大重试次数
+
+// reloadRetryDelay 重新加载 Prometheus 配置的基础重试延迟，测试中可调小
+var reloadRetryDelay = 1 * time.Second
+
+// ReloadPrometheus 调用 Prometheus 生命周期接口 /-/reload 重新加载配置，失败时按递增间隔重试
+func ReloadPrometheus(ctx context.Context, client *http.Client, l *zap.Logger, prometheusURL string) error {
+	reloadURL := strings.TrimRight(prometheusURL, "/") + "/-/reload"
+
+	var lastError error
+
+	for retryCount := 0; retryCount < maxReloadRetries; retryCount++ {
+		if retryCount > 0 {
+			delay := time.Duration(retryCount) * reloadRetryDelay
+			l.Info("重试重新加载 Prometheus 配置",
+				zap.String("url", reloadURL),
+				zap.Int("重试次数", retryCount),
+				zap.Duration("延迟时间", delay),
+				zap.Error(lastError),
+			)
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		if _, err := PostWithJson(ctx, client, l, reloadURL, "", nil, nil); err != nil {
+			lastError = err
+			continue
+		}
+
+		return nil
+	}
+
+	l.Error("重新加载 Prometheus 配置失败，达到最大重试次数",
+		zap.String("url", reloadURL),
+		zap.Int("最大重试次数", maxReloadRetries),
+		zap.Error(lastError),
+	)
+	return fmt.Errorf("重新加载 Prometheus 配置失败: %w", lastError)
+}
+
 // CloneMap 克隆一个字符串到字符串的映射
 func CloneMap(original map[string]string) map[string]string {
 	if original == nil {
@@ -522,3 +566,123 @@ func AtomicWriteFile(filePath string, data []byte) error {
 	}
 	return nil
 }
+
+// DiffLines 按行比较两段文本，返回逐行差异，未变化的行以 "  " 开头，删除行以 "- " 开头，新增行以 "+ " 开头。
+// 使用线性空间的 Myers 算法，内存占用与行数成正比，避免大配置预览时按 O(n·m) 分配内存
+func DiffLines(oldText, newText string) string {
+	oldLines := strings.Split(oldText, "\n")
+	newLines := strings.Split(newText, "\n")
+	if oldText == "" {
+		oldLines = nil
+	}
+	if newText == "" {
+		newLines = nil
+	}
+
+	d := &lineDiffer{a: oldLines, b: newLines}
+	d.diff(0, len(oldLines), 0, len(newLines))
+
+	return d.sb.String()
+}
+
+// lineDiffer 按 Myers 线性空间算法递归比较 a[a0:a1] 与 b[b0:b1]，并按顺序写出差异行
+type lineDiffer struct {
+	a, b []string
+	sb   strings.Builder
+}
+
+// diff 比较 a[a0:a1] 与 b[b0:b1]，先去掉公共前后缀，再以中间蛇为界拆分成两个更小的子问题
+func (d *lineDiffer) diff(a0, a1, b0, b1 int) {
+	for a0 < a1 && b0 < b1 && d.a[a0] == d.b[b0] {
+		d.sb.WriteString("  " + d.a[a0] + "\n")
+		a0++
+		b0++
+	}
+	suffix := 0
+	for a0 < a1-suffix && b0 < b1-suffix && d.a[a1-suffix-1] == d.b[b1-suffix-1] {
+		suffix++
+	}
+	a1 -= suffix
+	b1 -= suffix
+
+	switch {
+	case a0 == a1:
+		for ; b0 < b1; b0++ {
+			d.sb.WriteString("+ " + d.b[b0] + "\n")
+		}
+	case b0 == b1:
+		for ; a0 < a1; a0++ {
+			d.sb.WriteString("- " + d.a[a0] + "\n")
+		}
+	default:
+		x, y, u, v := d.middleSnake(a0, a1, b0, b1)
+		d.diff(a0, x, b0, y)
+		for ; x < u; x++ {
+			d.sb.WriteString("  " + d.a[x] + "\n")
+		}
+		d.diff(u, a1, v, b1)
+	}
+
+	for i := a1; i < a1+suffix; i++ {
+		d.sb.WriteString("  " + d.a[i] + "\n")
+	}
+}
+
+// middleSnake 从两端同时搜索最短编辑路径，返回两条路径相遇处的蛇 (x, y) -> (u, v)。
+// 调用方保证两段都非空且首尾行不同，因此编辑距离至少为 2，蛇两侧的子问题都严格更小
+func (d *lineDiffer) middleSnake(a0, a1, b0, b1 int) (x, y, u, v int) {
+	n, m := a1-a0, b1-b0
+	delta := n - m
+	odd := delta%2 != 0
+	maxD := (n + m + 1) / 2
+	off := maxD + 1
+
+	// vf[k] 为正向第 k 条对角线上到达的最远 x，vb[k] 为反向（从末尾倒推）第 k 条对角线上到达的最远 x
+	vf := make([]int, 2*maxD+3)
+	vb := make([]int, 2*maxD+3)
+
+	for step := 0; step <= maxD; step++ {
+		for k := -step; k <= step; k += 2 {
+			var px int
+			if k == -step || (k != step && vf[off+k-1] < vf[off+k+1]) {
+				px = vf[off+k+1]
+			} else {
+				px = vf[off+k-1] + 1
+			}
+			py := px - k
+			sx, sy := px, py
+			for px < n && py < m && d.a[a0+px] == d.b[b0+py] {
+				px++
+				py++
+			}
+			vf[off+k] = px
+
+			if kr := delta - k; odd && kr >= -(step-1) && kr <= step-1 && px+vb[off+kr] >= n {
+				return a0 + sx, b0 + sy, a0 + px, b0 + py
+			}
+		}
+
+		for k := -step; k <= step; k += 2 {
+			var px int
+			if k == -step || (k != step && vb[off+k-1] < vb[off+k+1]) {
+				px = vb[off+k+1]
+			} else {
+				px = vb[off+k-1] + 1
+			}
+			py := px - k
+			sx, sy := px, py
+			for px < n && py < m && d.a[a1-1-px] == d.b[b1-1-py] {
+				px++
+				py++
+			}
+			vb[off+k] = px
+
+			if kf := delta - k; !odd && kf >= -step && kf <= step && px+vf[off+kf] >= n {
+				return a1 - px, b1 - py, a1 - sx, b1 - sy
+			}
+		}
+	}
+
+	// 不可达：编辑距离不会超过 n+m
+	return a0, b0, a0, b0
+}
@@ -0,0 +1,186 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package utils
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// applyDiff 从 DiffLines 的输出中还原出旧文本和新文本，并统计编辑行数
+func applyDiff(t *testing.T, diff string) (oldLines, newLines []string, edits int) {
+	t.Helper()
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		prefix, text := line[:2], line[2:]
+		switch prefix {
+		case "  ":
+			oldLines = append(oldLines, text)
+			newLines = append(newLines, text)
+		case "- ":
+			oldLines = append(oldLines, text)
+			edits++
+		case "+ ":
+			newLines = append(newLines, text)
+			edits++
+		default:
+			t.Fatalf("未知的差异行前缀: %q", line)
+		}
+	}
+	return oldLines, newLines, edits
+}
+
+// lcsLength 以 O(n·m) 动态规划计算最长公共子序列长度，作为最短编辑数的参照
+func lcsLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func TestDiffLinesFormat(t *testing.T) {
+	got := DiffLines("a\nb\nc", "a\nc\nd")
+	want := "  a\n- b\n  c\n+ d\n"
+	if got != want {
+		t.Fatalf("DiffLines 输出不符合预期:\n%s\n期望:\n%s", got, want)
+	}
+
+	if got := DiffLines("", "x"); got != "+ x\n" {
+		t.Fatalf("旧文本为空时应全部为新增, 实际 %q", got)
+	}
+	if got := DiffLines("x", ""); got != "- x\n" {
+		t.Fatalf("新文本为空时应全部为删除, 实际 %q", got)
+	}
+}
+
+func TestDiffLinesIsMinimalAndReconstructsBothSides(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	alphabet := []string{"a", "b", "c", "d"}
+	randomLines := func() []string {
+		lines := make([]string, rng.Intn(30))
+		for i := range lines {
+			lines[i] = alphabet[rng.Intn(len(alphabet))]
+		}
+		return lines
+	}
+
+	for i := 0; i < 500; i++ {
+		a, b := randomLines(), randomLines()
+		diff := DiffLines(strings.Join(a, "\n"), strings.Join(b, "\n"))
+
+		gotOld, gotNew, edits := applyDiff(t, diff)
+		if strings.Join(gotOld, "\n") != strings.Join(a, "\n") || strings.Join(gotNew, "\n") != strings.Join(b, "\n") {
+			t.Fatalf("差异无法还原原文本:\n旧 %q\n新 %q\n差异:\n%s", a, b, diff)
+		}
+		if want := len(a) + len(b) - 2*lcsLength(a, b); edits != want {
+			t.Fatalf("编辑行数应为最小值 %d, 实际 %d:\n旧 %q\n新 %q", want, edits, a, b)
+		}
+	}
+}
+
+func TestDiffLinesLargeInputUsesLinearMemory(t *testing.T) {
+	oldLines := make([]string, 20000)
+	newLines := make([]string, 20000)
+	for i := range oldLines {
+		oldLines[i] = "line"
+		newLines[i] = "line"
+	}
+	oldLines[10000] = "old"
+	newLines[15000] = "new"
+
+	allocs := testing.AllocsPerRun(1, func() {
+		DiffLines(strings.Join(oldLines, "\n"), strings.Join(newLines, "\n"))
+	})
+	// O(n·m) 的实现需要为每一行分配一行表格，这里远小于行数
+	if allocs > 1000 {
+		t.Fatalf("大配置比较分配次数过多: %.0f", allocs)
+	}
+}
+
+// newReloadServer 启动模拟 Prometheus /-/reload 接口的测试服务，前 failures 次请求返回 500
+func newReloadServer(t *testing.T, failures int32) (*httptest.Server, *int32) {
+	t.Helper()
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/-/reload" {
+			t.Errorf("意外的请求: %s %s", r.Method, r.URL.Path)
+		}
+		if atomic.AddInt32(&calls, 1) <= failures {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestReloadPrometheusRetriesUntilSuccess(t *testing.T) {
+	old := reloadRetryDelay
+	reloadRetryDelay = time.Millisecond
+	t.Cleanup(func() { reloadRetryDelay = old })
+
+	srv, calls := newReloadServer(t, 1)
+	if err := ReloadPrometheus(context.Background(), srv.Client(), zap.NewNop(), srv.URL+"/"); err != nil {
+		t.Fatalf("重试后应重新加载成功: %v", err)
+	}
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Fatalf("期望请求 2 次，实际 %d 次", got)
+	}
+}
+
+func TestReloadPrometheusNon2xxReturnsError(t *testing.T) {
+	old := reloadRetryDelay
+	reloadRetryDelay = time.Millisecond
+	t.Cleanup(func() { reloadRetryDelay = old })
+
+	srv, calls := newReloadServer(t, maxReloadRetries)
+	if err := ReloadPrometheus(context.Background(), srv.Client(), zap.NewNop(), srv.URL); err == nil {
+		t.Fatal("非 2xx 响应应返回错误")
+	}
+	if got := atomic.LoadInt32(calls); got != maxReloadRetries {
+		t.Fatalf("期望请求 %d 次，实际 %d 次", maxReloadRetries, got)
+	}
+}
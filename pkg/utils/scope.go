@@ -0,0 +1,65 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+package utils
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 软删除约定：所有模型统一使用 int64 类型的 deleted_at 列，0 表示未删除，非 0 为删除时的 Unix 时间戳。
+// 不使用 0/1 布尔标记，否则 (业务字段, deleted_at) 联合唯一索引会导致同名记录无法被再次删除。
+
+// NotDeleted 返回过滤已软删除记录的查询条件，软删除约定为 deleted_at 非 0
+func NotDeleted() func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("deleted_at = ?", 0)
+	}
+}
+
+// Deleted 返回仅查询已软删除记录的查询条件
+func Deleted() func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("deleted_at <> ?", 0)
+	}
+}
+
+// SoftDeleteColumns 返回软删除记录时需要更新的列，deleted_at 记录删除时间
+func SoftDeleteColumns() map[string]interface{} {
+	now := time.Now().Unix()
+	return map[string]interface{}{
+		"deleted_at": now,
+		"updated_at": now,
+	}
+}
+
+// RestoreColumns 返回恢复已软删除记录时需要更新的列
+func RestoreColumns() map[string]interface{} {
+	return map[string]interface{}{
+		"deleted_at": 0,
+		"updated_at": time.Now().Unix(),
+	}
+}
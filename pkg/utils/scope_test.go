@@ -0,0 +1,91 @@
+/*
+ * MIT License
+ *
+ * Copyright (c) 2024 Bamboo
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+ *
+ */
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// softDeleteRecord 测试用的软删除模型
+type softDeleteRecord struct {
+	ID        int
+	Name      string
+	DeletedAt int64
+	UpdatedAt int64
+}
+
+func TestSoftDeleteScopes(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("打开测试数据库失败: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("获取数据库连接失败: %v", err)
+	}
+	// 内存库每个连接独立，限制为单连接保证数据可见
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(&softDeleteRecord{}); err != nil {
+		t.Fatalf("迁移测试表失败: %v", err)
+	}
+	for _, r := range []*softDeleteRecord{{Name: "live"}, {Name: "gone", DeletedAt: 100}} {
+		if err := db.Create(r).Error; err != nil {
+			t.Fatalf("写入测试数据失败: %v", err)
+		}
+	}
+
+	var live []softDeleteRecord
+	if err := db.Scopes(NotDeleted()).Find(&live).Error; err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if len(live) != 1 || live[0].Name != "live" {
+		t.Fatalf("NotDeleted 应排除已软删除的记录: %+v", live)
+	}
+
+	var deleted []softDeleteRecord
+	if err := db.Scopes(Deleted()).Find(&deleted).Error; err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0].Name != "gone" {
+		t.Fatalf("Deleted 应只返回已软删除的记录: %+v", deleted)
+	}
+
+	// 软删除后 NotDeleted 不再返回该记录
+	if err := db.Model(&softDeleteRecord{}).Where("id = ?", live[0].ID).Updates(SoftDeleteColumns()).Error; err != nil {
+		t.Fatalf("软删除失败: %v", err)
+	}
+	var count int64
+	if err := db.Model(&softDeleteRecord{}).Scopes(NotDeleted()).Count(&count).Error; err != nil {
+		t.Fatalf("统计失败: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("软删除后 NotDeleted 不应返回任何记录, 实际 %d 条", count)
+	}
+}